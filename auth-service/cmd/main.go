@@ -3,10 +3,13 @@ package main
 import (
 	"auth-service/internal/config"
 	"auth-service/internal/handlers"
+	"auth-service/internal/keys"
 	"auth-service/internal/middleware"
+	"auth-service/internal/repository"
 	postgres "auth-service/pkg/db/postgres"
 	"auth-service/pkg/db/redis"
 	"auth-service/pkg/logger"
+	"context"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 	"net/http"
@@ -40,6 +43,26 @@ func main() {
 	}
 	defer redisClient.Close()
 
+	// Initialize signing keys. The first instance to start creates a key; later ones
+	// are rotated with the rotate-keys command
+	keyCipher, err := keys.NewCipher(cfg.JWT.KeyEncryptionKey)
+	if err != nil {
+		log.Fatal("Invalid JWT_KEY_ENCRYPTION_KEY:", err)
+	}
+	signingKeys := repository.NewSigningKeyRepository(db.DB, keyCipher)
+	if n, err := signingKeys.EncryptPlaintextKeys(context.Background()); err != nil {
+		log.Fatal("Failed to encrypt signing keys:", err)
+	} else if n > 0 {
+		zapLogger.Info("Encrypted plaintext signing keys", "count", n)
+	}
+	err = signingKeys.EnsureKey(context.Background(), func() (*keys.Key, error) {
+		return keys.Generate(cfg.JWT.Algorithm)
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize signing keys:", err)
+	}
+	keySet := keys.NewKeySet(signingKeys, cfg.JWT.KeyRefresh)
+
 	r := echo.New()
 	if cfg.Environment == "production" {
 		// отключить debug-режим
@@ -69,22 +92,36 @@ func main() {
 	})
 
 	// Initialize handlers with JWT configuration and Redis
-	authHandler := handlers.NewAuthHandler(db, redisClient, []byte(cfg.JWT.Secret), cfg.JWT.TokenExpiry, zapLogger)
+	refreshTokens := repository.NewRefreshTokenRepository(db.DB)
+	apiKeys := repository.NewAPIKeyRepository(db.DB)
+	authHandler := handlers.NewAuthHandler(db, redisClient, refreshTokens, apiKeys, keySet, cfg.JWT.TokenExpiry, cfg.JWT.RefreshExpiry, zapLogger)
 
 	// Public routes
 	public := r.Group("/api/v1")
 	{
 		public.POST("/register", authHandler.Register)
 		public.POST("/login", authHandler.Login)
+		// The refresh token itself authenticates the request
+		public.POST("/refresh-token", authHandler.RefreshToken)
 	}
 
+	// Token introspection for other services. It is not under /api/v1, so the
+	// gateway does not expose it
+	r.POST("/auth/introspect", authHandler.Introspect)
+
+	// Public keys for verifying access tokens
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// Protected routes with JWT middleware
 	protected := r.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware([]byte(cfg.JWT.Secret), redisClient, zapLogger))
+	protected.Use(middleware.AuthMiddleware(keySet.Keyfunc, redisClient, zapLogger))
 	{
-		protected.POST("/refresh-token", authHandler.RefreshToken)
 		protected.POST("/logout", authHandler.Logout)
 		protected.GET("/profile", getUserProfile)
+		// API keys can only be managed with the access token of a login
+		protected.POST("/api-keys", authHandler.CreateAPIKey)
+		protected.GET("/api-keys", authHandler.ListAPIKeys)
+		protected.DELETE("/api-keys/:id", authHandler.RevokeAPIKey)
 	}
 
 	// Start server with configured host and port
@@ -106,10 +143,12 @@ func main() {
 func getUserProfile(c echo.Context) error {
 	userID := c.Get("user_id")
 	email := c.Get("email")
+	role := c.Get("role")
 
 	c.JSON(200, map[string]interface{}{
 		"user_id": userID,
 		"email":   email,
+		"role":    role,
 	})
 	return nil
 }
@@ -0,0 +1,57 @@
+// Command rotate-keys makes a new key the current signing key of auth-service. Previous
+// keys stop signing at once but keep verifying tokens for the overlap window, so tokens
+// issued before the rotation stay valid until they expire. Running instances pick up the
+// new key within JWT_KEY_REFRESH.
+//
+// Usage:
+//
+//	rotate-keys [-alg RS256|EdDSA] [-overlap 25h]
+package main
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/keys"
+	"auth-service/internal/repository"
+	postgres "auth-service/pkg/db/postgres"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	algorithm := flag.String("alg", cfg.JWT.Algorithm, "signing algorithm of the new key: RS256 or EdDSA")
+	overlap := flag.Duration("overlap", cfg.JWT.KeyOverlap, "how long previous keys keep verifying tokens")
+	flag.Parse()
+
+	if *overlap < cfg.JWT.TokenExpiry {
+		log.Fatalf("Overlap %s is shorter than the access token lifetime %s", *overlap, cfg.JWT.TokenExpiry)
+	}
+
+	key, err := keys.Generate(*algorithm)
+	if err != nil {
+		log.Fatal("Failed to generate key:", err)
+	}
+
+	keyCipher, err := keys.NewCipher(cfg.JWT.KeyEncryptionKey)
+	if err != nil {
+		log.Fatal("Invalid JWT_KEY_ENCRYPTION_KEY:", err)
+	}
+
+	db, err := postgres.NewDatabase(cfg.GetDSN())
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.DB.Close()
+
+	if err := repository.NewSigningKeyRepository(db.DB, keyCipher).Rotate(context.Background(), key, *overlap); err != nil {
+		log.Fatal("Failed to rotate signing key:", err)
+	}
+
+	fmt.Printf("Rotated signing key: kid=%s alg=%s, previous keys retire in %s\n", key.ID, key.Algorithm, *overlap)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"github.com/joho/godotenv"
 	"os"
@@ -25,9 +26,17 @@ type Config struct {
 	}
 
 	JWT struct {
-		Secret        string
+		Algorithm string
+		// TokenExpiry is kept short: services that verify access tokens against the JWKS
+		// do not see a logout, so a logged-out token works with them until it expires
 		TokenExpiry   time.Duration
 		RefreshExpiry time.Duration
+		// KeyOverlap is how long a rotated key keeps verifying tokens; it must cover TokenExpiry
+		KeyOverlap time.Duration
+		// KeyRefresh is how often keys rotated by another instance are picked up
+		KeyRefresh time.Duration
+		// KeyEncryptionKey encrypts the private signing keys stored in the database
+		KeyEncryptionKey []byte
 	}
 
 	Redis struct {
@@ -60,9 +69,28 @@ func Load() (*Config, error) {
 	cfg.Database.SSLMode = getEnv("PG_SSLMODE", "disable")
 
 	// JWT config
-	cfg.JWT.Secret = getEnv("JWT_SECRET", "your-secret-key")
-	cfg.JWT.TokenExpiry = time.Hour * 24    // 24 hours
+	cfg.JWT.Algorithm = getEnv("JWT_ALGORITHM", "RS256")
+	tokenExpiry, err := time.ParseDuration(getEnv("JWT_ACCESS_TOKEN_TTL", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_ACCESS_TOKEN_TTL: %w", err)
+	}
+	cfg.JWT.TokenExpiry = tokenExpiry
 	cfg.JWT.RefreshExpiry = time.Hour * 168 // 7 days
+	keyOverlap, err := time.ParseDuration(getEnv("JWT_KEY_OVERLAP", "25h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_KEY_OVERLAP: %w", err)
+	}
+	cfg.JWT.KeyOverlap = keyOverlap
+	keyRefresh, err := time.ParseDuration(getEnv("JWT_KEY_REFRESH", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_KEY_REFRESH: %w", err)
+	}
+	cfg.JWT.KeyRefresh = keyRefresh
+	kek, err := base64.StdEncoding.DecodeString(getEnv("JWT_KEY_ENCRYPTION_KEY", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_KEY_ENCRYPTION_KEY: %w", err)
+	}
+	cfg.JWT.KeyEncryptionKey = kek
 
 	// Redis config
 	cfg.Redis.Host = getEnv("REDIS_HOST", "localhost")
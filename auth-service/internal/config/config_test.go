@@ -16,18 +16,33 @@ func TestLoad_Defaults(t *testing.T) {
 	require.Equal(t, "0.0.0.0", cfg.Server.Host)
 	require.Equal(t, 15*time.Second, cfg.Server.ReadTimeout)
 	require.Equal(t, "localhost", cfg.Database.Host)
-	require.Equal(t, "your-secret-key", cfg.JWT.Secret)
+	require.Equal(t, "RS256", cfg.JWT.Algorithm)
+	require.Equal(t, 25*time.Hour, cfg.JWT.KeyOverlap)
 }
 
 func TestLoad_WithEnv(t *testing.T) {
 	t.Setenv("SERVER_PORT", "9000")
 	t.Setenv("DB_HOST", "postgres.internal")
-	t.Setenv("JWT_SECRET", "super‑secret")
+	t.Setenv("JWT_ALGORITHM", "EdDSA")
 
 	cfg, err := Load()
 	require.NoError(t, err)
 
 	require.Equal(t, "9000", cfg.Server.Port)
 	require.Equal(t, "postgres.internal", cfg.Database.Host)
-	require.Equal(t, "super‑secret", cfg.JWT.Secret)
+	require.Equal(t, "EdDSA", cfg.JWT.Algorithm)
+}
+
+func TestLoad_InvalidKeyOverlap(t *testing.T) {
+	t.Setenv("JWT_KEY_OVERLAP", "a day")
+
+	_, err := Load()
+	require.Error(t, err)
+}
+
+func TestLoad_InvalidKeyEncryptionKey(t *testing.T) {
+	t.Setenv("JWT_KEY_ENCRYPTION_KEY", "not base64!")
+
+	_, err := Load()
+	require.Error(t, err)
 }
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateAPIKey issues a personal API key for non-interactive clients such as CI jobs.
+// The key is returned only in this response
+func (h *AuthHandler) CreateAPIKey(c echo.Context) error {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Invalid API key data", "error", err)
+		c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Invalid API key data",
+			"details": err.Error(),
+		})
+		return nil
+	}
+	if err := req.Validate(); err != nil {
+		h.logger.Warn("Invalid API key data", "user_id", userID, "error", err)
+		c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Invalid API key data",
+			"details": err.Error(),
+		})
+		return nil
+	}
+
+	key, apiKey, err := h.apiKeys.Create(c.Request().Context(), userID, req)
+	if err != nil {
+		h.logger.Error("API key creation failed", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error":   "API key creation failed",
+			"details": err.Error(),
+		})
+		return nil
+	}
+
+	h.logger.Info("API key created", "user_id", userID, "api_key_id", apiKey.ID, "scopes", apiKey.Scopes)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"api_key": apiKey,
+		"key":     key,
+		"message": "Store the key now, it will not be shown again",
+	})
+	return nil
+}
+
+// ListAPIKeys lists the API keys of the current user without the keys themselves
+func (h *AuthHandler) ListAPIKeys(c echo.Context) error {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	keys, err := h.apiKeys.List(c.Request().Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list API keys", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error":   "Failed to list API keys",
+			"details": err.Error(),
+		})
+		return nil
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"api_keys": keys,
+	})
+	return nil
+}
+
+// RevokeAPIKey revokes an API key of the current user
+func (h *AuthHandler) RevokeAPIKey(c echo.Context) error {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "Invalid API key id",
+		})
+		return nil
+	}
+
+	err = h.apiKeys.Revoke(c.Request().Context(), userID, id)
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "API key not found",
+		})
+		return nil
+	}
+	if err != nil {
+		h.logger.Error("Failed to revoke API key", "user_id", userID, "api_key_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error":   "Failed to revoke API key",
+			"details": err.Error(),
+		})
+		return nil
+	}
+
+	h.logger.Info("API key revoked", "user_id", userID, "api_key_id", id)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "API key revoked",
+	})
+	return nil
+}
+
+// currentUser returns the id of the user authenticated by AuthMiddleware, answering
+// 401 when there is none
+func (h *AuthHandler) currentUser(c echo.Context) (int, bool) {
+	userID, ok := c.Get("user_id").(float64)
+	if !ok {
+		h.logger.Warn("User not authenticated")
+		c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"error": "User not authenticated",
+		})
+		return 0, false
+	}
+	return int(userID), true
+}
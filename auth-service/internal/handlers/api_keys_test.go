@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"auth-service/internal/repository"
+	"auth-service/pkg/logger"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupAPIKeys(t *testing.T) (*AuthHandler, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	ah := NewAuthHandler(nil, nil, nil, repository.NewAPIKeyRepository(sqlDB), testKeySet(t), time.Hour, 24*time.Hour, &logger.Logger{Logger: zap.NewNop()})
+	return ah, mock
+}
+
+func apiKeyContext(method, target, body string, userID interface{}) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if userID != nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	ah, mock := setupAPIKeys(t)
+
+	mock.ExpectQuery(`INSERT INTO api_keys`).
+		WithArgs(7, "ci", sqlmock.AnyArg(), sqlmock.AnyArg(), `{"tasks:write"}`, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+
+	c, rec := apiKeyContext(http.MethodPost, "/api-keys", `{"name":"ci","scopes":["tasks:write"]}`, float64(7))
+	require.NoError(t, ah.CreateAPIKey(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	var resp struct {
+		Key    string `json:"key"`
+		APIKey struct {
+			ID     int      `json:"id"`
+			Prefix string   `json:"prefix"`
+			Scopes []string `json:"scopes"`
+		} `json:"api_key"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.True(t, strings.HasPrefix(resp.Key, resp.APIKey.Prefix))
+	require.Equal(t, 3, resp.APIKey.ID)
+	require.Equal(t, []string{"tasks:write"}, resp.APIKey.Scopes)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAPIKey_Invalid(t *testing.T) {
+	ah, _ := setupAPIKeys(t)
+
+	c, rec := apiKeyContext(http.MethodPost, "/api-keys", `{"name":"ci","scopes":["admin"]}`, float64(7))
+	require.NoError(t, ah.CreateAPIKey(c))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	c, rec = apiKeyContext(http.MethodPost, "/api-keys", `{"name":"ci","scopes":["tasks:write"]}`, nil)
+	require.NoError(t, ah.CreateAPIKey(c))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRevokeAPIKey_NotFound(t *testing.T) {
+	ah, mock := setupAPIKeys(t)
+
+	mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+		WithArgs(3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	c, rec := apiKeyContext(http.MethodDelete, "/api-keys/3", "", float64(7))
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+	require.NoError(t, ah.RevokeAPIKey(c))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIntrospect_APIKey(t *testing.T) {
+	ah, mock := setupAPIKeys(t)
+	expiresAt := time.Unix(1800000000, 0)
+
+	mock.ExpectQuery(`UPDATE api_keys k SET last_used_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "email", "role", "scopes", "expires_at"}).
+			AddRow(7, "bob@mail.com", "user", "{tasks:write,templates:read}", expiresAt))
+	mock.ExpectQuery(`UPDATE api_keys k SET last_used_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "email", "role", "scopes", "expires_at"}))
+
+	body := url.Values{"token": {"tsk_valid"}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/auth/introspect", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	require.NoError(t, ah.Introspect(echo.New().NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{
+		"active": true,
+		"sub": "7",
+		"user_id": "7",
+		"username": "bob@mail.com",
+		"role": "user",
+		"token_type": "api_key",
+		"scope": "tasks:write templates:read",
+		"exp": 1800000000
+	}`, rec.Body.String())
+
+	body = url.Values{"token": {"tsk_revoked"}}.Encode()
+	req = httptest.NewRequest(http.MethodPost, "/auth/introspect", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec = httptest.NewRecorder()
+	require.NoError(t, ah.Introspect(echo.New().NewContext(req, rec)))
+	require.JSONEq(t, `{"active":false}`, rec.Body.String())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"auth-service/internal/keys"
 	"auth-service/internal/models"
+	"auth-service/internal/repository"
 	"auth-service/internal/utils"
 	postgres "auth-service/pkg/db/postgres"
 	"auth-service/pkg/db/redis"
 	"auth-service/pkg/logger"
 	"context"
 	"database/sql"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -17,21 +20,27 @@ import (
 )
 
 type AuthHandler struct {
-	db              *postgres.Database
-	redis           *redis.Redis
-	jwtSecret       []byte
-	tokenExpiration time.Duration
-	logger          *logger.Logger
+	db                *postgres.Database
+	redis             *redis.Redis
+	refreshTokens     *repository.RefreshTokenRepository
+	apiKeys           *repository.APIKeyRepository
+	keys              *keys.KeySet
+	tokenExpiration   time.Duration
+	refreshExpiration time.Duration
+	logger            *logger.Logger
 }
 
 // NewAuthHandler creates a new authentication handler
-func NewAuthHandler(db *postgres.Database, redis *redis.Redis, jwtSecret []byte, tokenExpiration time.Duration, logger *logger.Logger) *AuthHandler {
+func NewAuthHandler(db *postgres.Database, redis *redis.Redis, refreshTokens *repository.RefreshTokenRepository, apiKeys *repository.APIKeyRepository, keySet *keys.KeySet, tokenExpiration, refreshExpiration time.Duration, logger *logger.Logger) *AuthHandler {
 	return &AuthHandler{
-		db:              db,
-		redis:           redis,
-		jwtSecret:       jwtSecret,
-		tokenExpiration: tokenExpiration,
-		logger:          logger,
+		db:                db,
+		redis:             redis,
+		refreshTokens:     refreshTokens,
+		apiKeys:           apiKeys,
+		keys:              keySet,
+		tokenExpiration:   tokenExpiration,
+		refreshExpiration: refreshExpiration,
+		logger:            logger,
 	}
 }
 
@@ -151,11 +160,11 @@ func (h *AuthHandler) Login(c echo.Context) error {
 	// Get user from database
 	var user models.User
 	err := h.db.DB.QueryRow(`
-        SELECT id, email, password_hash 
+        SELECT id, email, role, password_hash 
         FROM users 
         WHERE email = $1`,
 		login.Email,
-	).Scan(&user.ID, &user.Email, &user.PasswordHash)
+	).Scan(&user.ID, &user.Email, &user.Role, &user.PasswordHash)
 
 	if err == sql.ErrNoRows {
 		h.logger.Warn("Invalid login attempt", "email", login.Email)
@@ -182,79 +191,64 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return nil
 	}
 
-	// Generate JWT with claims
-	now := time.Now()
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"iat":     now.Unix(),
-		"exp":     now.Add(h.tokenExpiration).Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(h.jwtSecret)
+	refreshToken, familyID, err := h.refreshTokens.Create(c.Request().Context(), user.ID, h.refreshExpiration)
 	if err != nil {
-		h.logger.Error("Token generation failed", "user_id", user.ID, "error", err)
+		h.logger.Error("Failed to issue refresh token", "user_id", user.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Token generation failed",
+			"error":   "Failed to issue refresh token",
 			"details": err.Error(),
 		})
 		return nil
 	}
 
-	// Save token in Redis with expiration
-	ctx := context.Background()
-	err = h.redis.Client.Set(ctx, tokenString, user.ID, h.tokenExpiration).Err()
+	tokenString, err := h.issueAccessToken(c.Request().Context(), user.ID, user.Email, user.Role, familyID)
 	if err != nil {
-		h.logger.Error("Failed to save token in Redis", "user_id", user.ID, "error", err)
+		h.logger.Error("Token generation failed", "user_id", user.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to save token",
+			"error":   "Token generation failed",
 			"details": err.Error(),
 		})
 		return nil
 	}
 
 	h.logger.Info("User logged in successfully", "user_id", user.ID, "email", user.Email)
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"token":      tokenString,
-		"expires_in": h.tokenExpiration.Seconds(),
-		"token_type": "Bearer",
-	})
+	c.JSON(http.StatusOK, h.tokenResponse(tokenString, refreshToken))
 	return nil
 }
 
-// RefreshToken generates a new token for valid users
+// RefreshToken exchanges a refresh token for a new access token and a new refresh token.
+// Each refresh token works once; presenting a used one revokes the whole login
 func (h *AuthHandler) RefreshToken(c echo.Context) error {
-	userID, ok := c.Get("user_id").(float64)
-	if !ok {
-		h.logger.Warn("User not authenticated")
-		c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"error": "User not authenticated",
+	var req models.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		h.logger.Warn("Refresh token missing")
+		c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "Refresh token missing",
 		})
 		return nil
 	}
 
-	email, ok := c.Get("email").(string)
-	if !ok {
-		h.logger.Warn("Invalid user data")
+	ctx := c.Request().Context()
+	session, refreshToken, err := h.refreshTokens.Rotate(ctx, req.RefreshToken, h.refreshExpiration)
+	if errors.Is(err, repository.ErrRefreshTokenReused) {
+		h.logger.Warn("Refresh token reused, revoking session", "user_id", session.UserID)
+		if err := h.revokeSession(ctx, session.FamilyID); err != nil {
+			h.logger.Error("Failed to revoke access tokens of session", "user_id", session.UserID, "error", err)
+		}
 		c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"error": "Invalid user data",
+			"error": "Invalid refresh token",
 		})
 		return nil
 	}
-
-	now := time.Now()
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"email":   email,
-		"iat":     now.Unix(),
-		"exp":     now.Add(h.tokenExpiration).Unix(),
+	if errors.Is(err, repository.ErrInvalidRefreshToken) {
+		h.logger.Warn("Invalid refresh token")
+		c.JSON(http.StatusUnauthorized, map[string]interface{}{
+			"error": "Invalid refresh token",
+		})
+		return nil
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(h.jwtSecret)
 	if err != nil {
-		h.logger.Error("Token refresh failed", "user_id", userID, "error", err)
+		h.logger.Error("Token refresh failed", "error", err)
 		c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"error":   "Token refresh failed",
 			"details": err.Error(),
@@ -262,27 +256,22 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 		return nil
 	}
 
-	ctx := context.Background()
-	err = h.redis.Client.Set(ctx, tokenString, userID, h.tokenExpiration).Err()
+	tokenString, err := h.issueAccessToken(ctx, session.UserID, session.Email, session.Role, session.FamilyID)
 	if err != nil {
-		h.logger.Error("Failed to save refreshed token", "user_id", userID, "error", err)
+		h.logger.Error("Token refresh failed", "user_id", session.UserID, "error", err)
 		c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":   "Failed to save refreshed token",
+			"error":   "Token refresh failed",
 			"details": err.Error(),
 		})
 		return nil
 	}
 
-	h.logger.Info("Token refreshed successfully", "user_id", userID, "email", email)
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"token":      tokenString,
-		"expires_in": h.tokenExpiration.Seconds(),
-		"token_type": "Bearer",
-	})
+	h.logger.Info("Token refreshed successfully", "user_id", session.UserID, "email", session.Email)
+	c.JSON(http.StatusOK, h.tokenResponse(tokenString, refreshToken))
 	return nil
 }
 
-// Logout invalidates the token
+// Logout invalidates the access token and every token of its login
 func (h *AuthHandler) Logout(c echo.Context) error {
 	authHeader := c.Request().Header.Get("Authorization")
 	if authHeader == "" {
@@ -303,7 +292,7 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 	}
 
 	tokenString := parts[1]
-	ctx := context.Background()
+	ctx := c.Request().Context()
 	err := h.redis.Client.Del(ctx, tokenString).Err()
 	if err != nil {
 		h.logger.Error("Failed to invalidate token", "error", err)
@@ -314,6 +303,26 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 		return nil
 	}
 
+	// Tokens issued before refresh tokens existed have no session
+	if familyID, ok := c.Get("sid").(string); ok && familyID != "" {
+		if err := h.refreshTokens.RevokeFamily(ctx, familyID); err != nil {
+			h.logger.Error("Failed to revoke refresh tokens", "error", err)
+			c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error":   "Failed to revoke refresh tokens",
+				"details": err.Error(),
+			})
+			return nil
+		}
+		if err := h.revokeSession(ctx, familyID); err != nil {
+			h.logger.Error("Failed to invalidate session tokens", "error", err)
+			c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error":   "Failed to invalidate token",
+				"details": err.Error(),
+			})
+			return nil
+		}
+	}
+
 	h.logger.Info("User logged out successfully")
 	c.JSON(http.StatusOK, map[string]interface{}{
 		"message":      "Successfully logged out",
@@ -321,3 +330,54 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 	})
 	return nil
 }
+
+// issueAccessToken signs an access token for the login identified by familyID and
+// registers it in Redis, both as an active token and as a token of the session
+func (h *AuthHandler) issueAccessToken(ctx context.Context, userID int, email, role, familyID string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"email":   email,
+		"role":    role,
+		"sid":     familyID,
+		"iat":     now.Unix(),
+		"exp":     now.Add(h.tokenExpiration).Unix(),
+	}
+
+	tokenString, err := h.keys.Sign(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+
+	pipe := h.redis.Client.TxPipeline()
+	pipe.Set(ctx, tokenString, userID, h.tokenExpiration)
+	pipe.SAdd(ctx, sessionKey(familyID), tokenString)
+	pipe.Expire(ctx, sessionKey(familyID), h.refreshExpiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return tokenString, nil
+}
+
+// revokeSession invalidates every access token issued for the login identified by familyID
+func (h *AuthHandler) revokeSession(ctx context.Context, familyID string) error {
+	tokens, err := h.redis.Client.SMembers(ctx, sessionKey(familyID)).Result()
+	if err != nil {
+		return err
+	}
+	return h.redis.Client.Del(ctx, append(tokens, sessionKey(familyID))...).Err()
+}
+
+func (h *AuthHandler) tokenResponse(accessToken, refreshToken string) map[string]interface{} {
+	return map[string]interface{}{
+		"token":              accessToken,
+		"expires_in":         h.tokenExpiration.Seconds(),
+		"token_type":         "Bearer",
+		"refresh_token":      refreshToken,
+		"refresh_expires_in": h.refreshExpiration.Seconds(),
+	}
+}
+
+func sessionKey(familyID string) string {
+	return "session:" + familyID
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"auth-service/internal/repository"
 	"auth-service/internal/utils"
 	database "auth-service/pkg/db/postgres"
 	"auth-service/pkg/db/redis"
@@ -51,7 +52,8 @@ func setupAuth(t *testing.T) (*AuthHandler, sqlmock.Sqlmock, *echo.Echo, *MockLo
 
 	db := &database.Database{DB: sqlDB}
 	rd, _ := redis.NewRedis("localhost:6379", "", 0) // real Redis не нужен: только client.Set/Del/Get
-	ah := NewAuthHandler(db, rd, []byte("secret"), time.Hour, logger)
+	keySet := testKeySet(t)
+	ah := NewAuthHandler(db, rd, repository.NewRefreshTokenRepository(sqlDB), repository.NewAPIKeyRepository(sqlDB), keySet, time.Hour, 24*time.Hour, logger)
 
 	e := echo.New()
 	return ah, mock, e, mockLogger
@@ -95,10 +97,14 @@ func TestLogin_Success(t *testing.T) {
 	hash, _ := utils.HashPassword(plaintext)
 
 	// SELECT user
-	mock.ExpectQuery(`SELECT id, email, password_hash`).
+	mock.ExpectQuery(`SELECT id, email, role, password_hash`).
 		WithArgs("bob@mail.com").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash"}).
-			AddRow(7, "bob@mail.com", hash))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "password_hash"}).
+			AddRow(7, "bob@mail.com", "user", hash))
+
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), 7, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	reqBody := `{"email":"bob@mail.com","password":"P@ssw0rd!"}`
 	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(reqBody))
@@ -114,8 +120,8 @@ func TestLogin_Success(t *testing.T) {
 	}
 	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
 
-	// токен должен валидироваться на том же секрете
-	tk, err := jwt.Parse(resp.Token, func(t *jwt.Token) (interface{}, error) { return []byte("secret"), nil })
+	// токен должен валидироваться ключом из того же набора
+	tk, err := jwt.Parse(resp.Token, ah.keys.Keyfunc)
 	require.NoError(t, err)
 	require.True(t, tk.Valid)
 
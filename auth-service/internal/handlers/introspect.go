@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"auth-service/internal/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Introspect tells other services whether an access token or API key is active (RFC 7662).
+// The token is sent in a form or JSON body, so that it never ends up in access logs
+func (h *AuthHandler) Introspect(c echo.Context) error {
+	var req models.IntrospectionRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		h.logger.Warn("Invalid introspection request")
+		c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid_request",
+		})
+		return nil
+	}
+
+	// Introspection responses must not be cached by intermediaries
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+
+	if utils.IsAPIKey(req.Token) {
+		return h.introspectAPIKey(c, req.Token)
+	}
+
+	claims, err := utils.ParseToken(req.Token, h.keys.Keyfunc)
+	if err != nil {
+		h.logger.Info("Introspected inactive token", "error", err)
+		c.JSON(http.StatusOK, models.IntrospectionResponse{Active: false})
+		return nil
+	}
+
+	// Logout deletes the token from Redis, so a signed token missing there is revoked
+	err = h.redis.Client.Get(c.Request().Context(), req.Token).Err()
+	if errors.Is(err, goredis.Nil) {
+		h.logger.Info("Introspected revoked token")
+		c.JSON(http.StatusOK, models.IntrospectionResponse{Active: false})
+		return nil
+	}
+	if err != nil {
+		h.logger.Error("Failed to verify token", "error", err)
+		c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error":   "Failed to verify token",
+			"details": err.Error(),
+		})
+		return nil
+	}
+
+	c.JSON(http.StatusOK, introspectionResponse(claims))
+	return nil
+}
+
+// introspectAPIKey describes an API key. Unlike access tokens, API keys are limited to
+// their scopes
+func (h *AuthHandler) introspectAPIKey(c echo.Context, key string) error {
+	owner, err := h.apiKeys.Authenticate(c.Request().Context(), key)
+	if errors.Is(err, repository.ErrInvalidAPIKey) {
+		h.logger.Info("Introspected inactive API key")
+		c.JSON(http.StatusOK, models.IntrospectionResponse{Active: false})
+		return nil
+	}
+	if err != nil {
+		h.logger.Error("Failed to verify API key", "error", err)
+		c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error":   "Failed to verify token",
+			"details": err.Error(),
+		})
+		return nil
+	}
+
+	c.JSON(http.StatusOK, apiKeyIntrospectionResponse(owner))
+	return nil
+}
+
+// apiKeyIntrospectionResponse describes an active API key from its owner
+func apiKeyIntrospectionResponse(owner *models.APIKeyOwner) models.IntrospectionResponse {
+	resp := models.IntrospectionResponse{
+		Active:    true,
+		UserID:    strconv.Itoa(owner.UserID),
+		Username:  owner.Email,
+		Role:      owner.Role,
+		TokenType: models.TokenTypeAPIKey,
+		Scope:     strings.Join(owner.Scopes, " "),
+	}
+	resp.Subject = resp.UserID
+	if owner.ExpiresAt != nil {
+		resp.ExpiresAt = owner.ExpiresAt.Unix()
+	}
+	return resp
+}
+
+// introspectionResponse describes an active token from its claims
+func introspectionResponse(claims jwt.MapClaims) models.IntrospectionResponse {
+	resp := models.IntrospectionResponse{Active: true, TokenType: "Bearer"}
+
+	switch id := claims["user_id"].(type) {
+	case float64:
+		resp.UserID = strconv.FormatFloat(id, 'f', -1, 64)
+	case string:
+		resp.UserID = id
+	case nil:
+	default:
+		resp.UserID = fmt.Sprint(id)
+	}
+	resp.Subject = resp.UserID
+	resp.Username, _ = claims["email"].(string)
+	// Tokens issued before roles existed belong to regular users
+	resp.Role, _ = claims["role"].(string)
+	if resp.Role == "" {
+		resp.Role = models.RoleUser
+	}
+
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		resp.ExpiresAt = exp.Unix()
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		resp.IssuedAt = iat.Unix()
+	}
+	return resp
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"auth-service/internal/keys"
+	"auth-service/pkg/logger"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type staticKeys []keys.Key
+
+func (s staticKeys) ActiveKeys(context.Context) ([]keys.Key, error) {
+	return s, nil
+}
+
+func testKeySet(t *testing.T) *keys.KeySet {
+	key, err := keys.Generate(keys.AlgorithmEdDSA)
+	require.NoError(t, err)
+	return keys.NewKeySet(staticKeys{*key}, time.Hour)
+}
+
+func introspect(t *testing.T, contentType, body string) *httptest.ResponseRecorder {
+	ah := NewAuthHandler(nil, nil, nil, nil, testKeySet(t), time.Hour, 24*time.Hour, &logger.Logger{Logger: zap.NewNop()})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/introspect", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec := httptest.NewRecorder()
+	require.NoError(t, ah.Introspect(echo.New().NewContext(req, rec)))
+	return rec
+}
+
+func TestIntrospect_InvalidRequest(t *testing.T) {
+	rec := introspect(t, echo.MIMEApplicationForm, "token_type_hint=access_token")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.JSONEq(t, `{"error":"invalid_request"}`, rec.Body.String())
+}
+
+func TestIntrospect_Inactive(t *testing.T) {
+	expired, err := testKeySet(t).Sign(context.Background(), jwt.MapClaims{
+		"user_id": 7,
+		"exp":     time.Now().Add(-time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+	// Signed with a key auth-service does not know
+	unknown, err := testKeySet(t).Sign(context.Background(), jwt.MapClaims{
+		"user_id": 7,
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+
+	for _, token := range []string{expired, unknown, "not.a.token"} {
+		rec := introspect(t, echo.MIMEApplicationForm, url.Values{"token": {token}}.Encode())
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		require.JSONEq(t, `{"active":false}`, rec.Body.String())
+	}
+
+	rec := introspect(t, echo.MIMEApplicationJSON, `{"token":"not.a.token"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"active":false}`, rec.Body.String())
+}
+
+func TestIntrospectionResponse(t *testing.T) {
+	resp := introspectionResponse(jwt.MapClaims{
+		"user_id": float64(42),
+		"email":   "bob@mail.com",
+		"role":    "admin",
+		"iat":     float64(1700000000),
+		"exp":     float64(1700086400),
+	})
+
+	require.True(t, resp.Active)
+	require.Equal(t, "42", resp.UserID)
+	require.Equal(t, "42", resp.Subject)
+	require.Equal(t, "bob@mail.com", resp.Username)
+	require.Equal(t, "admin", resp.Role)
+	require.Equal(t, "Bearer", resp.TokenType)
+	require.Equal(t, int64(1700000000), resp.IssuedAt)
+	require.Equal(t, int64(1700086400), resp.ExpiresAt)
+}
+
+func TestIntrospectionResponse_DefaultRole(t *testing.T) {
+	resp := introspectionResponse(jwt.MapClaims{"user_id": float64(42)})
+	require.Equal(t, "user", resp.Role)
+}
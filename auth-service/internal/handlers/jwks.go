@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JWKS publishes the public keys access tokens are signed with (RFC 7517), so that other
+// services can verify tokens without a shared secret. Retired keys stay listed until the
+// tokens they signed have expired
+func (h *AuthHandler) JWKS(c echo.Context) error {
+	set, err := h.keys.JWKS(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to load signing keys", "error", err)
+		c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error":   "Failed to load signing keys",
+			"details": err.Error(),
+		})
+		return nil
+	}
+
+	// Verifiers refetch the set when they see an unknown kid, so a short max-age is enough
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+	return nil
+}
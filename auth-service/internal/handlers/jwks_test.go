@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"auth-service/internal/keys"
+	"auth-service/pkg/logger"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestJWKS(t *testing.T) {
+	current, err := keys.Generate(keys.AlgorithmEdDSA)
+	require.NoError(t, err)
+	previous, err := keys.Generate(keys.AlgorithmRS256)
+	require.NoError(t, err)
+	retiresAt := time.Now().Add(time.Hour)
+	previous.RetiresAt = &retiresAt
+
+	keySet := keys.NewKeySet(staticKeys{*current, *previous}, time.Hour)
+	ah := NewAuthHandler(nil, nil, nil, nil, keySet, time.Hour, 24*time.Hour, &logger.Logger{Logger: zap.NewNop()})
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, ah.JWKS(echo.New().NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var set keys.JWKSet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 2)
+	kids := []string{set.Keys[0].KeyID, set.Keys[1].KeyID}
+	require.ElementsMatch(t, []string{current.ID, previous.ID}, kids)
+	require.NotContains(t, rec.Body.String(), `"d":`)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"auth-service/internal/repository"
+	"auth-service/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func refresh(t *testing.T, body string, expect func(mock sqlmock.Sqlmock)) *httptest.ResponseRecorder {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	expect(mock)
+
+	ah := NewAuthHandler(nil, nil, repository.NewRefreshTokenRepository(sqlDB), nil, testKeySet(t), time.Hour, 24*time.Hour, &logger.Logger{Logger: zap.NewNop()})
+
+	req := httptest.NewRequest(http.MethodPost, "/refresh-token", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	require.NoError(t, ah.RefreshToken(echo.New().NewContext(req, rec)))
+	require.NoError(t, mock.ExpectationsWereMet())
+	return rec
+}
+
+func TestRefreshToken_Missing(t *testing.T) {
+	rec := refresh(t, `{}`, func(sqlmock.Sqlmock) {})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRefreshToken_Unknown(t *testing.T) {
+	rec := refresh(t, `{"refresh_token":"unknown"}`, func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT rt.id, rt.family_id`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "family_id", "user_id", "email", "role", "expires_at", "used_at", "revoked_at"}))
+		mock.ExpectRollback()
+	})
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.JSONEq(t, `{"error":"Invalid refresh token"}`, rec.Body.String())
+}
@@ -0,0 +1,77 @@
+package keys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marks a private key encrypted by Cipher, as opposed to a plaintext PEM
+// block stored before keys were encrypted
+const sealedPrefix = "v1:"
+
+// ErrKeyEncryptionKey is returned for a key-encryption key that is not 32 bytes long
+var ErrKeyEncryptionKey = errors.New("key-encryption key must be 32 bytes")
+
+// Cipher encrypts private keys at rest with AES-256-GCM under a key-encryption key kept
+// out of the database. The kid is authenticated with every key, so a sealed key cannot
+// be moved to the row of another kid
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a 32-byte key-encryption key
+func NewCipher(kek []byte) (*Cipher, error) {
+	if len(kek) != 32 {
+		return nil, ErrKeyEncryptionKey
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Seal encrypts the encoded private key of kid
+func (c *Cipher) Seal(kid, encoded string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(encoded), []byte(kid))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a private key sealed for kid
+func (c *Cipher) Open(kid, sealed string) (string, error) {
+	encoded, ok := strings.CutPrefix(sealed, sealedPrefix)
+	if !ok {
+		return "", errors.New("private key is not encrypted")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < c.aead.NonceSize() {
+		return "", errors.New("encrypted private key is too short")
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return "", fmt.Errorf("decrypt private key %s: %w", kid, err)
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether a stored private key is encrypted
+func IsSealed(stored string) bool {
+	return strings.HasPrefix(stored, sealedPrefix)
+}
@@ -0,0 +1,144 @@
+// Package keys holds the asymmetric keys auth-service signs access tokens with.
+// Every key is identified by a kid, so verifiers can pick the right public key
+// from the published JWKS while keys are rotated
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 2048
+
+// Key is a signing key. Keys without RetiresAt are current; a retiring key is no longer
+// used for signing but still verifies the tokens it signed until RetiresAt
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	CreatedAt  time.Time
+	RetiresAt  *time.Time
+}
+
+// JWK is the public part of a key as published in the JWKS (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Generate creates a new key for the algorithm
+func Generate(algorithm string) (*Key, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{Algorithm: algorithm, PrivateKey: private, CreatedAt: time.Now().UTC()}
+	if key.ID, err = thumbprint(private.Public()); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// SigningMethod returns the JWT signing method of the key
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// JWK returns the public part of the key
+func (k *Key) JWK() JWK {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+	switch public := k.PrivateKey.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// MarshalPrivateKey encodes the private key as a PKCS #8 PEM block for storage
+func (k *Key) MarshalPrivateKey() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParsePrivateKey decodes a private key stored by MarshalPrivateKey
+func ParsePrivateKey(encoded string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		return private, nil
+	case ed25519.PrivateKey:
+		return private, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+}
+
+// thumbprint derives the kid from the public key, so that the same key always has the same id
+func thumbprint(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
@@ -0,0 +1,58 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := Generate(algorithm)
+			require.NoError(t, err)
+			require.NotEmpty(t, key.ID)
+			require.Equal(t, algorithm, key.SigningMethod().Alg())
+
+			encoded, err := key.MarshalPrivateKey()
+			require.NoError(t, err)
+			parsed, err := ParsePrivateKey(encoded)
+			require.NoError(t, err)
+			require.True(t, parsed.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(key.PrivateKey.Public()))
+
+			id, err := thumbprint(parsed.Public())
+			require.NoError(t, err)
+			require.Equal(t, key.ID, id)
+		})
+	}
+
+	_, err := Generate("HS256")
+	require.Error(t, err)
+}
+
+func TestJWK(t *testing.T) {
+	rsaKey, err := Generate(AlgorithmRS256)
+	require.NoError(t, err)
+	jwk := rsaKey.JWK()
+	require.Equal(t, "RSA", jwk.KeyType)
+	require.Equal(t, rsaKey.ID, jwk.KeyID)
+	require.Equal(t, "sig", jwk.Use)
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	require.NoError(t, err)
+	require.Equal(t, 0, new(big.Int).SetBytes(n).Cmp(rsaKey.PrivateKey.Public().(*rsa.PublicKey).N))
+	require.Equal(t, "AQAB", jwk.E)
+
+	edKey, err := Generate(AlgorithmEdDSA)
+	require.NoError(t, err)
+	jwk = edKey.JWK()
+	require.Equal(t, "OKP", jwk.KeyType)
+	require.Equal(t, "Ed25519", jwk.Curve)
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	require.NoError(t, err)
+	require.Equal(t, []byte(edKey.PrivateKey.Public().(ed25519.PublicKey)), x)
+}
@@ -0,0 +1,149 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNoSigningKey is returned when there is no current key to sign with
+var ErrNoSigningKey = errors.New("no signing key")
+
+// minReload limits how often tokens with unknown kids or failed reloads can hit the source
+const minReload = 5 * time.Second
+
+// Source loads the keys that are current or still in their overlap window
+type Source interface {
+	ActiveKeys(ctx context.Context) ([]Key, error)
+}
+
+// KeySet signs tokens with the newest key and verifies them with any key that has not
+// retired yet. Keys are loaded from the source and reloaded every refresh interval, so
+// a key rotated by another instance is picked up without a restart
+type KeySet struct {
+	source  Source
+	refresh time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	keys     []Key
+	loadedAt time.Time
+	retryAt  time.Time
+}
+
+// NewKeySet creates a key set that reloads keys from source every refresh interval
+func NewKeySet(source Source, refresh time.Duration) *KeySet {
+	return &KeySet{source: source, refresh: refresh, now: time.Now}
+}
+
+// Sign signs claims with the current key and sets its kid in the token header
+func (s *KeySet) Sign(ctx context.Context, claims jwt.Claims) (string, error) {
+	keys, err := s.active(ctx, false)
+	if err != nil {
+		return "", err
+	}
+
+	var current *Key
+	for i := range keys {
+		if keys[i].RetiresAt == nil {
+			current = &keys[i]
+			break
+		}
+	}
+	if current == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(current.SigningMethod(), claims)
+	token.Header["kid"] = current.ID
+	return token.SignedString(current.PrivateKey)
+}
+
+// Keyfunc returns the public key a token was signed with, for use with jwt.Parse
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	key, err := s.find(context.Background(), kid, false)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		// The key may have been rotated in by another instance since the last reload
+		if key, err = s.find(context.Background(), kid, true); err != nil {
+			return nil, err
+		}
+	}
+	if key == nil {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("signing method does not match the key")
+	}
+	return key.PrivateKey.Public(), nil
+}
+
+// JWKS returns the public keys of all keys that are not retired yet
+func (s *KeySet) JWKS(ctx context.Context) (JWKSet, error) {
+	keys, err := s.active(ctx, false)
+	if err != nil {
+		return JWKSet{}, err
+	}
+
+	set := JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for i := range keys {
+		set.Keys = append(set.Keys, keys[i].JWK())
+	}
+	return set, nil
+}
+
+func (s *KeySet) find(ctx context.Context, kid string, reload bool) (*Key, error) {
+	keys, err := s.active(ctx, reload)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].ID == kid {
+			return &keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// active returns the cached keys without those past their overlap window, reloading them
+// when they are stale or a reload is requested
+func (s *KeySet) active(ctx context.Context, reload bool) ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	stale := s.loadedAt.IsZero() || now.Sub(s.loadedAt) >= s.refresh
+	if (stale || reload) && !now.Before(s.retryAt) {
+		s.retryAt = now.Add(minReload)
+		keys, err := s.source.ActiveKeys(ctx)
+		switch {
+		case err != nil && s.loadedAt.IsZero():
+			return nil, err
+		case err == nil:
+			// Newest first, so the current key is the first one without RetiresAt
+			sort.SliceStable(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+			s.keys = keys
+			s.loadedAt = now
+		}
+		// When a reload fails the loaded keys are used until the next attempt
+	}
+
+	active := make([]Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		if key.RetiresAt == nil || now.Before(*key.RetiresAt) {
+			active = append(active, key)
+		}
+	}
+	return active, nil
+}
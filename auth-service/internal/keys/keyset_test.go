@@ -0,0 +1,136 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	keys  []Key
+	err   error
+	loads int
+}
+
+func (s *fakeSource) ActiveKeys(context.Context) ([]Key, error) {
+	s.loads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]Key(nil), s.keys...), nil
+}
+
+func newKey(t *testing.T, algorithm string, createdAt time.Time) Key {
+	key, err := Generate(algorithm)
+	require.NoError(t, err)
+	key.CreatedAt = createdAt
+	return *key
+}
+
+func parse(s *KeySet, token string) error {
+	_, err := jwt.Parse(token, s.Keyfunc)
+	return err
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := newKey(t, AlgorithmRS256, now.Add(-48*time.Hour))
+	source := &fakeSource{keys: []Key{old}}
+	set := NewKeySet(source, time.Minute)
+	set.now = func() time.Time { return now }
+
+	oldToken, err := set.Sign(context.Background(), jwt.MapClaims{"user_id": 7})
+	require.NoError(t, err)
+	require.NoError(t, parse(set, oldToken))
+
+	// Another instance rotates in a new key; the old one stays valid for an hour
+	current := newKey(t, AlgorithmEdDSA, now)
+	retiresAt := now.Add(time.Hour)
+	old.RetiresAt = &retiresAt
+	source.keys = []Key{old, current}
+
+	newToken, err := NewKeySet(source, time.Minute).Sign(context.Background(), jwt.MapClaims{"user_id": 7})
+	require.NoError(t, err)
+
+	// Unknown kid forces a reload once the cached keys are older than minReload
+	set.now = func() time.Time { return now.Add(10 * time.Second) }
+	require.NoError(t, parse(set, newToken))
+	require.NoError(t, parse(set, oldToken))
+
+	signed, err := set.Sign(context.Background(), jwt.MapClaims{"user_id": 7})
+	require.NoError(t, err)
+	token, _, err := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	require.NoError(t, err)
+	require.Equal(t, current.ID, token.Header["kid"])
+
+	jwks, err := set.JWKS(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 2)
+
+	// After the overlap window the old key no longer verifies
+	set.now = func() time.Time { return now.Add(2 * time.Hour) }
+	require.Error(t, parse(set, oldToken))
+	require.NoError(t, parse(set, newToken))
+	jwks, err = set.JWKS(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 1)
+}
+
+func TestKeySet_UnknownKidReloadIsLimited(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &fakeSource{keys: []Key{newKey(t, AlgorithmEdDSA, now)}}
+	set := NewKeySet(source, time.Minute)
+	set.now = func() time.Time { return now }
+
+	unknown, err := NewKeySet(&fakeSource{keys: []Key{newKey(t, AlgorithmEdDSA, now)}}, time.Minute).
+		Sign(context.Background(), jwt.MapClaims{"user_id": 7})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.Error(t, parse(set, unknown))
+	}
+	require.Equal(t, 1, source.loads)
+}
+
+func TestKeySet_NoSigningKey(t *testing.T) {
+	_, err := NewKeySet(&fakeSource{}, time.Minute).Sign(context.Background(), jwt.MapClaims{})
+	require.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestKeySet_AlgorithmMismatch(t *testing.T) {
+	key := newKey(t, AlgorithmRS256, time.Now())
+	set := NewKeySet(&fakeSource{keys: []Key{key}}, time.Minute)
+
+	// A token claiming another algorithm than the key it names is rejected
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{})
+	token.Header["kid"] = key.ID
+	_, err := set.Keyfunc(token)
+	require.Error(t, err)
+}
+
+func TestKeySet_SourceUnavailable(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &fakeSource{err: errors.New("connection refused")}
+	set := NewKeySet(source, time.Minute)
+	set.now = func() time.Time { return now }
+
+	_, err := set.Sign(context.Background(), jwt.MapClaims{})
+	require.Error(t, err)
+
+	// Keys loaded once keep working while the source is down
+	source.err = nil
+	source.keys = []Key{newKey(t, AlgorithmEdDSA, now)}
+	set.now = func() time.Time { return now.Add(minReload) }
+	token, err := set.Sign(context.Background(), jwt.MapClaims{"user_id": 7})
+	require.NoError(t, err)
+
+	source.err = errors.New("connection refused")
+	set.now = func() time.Time { return now.Add(time.Hour) }
+	require.NoError(t, parse(set, token))
+	require.NoError(t, parse(set, token))
+	require.Equal(t, 3, source.loads)
+}
@@ -1,12 +1,14 @@
 package middleware
 
 import (
+	"auth-service/internal/utils"
 	"auth-service/pkg/db/redis"
 	"auth-service/pkg/logger"
 	"context"
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	goredis "github.com/redis/go-redis/v9"
 	"golang.org/x/time/rate"
 	"net/http"
 	"strings"
@@ -14,7 +16,7 @@ import (
 )
 
 // AuthMiddleware verifies JWT tokens in incoming requests
-func AuthMiddleware(jwtSecret []byte, redis *redis.Redis, logger *logger.Logger) echo.MiddlewareFunc {
+func AuthMiddleware(keyfunc jwt.Keyfunc, redis *redis.Redis, logger *logger.Logger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
@@ -38,7 +40,7 @@ func AuthMiddleware(jwtSecret []byte, redis *redis.Redis, logger *logger.Logger)
 			tokenString := parts[1]
 			ctx := context.Background()
 			_, err := redis.Client.Get(ctx, tokenString).Result()
-			if errors.Is(err, redis.Close()) {
+			if errors.Is(err, goredis.Nil) {
 				logger.Warn("Token invalidated or expired", "token", tokenString)
 				c.JSON(http.StatusUnauthorized, map[string]interface{}{
 					"error": "Token invalidated or expired",
@@ -53,12 +55,7 @@ func AuthMiddleware(jwtSecret []byte, redis *redis.Redis, logger *logger.Logger)
 				return nil
 			}
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return jwtSecret, nil
-			})
+			token, err := jwt.Parse(tokenString, keyfunc, jwt.WithValidMethods(utils.SigningMethods))
 
 			if err != nil {
 				if err == jwt.ErrSignatureInvalid {
@@ -99,6 +96,8 @@ func AuthMiddleware(jwtSecret []byte, redis *redis.Redis, logger *logger.Logger)
 			logger.Info("User authenticated", "user_id", claims["user_id"], "email", claims["email"])
 			c.Set("user_id", claims["user_id"])
 			c.Set("email", claims["email"])
+			c.Set("role", claims["role"])
+			c.Set("sid", claims["sid"])
 
 			return next(c)
 		}
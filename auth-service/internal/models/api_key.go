@@ -0,0 +1,79 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scopes an API key can be limited to. Access tokens of a login are not limited
+const (
+	ScopeTasksRead      = "tasks:read"
+	ScopeTasksWrite     = "tasks:write"
+	ScopeTemplatesRead  = "templates:read"
+	ScopeTemplatesWrite = "templates:write"
+)
+
+// Scopes lists every scope an API key can be granted
+var Scopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeTemplatesRead, ScopeTemplatesWrite}
+
+// maxAPIKeyDays bounds the expiry of an API key
+const maxAPIKeyDays = 365
+
+// APIKey describes a personal API key. The key itself is shown only once, when it is created
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyOwner is the user an active API key belongs to, with the scopes it grants
+type APIKeyOwner struct {
+	UserID    int
+	Email     string
+	Role      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// CreateAPIKeyRequest represents API key creation data. Without ExpiresInDays the key
+// does not expire
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// Validate checks the name, scopes and expiry of the key
+func (r *CreateAPIKeyRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > 100 {
+		return errors.New("name must be 1 to 100 characters")
+	}
+	if len(r.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range r.Scopes {
+		if !validScope(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	if r.ExpiresInDays < 0 || r.ExpiresInDays > maxAPIKeyDays {
+		return fmt.Errorf("expires_in_days must be between 0 and %d", maxAPIKeyDays)
+	}
+	return nil
+}
+
+func validScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
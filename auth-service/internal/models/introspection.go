@@ -0,0 +1,26 @@
+package models
+
+// TokenTypeAPIKey is the token_type of API keys in introspection responses
+const TokenTypeAPIKey = "api_key"
+
+// IntrospectionRequest is an RFC 7662 token introspection request
+type IntrospectionRequest struct {
+	Token         string `form:"token" json:"token"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+}
+
+// IntrospectionResponse is an RFC 7662 token introspection response. An inactive token
+// gets only "active": false, without saying why
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Subject   string `json:"sub,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Role      string `json:"role,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	// Scope lists the scopes of an API key, separated by spaces. Access tokens have none
+	// and may do everything their user may
+	Scope     string `json:"scope,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
@@ -13,3 +13,25 @@ func TestUserRegisterValidate(t *testing.T) {
 		t.Errorf("invalid email accepted")
 	}
 }
+
+func TestCreateAPIKeyRequestValidate(t *testing.T) {
+	ok := CreateAPIKeyRequest{Name: " ci ", Scopes: []string{ScopeTasksWrite, ScopeTemplatesRead}, ExpiresInDays: 90}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("valid request reported as invalid: %v", err)
+	}
+	if ok.Name != "ci" {
+		t.Errorf("name not trimmed: %q", ok.Name)
+	}
+
+	bad := map[string]CreateAPIKeyRequest{
+		"no name":       {Scopes: []string{ScopeTasksWrite}},
+		"no scopes":     {Name: "ci"},
+		"unknown scope": {Name: "ci", Scopes: []string{"admin"}},
+		"expiry":        {Name: "ci", Scopes: []string{ScopeTasksWrite}, ExpiresInDays: 1000},
+	}
+	for name, req := range bad {
+		if err := req.Validate(); err == nil {
+			t.Errorf("%s: invalid request accepted", name)
+		}
+	}
+}
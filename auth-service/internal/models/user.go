@@ -6,10 +6,17 @@ import (
 	"time"
 )
 
+// Roles of users. Admins may read the resources of other users in other services
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents our database user
 type User struct {
 	ID           int       `json:"id"`
 	Email        string    `json:"email"`
+	Role         string    `json:"role"`
 	PasswordHash string    `json:"-"` // "-" means this won't be included in JSON
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -27,6 +34,11 @@ type UserRegister struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+// RefreshTokenRequest represents refresh request data
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+}
+
 // Validate checks if email format is valid
 func (u *UserRegister) Validate() error {
 	emailRegex := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"auth-service/internal/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrAPIKeyNotFound is returned for a key that does not exist or belongs to another user
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey is returned for a key that is unknown, expired or revoked
+	ErrInvalidAPIKey = errors.New("api key is invalid or expired")
+)
+
+// APIKeyRepository stores personal API keys by hash
+type APIKeyRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewAPIKeyRepository creates a repository of API keys
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db, now: time.Now}
+}
+
+// Create issues a key for the user and returns it along with its description. The key
+// is not stored and cannot be shown again
+func (r *APIKeyRepository) Create(ctx context.Context, userID int, req models.CreateAPIKeyRequest) (string, *models.APIKey, error) {
+	key, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	apiKey := &models.APIKey{Name: req.Name, Prefix: prefix, Scopes: req.Scopes}
+	if req.ExpiresInDays > 0 {
+		expiresAt := r.now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+        INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at`,
+		userID, apiKey.Name, apiKey.Prefix, utils.HashAPIKey(key), pq.Array(apiKey.Scopes), apiKey.ExpiresAt,
+	).Scan(&apiKey.ID, &apiKey.CreatedAt)
+	if err != nil {
+		return "", nil, err
+	}
+	return key, apiKey, nil
+}
+
+// List returns the keys of the user that are not revoked, newest first
+func (r *APIKeyRepository) List(ctx context.Context, userID int) ([]models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, name, prefix, scopes, expires_at, last_used_at, created_at
+        FROM api_keys
+        WHERE user_id = $1 AND revoked_at IS NULL
+        ORDER BY created_at DESC, id DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var (
+			key                   models.APIKey
+			expiresAt, lastUsedAt sql.NullTime
+		)
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &expiresAt, &lastUsedAt, &key.CreatedAt); err != nil {
+			return nil, err
+		}
+		key.ExpiresAt = nullTime(expiresAt)
+		key.LastUsedAt = nullTime(lastUsedAt)
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke revokes a key of the user
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE api_keys SET revoked_at = NOW()
+        WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// Authenticate returns the owner of an active key and records that the key was used
+func (r *APIKeyRepository) Authenticate(ctx context.Context, key string) (*models.APIKeyOwner, error) {
+	var (
+		owner     models.APIKeyOwner
+		expiresAt sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, `
+        UPDATE api_keys k SET last_used_at = NOW()
+        FROM users u
+        WHERE u.id = k.user_id AND k.key_hash = $1 AND k.revoked_at IS NULL
+          AND (k.expires_at IS NULL OR k.expires_at > $2)
+        RETURNING k.user_id, u.email, u.role, k.scopes, k.expires_at`,
+		utils.HashAPIKey(key), r.now(),
+	).Scan(&owner.UserID, &owner.Email, &owner.Role, pq.Array(&owner.Scopes), &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	owner.ExpiresAt = nullTime(expiresAt)
+	return &owner, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
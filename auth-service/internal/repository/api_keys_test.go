@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"auth-service/internal/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAPIKeys(t *testing.T) (*APIKeyRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo := NewAPIKeyRepository(db)
+	repo.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return repo, mock
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	repo, mock := setupAPIKeys(t)
+	expiresAt := repo.now().AddDate(0, 0, 30)
+
+	mock.ExpectQuery(`INSERT INTO api_keys`).
+		WithArgs(7, "ci", sqlmock.AnyArg(), sqlmock.AnyArg(), `{"tasks:write","templates:read"}`, &expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, repo.now()))
+
+	key, apiKey, err := repo.Create(context.Background(), 7, models.CreateAPIKeyRequest{
+		Name:          "ci",
+		Scopes:        []string{models.ScopeTasksWrite, models.ScopeTemplatesRead},
+		ExpiresInDays: 30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, apiKey.ID)
+	require.Equal(t, key[:12], apiKey.Prefix)
+	require.Equal(t, expiresAt, *apiKey.ExpiresAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAPIKeys(t *testing.T) {
+	repo, mock := setupAPIKeys(t)
+
+	mock.ExpectQuery(`SELECT id, name, prefix, scopes`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "scopes", "expires_at", "last_used_at", "created_at"}).
+			AddRow(3, "ci", "tsk_abcdefgh", "{tasks:write,templates:read}", nil, repo.now(), repo.now()))
+
+	keys, err := repo.List(context.Background(), 7)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, []string{"tasks:write", "templates:read"}, keys[0].Scopes)
+	require.Nil(t, keys[0].ExpiresAt)
+	require.Equal(t, repo.now(), *keys[0].LastUsedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	repo, mock := setupAPIKeys(t)
+
+	mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+		WithArgs(3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+		WithArgs(3, 8).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.Revoke(context.Background(), 7, 3))
+	require.ErrorIs(t, repo.Revoke(context.Background(), 8, 3), ErrAPIKeyNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	repo, mock := setupAPIKeys(t)
+
+	mock.ExpectQuery(`UPDATE api_keys k SET last_used_at`).
+		WithArgs(sqlmock.AnyArg(), repo.now()).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "email", "role", "scopes", "expires_at"}).
+			AddRow(7, "bob@mail.com", "user", "{tasks:write}", nil))
+	mock.ExpectQuery(`UPDATE api_keys k SET last_used_at`).
+		WithArgs(sqlmock.AnyArg(), repo.now()).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "email", "role", "scopes", "expires_at"}))
+
+	owner, err := repo.Authenticate(context.Background(), "tsk_valid")
+	require.NoError(t, err)
+	require.Equal(t, models.APIKeyOwner{UserID: 7, Email: "bob@mail.com", Role: "user", Scopes: []string{"tasks:write"}}, *owner)
+
+	_, err = repo.Authenticate(context.Background(), "tsk_revoked")
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"auth-service/internal/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for a refresh token that is unknown, expired or revoked
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	// ErrRefreshTokenReused is returned when a refresh token is presented a second time.
+	// Its whole family is revoked by then
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+)
+
+// RefreshSession is the login a refresh token belongs to
+type RefreshSession struct {
+	FamilyID string
+	UserID   int
+	Email    string
+	Role     string
+}
+
+// RefreshTokenRepository stores refresh tokens by hash. Tokens rotated from one login
+// share a family, so a stolen token that is used twice revokes the whole login
+type RefreshTokenRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewRefreshTokenRepository creates a repository of refresh tokens
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db, now: time.Now}
+}
+
+// Create issues the first refresh token of a new family and returns the token with its family id
+func (r *RefreshTokenRepository) Create(ctx context.Context, userID int, ttl time.Duration) (string, string, error) {
+	familyID, err := utils.GenerateFamilyID()
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := r.insert(ctx, r.db, familyID, userID, ttl)
+	if err != nil {
+		return "", "", err
+	}
+	return token, familyID, nil
+}
+
+// Rotate exchanges a refresh token for a new one of the same family. Every token can be
+// exchanged once; presenting it again revokes the family and returns ErrRefreshTokenReused
+// along with the session, so that the caller can revoke its access tokens too
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, token string, ttl time.Duration) (*RefreshSession, string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	var (
+		id                int
+		session           RefreshSession
+		expiresAt         time.Time
+		usedAt, revokedAt sql.NullTime
+	)
+	err = tx.QueryRowContext(ctx, `
+        SELECT rt.id, rt.family_id, rt.user_id, u.email, u.role, rt.expires_at, rt.used_at, rt.revoked_at
+        FROM refresh_tokens rt
+        JOIN users u ON u.id = rt.user_id
+        WHERE rt.token_hash = $1
+        FOR UPDATE OF rt`,
+		utils.HashRefreshToken(token),
+	).Scan(&id, &session.FamilyID, &session.UserID, &session.Email, &session.Role, &expiresAt, &usedAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if revokedAt.Valid {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if usedAt.Valid {
+		if err := r.revokeFamily(ctx, tx, session.FamilyID); err != nil {
+			return nil, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, "", err
+		}
+		return &session, "", ErrRefreshTokenReused
+	}
+	if !r.now().Before(expiresAt) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, id); err != nil {
+		return nil, "", err
+	}
+	newToken, err := r.insert(ctx, tx, session.FamilyID, session.UserID, ttl)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, "", err
+	}
+	return &session, newToken, nil
+}
+
+// RevokeFamily revokes every refresh token issued for one login
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.revokeFamily(ctx, r.db, familyID)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *RefreshTokenRepository) insert(ctx context.Context, db execer, familyID string, userID int, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.ExecContext(ctx, `
+        INSERT INTO refresh_tokens (family_id, user_id, token_hash, expires_at)
+        VALUES ($1, $2, $3, $4)`,
+		familyID, userID, utils.HashRefreshToken(token), r.now().Add(ttl),
+	)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *RefreshTokenRepository) revokeFamily(ctx context.Context, db execer, familyID string) error {
+	_, err := db.ExecContext(ctx, `
+        UPDATE refresh_tokens SET revoked_at = NOW()
+        WHERE family_id = $1 AND revoked_at IS NULL`,
+		familyID,
+	)
+	return err
+}
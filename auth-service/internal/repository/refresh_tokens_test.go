@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"auth-service/internal/utils"
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+var rotateColumns = []string{"id", "family_id", "user_id", "email", "role", "expires_at", "used_at", "revoked_at"}
+
+func setupRepository(t *testing.T) (*RefreshTokenRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo := NewRefreshTokenRepository(db)
+	repo.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return repo, mock
+}
+
+func TestCreate(t *testing.T) {
+	repo, mock := setupRepository(t)
+
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), 7, sqlmock.AnyArg(), repo.now().Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	token, familyID, err := repo.Create(context.Background(), 7, time.Hour)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, familyID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotate_Success(t *testing.T) {
+	repo, mock := setupRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT rt.id, rt.family_id`).
+		WithArgs(utils.HashRefreshToken("old")).
+		WillReturnRows(sqlmock.NewRows(rotateColumns).
+			AddRow(1, "family", 7, "bob@mail.com", "user", repo.now().Add(time.Hour), nil, nil))
+	mock.ExpectExec(`UPDATE refresh_tokens SET used_at`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs("family", 7, sqlmock.AnyArg(), repo.now().Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	session, token, err := repo.Rotate(context.Background(), "old", time.Hour)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEqual(t, "old", token)
+	require.Equal(t, RefreshSession{FamilyID: "family", UserID: 7, Email: "bob@mail.com", Role: "user"}, *session)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotate_ReuseRevokesFamily(t *testing.T) {
+	repo, mock := setupRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT rt.id, rt.family_id`).
+		WithArgs(utils.HashRefreshToken("used")).
+		WillReturnRows(sqlmock.NewRows(rotateColumns).
+			AddRow(1, "family", 7, "bob@mail.com", "user", repo.now().Add(time.Hour), repo.now().Add(-time.Minute), nil))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at`).
+		WithArgs("family").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	session, token, err := repo.Rotate(context.Background(), "used", time.Hour)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+	require.Empty(t, token)
+	require.Equal(t, "family", session.FamilyID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotate_Invalid(t *testing.T) {
+	tests := map[string][]driver.Value{
+		"expired": {1, "family", 7, "bob@mail.com", "user", time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), nil, nil},
+		"revoked": {1, "family", 7, "bob@mail.com", "user", time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC), nil, time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)},
+	}
+	for name, row := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, mock := setupRepository(t)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT rt.id, rt.family_id`).
+				WillReturnRows(sqlmock.NewRows(rotateColumns).AddRow(row...))
+			mock.ExpectRollback()
+
+			_, _, err := repo.Rotate(context.Background(), "token", time.Hour)
+			require.ErrorIs(t, err, ErrInvalidRefreshToken)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		repo, mock := setupRepository(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT rt.id, rt.family_id`).
+			WillReturnRows(sqlmock.NewRows(rotateColumns))
+		mock.ExpectRollback()
+
+		_, _, err := repo.Rotate(context.Background(), "token", time.Hour)
+		require.ErrorIs(t, err, ErrInvalidRefreshToken)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRevokeFamily(t *testing.T) {
+	repo, mock := setupRepository(t)
+
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at`).
+		WithArgs("family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	require.NoError(t, repo.RevokeFamily(context.Background(), "family"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"auth-service/internal/keys"
+	"context"
+	"crypto"
+	"database/sql"
+	"time"
+)
+
+// signingKeysLock serialises key creation between instances starting at the same time
+const signingKeysLock = 7240001
+
+// SigningKeyRepository stores the keys access tokens are signed with. Private keys are
+// encrypted with cipher before they are written
+type SigningKeyRepository struct {
+	db     *sql.DB
+	cipher *keys.Cipher
+	now    func() time.Time
+}
+
+// NewSigningKeyRepository creates a repository of signing keys
+func NewSigningKeyRepository(db *sql.DB, cipher *keys.Cipher) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db, cipher: cipher, now: time.Now}
+}
+
+// ActiveKeys returns the current key and the keys still in their overlap window
+func (r *SigningKeyRepository) ActiveKeys(ctx context.Context) ([]keys.Key, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT kid, algorithm, private_key, created_at, retires_at
+        FROM signing_keys
+        WHERE retires_at IS NULL OR retires_at > $1
+        ORDER BY created_at DESC`,
+		r.now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []keys.Key
+	for rows.Next() {
+		var (
+			key       keys.Key
+			stored    string
+			retiresAt sql.NullTime
+		)
+		if err := rows.Scan(&key.ID, &key.Algorithm, &stored, &key.CreatedAt, &retiresAt); err != nil {
+			return nil, err
+		}
+		if key.PrivateKey, err = r.parse(key.ID, stored); err != nil {
+			return nil, err
+		}
+		if retiresAt.Valid {
+			key.RetiresAt = &retiresAt.Time
+		}
+		result = append(result, key)
+	}
+	return result, rows.Err()
+}
+
+// Rotate makes key the current key. The previous keys stop signing and are retired after
+// overlap, which must cover the lifetime of the tokens they signed
+func (r *SigningKeyRepository) Rotate(ctx context.Context, key *keys.Key, overlap time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, signingKeysLock); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE signing_keys SET retires_at = $1
+        WHERE retires_at IS NULL`,
+		r.now().Add(overlap),
+	); err != nil {
+		return err
+	}
+	if err := r.insert(ctx, tx, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// EnsureKey stores a key made by generate unless there already is a current key
+func (r *SigningKeyRepository) EnsureKey(ctx context.Context, generate func() (*keys.Key, error)) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, signingKeysLock); err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM signing_keys WHERE retires_at IS NULL)`).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	key, err := generate()
+	if err != nil {
+		return err
+	}
+	if err := r.insert(ctx, tx, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// EncryptPlaintextKeys encrypts the private keys stored in plaintext before keys were
+// encrypted, and returns how many it encrypted
+func (r *SigningKeyRepository) EncryptPlaintextKeys(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, signingKeysLock); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT kid, private_key FROM signing_keys WHERE private_key LIKE '-----BEGIN%'`)
+	if err != nil {
+		return 0, err
+	}
+	plaintext := map[string]string{}
+	for rows.Next() {
+		var kid, encoded string
+		if err := rows.Scan(&kid, &encoded); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		plaintext[kid] = encoded
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for kid, encoded := range plaintext {
+		sealed, err := r.cipher.Seal(kid, encoded)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE signing_keys SET private_key = $1 WHERE kid = $2`, sealed, kid); err != nil {
+			return 0, err
+		}
+	}
+	return len(plaintext), tx.Commit()
+}
+
+// parse decrypts and decodes a stored private key. Keys stored in plaintext before keys
+// were encrypted are still read until EncryptPlaintextKeys has run
+func (r *SigningKeyRepository) parse(kid, stored string) (crypto.Signer, error) {
+	encoded := stored
+	if keys.IsSealed(stored) {
+		var err error
+		if encoded, err = r.cipher.Open(kid, stored); err != nil {
+			return nil, err
+		}
+	}
+	return keys.ParsePrivateKey(encoded)
+}
+
+func (r *SigningKeyRepository) insert(ctx context.Context, db execer, key *keys.Key) error {
+	encoded, err := key.MarshalPrivateKey()
+	if err != nil {
+		return err
+	}
+	sealed, err := r.cipher.Seal(key.ID, encoded)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `
+        INSERT INTO signing_keys (kid, algorithm, private_key, created_at)
+        VALUES ($1, $2, $3, $4)`,
+		key.ID, key.Algorithm, sealed, key.CreatedAt,
+	)
+	return err
+}
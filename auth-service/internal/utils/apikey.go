@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so that keys are easy to tell from access tokens
+// and easy to find for secret scanners
+const APIKeyPrefix = "tsk_"
+
+// apiKeyDisplayLength is how much of a key is kept in clear to tell keys apart in listings
+const apiKeyDisplayLength = 12
+
+// GenerateAPIKey creates a new API key and the prefix it is listed under
+func GenerateAPIKey() (key, prefix string, err error) {
+	random, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	key = APIKeyPrefix + random
+	return key, key[:apiKeyDisplayLength], nil
+}
+
+// IsAPIKey reports whether a token is an API key rather than an access token
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// HashAPIKey returns the hash under which an API key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
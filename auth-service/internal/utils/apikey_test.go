@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if !IsAPIKey(key) {
+		t.Fatalf("key %q has no API key prefix", key)
+	}
+	if !strings.HasPrefix(key, prefix) || len(prefix) != 12 {
+		t.Fatalf("want 12 character prefix of the key, got %q", prefix)
+	}
+	if HashAPIKey(key) == HashAPIKey(key+"x") {
+		t.Fatal("different keys must have different hashes")
+	}
+	if IsAPIKey("eyJhbGciOiJFZERTQSJ9.e30.sig") {
+		t.Fatal("access token reported as API key")
+	}
+}
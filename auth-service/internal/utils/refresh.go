@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateRefreshToken creates an opaque refresh token with 256 bits of randomness
+func GenerateRefreshToken() (string, error) {
+	return randomString(32)
+}
+
+// GenerateFamilyID creates the id shared by all refresh tokens rotated from one login
+func GenerateFamilyID() (string, error) {
+	return randomString(16)
+}
+
+// HashRefreshToken returns the hash under which a refresh token is stored. Refresh
+// tokens are random, so a fast unsalted hash is enough
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
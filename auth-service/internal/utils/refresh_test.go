@@ -0,0 +1,33 @@
+package utils
+
+import "testing"
+
+func TestGenerateRefreshToken(t *testing.T) {
+	first, err := GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	second, err := GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if first == second {
+		t.Fatal("tokens must differ")
+	}
+	if len(first) != 43 {
+		t.Fatalf("want 43 characters, got %d", len(first))
+	}
+}
+
+func TestHashRefreshToken(t *testing.T) {
+	hash := HashRefreshToken("token")
+	if hash != HashRefreshToken("token") {
+		t.Fatal("hash must be deterministic")
+	}
+	if hash == HashRefreshToken("other") {
+		t.Fatal("different tokens must have different hashes")
+	}
+	if len(hash) != 64 {
+		t.Fatalf("want 64 hex characters, got %d", len(hash))
+	}
+}
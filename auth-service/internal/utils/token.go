@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningMethods are the algorithms access tokens may be signed with
+var SigningMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+// ParseToken verifies the signature and expiry of an access token and returns its claims.
+// keyfunc returns the public key named by the token's kid
+func ParseToken(tokenString string, keyfunc jwt.Keyfunc) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, keyfunc, jwt.WithValidMethods(SigningMethods), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("sign error: %v", err)
+	}
+	return token
+}
+
+func TestParseToken(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	keyfunc := func(*jwt.Token) (interface{}, error) { return public, nil }
+	exp := time.Now().Add(time.Hour).Unix()
+
+	claims, err := ParseToken(signToken(t, jwt.SigningMethodEdDSA, private, jwt.MapClaims{"user_id": 7, "exp": exp}), keyfunc)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if claims["user_id"] != float64(7) {
+		t.Fatalf("want user_id 7, got %v", claims["user_id"])
+	}
+
+	rejected := map[string]string{
+		"wrong key": signToken(t, jwt.SigningMethodEdDSA, other, jwt.MapClaims{"exp": exp}),
+		"expired":   signToken(t, jwt.SigningMethodEdDSA, private, jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}),
+		"no expiry": signToken(t, jwt.SigningMethodEdDSA, private, jwt.MapClaims{"user_id": 7}),
+		"hmac":      signToken(t, jwt.SigningMethodHS256, []byte("secret"), jwt.MapClaims{"exp": exp}),
+		"malformed": "not.a.token",
+	}
+	for name, token := range rejected {
+		if _, err := ParseToken(token, keyfunc); err == nil {
+			t.Fatalf("%s: want error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    retires_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"notification-service/internal/config"
+	"notification-service/internal/middleware"
+	"notification-service/internal/repository"
+	"notification-service/internal/routes"
+	"notification-service/internal/services"
+	http_transport "notification-service/internal/transport/http"
+	"notification-service/internal/transport/http/handlers"
+	"notification-service/migrations"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"egress"
+	"notification-service/pkg/broker/kafka"
+	"notification-service/pkg/db/postgres"
+	"notification-service/pkg/jwks"
+	"notification-service/pkg/logger"
+)
+
+func main() {
+	//init config
+	cfg, err := config.New()
+	if err != nil {
+		tempLogger, _ := logger.New("dev")
+		tempLogger.Fatal("Failed to initialize config: ", err)
+	}
+
+	//init logger
+	log, err := logger.New(cfg.Env)
+	if err != nil {
+		panic(err)
+	}
+	defer log.Sync()
+
+	//init postgres
+	pgClient, err := postgres.NewPostgres(cfg.Postgres, log.SugaredLogger)
+	if err != nil {
+		log.Fatal("Failed to initialize Postgres: ", err)
+	}
+	defer pgClient.Close()
+
+	//migrations pgdb
+	migrator, err := migrations.New(cfg.Postgres, log.SugaredLogger)
+	if err != nil {
+		log.Fatalf("Failed to initialize migrator: %v", err)
+	}
+	if err := migrator.RunMigrations(); err != nil {
+		log.Fatalf("Database migration failed: %v", err)
+	}
+
+	//init router
+	routerConfig := http_transport.NewRouterConfig(cfg)
+	router := http_transport.NewRouter(routerConfig, log)
+
+	//init repositories
+	webhookRepository := repository.NewWebhookRepository(pgClient, log.SugaredLogger)
+	deliveryRepository := repository.NewDeliveryRepository(pgClient, log.SugaredLogger)
+
+	//init services
+	webhookService := services.NewWebhookService(webhookRepository, deliveryRepository, net.DefaultResolver, log.SugaredLogger)
+	dispatcher := services.NewDispatcher(deliveryRepository, log.SugaredLogger)
+	deliverer := services.NewDeliverer(
+		deliveryRepository,
+		egress.NewClient(time.Duration(cfg.Delivery.Timeout)*time.Second),
+		time.Duration(cfg.Delivery.PollInterval)*time.Second,
+		time.Duration(cfg.Delivery.Timeout)*time.Second,
+		cfg.Delivery.BatchSize,
+		cfg.Delivery.Workers,
+		cfg.Delivery.MaxAttempts,
+		services.Backoff{
+			Base: time.Duration(cfg.Delivery.BackoffBase) * time.Second,
+			Max:  time.Duration(cfg.Delivery.BackoffMax) * time.Second,
+		},
+		log.SugaredLogger,
+	)
+
+	//init notification consumer
+	notificationConsumer := kafka.NewNotificationConsumer(cfg.Kafka, dispatcher, log.SugaredLogger)
+	defer func() {
+		if err := notificationConsumer.Close(); err != nil {
+			log.Errorf("Failed to close notification consumer: %v", err)
+		}
+	}()
+
+	consumeCtx, stopConsuming := context.WithCancel(context.Background())
+	defer stopConsuming()
+	go func() {
+		if err := notificationConsumer.Consume(consumeCtx); err != nil {
+			log.Errorf("Notification consumer stopped: %v", err)
+		}
+	}()
+	go deliverer.Run(consumeCtx)
+
+	//init handlers
+	webhookHandler := handlers.NewWebhookHandler(webhookService, log.SugaredLogger)
+
+	//init auth keys
+	authKeys := jwks.New(
+		cfg.Auth.JWKSURL,
+		&http.Client{Timeout: time.Duration(cfg.Auth.Timeout) * time.Second},
+		time.Duration(cfg.Auth.RefreshInterval)*time.Second,
+	)
+
+	//init routes
+	routes.SetupWebhookRoutes(router.Echo(), webhookHandler, middleware.AuthMiddleware(authKeys.Keyfunc))
+
+	//run server
+	go func() {
+		maxRetries := cfg.HTTPServer.MaxRetries
+		retryDelay := time.Duration(cfg.HTTPServer.RetryDelay) * time.Second
+		for attempt := 1; attempt <= maxRetries; attempt++ {
+			if err := router.Run(); err != nil && err != http.ErrServerClosed {
+				log.Errorf("Server failed (attempt %d/%d): retrying in %v...", attempt, maxRetries, retryDelay)
+				time.Sleep(retryDelay)
+			} else {
+				break
+			}
+		}
+
+		log.Fatalf("Server failed after %d attempts, exiting...", maxRetries)
+	}()
+
+	//graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Info("Received shutdown signal, shutting down gracefully...")
+	stopConsuming()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := router.ShuttingDown(ctx); err != nil {
+		log.Errorf("failed to shutdown http server: %s", err)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+type HTTPServer struct {
+	Host       string `yaml:"host" env:"HOST" validate:"required"`
+	Port       string `yaml:"port" env:"PORT" env-default:"8080" validate:"required,numeric"`
+	MaxRetries int    `yaml:"max_retries" env:"MAX_RETRIES" env-default:"5" validate:"gte=1"`
+	RetryDelay int    `yaml:"retry_delay" env:"RETRY_DELAY" env-default:"5" validate:"gte=1"`
+}
+
+type PostgresConfig struct {
+	Host       string `yaml:"host" env:"PG_HOST" validate:"required"`
+	Port       string `yaml:"port" env:"PG_PORT" env-default:"5432" validate:"required,numeric"`
+	User       string `yaml:"user" env:"PG_USER" env-default:"postgres" validate:"required"`
+	Password   string `yaml:"password" env:"PG_PASSWORD" env-default:""`
+	DBName     string `yaml:"dbname" env:"PG_DBNAME" validate:"required"`
+	SSLMode    string `yaml:"sslmode" env:"PG_SSLMODE" env-default:"disable" validate:"oneof=disable require"`
+	MaxConns   int32  `yaml:"max_conns" env:"PG_MAX_CONNS" env-default:"20" validate:"gte=1"`
+	MinConns   int32  `yaml:"min_conns" env:"PG_MIN_CONNS" env-default:"2" validate:"gte=1"`
+	Timeout    int    `yaml:"timeout" env:"PG_TIMEOUT" env-default:"5" validate:"gte=1"`
+	MaxRetries int    `yaml:"max_retries" env:"PG_MAX_RETRIES" env-default:"5" validate:"gte=1"`
+	RetryDelay int    `yaml:"retry_delay" env:"PG_RETRY_DELAY" env-default:"2" validate:"gte=1"`
+}
+
+type KafkaConfig struct {
+	Brokers     string `yaml:"brokers" env:"KAFKA_BROKERS" validate:"required"`
+	NotifyTopic string `yaml:"notify_topic" env:"KAFKA_NOTIFY_TOPIC" env-default:"task-notifications" validate:"required"`
+	GroupID     string `yaml:"group_id" env:"KAFKA_GROUP_ID" env-default:"notification-service" validate:"required"`
+	RetryDelay  int    `yaml:"retry_delay" env:"KAFKA_RETRY_DELAY" env-default:"3" validate:"gte=1"`
+	Timeout     int    `yaml:"timeout" env:"KAFKA_TIMEOUT" env-default:"5" validate:"gte=1"`
+}
+
+// AuthConfig locates the public keys auth-service signs its access tokens with. Keys are
+// refetched every RefreshInterval seconds and when a token names an unknown key.
+type AuthConfig struct {
+	JWKSURL         string `yaml:"jwks_url" env:"AUTH_JWKS_URL" env-default:"http://auth-service:8080/.well-known/jwks.json" validate:"required,url"`
+	Timeout         int    `yaml:"timeout" env:"AUTH_TIMEOUT" env-default:"5" validate:"gte=1"`
+	RefreshInterval int    `yaml:"refresh_interval" env:"AUTH_JWKS_REFRESH" env-default:"300" validate:"gte=1"`
+}
+
+// DeliveryConfig controls how webhooks are delivered. A failed delivery is retried after
+// BackoffBase seconds, doubling up to BackoffMax, and is moved to the dead-letter log
+// after MaxAttempts attempts.
+type DeliveryConfig struct {
+	PollInterval int `yaml:"poll_interval" env:"DELIVERY_POLL_INTERVAL" env-default:"1" validate:"gte=1"`
+	BatchSize    int `yaml:"batch_size" env:"DELIVERY_BATCH_SIZE" env-default:"50" validate:"gte=1"`
+	Workers      int `yaml:"workers" env:"DELIVERY_WORKERS" env-default:"8" validate:"gte=1"`
+	Timeout      int `yaml:"timeout" env:"DELIVERY_TIMEOUT" env-default:"10" validate:"gte=1"`
+	MaxAttempts  int `yaml:"max_attempts" env:"DELIVERY_MAX_ATTEMPTS" env-default:"8" validate:"gte=1"`
+	BackoffBase  int `yaml:"backoff_base" env:"DELIVERY_BACKOFF_BASE" env-default:"10" validate:"gte=1"`
+	BackoffMax   int `yaml:"backoff_max" env:"DELIVERY_BACKOFF_MAX" env-default:"3600" validate:"gtefield=BackoffBase"`
+}
+
+type Config struct {
+	Env        string         `yaml:"env" env:"ENV" env-default:"prod" validate:"oneof=dev prod test"`
+	HTTPServer HTTPServer     `yaml:"http_server" validate:"required"`
+	Postgres   PostgresConfig `yaml:"postgres" validate:"required"`
+	Kafka      KafkaConfig    `yaml:"kafka" validate:"required"`
+	Auth       AuthConfig     `yaml:"auth" validate:"required"`
+	Delivery   DeliveryConfig `yaml:"delivery" validate:"required"`
+}
+
+func New() (*Config, error) {
+	var cfg Config
+
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to read config from env: %w", err)
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to validate config: %w", err)
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	authPublic, authPrivate, _ = ed25519.GenerateKey(rand.Reader)
+	_, otherPrivate, _         = ed25519.GenerateKey(rand.Reader)
+)
+
+func signToken(t *testing.T, key ed25519.PrivateKey, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func runAuth(header string) (*httptest.ResponseRecorder, string) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var userID string
+	keyfunc := func(*jwt.Token) (interface{}, error) { return authPublic, nil }
+	handler := AuthMiddleware(keyfunc)(func(c echo.Context) error {
+		userID, _ = c.Get("user_id").(string)
+		return c.NoContent(http.StatusOK)
+	})
+	_ = handler(c)
+	return rec, userID
+}
+
+// TestAuthMiddleware проверяет допуск запросов с действующим токеном auth-service.
+func TestAuthMiddleware(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+
+	rec, userID := runAuth("Bearer " + signToken(t, authPrivate, jwt.MapClaims{"user_id": 42, "exp": exp}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "42", userID)
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 42, "exp": exp}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"no header", ""},
+		{"not bearer", "Basic dXNlcjpwYXNz"},
+		{"wrong key", "Bearer " + signToken(t, otherPrivate, jwt.MapClaims{"user_id": 42, "exp": exp})},
+		{"expired", "Bearer " + signToken(t, authPrivate, jwt.MapClaims{"user_id": 42, "exp": time.Now().Add(-time.Minute).Unix()})},
+		{"no exp", "Bearer " + signToken(t, authPrivate, jwt.MapClaims{"user_id": 42})},
+		{"no user", "Bearer " + signToken(t, authPrivate, jwt.MapClaims{"exp": exp})},
+		{"shared secret", "Bearer " + hmac},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, userID := runAuth(tt.header)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Empty(t, userID)
+		})
+	}
+}
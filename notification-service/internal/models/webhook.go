@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Task statuses a webhook can subscribe to.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// EventTaskFinished is the event type of the notifications task-service publishes and of
+// the webhooks delivered for them.
+const EventTaskFinished = "task.finished"
+
+// Webhook is an endpoint of a user that receives a signed request whenever one of the
+// user's tasks finishes with one of Statuses (any terminal status if empty). Secret is
+// only shown when the webhook is created.
+type Webhook struct {
+	ID          int64     `json:"id" db:"id"`
+	UserID      string    `json:"user_id" db:"user_id"`
+	URL         string    `json:"url" db:"url"`
+	Description string    `json:"description,omitempty" db:"description"`
+	Statuses    []string  `json:"statuses" db:"statuses"`
+	Secret      string    `json:"secret,omitempty" db:"secret"`
+	Enabled     bool      `json:"enabled" db:"enabled"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookRequest registers or updates a webhook. Enabled defaults to true.
+type WebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,startswith=http,max=2048"`
+	Description string   `json:"description,omitempty" validate:"max=255"`
+	Statuses    []string `json:"statuses,omitempty" validate:"max=3,dive,oneof=succeeded failed cancelled"`
+	Enabled     *bool    `json:"enabled,omitempty"`
+}
+
+// TaskNotification is published by task-service when a task reaches a terminal status.
+type TaskNotification struct {
+	TaskID           string    `json:"task_id"`
+	UserID           string    `json:"user_id"`
+	Type             string    `json:"type"`
+	Status           string    `json:"status"`
+	RecordsGenerated int       `json:"records_generated"`
+	ResultSize       int64     `json:"result_size,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	FinishedAt       time.Time `json:"finished_at"`
+}
+
+// EventID identifies the notification in every delivery made for it, so that receivers can
+// drop the duplicates retries may cause.
+func (n TaskNotification) EventID() string {
+	return n.TaskID + "." + n.Status
+}
+
+// WebhookEvent is the body of a webhook request.
+type WebhookEvent struct {
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      TaskNotification `json:"data"`
+}
+
+// Statuses of a delivery. A dead delivery has exhausted its attempts and stays in the
+// dead-letter log until it is redelivered.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Delivery is one webhook request and the history of its attempts.
+type Delivery struct {
+	ID             int64           `json:"id" db:"id"`
+	WebhookID      int64           `json:"webhook_id" db:"webhook_id"`
+	EventID        string          `json:"event_id" db:"event_id"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty" db:"response_status"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+
+	// URL and Secret are those of the webhook at the time of the attempt.
+	URL    string `json:"-" db:"-"`
+	Secret string `json:"-" db:"-"`
+}
+
+// DeliveryResult is the outcome of one attempt of a delivery.
+type DeliveryResult struct {
+	ResponseStatus int
+	Err            error
+}
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	ErrNotDead          = errors.New("only dead deliveries can be redelivered")
+	ErrForbiddenURL     = errors.New("webhook URL must point to a public address")
+)
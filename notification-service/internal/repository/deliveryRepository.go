@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"notification-service/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type DeliveryRepository interface {
+	// EnqueueDeliveries creates a pending delivery of payload for every enabled webhook of
+	// the notified user that subscribes to its status, and returns how many were created.
+	// A webhook that already has a delivery of the event does not get another one.
+	EnqueueDeliveries(ctx context.Context, notification models.TaskNotification, payload []byte) (int, error)
+	// ClaimDue returns up to limit pending deliveries that are due at now with the URL and
+	// secret of their webhooks. Claimed deliveries are not due again until lease has passed,
+	// so that a delivery whose deliverer died is retried.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error)
+	// RecordAttempt stores the outcome of an attempt: the status, attempts, response,
+	// error and next attempt of delivery.
+	RecordAttempt(ctx context.Context, delivery models.Delivery) error
+	// ListDeliveries returns the latest deliveries of a webhook, newest first, optionally
+	// only those in status.
+	ListDeliveries(ctx context.Context, webhookID int64, status string, limit int) ([]models.Delivery, error)
+	// Redeliver moves a dead delivery back to pending with a fresh set of attempts.
+	Redeliver(ctx context.Context, webhookID, deliveryID int64) error
+}
+
+type postgresDeliveryRepository struct {
+	db     DB
+	logger *zap.SugaredLogger
+}
+
+func NewDeliveryRepository(db DB, logger *zap.SugaredLogger) *postgresDeliveryRepository {
+	return &postgresDeliveryRepository{db: db, logger: logger}
+}
+
+func (r *postgresDeliveryRepository) EnqueueDeliveries(ctx context.Context, notification models.TaskNotification, payload []byte) (int, error) {
+	query := `WITH inserted AS (
+                  INSERT INTO deliveries (webhook_id, event_id, payload, status, next_attempt_at, created_at)
+                  SELECT id, $1, $2, $3, $4, $4 FROM webhooks
+                  WHERE user_id = $5 AND enabled AND (cardinality(statuses) = 0 OR $6 = ANY(statuses))
+                  ON CONFLICT (webhook_id, event_id) DO NOTHING
+                  RETURNING id
+              )
+              SELECT COUNT(*) FROM inserted`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, notification.EventID(), payload, models.DeliveryPending, time.Now(), notification.UserID, notification.Status).Scan(&count)
+	if err != nil {
+		r.logger.Errorf("Failed to enqueue deliveries of %s: %v", notification.EventID(), err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *postgresDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+	// SKIP LOCKED lets several replicas claim concurrently without delivering twice.
+	query := `UPDATE deliveries d
+              SET next_attempt_at = $1
+              FROM webhooks w
+              WHERE w.id = d.webhook_id AND d.id IN (
+                  SELECT due.id FROM deliveries due JOIN webhooks hook ON hook.id = due.webhook_id
+                  WHERE due.status = $2 AND due.next_attempt_at <= $3 AND hook.enabled
+                  ORDER BY due.next_attempt_at
+                  LIMIT $4
+                  FOR UPDATE OF due SKIP LOCKED
+              )
+              RETURNING d.id, d.webhook_id, d.event_id, d.payload, d.status, d.attempts, d.created_at, w.url, w.secret`
+
+	rows, err := r.db.Query(ctx, query, now.Add(lease), models.DeliveryPending, now, limit)
+	if err != nil {
+		r.logger.Errorf("Failed to claim due deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.Delivery
+	for rows.Next() {
+		var d models.Delivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Payload, &d.Status, &d.Attempts, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			r.logger.Errorf("Failed to scan delivery row: %v", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *postgresDeliveryRepository) RecordAttempt(ctx context.Context, delivery models.Delivery) error {
+	query := `UPDATE deliveries
+              SET status = $1, attempts = $2, response_status = $3, last_error = $4, next_attempt_at = $5, delivered_at = $6
+              WHERE id = $7`
+
+	err := r.db.Exec(ctx, query, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.LastError, delivery.NextAttemptAt, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to record attempt of delivery %d: %v", delivery.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *postgresDeliveryRepository) ListDeliveries(ctx context.Context, webhookID int64, status string, limit int) ([]models.Delivery, error) {
+	query := `SELECT id, webhook_id, event_id, payload, status, attempts, response_status, last_error, next_attempt_at, delivered_at, created_at
+              FROM deliveries
+              WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+              ORDER BY created_at DESC, id DESC
+              LIMIT $3`
+
+	rows, err := r.db.Query(ctx, query, webhookID, status, limit)
+	if err != nil {
+		r.logger.Errorf("Failed to query deliveries of webhook %d: %v", webhookID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.Delivery{}
+	for rows.Next() {
+		var d models.Delivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Payload, &d.Status, &d.Attempts, &d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt); err != nil {
+			r.logger.Errorf("Failed to scan delivery row: %v", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Errorf("Error during deliveries iteration: %v", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *postgresDeliveryRepository) Redeliver(ctx context.Context, webhookID, deliveryID int64) error {
+	query := `UPDATE deliveries
+              SET status = $1, attempts = 0, last_error = '', response_status = 0, next_attempt_at = $2
+              WHERE id = $3 AND webhook_id = $4 AND status = $5
+              RETURNING id`
+
+	var id int64
+	err := r.db.QueryRow(ctx, query, models.DeliveryPending, time.Now(), deliveryID, webhookID, models.DeliveryDead).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Tell a missing delivery from one that is not dead.
+		var status string
+		err = r.db.QueryRow(ctx, `SELECT status FROM deliveries WHERE id = $1 AND webhook_id = $2`, deliveryID, webhookID).Scan(&status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrDeliveryNotFound
+		}
+		if err == nil {
+			return models.ErrNotDead
+		}
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to redeliver delivery %d: %v", deliveryID, err)
+		return err
+	}
+	return nil
+}
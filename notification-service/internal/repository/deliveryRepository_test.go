@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"notification-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDB — фейковая реализация интерфейса DB для тестов.
+type fakeDB struct {
+	mock pgxmock.PgxPoolIface
+}
+
+func (f *fakeDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return f.mock.QueryRow(ctx, sql, args...)
+}
+
+func (f *fakeDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return f.mock.Query(ctx, sql, args...)
+}
+
+func (f *fakeDB) Exec(ctx context.Context, sql string, args ...interface{}) error {
+	_, err := f.mock.Exec(ctx, sql, args...)
+	return err
+}
+
+func (f *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return f.mock.Begin(ctx)
+}
+
+func (f *fakeDB) Ping(ctx context.Context) error {
+	return f.mock.Ping(ctx)
+}
+
+func (f *fakeDB) Close() {
+	f.mock.Close()
+}
+
+func setupDeliveryRepository(t *testing.T) (*postgresDeliveryRepository, pgxmock.PgxPoolIface) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+
+	repo := NewDeliveryRepository(&fakeDB{mock: mock}, zap.NewNop().Sugar())
+	return repo, mock
+}
+
+// TestEnqueueDeliveries проверяет постановку доставок по подписанным вебхукам пользователя.
+func TestEnqueueDeliveries(t *testing.T) {
+	repo, mock := setupDeliveryRepository(t)
+	defer mock.Close()
+
+	notification := models.TaskNotification{TaskID: "task-1", UserID: "42", Status: models.StatusFailed}
+	payload := []byte(`{"id":"task-1.failed"}`)
+
+	mock.ExpectQuery(`WITH inserted AS \(\s+INSERT INTO deliveries`).
+		WithArgs("task-1.failed", payload, models.DeliveryPending, pgxmock.AnyArg(), "42", models.StatusFailed).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := repo.EnqueueDeliveries(context.Background(), notification, payload)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestClaimDue проверяет захват доставок вместе с адресом и секретом вебхука.
+func TestClaimDue(t *testing.T) {
+	repo, mock := setupDeliveryRepository(t)
+	defer mock.Close()
+
+	now := time.Date(2025, time.March, 14, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`UPDATE deliveries d\s+SET next_attempt_at = \$1\s+FROM webhooks w`).
+		WithArgs(now.Add(time.Minute), models.DeliveryPending, now, 10).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "webhook_id", "event_id", "payload", "status", "attempts", "created_at", "url", "secret"}).
+			AddRow(int64(1), int64(2), "task-1.failed", []byte(`{}`), models.DeliveryPending, 1, now, "https://ci.example.com/hook", "whsec_test"))
+
+	due, err := repo.ClaimDue(context.Background(), now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "https://ci.example.com/hook", due[0].URL)
+	assert.Equal(t, "whsec_test", due[0].Secret)
+	assert.Equal(t, 1, due[0].Attempts)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRedeliver_NotDead проверяет отказ повторной отправки доставки, которая не в dead-letter.
+func TestRedeliver_NotDead(t *testing.T) {
+	repo, mock := setupDeliveryRepository(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(`UPDATE deliveries`).
+		WithArgs(models.DeliveryPending, pgxmock.AnyArg(), int64(5), int64(2), models.DeliveryDead).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`SELECT status FROM deliveries WHERE id = \$1 AND webhook_id = \$2`).
+		WithArgs(int64(5), int64(2)).
+		WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(models.DeliveryDelivered))
+
+	err := repo.Redeliver(context.Background(), 2, 5)
+	assert.ErrorIs(t, err, models.ErrNotDead)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRedeliver_NotFound проверяет ошибку для несуществующей доставки.
+func TestRedeliver_NotFound(t *testing.T) {
+	repo, mock := setupDeliveryRepository(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(`UPDATE deliveries`).
+		WithArgs(models.DeliveryPending, pgxmock.AnyArg(), int64(5), int64(2), models.DeliveryDead).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`SELECT status FROM deliveries`).
+		WithArgs(int64(5), int64(2)).
+		WillReturnError(pgx.ErrNoRows)
+
+	err := repo.Redeliver(context.Background(), 2, 5)
+	assert.ErrorIs(t, err, models.ErrDeliveryNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"notification-service/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type DB interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...interface{}) error
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Ping(ctx context.Context) error
+	Close()
+}
+
+// WebhookRepository stores webhooks. Every lookup is scoped to the user owning the
+// webhook, so a webhook of another user is reported as models.ErrWebhookNotFound.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (int64, error)
+	GetWebhook(ctx context.Context, id int64, userID string) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook models.Webhook) error
+	DeleteWebhook(ctx context.Context, id int64, userID string) error
+}
+
+type postgresWebhookRepository struct {
+	db     DB
+	logger *zap.SugaredLogger
+}
+
+func NewWebhookRepository(db DB, logger *zap.SugaredLogger) *postgresWebhookRepository {
+	return &postgresWebhookRepository{db: db, logger: logger}
+}
+
+const webhookColumns = `id, user_id, url, description, statuses, secret, enabled, created_at, updated_at`
+
+func scanWebhook(row pgx.Row) (*models.Webhook, error) {
+	var w models.Webhook
+	if err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.Description, &w.Statuses, &w.Secret, &w.Enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *postgresWebhookRepository) CreateWebhook(ctx context.Context, webhook models.Webhook) (int64, error) {
+	query := `INSERT INTO webhooks (user_id, url, description, statuses, secret, enabled, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+              RETURNING id`
+
+	var id int64
+	err := r.db.QueryRow(ctx, query, webhook.UserID, webhook.URL, webhook.Description, webhook.Statuses, webhook.Secret, webhook.Enabled, webhook.CreatedAt, webhook.UpdatedAt).Scan(&id)
+	if err != nil {
+		r.logger.Errorf("Failed to insert webhook: %v", err)
+		return 0, err
+	}
+
+	r.logger.Infof("Webhook created with ID: %d", id)
+	return id, nil
+}
+
+func (r *postgresWebhookRepository) GetWebhook(ctx context.Context, id int64, userID string) (*models.Webhook, error) {
+	webhook, err := scanWebhook(r.db.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1 AND user_id = $2`, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWebhookNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to get webhook %d: %v", id, err)
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *postgresWebhookRepository) ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		r.logger.Errorf("Failed to query webhooks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			r.logger.Errorf("Failed to scan webhook row: %v", err)
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Errorf("Error during webhooks iteration: %v", err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook replaces the endpoint, description, statuses and state of a webhook; its
+// secret does not change.
+func (r *postgresWebhookRepository) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	query := `UPDATE webhooks
+              SET url = $1, description = $2, statuses = $3, enabled = $4, updated_at = $5
+              WHERE id = $6 AND user_id = $7
+              RETURNING id`
+
+	var id int64
+	err := r.db.QueryRow(ctx, query, webhook.URL, webhook.Description, webhook.Statuses, webhook.Enabled, webhook.UpdatedAt, webhook.ID, webhook.UserID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrWebhookNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to update webhook %d: %v", webhook.ID, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteWebhook deletes a webhook together with its deliveries.
+func (r *postgresWebhookRepository) DeleteWebhook(ctx context.Context, id int64, userID string) error {
+	var deleted int64
+	err := r.db.QueryRow(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2 RETURNING id`, id, userID).Scan(&deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrWebhookNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to delete webhook %d: %v", id, err)
+		return err
+	}
+	return nil
+}
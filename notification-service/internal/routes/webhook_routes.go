@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"notification-service/internal/transport/http/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+func SetupWebhookRoutes(router *echo.Echo, webhookHandler *handlers.WebhookHandler, auth echo.MiddlewareFunc) {
+	api := router.Group("/api/v2/webhooks", auth)
+	{
+		api.POST("", webhookHandler.CreateWebhook)
+		api.GET("", webhookHandler.ListWebhooks)
+		api.GET("/:id", webhookHandler.GetWebhook)
+		api.PUT("/:id", webhookHandler.UpdateWebhook)
+		api.DELETE("/:id", webhookHandler.DeleteWebhook)
+		api.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+		api.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+	}
+}
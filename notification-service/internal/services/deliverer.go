@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"notification-service/pkg/signature"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Backoff is the delay before the next attempt of a failed delivery: Base after the first
+// attempt, doubling with every further one up to Max.
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay returns the delay after the given number of failed attempts.
+func (b Backoff) Delay(attempts int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempts && delay < b.Max; i++ {
+		delay *= 2
+	}
+	return min(delay, b.Max)
+}
+
+// Deliverer sends due webhook deliveries, retrying failed ones with exponential backoff
+// until they run out of attempts and move to the dead-letter log.
+type Deliverer struct {
+	deliveries  repository.DeliveryRepository
+	client      HTTPClient
+	interval    time.Duration
+	timeout     time.Duration
+	batchSize   int
+	workers     int
+	maxAttempts int
+	backoff     Backoff
+	logger      *zap.SugaredLogger
+}
+
+func NewDeliverer(
+	deliveries repository.DeliveryRepository,
+	client HTTPClient,
+	interval time.Duration,
+	timeout time.Duration,
+	batchSize int,
+	workers int,
+	maxAttempts int,
+	backoff Backoff,
+	logger *zap.SugaredLogger,
+) *Deliverer {
+	return &Deliverer{
+		deliveries:  deliveries,
+		client:      client,
+		interval:    interval,
+		timeout:     timeout,
+		batchSize:   batchSize,
+		workers:     workers,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		logger:      logger,
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (d *Deliverer) Run(ctx context.Context) {
+	d.logger.Infof("Deliverer started, polling every %v", d.interval)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Deliverer stopped")
+			return
+		case <-ticker.C:
+			// Drain the backlog before waiting for the next tick.
+			for {
+				sent, err := d.DeliverOnce(ctx)
+				if err != nil {
+					d.logger.Errorf("Delivery iteration failed: %v", err)
+					break
+				}
+				if sent < d.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DeliverOnce makes one attempt of a batch of due deliveries and returns how many it tried.
+func (d *Deliverer) DeliverOnce(ctx context.Context) (int, error) {
+	// A claim outlives the slowest batch, so that only a dead deliverer's claims expire.
+	lease := d.timeout*time.Duration((d.batchSize+d.workers-1)/d.workers) + time.Minute
+	due, err := d.deliveries.ClaimDue(ctx, time.Now(), lease, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	jobs := make(chan models.Delivery)
+	var wg sync.WaitGroup
+	for range min(d.workers, len(due)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range jobs {
+				d.attempt(ctx, delivery)
+			}
+		}()
+	}
+	for _, delivery := range due {
+		jobs <- delivery
+	}
+	close(jobs)
+	wg.Wait()
+
+	return len(due), nil
+}
+
+// attempt sends a delivery once and records the outcome.
+func (d *Deliverer) attempt(ctx context.Context, delivery models.Delivery) {
+	result := d.send(ctx, delivery)
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.ResponseStatus = result.ResponseStatus
+	switch {
+	case result.Err == nil:
+		delivery.Status = models.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = now
+	case delivery.Attempts >= d.maxAttempts:
+		delivery.Status = models.DeliveryDead
+		delivery.LastError = result.Err.Error()
+		delivery.NextAttemptAt = now
+		d.logger.Warnf("Delivery %d of %s to webhook %d is dead after %d attempts: %v", delivery.ID, delivery.EventID, delivery.WebhookID, delivery.Attempts, result.Err)
+	default:
+		delivery.Status = models.DeliveryPending
+		delivery.LastError = result.Err.Error()
+		delivery.NextAttemptAt = now.Add(d.backoff.Delay(delivery.Attempts))
+		d.logger.Infof("Delivery %d of %s to webhook %d failed (attempt %d), retrying at %v: %v", delivery.ID, delivery.EventID, delivery.WebhookID, delivery.Attempts, delivery.NextAttemptAt, result.Err)
+	}
+
+	// The context may be cancelled by a shutdown; the outcome is still worth keeping.
+	if err := d.deliveries.RecordAttempt(context.WithoutCancel(ctx), delivery); err != nil {
+		d.logger.Errorf("Failed to record attempt of delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// send makes the signed request of a delivery. Any response outside 2xx is a failure.
+func (d *Deliverer) send(ctx context.Context, delivery models.Delivery) models.DeliveryResult {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return models.DeliveryResult{Err: err}
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fakeid-webhooks/1")
+	req.Header.Set(signature.IDHeader, delivery.EventID)
+	req.Header.Set(signature.EventHeader, models.EventTaskFinished)
+	req.Header.Set(signature.TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(signature.SignatureHeader, signature.Sign(delivery.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return models.DeliveryResult{Err: err}
+	}
+	defer resp.Body.Close()
+
+	// The response body is not kept: the deliveries API shows the last error to the owner
+	// of the webhook, who must not be able to read responses of whatever the URL reaches.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return models.DeliveryResult{
+			ResponseStatus: resp.StatusCode,
+			Err:            fmt.Errorf("status %d", resp.StatusCode),
+		}
+	}
+	return models.DeliveryResult{ResponseStatus: resp.StatusCode}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"notification-service/internal/models"
+	"notification-service/pkg/signature"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDeliveryRepository — очередь доставок в памяти, запоминающая результаты попыток.
+type fakeDeliveryRepository struct {
+	mu        sync.Mutex
+	due       []models.Delivery
+	recorded  []models.Delivery
+	enqueued  []string
+	payloads  [][]byte
+	webhooks  int
+	redeliver func(webhookID, deliveryID int64) error
+}
+
+func (f *fakeDeliveryRepository) EnqueueDeliveries(ctx context.Context, notification models.TaskNotification, payload []byte) (int, error) {
+	f.enqueued = append(f.enqueued, notification.EventID())
+	f.payloads = append(f.payloads, payload)
+	return f.webhooks, nil
+}
+
+func (f *fakeDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+	due := f.due
+	f.due = nil
+	return due, nil
+}
+
+func (f *fakeDeliveryRepository) RecordAttempt(ctx context.Context, delivery models.Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recorded = append(f.recorded, delivery)
+	return nil
+}
+
+func (f *fakeDeliveryRepository) ListDeliveries(ctx context.Context, webhookID int64, status string, limit int) ([]models.Delivery, error) {
+	return []models.Delivery{{ID: 1, WebhookID: webhookID, Status: status}}, nil
+}
+
+func (f *fakeDeliveryRepository) Redeliver(ctx context.Context, webhookID, deliveryID int64) error {
+	return f.redeliver(webhookID, deliveryID)
+}
+
+func newTestDeliverer(repo *fakeDeliveryRepository, maxAttempts int) *Deliverer {
+	return NewDeliverer(repo, http.DefaultClient, time.Second, time.Second, 10, 2, maxAttempts,
+		Backoff{Base: 10 * time.Second, Max: time.Minute}, zap.NewNop().Sugar())
+}
+
+// TestDeliverer_Delivered проверяет подписанный запрос и отметку успешной доставки.
+func TestDeliverer_Delivered(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := []byte(`{"id":"task-1.succeeded"}`)
+	repo := &fakeDeliveryRepository{due: []models.Delivery{
+		{ID: 1, WebhookID: 2, EventID: "task-1.succeeded", Payload: payload, URL: server.URL, Secret: "whsec_test"},
+	}}
+
+	sent, err := newTestDeliverer(repo, 3).DeliverOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	require.NotNil(t, got)
+	assert.Equal(t, payload, body)
+	assert.Equal(t, "task-1.succeeded", got.Header.Get(signature.IDHeader))
+	assert.Equal(t, models.EventTaskFinished, got.Header.Get(signature.EventHeader))
+	assert.NoError(t, signature.Verify("whsec_test", got.Header.Get(signature.TimestampHeader), got.Header.Get(signature.SignatureHeader), body, time.Now(), time.Minute))
+
+	require.Len(t, repo.recorded, 1)
+	assert.Equal(t, models.DeliveryDelivered, repo.recorded[0].Status)
+	assert.Equal(t, 1, repo.recorded[0].Attempts)
+	assert.Equal(t, http.StatusNoContent, repo.recorded[0].ResponseStatus)
+	assert.NotNil(t, repo.recorded[0].DeliveredAt)
+}
+
+// TestDeliverer_Retry проверяет перенос неудачной доставки с экспоненциальной задержкой.
+func TestDeliverer_Retry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := &fakeDeliveryRepository{due: []models.Delivery{
+		{ID: 1, EventID: "task-1.failed", Payload: []byte(`{}`), URL: server.URL, Attempts: 2},
+	}}
+
+	before := time.Now()
+	_, err := newTestDeliverer(repo, 5).DeliverOnce(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, repo.recorded, 1)
+	delivery := repo.recorded[0]
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.Equal(t, "status 503", delivery.LastError)
+	assert.WithinDuration(t, before.Add(40*time.Second), delivery.NextAttemptAt, 5*time.Second)
+	assert.Nil(t, delivery.DeliveredAt)
+}
+
+// TestDeliverer_Dead проверяет перевод доставки в dead-letter после последней попытки.
+func TestDeliverer_Dead(t *testing.T) {
+	repo := &fakeDeliveryRepository{due: []models.Delivery{
+		{ID: 1, EventID: "task-1.failed", Payload: []byte(`{}`), URL: "http://127.0.0.1:1", Attempts: 2},
+	}}
+
+	_, err := newTestDeliverer(repo, 3).DeliverOnce(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, repo.recorded, 1)
+	assert.Equal(t, models.DeliveryDead, repo.recorded[0].Status)
+	assert.Equal(t, 3, repo.recorded[0].Attempts)
+	assert.NotEmpty(t, repo.recorded[0].LastError)
+}
+
+// TestBackoff_Delay проверяет удвоение задержки и её ограничение сверху.
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Base: 10 * time.Second, Max: time.Minute}
+	assert.Equal(t, 10*time.Second, b.Delay(1))
+	assert.Equal(t, 20*time.Second, b.Delay(2))
+	assert.Equal(t, 40*time.Second, b.Delay(3))
+	assert.Equal(t, time.Minute, b.Delay(4))
+	assert.Equal(t, time.Minute, b.Delay(100))
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// Dispatcher turns task notifications into deliveries to the webhooks of their users.
+type Dispatcher struct {
+	deliveries repository.DeliveryRepository
+	logger     *zap.SugaredLogger
+}
+
+func NewDispatcher(deliveries repository.DeliveryRepository, logger *zap.SugaredLogger) *Dispatcher {
+	return &Dispatcher{deliveries: deliveries, logger: logger}
+}
+
+// Dispatch queues a delivery of the notification for every webhook subscribed to it. A
+// notification received again queues nothing new.
+func (d *Dispatcher) Dispatch(ctx context.Context, notification models.TaskNotification) error {
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:        notification.EventID(),
+		Type:      models.EventTaskFinished,
+		CreatedAt: notification.FinishedAt,
+		Data:      notification,
+	})
+	if err != nil {
+		return err
+	}
+
+	queued, err := d.deliveries.EnqueueDeliveries(ctx, notification, payload)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		d.logger.Infof("Task %s %s: %d webhook deliveries queued", notification.TaskID, notification.Status, queued)
+	}
+	return nil
+}
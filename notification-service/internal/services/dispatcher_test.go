@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"notification-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestDispatcher_Dispatch проверяет тело вебхука, которое ставится в очередь для уведомления.
+func TestDispatcher_Dispatch(t *testing.T) {
+	repo := &fakeDeliveryRepository{webhooks: 2}
+	finished := time.Date(2025, time.March, 14, 10, 0, 0, 0, time.UTC)
+	notification := models.TaskNotification{TaskID: "task-1", UserID: "42", Type: "generate", Status: models.StatusSucceeded, RecordsGenerated: 100, FinishedAt: finished}
+
+	require.NoError(t, NewDispatcher(repo, zap.NewNop().Sugar()).Dispatch(context.Background(), notification))
+
+	assert.Equal(t, []string{"task-1.succeeded"}, repo.enqueued)
+	var event models.WebhookEvent
+	require.NoError(t, json.Unmarshal(repo.payloads[0], &event))
+	assert.Equal(t, models.WebhookEvent{ID: "task-1.succeeded", Type: models.EventTaskFinished, CreatedAt: finished, Data: notification}, event)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"egress"
+	"encoding/hex"
+	"fmt"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultDeliveriesLimit is how many deliveries are listed when the caller sets no limit.
+const defaultDeliveriesLimit = 50
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, userID string, req models.WebhookRequest) (*models.Webhook, error)
+	GetWebhook(ctx context.Context, id int64, userID string) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int64, userID string, req models.WebhookRequest) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64, userID string) error
+	ListDeliveries(ctx context.Context, id int64, userID, status string, limit int) ([]models.Delivery, error)
+	Redeliver(ctx context.Context, id int64, userID string, deliveryID int64) error
+}
+
+type webhookService struct {
+	webhooks   repository.WebhookRepository
+	deliveries repository.DeliveryRepository
+	resolver   egress.Resolver
+	logger     *zap.SugaredLogger
+}
+
+// NewWebhookService creates the webhook service; resolver resolves the hosts of webhook
+// URLs, which must not point into internal networks.
+func NewWebhookService(webhooks repository.WebhookRepository, deliveries repository.DeliveryRepository, resolver egress.Resolver, logger *zap.SugaredLogger) WebhookService {
+	return &webhookService{webhooks: webhooks, deliveries: deliveries, resolver: resolver, logger: logger}
+}
+
+// CreateWebhook registers a webhook with a new signing secret. The returned webhook is the
+// only one that carries the secret.
+func (s *webhookService) CreateWebhook(ctx context.Context, userID string, req models.WebhookRequest) (*models.Webhook, error) {
+	if err := s.checkURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	webhook := models.Webhook{UserID: userID, Secret: secret, CreatedAt: now}
+	applyWebhookRequest(&webhook, req, now)
+
+	id, err := s.webhooks.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.ID = id
+	s.logger.Infof("Webhook %d registered for user %s", id, userID)
+	return &webhook, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, id int64, userID string) (*models.Webhook, error) {
+	webhook, err := s.webhooks.GetWebhook(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error) {
+	webhooks, err := s.webhooks.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+func (s *webhookService) UpdateWebhook(ctx context.Context, id int64, userID string, req models.WebhookRequest) (*models.Webhook, error) {
+	webhook, err := s.webhooks.GetWebhook(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	applyWebhookRequest(webhook, req, time.Now().UTC())
+
+	if err := s.webhooks.UpdateWebhook(ctx, *webhook); err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = ""
+	s.logger.Infof("Webhook %d updated", id)
+	return webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id int64, userID string) error {
+	if err := s.webhooks.DeleteWebhook(ctx, id, userID); err != nil {
+		return err
+	}
+	s.logger.Infof("Webhook %d deleted", id)
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of a webhook; status "dead" lists its
+// dead-letter log.
+func (s *webhookService) ListDeliveries(ctx context.Context, id int64, userID, status string, limit int) ([]models.Delivery, error) {
+	if _, err := s.webhooks.GetWebhook(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveriesLimit
+	}
+	return s.deliveries.ListDeliveries(ctx, id, status, limit)
+}
+
+// Redeliver retries a dead delivery from its first attempt.
+func (s *webhookService) Redeliver(ctx context.Context, id int64, userID string, deliveryID int64) error {
+	if _, err := s.webhooks.GetWebhook(ctx, id, userID); err != nil {
+		return err
+	}
+	if err := s.deliveries.Redeliver(ctx, id, deliveryID); err != nil {
+		return err
+	}
+	s.logger.Infof("Delivery %d of webhook %d queued for redelivery", deliveryID, id)
+	return nil
+}
+
+// checkURL rejects webhook URLs whose host resolves to a loopback, private or link-local
+// address. The deliverer checks the address again when it connects.
+func (s *webhookService) checkURL(ctx context.Context, rawURL string) error {
+	if err := egress.CheckURL(ctx, s.resolver, rawURL); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrForbiddenURL, err)
+	}
+	return nil
+}
+
+func applyWebhookRequest(webhook *models.Webhook, req models.WebhookRequest, now time.Time) {
+	webhook.URL = req.URL
+	webhook.Description = req.Description
+	webhook.Statuses = req.Statuses
+	if webhook.Statuses == nil {
+		webhook.Statuses = []string{}
+	}
+	webhook.Enabled = req.Enabled == nil || *req.Enabled
+	webhook.UpdatedAt = now
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"net/netip"
+	"notification-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeWebhookRepository — вебхуки в памяти с проверкой владельца, как в Postgres.
+type fakeWebhookRepository struct {
+	webhooks map[int64]models.Webhook
+}
+
+func (f *fakeWebhookRepository) CreateWebhook(ctx context.Context, webhook models.Webhook) (int64, error) {
+	webhook.ID = int64(len(f.webhooks) + 1)
+	f.webhooks[webhook.ID] = webhook
+	return webhook.ID, nil
+}
+
+func (f *fakeWebhookRepository) GetWebhook(ctx context.Context, id int64, userID string) (*models.Webhook, error) {
+	webhook, ok := f.webhooks[id]
+	if !ok || webhook.UserID != userID {
+		return nil, models.ErrWebhookNotFound
+	}
+	return &webhook, nil
+}
+
+func (f *fakeWebhookRepository) ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error) {
+	var out []models.Webhook
+	for _, webhook := range f.webhooks {
+		if webhook.UserID == userID {
+			out = append(out, webhook)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWebhookRepository) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	f.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (f *fakeWebhookRepository) DeleteWebhook(ctx context.Context, id int64, userID string) error {
+	if _, err := f.GetWebhook(ctx, id, userID); err != nil {
+		return err
+	}
+	delete(f.webhooks, id)
+	return nil
+}
+
+// fakeResolver — резолвер с фиксированными адресами хостов.
+type fakeResolver map[string]string
+
+func (f fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	return []netip.Addr{netip.MustParseAddr(f[host])}, nil
+}
+
+func newTestWebhookService() (WebhookService, *fakeWebhookRepository, *fakeDeliveryRepository) {
+	webhooks := &fakeWebhookRepository{webhooks: map[int64]models.Webhook{}}
+	deliveries := &fakeDeliveryRepository{}
+	resolver := fakeResolver{"ci.example.com": "93.184.216.34", "metadata.internal": "169.254.169.254"}
+	return NewWebhookService(webhooks, deliveries, resolver, zap.NewNop().Sugar()), webhooks, deliveries
+}
+
+// TestWebhookService_Create проверяет, что секрет генерируется и показывается только при создании.
+func TestWebhookService_Create(t *testing.T) {
+	service, repo, _ := newTestWebhookService()
+
+	created, err := service.CreateWebhook(context.Background(), "42", models.WebhookRequest{URL: "https://ci.example.com/hook"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, created.Secret)
+	assert.True(t, created.Enabled)
+	assert.Equal(t, []string{}, created.Statuses)
+	assert.Equal(t, created.Secret, repo.webhooks[created.ID].Secret)
+
+	got, err := service.GetWebhook(context.Background(), created.ID, "42")
+	require.NoError(t, err)
+	assert.Empty(t, got.Secret)
+
+	list, err := service.ListWebhooks(context.Background(), "42")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Secret)
+}
+
+// TestWebhookService_ForbiddenURL проверяет отказ для URL, указывающих во внутреннюю сеть.
+func TestWebhookService_ForbiddenURL(t *testing.T) {
+	service, repo, _ := newTestWebhookService()
+
+	_, err := service.CreateWebhook(context.Background(), "42", models.WebhookRequest{URL: "http://metadata.internal/latest"})
+	assert.ErrorIs(t, err, models.ErrForbiddenURL)
+	assert.Empty(t, repo.webhooks)
+
+	created, err := service.CreateWebhook(context.Background(), "42", models.WebhookRequest{URL: "https://ci.example.com/hook"})
+	require.NoError(t, err)
+	_, err = service.UpdateWebhook(context.Background(), created.ID, "42", models.WebhookRequest{URL: "http://metadata.internal/latest"})
+	assert.ErrorIs(t, err, models.ErrForbiddenURL)
+	assert.Equal(t, "https://ci.example.com/hook", repo.webhooks[created.ID].URL)
+}
+
+// TestWebhookService_OtherUser проверяет, что чужой вебхук недоступен.
+func TestWebhookService_OtherUser(t *testing.T) {
+	service, _, _ := newTestWebhookService()
+
+	created, err := service.CreateWebhook(context.Background(), "42", models.WebhookRequest{URL: "https://ci.example.com/hook"})
+	require.NoError(t, err)
+
+	_, err = service.GetWebhook(context.Background(), created.ID, "7")
+	assert.ErrorIs(t, err, models.ErrWebhookNotFound)
+	_, err = service.ListDeliveries(context.Background(), created.ID, "7", "", 0)
+	assert.ErrorIs(t, err, models.ErrWebhookNotFound)
+	assert.ErrorIs(t, service.Redeliver(context.Background(), created.ID, "7", 1), models.ErrWebhookNotFound)
+	assert.ErrorIs(t, service.DeleteWebhook(context.Background(), created.ID, "7"), models.ErrWebhookNotFound)
+}
+
+// TestWebhookService_UpdateKeepsSecret проверяет, что обновление не меняет секрет.
+func TestWebhookService_UpdateKeepsSecret(t *testing.T) {
+	service, repo, _ := newTestWebhookService()
+
+	created, err := service.CreateWebhook(context.Background(), "42", models.WebhookRequest{URL: "https://ci.example.com/hook"})
+	require.NoError(t, err)
+
+	disabled := false
+	updated, err := service.UpdateWebhook(context.Background(), created.ID, "42", models.WebhookRequest{
+		URL:      "https://ci.example.com/v2",
+		Statuses: []string{models.StatusFailed},
+		Enabled:  &disabled,
+	})
+	require.NoError(t, err)
+	assert.False(t, updated.Enabled)
+	assert.Empty(t, updated.Secret)
+	assert.Equal(t, created.Secret, repo.webhooks[created.ID].Secret)
+	assert.Equal(t, "https://ci.example.com/v2", repo.webhooks[created.ID].URL)
+}
+
+// TestWebhookService_Redeliver проверяет повторную отправку из dead-letter.
+func TestWebhookService_Redeliver(t *testing.T) {
+	service, _, deliveries := newTestWebhookService()
+	deliveries.redeliver = func(webhookID, deliveryID int64) error {
+		if deliveryID == 5 {
+			return nil
+		}
+		return models.ErrNotDead
+	}
+
+	created, err := service.CreateWebhook(context.Background(), "42", models.WebhookRequest{URL: "https://ci.example.com/hook"})
+	require.NoError(t, err)
+
+	assert.NoError(t, service.Redeliver(context.Background(), created.ID, "42", 5))
+	assert.ErrorIs(t, service.Redeliver(context.Background(), created.ID, "42", 6), models.ErrNotDead)
+}
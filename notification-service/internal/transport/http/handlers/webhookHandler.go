@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"notification-service/internal/middleware"
+	"notification-service/internal/models"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, userID string, req models.WebhookRequest) (*models.Webhook, error)
+	GetWebhook(ctx context.Context, id int64, userID string) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int64, userID string, req models.WebhookRequest) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64, userID string) error
+	ListDeliveries(ctx context.Context, id int64, userID, status string, limit int) ([]models.Delivery, error)
+	Redeliver(ctx context.Context, id int64, userID string, deliveryID int64) error
+}
+
+type WebhookHandler struct {
+	service WebhookService
+	logger  *zap.SugaredLogger
+}
+
+func NewWebhookHandler(service WebhookService, logger *zap.SugaredLogger) *WebhookHandler {
+	return &WebhookHandler{service: service, logger: logger}
+}
+
+// CreateWebhook registers a webhook. The response is the only one to include its secret.
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	req, err := bindWebhookRequest(c)
+	if err != nil {
+		logger.Errorf("Invalid webhook request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	webhook, err := h.service.CreateWebhook(c.Request().Context(), userID(c), req)
+	switch {
+	case errors.Is(err, models.ErrForbiddenURL):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case err != nil:
+		logger.Errorf("Failed to create webhook: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to create webhook"})
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) GetWebhook(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	webhook, err := h.service.GetWebhook(c.Request().Context(), id, userID(c))
+	switch {
+	case errors.Is(err, models.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+	case err != nil:
+		logger.Errorf("Failed to get webhook %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get webhook"})
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) ListWebhooks(c echo.Context) error {
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	webhooks, err := h.service.ListWebhooks(c.Request().Context(), userID(c))
+	if err != nil {
+		logger.Errorf("Failed to list webhooks: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to retrieve webhooks"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": webhooks})
+}
+
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	req, err := bindWebhookRequest(c)
+	if err != nil {
+		logger.Errorf("Invalid webhook request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	webhook, err := h.service.UpdateWebhook(c.Request().Context(), id, userID(c), req)
+	switch {
+	case errors.Is(err, models.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+	case errors.Is(err, models.ErrForbiddenURL):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case err != nil:
+		logger.Errorf("Failed to update webhook %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to update webhook"})
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	err = h.service.DeleteWebhook(c.Request().Context(), id, userID(c))
+	switch {
+	case errors.Is(err, models.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+	case err != nil:
+		logger.Errorf("Failed to delete webhook %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to delete webhook"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListDeliveries returns the latest deliveries of a webhook; ?status=dead lists its
+// dead-letter log.
+func (h *WebhookHandler) ListDeliveries(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+
+	status := c.QueryParam("status")
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit > 100 {
+		limit = 100
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	deliveries, err := h.service.ListDeliveries(c.Request().Context(), id, userID(c), status, limit)
+	switch {
+	case errors.Is(err, models.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+	case err != nil:
+		logger.Errorf("Failed to list deliveries of webhook %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to retrieve deliveries"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": deliveries})
+}
+
+// Redeliver retries a delivery from the dead-letter log.
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil || deliveryID < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid delivery ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	err = h.service.Redeliver(c.Request().Context(), id, userID(c), deliveryID)
+	switch {
+	case errors.Is(err, models.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+	case errors.Is(err, models.ErrDeliveryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "delivery not found"})
+	case errors.Is(err, models.ErrNotDead):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		logger.Errorf("Failed to redeliver delivery %d: %v", deliveryID, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to redeliver"})
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func bindWebhookRequest(c echo.Context) (models.WebhookRequest, error) {
+	var req models.WebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return req, errors.New("invalid request")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+// userID returns the caller set by the auth middleware.
+func userID(c echo.Context) string {
+	id, _ := c.Get("user_id").(string)
+	return id
+}
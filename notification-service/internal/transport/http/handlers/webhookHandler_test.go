@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"notification-service/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, userID string, req models.WebhookRequest) (*models.Webhook, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhook(ctx context.Context, id int64, userID string) (*models.Webhook, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, id int64, userID string, req models.WebhookRequest) (*models.Webhook, error) {
+	args := m.Called(ctx, id, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, id int64, userID string) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, id int64, userID, status string, limit int) ([]models.Delivery, error) {
+	args := m.Called(ctx, id, userID, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, id int64, userID string, deliveryID int64) error {
+	args := m.Called(ctx, id, userID, deliveryID)
+	return args.Error(0)
+}
+
+func newWebhookContext(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "42")
+	return c, rec
+}
+
+// TestCreateWebhook проверяет создание вебхука от имени вызывающего пользователя.
+func TestCreateWebhook(t *testing.T) {
+	service := new(MockWebhookService)
+	handler := NewWebhookHandler(service, zap.NewNop().Sugar())
+
+	req := models.WebhookRequest{URL: "https://ci.example.com/hook", Statuses: []string{models.StatusFailed}}
+	service.On("CreateWebhook", mock.Anything, "42", req).
+		Return(&models.Webhook{ID: 1, UserID: "42", URL: req.URL, Secret: "whsec_test"}, nil)
+
+	c, rec := newWebhookContext(http.MethodPost, "/api/v2/webhooks", `{"url":"https://ci.example.com/hook","statuses":["failed"]}`)
+	assert.NoError(t, handler.CreateWebhook(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"secret":"whsec_test"`)
+	service.AssertExpectations(t)
+}
+
+// TestCreateWebhook_Invalid проверяет отклонение некорректного адреса и статуса.
+func TestCreateWebhook_Invalid(t *testing.T) {
+	handler := NewWebhookHandler(new(MockWebhookService), zap.NewNop().Sugar())
+
+	for _, body := range []string{
+		`{"url":"ftp://ci.example.com/hook"}`,
+		`{"url":"https://ci.example.com/hook","statuses":["running"]}`,
+		`{}`,
+	} {
+		c, rec := newWebhookContext(http.MethodPost, "/api/v2/webhooks", body)
+		assert.NoError(t, handler.CreateWebhook(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
+
+// TestCreateWebhook_ForbiddenURL проверяет 400 для адреса во внутренней сети.
+func TestCreateWebhook_ForbiddenURL(t *testing.T) {
+	service := new(MockWebhookService)
+	handler := NewWebhookHandler(service, zap.NewNop().Sugar())
+	service.On("CreateWebhook", mock.Anything, "42", mock.Anything).
+		Return(nil, fmt.Errorf("%w: 169.254.169.254 is not public", models.ErrForbiddenURL))
+
+	c, rec := newWebhookContext(http.MethodPost, "/api/v2/webhooks", `{"url":"http://169.254.169.254/latest"}`)
+	assert.NoError(t, handler.CreateWebhook(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "public address")
+}
+
+// TestGetWebhook_NotFound проверяет 404 для чужого или несуществующего вебхука.
+func TestGetWebhook_NotFound(t *testing.T) {
+	service := new(MockWebhookService)
+	handler := NewWebhookHandler(service, zap.NewNop().Sugar())
+	service.On("GetWebhook", mock.Anything, int64(7), "42").Return(nil, models.ErrWebhookNotFound)
+
+	c, rec := newWebhookContext(http.MethodGet, "/api/v2/webhooks/7", "")
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+	assert.NoError(t, handler.GetWebhook(c))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestListDeliveries проверяет фильтр по статусу и ограничение limit.
+func TestListDeliveries(t *testing.T) {
+	service := new(MockWebhookService)
+	handler := NewWebhookHandler(service, zap.NewNop().Sugar())
+	service.On("ListDeliveries", mock.Anything, int64(1), "42", models.DeliveryDead, 100).
+		Return([]models.Delivery{{ID: 3, WebhookID: 1, Status: models.DeliveryDead}}, nil)
+
+	c, rec := newWebhookContext(http.MethodGet, "/api/v2/webhooks/1/deliveries?status=dead&limit=500", "")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	assert.NoError(t, handler.ListDeliveries(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	service.AssertExpectations(t)
+
+	c, rec = newWebhookContext(http.MethodGet, "/api/v2/webhooks/1/deliveries?status=lost", "")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	assert.NoError(t, handler.ListDeliveries(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestRedeliver проверяет коды ответа повторной отправки.
+func TestRedeliver(t *testing.T) {
+	service := new(MockWebhookService)
+	handler := NewWebhookHandler(service, zap.NewNop().Sugar())
+	service.On("Redeliver", mock.Anything, int64(1), "42", int64(3)).Return(nil)
+	service.On("Redeliver", mock.Anything, int64(1), "42", int64(4)).Return(models.ErrNotDead)
+	service.On("Redeliver", mock.Anything, int64(1), "42", int64(5)).Return(models.ErrDeliveryNotFound)
+
+	for deliveryID, code := range map[string]int{"3": http.StatusAccepted, "4": http.StatusConflict, "5": http.StatusNotFound} {
+		c, rec := newWebhookContext(http.MethodPost, "/api/v2/webhooks/1/deliveries/"+deliveryID+"/redeliver", "")
+		c.SetParamNames("id", "delivery_id")
+		c.SetParamValues("1", deliveryID)
+		assert.NoError(t, handler.Redeliver(c))
+		assert.Equal(t, code, rec.Code, deliveryID)
+	}
+}
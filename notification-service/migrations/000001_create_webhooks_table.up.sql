@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    statuses TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"notification-service/internal/config"
+	"notification-service/internal/models"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// NotificationHandler queues the webhook deliveries of a task notification.
+type NotificationHandler interface {
+	Dispatch(ctx context.Context, notification models.TaskNotification) error
+}
+
+// NotificationConsumer consumes the task notifications published by task-service.
+type NotificationConsumer interface {
+	Consume(ctx context.Context) error
+	Close() error
+}
+
+type notificationConsumer struct {
+	reader  *kafka.Reader
+	logger  *zap.SugaredLogger
+	config  config.KafkaConfig
+	handler NotificationHandler
+}
+
+// NewNotificationConsumer creates a consumer for the task notification topic.
+func NewNotificationConsumer(cfg config.KafkaConfig, handler NotificationHandler, logger *zap.SugaredLogger) NotificationConsumer {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     strings.Split(cfg.Brokers, ","),
+		Topic:       cfg.NotifyTopic,
+		GroupID:     cfg.GroupID,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     1 * time.Second,
+		StartOffset: kafka.FirstOffset,
+	})
+
+	return &notificationConsumer{
+		reader:  reader,
+		logger:  logger,
+		config:  cfg,
+		handler: handler,
+	}
+}
+
+// Consume dispatches notifications until ctx is cancelled. A message is committed only
+// once its deliveries are queued, so a failure retries it instead of losing it.
+func (n *notificationConsumer) Consume(ctx context.Context) error {
+	for {
+		msg, err := n.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				n.logger.Info("Stopping notification consumer due to context cancellation")
+				return nil
+			}
+			return fmt.Errorf("failed to fetch message: %w", err)
+		}
+
+		for {
+			err := n.dispatch(ctx, msg)
+			if err == nil || ctx.Err() != nil {
+				break
+			}
+			n.logger.Errorf("Failed to dispatch notification %s: %v", msg.Key, err)
+			time.Sleep(time.Duration(n.config.RetryDelay) * time.Second)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err := n.reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+			n.logger.Errorf("Failed to commit notification %s: %v", msg.Key, err)
+		}
+	}
+}
+
+func (n *notificationConsumer) dispatch(ctx context.Context, msg kafka.Message) error {
+	var notification models.TaskNotification
+	if err := json.Unmarshal(msg.Value, &notification); err != nil {
+		n.logger.Errorf("Failed to unmarshal task notification: %v", err)
+		return nil // Skip bad messages
+	}
+	return n.handler.Dispatch(ctx, notification)
+}
+
+func (n *notificationConsumer) Close() error {
+	if n.reader != nil {
+		if err := n.reader.Close(); err != nil {
+			n.logger.Errorf("Failed to close notification consumer: %v", err)
+			return err
+		}
+		n.logger.Info("Notification consumer connection closed")
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Package jwks verifies access tokens of auth-service locally with the public keys it
+// publishes at /.well-known/jwks.json (RFC 7517). Keys are cached and refetched
+// periodically and whenever a token names an unknown kid, so key rotations in
+// auth-service need no restart here.
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnknownKey is returned for a token signed with a key auth-service does not publish.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// minRefetch limits how often tokens with unknown kids or failed fetches can trigger a fetch.
+const minRefetch = 5 * time.Second
+
+// SigningMethods are the algorithms auth-service signs access tokens with.
+var SigningMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+type publicKey struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+// Client holds the public keys of auth-service.
+type Client struct {
+	url     string
+	client  HTTPClient
+	refresh time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]publicKey
+	fetchedAt time.Time
+	retryAt   time.Time
+}
+
+// New creates a client for the JWKS at url that refetches keys every refresh interval.
+func New(url string, client HTTPClient, refresh time.Duration) *Client {
+	return &Client{
+		url:     url,
+		client:  client,
+		refresh: refresh,
+		now:     time.Now,
+		keys:    make(map[string]publicKey),
+	}
+}
+
+// Keyfunc returns the public key a token was signed with, for use with jwt.Parse.
+func (c *Client) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	key, err := c.key(context.Background(), kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.algorithm {
+		return nil, errors.New("signing method does not match the key")
+	}
+	return key.key, nil
+}
+
+func (c *Client) key(ctx context.Context, kid string) (publicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	key, ok := c.keys[kid]
+	stale := c.fetchedAt.IsZero() || now.Sub(c.fetchedAt) >= c.refresh
+	if (stale || !ok) && !now.Before(c.retryAt) {
+		c.retryAt = now.Add(minRefetch)
+		keys, err := c.fetch(ctx)
+		if err != nil {
+			// Keep verifying with the known keys while auth-service is unreachable
+			if ok {
+				return key, nil
+			}
+			return publicKey{}, err
+		}
+		c.keys = keys
+		c.fetchedAt = now
+		key, ok = c.keys[kid]
+	}
+	if !ok {
+		return publicKey{}, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func (c *Client) fetch(ctx context.Context) (map[string]publicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signing keys: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode signing keys: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			// A key of an unsupported type must not hide the others
+			continue
+		}
+		keys[k.KeyID] = publicKey{algorithm: k.Algorithm, key: key}
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.KeyType == "RSA" && k.Algorithm == jwt.SigningMethodRS256.Alg():
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.KeyType == "OKP" && k.Curve == "Ed25519" && k.Algorithm == jwt.SigningMethodEdDSA.Alg():
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q with algorithm %q", k.KeyType, k.Algorithm)
+	}
+}
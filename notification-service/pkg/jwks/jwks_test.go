@@ -0,0 +1,130 @@
+package jwks
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyServer struct {
+	keys    atomic.Value
+	fetches atomic.Int32
+	server  *httptest.Server
+}
+
+func newKeyServer(t *testing.T, keys ...jwk) *keyServer {
+	s := &keyServer{}
+	s.keys.Store(keys)
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string][]jwk{"keys": s.keys.Load().([]jwk)})
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func edKey(t *testing.T, kid string) (jwk, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return jwk{KeyType: "OKP", KeyID: kid, Algorithm: "EdDSA", Curve: "Ed25519", X: base64.RawURLEncoding.EncodeToString(public)}, private
+}
+
+func rsaKey(t *testing.T, kid string) (jwk, *rsa.PrivateKey) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return jwk{
+		KeyType:   "RSA",
+		KeyID:     kid,
+		Algorithm: "RS256",
+		N:         base64.RawURLEncoding.EncodeToString(private.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(private.E)).Bytes()),
+	}, private
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"user_id": 42})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func parse(c *Client, token string) error {
+	_, err := jwt.Parse(token, c.Keyfunc, jwt.WithValidMethods(SigningMethods))
+	return err
+}
+
+// TestKeyfunc проверяет проверку токенов ключами RS256 и EdDSA из JWKS.
+func TestKeyfunc(t *testing.T) {
+	edJWK, edPrivate := edKey(t, "ed")
+	rsaJWK, rsaPrivate := rsaKey(t, "rsa")
+	server := newKeyServer(t, edJWK, rsaJWK)
+	client := New(server.server.URL, server.server.Client(), time.Minute)
+
+	assert.NoError(t, parse(client, sign(t, jwt.SigningMethodEdDSA, "ed", edPrivate)))
+	assert.NoError(t, parse(client, sign(t, jwt.SigningMethodRS256, "rsa", rsaPrivate)))
+	assert.Equal(t, int32(1), server.fetches.Load())
+
+	// Токен, подписанный чужим ключом под известным kid
+	_, otherPrivate := edKey(t, "ed")
+	assert.Error(t, parse(client, sign(t, jwt.SigningMethodEdDSA, "ed", otherPrivate)))
+
+	// Алгоритм токена не совпадает с алгоритмом ключа
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{})
+	token.Header["kid"] = "ed"
+	_, err := client.Keyfunc(token)
+	assert.Error(t, err)
+}
+
+// TestKeyfunc_Rotation проверяет подхват нового ключа по неизвестному kid и ограничение частоты запросов.
+func TestKeyfunc_Rotation(t *testing.T) {
+	oldJWK, _ := edKey(t, "old")
+	server := newKeyServer(t, oldJWK)
+	client := New(server.server.URL, server.server.Client(), time.Hour)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+
+	newJWK, newPrivate := edKey(t, "new")
+	token := sign(t, jwt.SigningMethodEdDSA, "new", newPrivate)
+
+	// Первый запрос загружает ключи, повторный в пределах minRefetch не ходит в auth-service
+	assert.ErrorIs(t, parse(client, token), ErrUnknownKey)
+	assert.ErrorIs(t, parse(client, token), ErrUnknownKey)
+	assert.Equal(t, int32(1), server.fetches.Load())
+
+	server.keys.Store([]jwk{oldJWK, newJWK})
+	client.now = func() time.Time { return now.Add(minRefetch) }
+	assert.NoError(t, parse(client, token))
+	assert.Equal(t, int32(2), server.fetches.Load())
+}
+
+// TestKeyfunc_Unavailable проверяет, что известные ключи работают, пока auth-service недоступен.
+func TestKeyfunc_Unavailable(t *testing.T) {
+	edJWK, edPrivate := edKey(t, "ed")
+	server := newKeyServer(t, edJWK)
+	client := New(server.server.URL, server.server.Client(), time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+
+	token := sign(t, jwt.SigningMethodEdDSA, "ed", edPrivate)
+	require.NoError(t, parse(client, token))
+
+	server.server.Close()
+	client.now = func() time.Time { return now.Add(time.Hour) }
+	assert.NoError(t, parse(client, token))
+
+	_, otherPrivate := edKey(t, "other")
+	assert.Error(t, parse(client, sign(t, jwt.SigningMethodEdDSA, "other", otherPrivate)))
+}
@@ -0,0 +1,65 @@
+// Package signature signs webhook requests and verifies their signatures.
+//
+// A request is signed with HMAC-SHA256 of "<timestamp>.<body>" under the secret of its
+// webhook. The timestamp is sent in the TimestampHeader as Unix seconds and the signature
+// in the SignatureHeader as "sha256=<hex digest>". Receivers should reject requests whose
+// timestamp is older than a few minutes, so that a captured request cannot be replayed.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	IDHeader        = "X-Webhook-Id"
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+
+	prefix = "sha256="
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpired          = errors.New("webhook timestamp outside of tolerance")
+)
+
+// Sign returns the value of the SignatureHeader for body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return prefix + hex.EncodeToString(digest(secret, strconv.FormatInt(timestamp.Unix(), 10), body))
+}
+
+// Verify checks the headers of a request received at now against its body. Timestamps
+// further than tolerance from now are rejected.
+func Verify(secret, timestamp, sig string, body []byte, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return ErrExpired
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(sig, prefix))
+	if err != nil || !strings.HasPrefix(sig, prefix) {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal(got, digest(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func digest(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
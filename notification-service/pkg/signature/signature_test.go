@@ -0,0 +1,24 @@
+package signature
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSignVerify проверяет подпись запроса и отказ при подмене тела, секрета или времени.
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"task-1.succeeded"}`)
+	sentAt := time.Unix(1700000000, 0)
+	sig := Sign("whsec_test", sentAt, body)
+
+	assert.Equal(t, "sha256=", sig[:7])
+	assert.NoError(t, Verify("whsec_test", "1700000000", sig, body, sentAt.Add(time.Minute), 5*time.Minute))
+
+	assert.ErrorIs(t, Verify("whsec_test", "1700000000", sig, []byte(`{}`), sentAt, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec_other", "1700000000", sig, body, sentAt, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec_test", "1700000000", sig[7:], body, sentAt, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec_test", "1700000000", sig, body, sentAt.Add(time.Hour), 5*time.Minute), ErrExpired)
+	assert.ErrorIs(t, Verify("whsec_test", "soon", sig, body, sentAt, 5*time.Minute), ErrInvalidSignature)
+}
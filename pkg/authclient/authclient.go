@@ -0,0 +1,171 @@
+// Package authclient checks access tokens against the introspection endpoint of
+// auth-service (RFC 7662) and caches active tokens for a short time.
+package authclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenTypeAPIKey is the token type of personal API keys, which are limited to their scopes.
+const TokenTypeAPIKey = "api_key"
+
+// ErrInactive is returned for a token that is expired, revoked or not issued by auth-service.
+var ErrInactive = errors.New("token is not active")
+
+// maxCacheEntries bounds the cache; when it is full, expired entries are dropped and, if
+// that is not enough, the whole cache.
+const maxCacheEntries = 10000
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Introspection is what auth-service knows about an active token.
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Subject   string `json:"sub"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type"`
+	Scope     string `json:"scope"`
+	ExpiresAt int64  `json:"exp"`
+	IssuedAt  int64  `json:"iat"`
+}
+
+// Allows reports whether the token grants scope. Access tokens of a login may do
+// everything their user may; API keys only what their scopes allow.
+func (i *Introspection) Allows(scope string) bool {
+	if i.TokenType != TokenTypeAPIKey {
+		return true
+	}
+	for _, s := range strings.Fields(i.Scope) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type cachedToken struct {
+	introspection Introspection
+	expiresAt     time.Time
+}
+
+// Client introspects tokens. Only active tokens are cached, for at most ttl and never
+// past their expiry, so a token revoked by a logout is still accepted for up to ttl.
+type Client struct {
+	url    string
+	client HTTPClient
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedToken
+}
+
+// New creates a client for the introspection endpoint at url. A zero ttl disables the cache.
+func New(url string, client HTTPClient, ttl time.Duration) *Client {
+	return &Client{
+		url:    url,
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+		cache:  make(map[string]cachedToken),
+	}
+}
+
+// Introspect returns the introspection of an active token, or ErrInactive.
+func (c *Client) Introspect(ctx context.Context, token string) (*Introspection, error) {
+	// Tokens are cached by hash, so the cache does not hold usable credentials.
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	now := c.now()
+	if introspection, ok := c.cached(key, now); ok {
+		return &introspection, nil
+	}
+
+	introspection, err := c.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !introspection.Active {
+		return nil, ErrInactive
+	}
+
+	expiresAt := now.Add(c.ttl)
+	if introspection.ExpiresAt > 0 {
+		if exp := time.Unix(introspection.ExpiresAt, 0); exp.Before(expiresAt) {
+			expiresAt = exp
+		}
+	}
+	if expiresAt.After(now) {
+		c.store(key, *introspection, expiresAt, now)
+	}
+	return introspection, nil
+}
+
+func (c *Client) introspect(ctx context.Context, token string) (*Introspection, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to introspect token: status %d", resp.StatusCode)
+	}
+
+	var introspection Introspection
+	if err := json.NewDecoder(resp.Body).Decode(&introspection); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+	return &introspection, nil
+}
+
+func (c *Client) cached(key string, now time.Time) (Introspection, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.cache[key]
+	if !ok || !now.Before(cached.expiresAt) {
+		return Introspection{}, false
+	}
+	return cached.introspection, true
+}
+
+func (c *Client) store(key string, introspection Introspection, expiresAt, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.cache) >= maxCacheEntries {
+		for k, cached := range c.cache {
+			if !now.Before(cached.expiresAt) {
+				delete(c.cache, k)
+			}
+		}
+		if len(c.cache) >= maxCacheEntries {
+			clear(c.cache)
+		}
+	}
+	c.cache[key] = cachedToken{introspection: introspection, expiresAt: expiresAt}
+}
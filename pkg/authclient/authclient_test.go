@@ -0,0 +1,126 @@
+package authclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIntrospectionServer отвечает активным токеном на "good" и неактивным на остальные.
+func newIntrospectionServer(t *testing.T, exp int64) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		assert.Empty(t, r.URL.RawQuery)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostFormValue("token") {
+		case "good":
+			_, _ = w.Write([]byte(`{"active":true,"sub":"42","user_id":"42","token_type":"Bearer","exp":` + strconv.FormatInt(exp, 10) + `}`))
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(`{"active":false}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// TestIntrospect_CachesActive проверяет, что активный токен кешируется до истечения TTL.
+func TestIntrospect_CachesActive(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server, calls := newIntrospectionServer(t, now.Add(time.Hour).Unix())
+	client := New(server.URL, server.Client(), 30*time.Second)
+	client.now = func() time.Time { return now }
+
+	for range 3 {
+		introspection, err := client.Introspect(context.Background(), "good")
+		require.NoError(t, err)
+		assert.Equal(t, "42", introspection.UserID)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	now = now.Add(31 * time.Second)
+	_, err := client.Introspect(context.Background(), "good")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestIntrospect_CacheStopsAtExpiry проверяет, что токен не кешируется дольше своего срока.
+func TestIntrospect_CacheStopsAtExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server, calls := newIntrospectionServer(t, now.Add(5*time.Second).Unix())
+	client := New(server.URL, server.Client(), time.Minute)
+	client.now = func() time.Time { return now }
+
+	_, err := client.Introspect(context.Background(), "good")
+	require.NoError(t, err)
+
+	now = now.Add(6 * time.Second)
+	_, err = client.Introspect(context.Background(), "good")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestIntrospect_Inactive проверяет, что неактивные токены и ошибки не кешируются.
+func TestIntrospect_Inactive(t *testing.T) {
+	server, calls := newIntrospectionServer(t, 0)
+	client := New(server.URL, server.Client(), time.Minute)
+
+	for range 2 {
+		_, err := client.Introspect(context.Background(), "revoked")
+		assert.ErrorIs(t, err, ErrInactive)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	_, err := client.Introspect(context.Background(), "broken")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInactive)
+}
+
+// TestIntrospection_Allows проверяет, что API-ключи ограничены своими scope, а токены входа — нет.
+func TestIntrospection_Allows(t *testing.T) {
+	session := &Introspection{Active: true, TokenType: "Bearer"}
+	assert.True(t, session.Allows("tasks:write"))
+
+	apiKey := &Introspection{Active: true, TokenType: TokenTypeAPIKey, Scope: "tasks:write templates:read"}
+	assert.True(t, apiKey.Allows("tasks:write"))
+	assert.True(t, apiKey.Allows("templates:read"))
+	assert.False(t, apiKey.Allows("tasks:read"))
+	assert.False(t, apiKey.Allows("tasks"))
+}
+
+// TestTokenFromHeader проверяет выбор токена из Authorization и X-API-Key.
+func TestTokenFromHeader(t *testing.T) {
+	tests := []struct {
+		authorization, apiKey, want string
+	}{
+		{"Bearer good", "", "good"},
+		{"Bearer good", "tsk_ci", "good"},
+		{"", "tsk_ci", "tsk_ci"},
+		{"Basic dXNlcjpwYXNz", "tsk_ci", "tsk_ci"},
+		{"Bearer ", "tsk_ci", "tsk_ci"},
+		{"Basic dXNlcjpwYXNz", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.authorization != "" {
+			header.Set("Authorization", tt.authorization)
+		}
+		if tt.apiKey != "" {
+			header.Set(APIKeyHeader, tt.apiKey)
+		}
+		assert.Equal(t, tt.want, TokenFromHeader(header), tt)
+	}
+}
@@ -0,0 +1,19 @@
+package authclient
+
+import (
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader carries a personal API key, as an alternative to a bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// TokenFromHeader returns the credential of a request: the bearer token of the
+// Authorization header or, if that header carries none, the API key in APIKeyHeader.
+// It returns "" if the request has neither.
+func TokenFromHeader(header http.Header) string {
+	if token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer "); ok && token != "" {
+		return token
+	}
+	return header.Get(APIKeyHeader)
+}
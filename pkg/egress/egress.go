@@ -0,0 +1,102 @@
+// Package egress keeps requests to user-chosen URLs, such as webhooks and the requests
+// of http tasks, away from internal networks.
+//
+// Without a check a service could be made to send requests to itself, to other services
+// of the deployment or to cloud metadata endpoints. CheckURL rejects URLs whose host
+// resolves to such an address when the URL is registered, and the Client checks the
+// address it actually connects to and every redirect, so that a host re-pointed in DNS
+// after registration or a redirect cannot reach them either.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("address is not publicly routable")
+
+// Resolver looks up the addresses of a host; net.DefaultResolver is one.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not public either.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Allowed reports whether addr is a public unicast address.
+func Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL resolves the host of an http(s) URL and fails with ErrForbiddenAddress if any
+// of its addresses is not public.
+func CheckURL(ctx context.Context, resolver Resolver, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	host := u.Hostname()
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !Allowed(addr) {
+			return fmt.Errorf("%s resolves to %s: %w", host, addr, ErrForbiddenAddress)
+		}
+	}
+	return nil
+}
+
+// maxRedirects is how many redirects a Client follows, as many as http.Client does.
+const maxRedirects = 10
+
+// NewClient returns an HTTP client that refuses to connect to addresses that are not
+// public, including the targets of redirects. It does not use a proxy, since the proxy
+// would make the connection instead.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport, CheckRedirect: checkRedirect}
+}
+
+// checkRedirect checks the target of a redirect before it is followed, so that a redirect
+// to another scheme or to an internal host fails with a clear error. The dialer checks
+// the address once more when it connects.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return CheckURL(req.Context(), net.DefaultResolver, req.URL.String())
+}
+
+// control runs after the address to dial has been resolved, so it sees the address the
+// connection is made to.
+func control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !Allowed(addrPort.Addr()) {
+		return fmt.Errorf("dial %s: %w", address, ErrForbiddenAddress)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package egress
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticResolver — резолвер с фиксированными адресами хостов.
+type staticResolver map[string][]netip.Addr
+
+func (r staticResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	return r[host], nil
+}
+
+// TestAllowed проверяет, что разрешены только публичные адреса.
+func TestAllowed(t *testing.T) {
+	for _, addr := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.True(t, Allowed(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{
+		"127.0.0.1", "10.0.0.5", "172.16.3.4", "192.168.1.1", "169.254.169.254", "100.64.0.1",
+		"0.0.0.0", "224.0.0.1", "::1", "fe80::1", "fc00::1", "::ffff:127.0.0.1",
+	} {
+		assert.False(t, Allowed(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+// TestCheckURL проверяет отказ для URL, указывающих во внутреннюю сеть.
+func TestCheckURL(t *testing.T) {
+	resolver := staticResolver{
+		"ci.example.com": {netip.MustParseAddr("93.184.216.34")},
+		"internal.test":  {netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")},
+	}
+	ctx := context.Background()
+
+	assert.NoError(t, CheckURL(ctx, resolver, "https://ci.example.com/hook"))
+	assert.ErrorIs(t, CheckURL(ctx, resolver, "http://internal.test/hook"), ErrForbiddenAddress)
+	assert.ErrorIs(t, CheckURL(ctx, resolver, "http://169.254.169.254/latest/meta-data"), ErrForbiddenAddress)
+	assert.ErrorIs(t, CheckURL(ctx, resolver, "http://[::1]:8080/"), ErrForbiddenAddress)
+	assert.Error(t, CheckURL(ctx, resolver, "ftp://ci.example.com/hook"))
+}
+
+// TestClient_RefusesLoopback проверяет, что клиент не подключается к локальному адресу.
+func TestClient_RefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must not reach the server")
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Get(server.URL)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+}
+
+// TestClient_RefusesRedirect проверяет, что клиент не следует перенаправлению во внутреннюю сеть.
+func TestClient_RefusesRedirect(t *testing.T) {
+	client := NewClient(time.Second)
+	target := httptest.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data", nil)
+	via := []*http.Request{httptest.NewRequest(http.MethodGet, "https://ci.example.com/hook", nil)}
+
+	err := client.CheckRedirect(target, via)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+
+	target = httptest.NewRequest(http.MethodGet, "file:///etc/passwd", nil)
+	assert.Error(t, client.CheckRedirect(target, via))
+}
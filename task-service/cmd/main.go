@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"task-service/internal/config"
+	"task-service/internal/middleware"
+	"task-service/internal/models"
 	"task-service/internal/repository"
 	"task-service/internal/routes"
 	"task-service/internal/services"
@@ -15,10 +19,14 @@ import (
 	"task-service/migrations"
 	"time"
 
+	"authclient"
 	"task-service/pkg/broker/kafka"
 	"task-service/pkg/db/postgres"
 	"task-service/pkg/db/redis"
 	"task-service/pkg/logger"
+	"task-service/pkg/storage"
+
+	"github.com/google/uuid"
 )
 
 func main() {
@@ -71,24 +79,127 @@ func main() {
 		}
 	}()
 
+	// Cancel commands go to their own topic, consumed by every worker instance.
+	cancelKafkaCfg := cfg.Kafka
+	cancelKafkaCfg.Topic = cfg.Kafka.CancelTopic
+	cancelProducer, err := kafka.NewKafkaProducer(ctx, cancelKafkaCfg, log.SugaredLogger)
+	if err != nil {
+		log.Fatal("Failed to initialize Kafka cancel producer: ", err)
+	}
+	defer func() {
+		if err := cancelProducer.Close(); err != nil {
+			log.Errorf("Failed to close Kafka cancel producer: %v", err)
+		}
+	}()
+
+	// Finished tasks are announced to notification-service on their own topic.
+	notifyKafkaCfg := cfg.Kafka
+	notifyKafkaCfg.Topic = cfg.Kafka.NotifyTopic
+	notifyProducer, err := kafka.NewKafkaProducer(ctx, notifyKafkaCfg, log.SugaredLogger)
+	if err != nil {
+		log.Fatal("Failed to initialize Kafka notification producer: ", err)
+	}
+	defer func() {
+		if err := notifyProducer.Close(); err != nil {
+			log.Errorf("Failed to close Kafka notification producer: %v", err)
+		}
+	}()
+
 	//init router
 	routerConfig := http_transport.NewRouterConfig(cfg)
 	router := http_transport.NewRouter(routerConfig, log)
 
 	//init repositories
 	taskRepository := repository.NewTaskRepository(pgClient, log.SugaredLogger)
+	outboxRepository := repository.NewOutboxRepository(pgClient, log.SugaredLogger)
+	scheduleRepository := repository.NewScheduleRepository(pgClient, log.SugaredLogger)
 
 	//init clients
 	templateClient := &http.Client{Timeout: 5 * time.Second}
+	authClient := authclient.New(
+		cfg.Auth.IntrospectURL,
+		&http.Client{Timeout: time.Duration(cfg.Auth.Timeout) * time.Second},
+		time.Duration(cfg.Auth.CacheTTL)*time.Second,
+	)
 
 	//init services
-	taskService := services.NewTaskService(taskRepository, redisClient, kafkaClient, log.SugaredLogger, templateClient)
+	taskService := services.NewTaskService(taskRepository, redisClient, log.SugaredLogger, templateClient, models.ShardPolicy(cfg.Sharding), net.DefaultResolver)
+
+	//init artifact store
+	store, err := storage.New(ctx, cfg.Storage, log.SugaredLogger)
+	if err != nil {
+		log.Fatal("Failed to initialize artifact store: ", err)
+	}
+
+	urlSecret := []byte(cfg.Storage.URLSecret)
+	if len(urlSecret) == 0 {
+		urlSecret = make([]byte, 32)
+		if _, err := rand.Read(urlSecret); err != nil {
+			log.Fatal("Failed to generate result URL secret: ", err)
+		}
+		log.Warn("RESULT_URL_SECRET is not set, signed result URLs will not survive a restart")
+	}
+	scheduleService := services.NewScheduleService(scheduleRepository, log.SugaredLogger)
+	resultService := services.NewResultService(taskService, store, urlSecret, time.Duration(cfg.Storage.URLTTL)*time.Second, log.SugaredLogger)
+
+	//init outbox relay
+	outboxRelay := services.NewOutboxRelay(
+		outboxRepository,
+		map[string]kafka.KafkaProducer{
+			models.EventTaskCreated:   kafkaClient,
+			models.EventTaskMerge:     kafkaClient,
+			models.EventTaskCancelled: cancelProducer,
+			models.EventTaskFinished:  notifyProducer,
+		},
+		taskService,
+		time.Duration(cfg.Outbox.PollInterval)*time.Second,
+		cfg.Outbox.BatchSize,
+		log.SugaredLogger,
+	)
+
+	//init scheduler
+	if cfg.Scheduler.APIKey == "" {
+		log.Warn("SCHEDULER_API_KEY is not set, scheduled tasks cannot fetch their templates")
+	}
+	hostname, _ := os.Hostname()
+	scheduler := services.NewScheduler(
+		scheduleRepository,
+		taskService,
+		cfg.Scheduler.APIKey,
+		hostname+"-"+uuid.NewString(),
+		time.Duration(cfg.Scheduler.PollInterval)*time.Second,
+		time.Duration(cfg.Scheduler.LeaseTTL)*time.Second,
+		cfg.Scheduler.BatchSize,
+		log.SugaredLogger,
+	)
+
+	//init status consumer
+	statusConsumer := kafka.NewStatusConsumer(cfg.Kafka, taskService, log.SugaredLogger)
+	defer func() {
+		if err := statusConsumer.Close(); err != nil {
+			log.Errorf("Failed to close status consumer: %v", err)
+		}
+	}()
+
+	consumeCtx, stopConsuming := context.WithCancel(context.Background())
+	defer stopConsuming()
+	go func() {
+		if err := statusConsumer.Consume(consumeCtx); err != nil {
+			log.Errorf("Status consumer stopped: %v", err)
+		}
+	}()
+	go outboxRelay.Run(consumeCtx)
+	go scheduler.Run(consumeCtx)
 
 	//init handlers
 	taskHandler := handlers.NewTaskHandler(taskService, log.SugaredLogger)
+	resultHandler := handlers.NewResultHandler(resultService, log.SugaredLogger)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService, log.SugaredLogger)
 
 	//init routes
-	routes.SetupTaskRoutes(router.Echo(), taskHandler)
+	auth := middleware.AuthMiddleware(authClient)
+	routes.SetupTaskRoutes(router.Echo(), taskHandler, resultHandler, auth)
+	routes.SetupScheduleRoutes(router.Echo(), scheduleHandler, auth)
 
 	//run server
 	go func() {
@@ -111,6 +222,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Info("Received shutdown signal, shutting down gracefully...")
+	stopConsuming()
 
 	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -39,19 +39,76 @@ type RedisConfig struct {
 }
 
 type KafkaConfig struct {
-	Brokers    string `yaml:"brokers" env:"KAFKA_BROKERS" validate:"required"`
-	Topic      string `yaml:"topic" env:"KAFKA_TOPIC" validate:"required"`
-	MaxRetries int    `yaml:"max_retries" env:"KAFKA_MAX_RETRIES" env-default:"5" validate:"gte=1"`
-	RetryDelay int    `yaml:"retry_delay" env:"KAFKA_RETRY_DELAY" env-default:"3" validate:"gte=1"`
-	Timeout    int    `yaml:"timeout" env:"KAFKA_TIMEOUT" env-default:"5" validate:"gte=1"`
+	Brokers     string `yaml:"brokers" env:"KAFKA_BROKERS" validate:"required"`
+	Topic       string `yaml:"topic" env:"KAFKA_TOPIC" validate:"required"`
+	StatusTopic string `yaml:"status_topic" env:"KAFKA_STATUS_TOPIC" env-default:"task-status" validate:"required"`
+	CancelTopic string `yaml:"cancel_topic" env:"KAFKA_CANCEL_TOPIC" env-default:"task-cancel" validate:"required"`
+	NotifyTopic string `yaml:"notify_topic" env:"KAFKA_NOTIFY_TOPIC" env-default:"task-notifications" validate:"required"`
+	MaxRetries  int    `yaml:"max_retries" env:"KAFKA_MAX_RETRIES" env-default:"5" validate:"gte=1"`
+	RetryDelay  int    `yaml:"retry_delay" env:"KAFKA_RETRY_DELAY" env-default:"3" validate:"gte=1"`
+	Timeout     int    `yaml:"timeout" env:"KAFKA_TIMEOUT" env-default:"5" validate:"gte=1"`
+}
+
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+type StorageConfig struct {
+	Backend     string `yaml:"backend" env:"STORAGE_BACKEND" env-default:"local" validate:"oneof=local s3"`
+	LocalDir    string `yaml:"local_dir" env:"STORAGE_LOCAL_DIR" env-default:"./data" validate:"required_if=Backend local"`
+	S3Endpoint  string `yaml:"s3_endpoint" env:"S3_ENDPOINT" validate:"required_if=Backend s3"`
+	S3Bucket    string `yaml:"s3_bucket" env:"S3_BUCKET" env-default:"fakeid-results" validate:"required_if=Backend s3"`
+	S3AccessKey string `yaml:"s3_access_key" env:"S3_ACCESS_KEY"`
+	S3SecretKey string `yaml:"s3_secret_key" env:"S3_SECRET_KEY"`
+	S3Region    string `yaml:"s3_region" env:"S3_REGION" env-default:"us-east-1"`
+	S3UseSSL    bool   `yaml:"s3_use_ssl" env:"S3_USE_SSL" env-default:"false"`
+	URLSecret   string `yaml:"url_secret" env:"RESULT_URL_SECRET"`
+	URLTTL      int    `yaml:"url_ttl" env:"RESULT_URL_TTL" env-default:"300" validate:"gte=1"`
+}
+
+type OutboxConfig struct {
+	PollInterval int `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" env-default:"1" validate:"gte=1"`
+	BatchSize    int `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" env-default:"100" validate:"gte=1"`
+}
+
+// ShardingConfig controls splitting large tasks into shards generated by several workers.
+type ShardingConfig struct {
+	Threshold int `yaml:"threshold" env:"SHARD_THRESHOLD" env-default:"1000000" validate:"gte=0"`
+	Size      int `yaml:"size" env:"SHARD_SIZE" env-default:"500000" validate:"gte=1"`
+	MaxShards int `yaml:"max_shards" env:"SHARD_MAX_COUNT" env-default:"64" validate:"gte=2"`
+}
+
+// SchedulerConfig controls the scheduler that creates the tasks of cron schedules. Every
+// replica polls, but only the holder of the scheduler lease fires schedules. APIKey is the
+// API key, granted the templates:read scope, that scheduled tasks fetch their templates with;
+// it should belong to a service user, since it is used for the schedules of every user.
+type SchedulerConfig struct {
+	PollInterval int    `yaml:"poll_interval" env:"SCHEDULER_POLL_INTERVAL" env-default:"5" validate:"gte=1"`
+	LeaseTTL     int    `yaml:"lease_ttl" env:"SCHEDULER_LEASE_TTL" env-default:"30" validate:"gtefield=PollInterval"`
+	BatchSize    int    `yaml:"batch_size" env:"SCHEDULER_BATCH_SIZE" env-default:"100" validate:"gte=1"`
+	APIKey       string `yaml:"api_key" env:"SCHEDULER_API_KEY"`
+}
+
+// AuthConfig points at the token introspection endpoint of auth-service. Active tokens
+// are cached for CacheTTL seconds, so a logout takes up to that long to take effect.
+type AuthConfig struct {
+	IntrospectURL string `yaml:"introspect_url" env:"AUTH_INTROSPECT_URL" env-default:"http://auth-service:8080/auth/introspect" validate:"required,url"`
+	Timeout       int    `yaml:"timeout" env:"AUTH_TIMEOUT" env-default:"5" validate:"gte=1"`
+	CacheTTL      int    `yaml:"cache_ttl" env:"AUTH_CACHE_TTL" env-default:"30" validate:"gte=0"`
 }
 
 type Config struct {
-	Env        string         `yaml:"env" env:"ENV" env-default:"prod" validate:"oneof=dev prod test"`
-	HTTPServer HTTPServer     `yaml:"http_server" validate:"required"`
-	Postgres   PostgresConfig `yaml:"postgres" validate:"required"`
-	Redis      RedisConfig    `yaml:"redis" validate:"required"`
-	Kafka      KafkaConfig    `yaml:"kafka" validate:"required"`
+	Env        string          `yaml:"env" env:"ENV" env-default:"prod" validate:"oneof=dev prod test"`
+	HTTPServer HTTPServer      `yaml:"http_server" validate:"required"`
+	Postgres   PostgresConfig  `yaml:"postgres" validate:"required"`
+	Redis      RedisConfig     `yaml:"redis" validate:"required"`
+	Kafka      KafkaConfig     `yaml:"kafka" validate:"required"`
+	Storage    StorageConfig   `yaml:"storage" validate:"required"`
+	Outbox     OutboxConfig    `yaml:"outbox" validate:"required"`
+	Sharding   ShardingConfig  `yaml:"sharding" validate:"required"`
+	Scheduler  SchedulerConfig `yaml:"scheduler" validate:"required"`
+	Auth       AuthConfig      `yaml:"auth" validate:"required"`
 }
 
 func New() (*Config, error) {
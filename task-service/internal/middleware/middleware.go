@@ -1,7 +1,12 @@
 package middleware
 
 import (
+	"authclient"
 	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"task-service/internal/models"
 	"task-service/pkg/logger"
 	"time"
 
@@ -69,6 +74,60 @@ func RequestLogger() echo.MiddlewareFunc {
 	}
 }
 
+// TokenIntrospector checks an access token with auth-service.
+type TokenIntrospector interface {
+	Introspect(ctx context.Context, token string) (*authclient.Introspection, error)
+}
+
+// AuthMiddleware accepts requests bearing an access token or a personal API key that
+// auth-service reports as active. It stores the user it was issued to as "user_id" and
+// "role", and the caller in the request context (see models.CallerFromContext).
+func AuthMiddleware(introspector TokenIntrospector) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := authclient.TokenFromHeader(c.Request().Header)
+			if token == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing bearer token"})
+			}
+
+			introspection, err := introspector.Introspect(c.Request().Context(), token)
+			switch {
+			case errors.Is(err, authclient.ErrInactive):
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			case err != nil:
+				GetLoggerFromCtx(c.Request().Context()).Errorf("Failed to introspect token: %v", err)
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "failed to verify token"})
+			case introspection.UserID == "":
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			}
+
+			caller := models.Caller{UserID: introspection.UserID, Role: introspection.Role, Token: token}
+			if introspection.TokenType == authclient.TokenTypeAPIKey {
+				caller.APIKey = true
+				caller.Scopes = strings.Fields(introspection.Scope)
+			}
+			c.SetRequest(c.Request().WithContext(models.WithCaller(c.Request().Context(), caller)))
+			c.Set("user_id", caller.UserID)
+			c.Set("role", caller.Role)
+			return next(c)
+		}
+	}
+}
+
+// RequireScope rejects requests made with an API key that was not granted scope. It must
+// run after AuthMiddleware.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			caller, _ := models.CallerFromContext(c.Request().Context())
+			if !caller.Allows(scope) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "api key lacks scope " + scope})
+			}
+			return next(c)
+		}
+	}
+}
+
 func GetLoggerFromCtx(ctx context.Context) *zap.SugaredLogger {
 	log, ok := ctx.Value(LoggerKey).(*zap.SugaredLogger)
 	if !ok {
@@ -1,9 +1,13 @@
 package middleware
 
 import (
+	"authclient"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"task-service/internal/models"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -71,3 +75,145 @@ func TestRequestLogger_LogsSuccessAndError(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+type fakeIntrospector struct {
+	tokens map[string]string
+	err    error
+}
+
+func (f *fakeIntrospector) Introspect(ctx context.Context, token string) (*authclient.Introspection, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	userID, ok := f.tokens[token]
+	if !ok {
+		return nil, authclient.ErrInactive
+	}
+	if strings.HasPrefix(token, "tsk_") {
+		return &authclient.Introspection{Active: true, UserID: userID, Role: "user", TokenType: authclient.TokenTypeAPIKey, Scope: "tasks:write templates:read"}, nil
+	}
+	return &authclient.Introspection{Active: true, UserID: userID, Role: "user"}, nil
+}
+
+// TestAuthMiddleware проверяет допуск по токену и ответы на отсутствующий, неактивный токен и сбой auth-service.
+func TestAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		err    error
+		status int
+		userID string
+	}{
+		{name: "active token", header: "Bearer good", status: http.StatusOK, userID: "42"},
+		{name: "missing header", header: "", status: http.StatusUnauthorized},
+		{name: "not bearer", header: "good", status: http.StatusUnauthorized},
+		{name: "inactive token", header: "Bearer revoked", status: http.StatusUnauthorized},
+		{name: "auth service down", header: "Bearer good", err: errors.New("connection refused"), status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var userID string
+			var caller models.Caller
+			handler := AuthMiddleware(&fakeIntrospector{tokens: map[string]string{"good": "42"}, err: tt.err})(func(c echo.Context) error {
+				userID, _ = c.Get("user_id").(string)
+				caller, _ = models.CallerFromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			assert.NoError(t, handler(c))
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.userID, userID)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, models.Caller{UserID: "42", Role: "user", Token: "good"}, caller)
+			}
+		})
+	}
+}
+
+// TestAuthMiddleware_APIKey проверяет допуск по API-ключу в заголовке X-API-Key и в Authorization.
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	want := models.Caller{UserID: "42", Role: "user", Token: "tsk_ci", APIKey: true, Scopes: []string{"tasks:write", "templates:read"}}
+
+	for _, header := range []string{authclient.APIKeyHeader, "Authorization"} {
+		t.Run(header, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if header == "Authorization" {
+				req.Header.Set(header, "Bearer tsk_ci")
+			} else {
+				req.Header.Set(header, "tsk_ci")
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var caller models.Caller
+			handler := AuthMiddleware(&fakeIntrospector{tokens: map[string]string{"tsk_ci": "42"}})(func(c echo.Context) error {
+				caller, _ = models.CallerFromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			assert.NoError(t, handler(c))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, want, caller)
+		})
+	}
+}
+
+// TestAuthMiddleware_APIKeyWithNonBearerAuthorization проверяет, что при Authorization
+// не Bearer используется ключ из X-API-Key, как и в template-service.
+func TestAuthMiddleware_APIKeyWithNonBearerAuthorization(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	req.Header.Set(authclient.APIKeyHeader, "tsk_ci")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var caller models.Caller
+	handler := AuthMiddleware(&fakeIntrospector{tokens: map[string]string{"tsk_ci": "42"}})(func(c echo.Context) error {
+		caller, _ = models.CallerFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+
+	assert.NoError(t, handler(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "tsk_ci", caller.Token)
+}
+
+// TestRequireScope проверяет, что API-ключ без нужного scope получает 403, а токен входа проходит.
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller models.Caller
+		status int
+	}{
+		{name: "login token", caller: models.Caller{UserID: "42"}, status: http.StatusOK},
+		{name: "api key with scope", caller: models.Caller{UserID: "42", APIKey: true, Scopes: []string{"tasks:write"}}, status: http.StatusOK},
+		{name: "api key without scope", caller: models.Caller{UserID: "42", APIKey: true, Scopes: []string{"tasks:read"}}, status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req = req.WithContext(models.WithCaller(req.Context(), tt.caller))
+			rec := httptest.NewRecorder()
+
+			handler := RequireScope(models.ScopeTasksWrite)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			assert.NoError(t, handler(e.NewContext(req, rec)))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"errors"
+)
+
+// RoleAdmin is the auth-service role that may read and manage the tasks of other users.
+const RoleAdmin = "admin"
+
+// Scopes of personal API keys used by task-service. Access tokens of a login have no
+// scopes and may do everything their user may.
+const (
+	ScopeTasksRead     = "tasks:read"
+	ScopeTasksWrite    = "tasks:write"
+	ScopeTemplatesRead = "templates:read"
+)
+
+// ErrForbidden is returned when a caller asks for the resources of another user.
+var ErrForbidden = errors.New("forbidden")
+
+// Caller is the user a request is made by, as authenticated by AuthMiddleware.
+type Caller struct {
+	UserID string
+	Role   string
+	// Token is the bearer token or API key of the request, forwarded to template-service.
+	Token string
+	// APIKey is set when the request was made with a personal API key, which is limited
+	// to Scopes.
+	APIKey bool
+	Scopes []string
+	// OnBehalf is set when a service acts for UserID with a Token of its own, which may
+	// reach more than the resources of UserID. Templates fetched with it must belong to
+	// UserID.
+	OnBehalf bool
+}
+
+func (c Caller) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+// Allows reports whether the caller may perform actions requiring scope.
+func (c Caller) Allows(scope string) bool {
+	if !c.APIKey {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccess reports whether the caller may access a resource owned by ownerID.
+func (c Caller) CanAccess(ownerID string) bool {
+	return c.IsAdmin() || (c.UserID != "" && c.UserID == ownerID)
+}
+
+type callerKey struct{}
+
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller stored by WithCaller, if any.
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
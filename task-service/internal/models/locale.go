@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Locales lists the locale packs shipped with worker-service, as template-service does.
+// Regional packs fall back to the main pack of their language and every language falls
+// back to en_US.
+var Locales = []string{"en_US", "en_GB", "ru_RU", "de_DE", "de_AT"}
+
+// ValidLocale reports whether code names a known locale. Codes are case-insensitive,
+// may use '-' instead of '_' and may be a bare language such as "ru".
+func ValidLocale(code string) bool {
+	code = strings.ReplaceAll(strings.TrimSpace(code), "-", "_")
+	for _, locale := range Locales {
+		lang, _, _ := strings.Cut(locale, "_")
+		if strings.EqualFold(code, locale) || strings.EqualFold(code, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewValidator returns a validator that also knows the "locale" tag of task requests.
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+		return ValidLocale(fl.Field().String())
+	})
+	return validate
+}
@@ -0,0 +1,68 @@
+package models
+
+import "errors"
+
+var (
+	// ErrReportNotFound is returned for tasks that do not send requests and so have no report.
+	ErrReportNotFound = errors.New("task has no report")
+	// ErrReportNotReady is returned while an http task has not reported any requests yet.
+	ErrReportNotReady = errors.New("task report is not ready")
+)
+
+// Report summarizes the requests of an http task. worker-service sends it with the
+// progress events of the task, so it covers the requests sent so far.
+type Report struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// Errors counts the failed requests that got no response at all.
+	Errors      int              `json:"errors"`
+	StatusCodes map[string]int   `json:"status_codes,omitempty"`
+	Latency     LatencySummary   `json:"latency"`
+	Throughput  *Throughput      `json:"throughput,omitempty"`
+	Failures    []RequestFailure `json:"failures,omitempty"`
+}
+
+// SuccessRate returns the percentage of passed requests.
+func (r Report) SuccessRate() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Passed) * 100 / float64(r.Total)
+}
+
+// LatencySummary describes the distribution of request latencies in milliseconds.
+type LatencySummary struct {
+	Min  float64 `json:"min_ms"`
+	Mean float64 `json:"mean_ms"`
+	P50  float64 `json:"p50_ms"`
+	P95  float64 `json:"p95_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+}
+
+// Throughput compares the request rate a task achieved with the rate its load profile
+// asked for. TargetRPS is 0 for tasks without a rate limit.
+type Throughput struct {
+	ElapsedSec  float64 `json:"elapsed_sec"`
+	TargetRPS   float64 `json:"target_rps,omitempty"`
+	AchievedRPS float64 `json:"achieved_rps"`
+}
+
+// RequestFailure is a sample of a failed request.
+type RequestFailure struct {
+	Index     int      `json:"index"`
+	URL       string   `json:"url"`
+	Status    int      `json:"status"`
+	LatencyMs float64  `json:"latency_ms"`
+	Reasons   []string `json:"reasons"`
+	Response  string   `json:"response,omitempty"`
+}
+
+// TaskReport is the report of a task as served by the API.
+type TaskReport struct {
+	TaskID      string  `json:"task_id"`
+	Status      string  `json:"status"`
+	SuccessRate float64 `json:"success_rate"`
+	*Report
+}
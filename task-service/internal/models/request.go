@@ -0,0 +1,80 @@
+package models
+
+import "errors"
+
+// TaskTypeHTTP is the type of tasks that send every generated record to a target API.
+// The result of such a task is the log of the requests instead of the records.
+const TaskTypeHTTP = "http"
+
+// ErrForbiddenURL is returned for http tasks whose target does not resolve to a public address.
+var ErrForbiddenURL = errors.New("request URL must point to a public address")
+
+// HTTPRequest describes the request an http task sends for every generated record.
+// URL, Params, Headers and Body may refer to record fields with {{field}} placeholders;
+// a task without a Body sends the whole record as JSON with POST, PUT and PATCH.
+type HTTPRequest struct {
+	Method  string            `json:"method" validate:"required,oneof=GET POST PUT PATCH DELETE HEAD"`
+	URL     string            `json:"url" validate:"required,max=2048"`
+	Params  map[string]string `json:"params,omitempty" validate:"max=50"`
+	Headers map[string]string `json:"headers,omitempty" validate:"max=50"`
+	Body    string            `json:"body,omitempty" validate:"max=65536"`
+	// Concurrency caps the requests in flight at once, 1 by default. A load profile that
+	// asks for more than Concurrency requests can complete reaches a lower rate.
+	Concurrency int `json:"concurrency,omitempty" validate:"omitempty,gte=1,lte=100"`
+	// Rate caps the requests per second; 0 sends them as fast as Concurrency allows.
+	// It is shorthand for a constant Load and cannot be combined with one.
+	Rate      float64      `json:"rate,omitempty" validate:"excluded_with=Load,gte=0,lte=10000"`
+	Load      *LoadProfile `json:"load,omitempty" validate:"omitempty"`
+	TimeoutMs int          `json:"timeout_ms,omitempty" validate:"omitempty,gte=1,lte=60000"`
+	// Assertions decide whether a request passed; without them any status below 400 passes.
+	Assertions *Assertions `json:"assertions,omitempty" validate:"omitempty"`
+}
+
+// Load profile types.
+const (
+	LoadConstant = "constant"
+	LoadRamp     = "ramp"
+	LoadStep     = "step"
+	LoadSpike    = "spike"
+	LoadSoak     = "soak"
+)
+
+// LoadProfile shapes the request rate of an http task over time, counted from its first
+// request. Rate is the constant and soak rate, the final rate of ramp and step, and the
+// peak of spike; StartRate is the first rate of ramp and step and the base of spike.
+// Ramp and step reach Rate after DurationSec and keep it; a soak task ends after
+// DurationSec even if records are left. A spike rises to Rate SpikeAtSec into the task
+// for SpikeSec. Burst is how many requests may be sent at once to catch up, 1 by default.
+type LoadProfile struct {
+	Type        string  `json:"type" validate:"required,oneof=constant ramp step spike soak"`
+	Rate        float64 `json:"rate" validate:"gt=0,lte=10000"`
+	StartRate   float64 `json:"start_rate,omitempty" validate:"required_if=Type spike,gte=0,lte=10000"`
+	DurationSec int     `json:"duration_sec,omitempty" validate:"required_unless=Type constant Type spike,gte=0,lte=604800"`
+	Steps       int     `json:"steps,omitempty" validate:"required_if=Type step,lte=100"`
+	SpikeAtSec  int     `json:"spike_at_sec,omitempty" validate:"gte=0,lte=604800"`
+	SpikeSec    int     `json:"spike_sec,omitempty" validate:"required_if=Type spike,gte=0,lte=604800"`
+	Burst       int     `json:"burst,omitempty" validate:"gte=0,lte=10000"`
+}
+
+// Assertions are the checks a response must pass for its request to count as passed.
+type Assertions struct {
+	Status       []int             `json:"status,omitempty" validate:"max=20,dive,gte=100,lte=599"`
+	MaxLatencyMs int               `json:"max_latency_ms,omitempty" validate:"gte=0,lte=600000"`
+	Headers      []HeaderAssertion `json:"headers,omitempty" validate:"max=20,dive"`
+	Body         []BodyAssertion   `json:"body,omitempty" validate:"max=20,dive"`
+}
+
+// HeaderAssertion requires a response header, optionally with a value or matching a regex.
+type HeaderAssertion struct {
+	Name   string `json:"name" validate:"required,max=256"`
+	Equals string `json:"equals,omitempty" validate:"max=1024"`
+	Regex  string `json:"regex,omitempty" validate:"max=1024"`
+}
+
+// BodyAssertion checks the value at JSONPath of a JSON response body, or the whole body
+// against Regex if JSONPath is empty. JSONPath supports $.name, $['name'] and $[index].
+type BodyAssertion struct {
+	JSONPath string      `json:"json_path,omitempty" validate:"omitempty,startswith=$,max=256"`
+	Equals   interface{} `json:"equals,omitempty"`
+	Regex    string      `json:"regex,omitempty" validate:"required_without=JSONPath,max=1024"`
+}
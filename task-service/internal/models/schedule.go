@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Schedule creates a task from Task every time its cron expression fires in Timezone.
+// NextRunAt is nil while the schedule is disabled.
+type Schedule struct {
+	ID        int64             `json:"id" db:"id"`
+	UserID    string            `json:"user_id" db:"user_id"`
+	Name      string            `json:"name" db:"name"`
+	Cron      string            `json:"cron" db:"cron"`
+	Timezone  string            `json:"timezone" db:"timezone"`
+	Task      CreateTaskRequest `json:"task" db:"task"`
+	Enabled   bool              `json:"enabled" db:"enabled"`
+	NextRunAt *time.Time        `json:"next_run_at,omitempty" db:"next_run_at"`
+	LastRunAt *time.Time        `json:"last_run_at,omitempty" db:"last_run_at"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ScheduleRequest creates or replaces a schedule. Timezone defaults to UTC and Enabled to true.
+type ScheduleRequest struct {
+	Name     string            `json:"name" validate:"max=255"`
+	Cron     string            `json:"cron" validate:"required,max=255"`
+	Timezone string            `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Task     CreateTaskRequest `json:"task" validate:"required"`
+	Enabled  *bool             `json:"enabled,omitempty"`
+}
+
+// Statuses of a schedule run.
+const (
+	RunPending = "pending"
+	RunCreated = "created"
+	RunFailed  = "failed"
+)
+
+// ScheduleRun is one firing of a schedule. TaskID refers to the task it created and
+// TaskStatus is the current status of that task.
+type ScheduleRun struct {
+	ID          int64     `json:"id" db:"id"`
+	ScheduleID  int64     `json:"schedule_id" db:"schedule_id"`
+	ScheduledAt time.Time `json:"scheduled_at" db:"scheduled_at"`
+	Status      string    `json:"status" db:"status"`
+	TaskID      *int64    `json:"task_id,omitempty" db:"task_id"`
+	TaskStatus  *string   `json:"task_status,omitempty" db:"-"`
+	Error       string    `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+var (
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+)
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Shard is one part of a sharded task: Amount records starting at record Offset.
+// Every shard is published as its own Kafka message and generated by whichever
+// worker receives it; a merge message then assembles the shard results.
+type Shard struct {
+	Index  int `json:"index"`
+	Count  int `json:"count"`
+	Offset int `json:"offset"`
+	Amount int `json:"amount"`
+}
+
+// ShardPolicy decides whether a task is split into shards.
+type ShardPolicy struct {
+	// Threshold is the amount above which a task is sharded; 0 disables sharding.
+	Threshold int
+	// Size is the number of records a shard should hold.
+	Size int
+	// MaxShards caps the number of shards of one task.
+	MaxShards int
+}
+
+// ShardCount returns the number of shards for a task of amount records, or 0 if the task is not sharded.
+func (p ShardPolicy) ShardCount(amount int) int {
+	if p.Threshold <= 0 || p.Size <= 0 || amount <= p.Threshold {
+		return 0
+	}
+	n := (amount + p.Size - 1) / p.Size
+	if p.MaxShards > 0 && n > p.MaxShards {
+		n = p.MaxShards
+	}
+	if n < 2 {
+		return 0
+	}
+	return n
+}
+
+// SplitShards divides amount records into count consecutive shards whose sizes differ by at most one.
+func SplitShards(amount, count int) []Shard {
+	shards := make([]Shard, count)
+	offset := 0
+	for i := range shards {
+		size := amount / count
+		if i < amount%count {
+			size++
+		}
+		shards[i] = Shard{Index: i, Count: count, Offset: offset, Amount: size}
+		offset += size
+	}
+	return shards
+}
+
+// ShardKey returns the Kafka message key of a shard. The shards of a task have distinct
+// keys, so they are spread over the partitions of the task topic.
+func ShardKey(taskID string, index int) string {
+	return fmt.Sprintf("%s/%d", taskID, index)
+}
+
+// ParseMessageKey splits a task message key into the task id and, for a shard, its index.
+// shard is -1 for a key without a shard suffix.
+func ParseMessageKey(key string) (taskID string, shard int) {
+	taskID, suffix, ok := strings.Cut(key, "/")
+	if !ok {
+		return key, -1
+	}
+	index, err := strconv.Atoi(suffix)
+	if err != nil {
+		return key, -1
+	}
+	return taskID, index
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardPolicy_ShardCount(t *testing.T) {
+	policy := ShardPolicy{Threshold: 1000, Size: 400, MaxShards: 4}
+
+	assert.Equal(t, 0, policy.ShardCount(1000))
+	assert.Equal(t, 3, policy.ShardCount(1001))
+	assert.Equal(t, 4, policy.ShardCount(100000))
+	assert.Equal(t, 0, ShardPolicy{Size: 400}.ShardCount(100000))
+	assert.Equal(t, 0, ShardPolicy{Threshold: 10, Size: 1000}.ShardCount(100))
+}
+
+func TestSplitShards(t *testing.T) {
+	assert.Equal(t, []Shard{
+		{Index: 0, Count: 3, Offset: 0, Amount: 4},
+		{Index: 1, Count: 3, Offset: 4, Amount: 3},
+		{Index: 2, Count: 3, Offset: 7, Amount: 3},
+	}, SplitShards(10, 3))
+}
+
+func TestParseMessageKey(t *testing.T) {
+	taskID, shard := ParseMessageKey(ShardKey("task-1", 3))
+	assert.Equal(t, "task-1", taskID)
+	assert.Equal(t, 3, shard)
+
+	taskID, shard = ParseMessageKey("task-1")
+	assert.Equal(t, "task-1", taskID)
+	assert.Equal(t, -1, shard)
+}
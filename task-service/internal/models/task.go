@@ -1,28 +1,128 @@
 package models
 
 import (
+	"errors"
+	"io"
+	"math/rand/v2"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Task struct {
-	ID         int64                  `json:"id" db:"id"`
-	TaskID     string                 `json:"task_id" db:"task_id"`
-	UserID     string                 `json:"user_id" db:"user_id"`
-	Type       string                 `json:"type" db:"type"`
-	TemplateID string                 `json:"template_id" db:"template_id"`
-	Template   map[string]interface{} `json:"template" db:"template"`
-	Amount     int                    `json:"amount" db:"amount"`
-	Status     string                 `json:"status" db:"status"`
-	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time              `json:"updated_at" db:"updated_at"`
+	ID               int64                  `json:"id" db:"id"`
+	TaskID           string                 `json:"task_id" db:"task_id"`
+	UserID           string                 `json:"user_id" db:"user_id"`
+	Type             string                 `json:"type" db:"type"`
+	TemplateID       string                 `json:"template_id" db:"template_id"`
+	TemplateVersion  int                    `json:"template_version" db:"template_version"`
+	Template         map[string]interface{} `json:"template" db:"template"`
+	Amount           int                    `json:"amount" db:"amount"`
+	Seed             int64                  `json:"seed" db:"seed"`
+	Locale           string                 `json:"locale,omitempty" db:"locale"`
+	Format           string                 `json:"format" db:"format"`
+	TableName        string                 `json:"table_name,omitempty" db:"table_name"`
+	SQLDialect       string                 `json:"sql_dialect,omitempty" db:"sql_dialect"`
+	Request          *HTTPRequest           `json:"request,omitempty" db:"request"`
+	Status           string                 `json:"status" db:"status"`
+	RecordsGenerated int                    `json:"records_generated" db:"records_generated"`
+	Error            string                 `json:"error,omitempty" db:"error"`
+	ResultKey        string                 `json:"result_key,omitempty" db:"result_key"`
+	ResultSize       int64                  `json:"result_size,omitempty" db:"result_size"`
+	ShardCount       int                    `json:"shard_count,omitempty" db:"shard_count"`
+	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at" db:"updated_at"`
+
+	// Shard and Merge are only set in the Kafka messages of a sharded task: a shard
+	// message carries its Shard, the final message that assembles the result has Merge.
+	Shard *Shard `json:"shard,omitempty" db:"-"`
+	Merge bool   `json:"merge,omitempty" db:"-"`
+	// ShardsPinned keeps ShardCount instead of applying the current shard policy when
+	// the task is created; it is set on regenerated tasks.
+	ShardsPinned bool `json:"-" db:"-"`
+}
+
+// ResultFileName returns the download file name of the task result.
+func (t Task) ResultFileName() string {
+	format := t.Format
+	if format == "" {
+		format = "json"
+	}
+	return t.TaskID + "." + format
+}
+
+// ResultContentType returns the MIME type of the task result.
+func (t Task) ResultContentType() string {
+	switch t.Format {
+	case "ndjson":
+		return "application/x-ndjson"
+	case "csv":
+		return "text/csv; charset=utf-8"
+	case "sql":
+		return "application/sql"
+	default:
+		return "application/json"
+	}
 }
 
 type CreateTaskRequest struct {
-	Type       string                 `json:"type" validate:"required"`
-	TemplateID string                 `json:"template_id,omitempty"`
-	Template   map[string]interface{} `json:"template,omitempty"`
-	Amount     int                    `json:"amount" validate:"required,gte=1"`
-	Format     string                 `json:"format" validate:"required,oneof=json csv sql"`
+	Type            string `json:"type" validate:"required"`
+	TemplateID      string `json:"template_id" validate:"required"`
+	TemplateVersion int    `json:"template_version,omitempty" validate:"omitempty,gte=1"`
+	Amount          int    `json:"amount" validate:"required,gte=1"`
+	Seed            *int64 `json:"seed,omitempty"`
+	Locale          string `json:"locale,omitempty" validate:"omitempty,max=16,locale"`
+	Format          string `json:"format" validate:"required,oneof=json ndjson csv sql"`
+	TableName       string `json:"table_name,omitempty" validate:"omitempty,max=63"`
+	SQLDialect      string `json:"sql_dialect,omitempty" validate:"omitempty,oneof=postgres mysql sqlite mssql"`
+	// Request is required for, and only accepted with, tasks of type http.
+	Request *HTTPRequest `json:"request,omitempty" validate:"required_if=Type http,excluded_unless=Type http"`
+}
+
+// NewTask returns the task a request creates for userID. Tasks without an explicit seed
+// still get one, so any task can be regenerated byte for byte.
+func (r CreateTaskRequest) NewTask(userID string) Task {
+	seed := rand.Int64()
+	if r.Seed != nil {
+		seed = *r.Seed
+	}
+
+	return Task{
+		TaskID:          uuid.NewString(),
+		UserID:          userID,
+		Type:            r.Type,
+		TemplateID:      r.TemplateID,
+		TemplateVersion: r.TemplateVersion,
+		Amount:          r.Amount,
+		Seed:            seed,
+		Locale:          r.Locale,
+		Format:          r.Format,
+		TableName:       r.TableName,
+		SQLDialect:      r.SQLDialect,
+		Request:         r.Request,
+	}
+}
+
+// Regenerate returns a new task of userID that reproduces t: it is pinned to the same
+// template revision and generates the same amount with the same seed, locale and format,
+// split into the same shards whatever the shard policy is now.
+func (t Task) Regenerate(userID string) Task {
+	return Task{
+		TaskID:          uuid.NewString(),
+		UserID:          userID,
+		Type:            t.Type,
+		TemplateID:      t.TemplateID,
+		TemplateVersion: t.TemplateVersion,
+		Amount:          t.Amount,
+		Seed:            t.Seed,
+		Locale:          t.Locale,
+		Format:          t.Format,
+		TableName:       t.TableName,
+		SQLDialect:      t.SQLDialect,
+		Request:         t.Request,
+		ShardCount:      t.ShardCount,
+		ShardsPinned:    true,
+	}
 }
 
 type TaskFilter struct {
@@ -38,3 +138,122 @@ func (f TaskFilter) IsCacheable() bool {
 	// Only cache results for specific filters, not general listings
 	return f.UserID != "" || f.Type != "" || f.Status != ""
 }
+
+const (
+	StatusPending   = "pending"
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+var (
+	ErrTaskNotFound            = errors.New("task not found")
+	ErrInvalidStatusTransition = errors.New("invalid task status transition")
+	ErrResultNotReady          = errors.New("task result is not ready")
+	ErrInvalidSignature        = errors.New("invalid or expired download signature")
+)
+
+// TaskResult is an open handle to a task's generated output.
+type TaskResult struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+	FileName    string
+}
+
+// statusTransitions lists, for each status, the statuses a task may move to from it.
+// running → running is allowed so that progress updates can be recorded.
+var statusTransitions = map[string][]string{
+	StatusPending: {StatusQueued, StatusRunning, StatusFailed, StatusCancelled},
+	StatusQueued:  {StatusRunning, StatusFailed, StatusCancelled},
+	StatusRunning: {StatusRunning, StatusSucceeded, StatusFailed, StatusCancelled},
+}
+
+// IsValidStatus reports whether status is part of the task lifecycle.
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusPending, StatusQueued, StatusRunning, StatusSucceeded, StatusFailed, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsTerminalStatus reports whether no further transitions are possible from status.
+func IsTerminalStatus(status string) bool {
+	return status == StatusSucceeded || status == StatusFailed || status == StatusCancelled
+}
+
+// CanTransition reports whether a task may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// PreviousStatuses returns every status from which a task may move to status.
+func PreviousStatuses(status string) []string {
+	var from []string
+	for _, s := range []string{StatusPending, StatusQueued, StatusRunning} {
+		if CanTransition(s, status) {
+			from = append(from, s)
+		}
+	}
+	return from
+}
+
+// StatusEvent is published by worker-service to report task progress.
+// Events of a shard carry its index in Shard, events of an http task its Report.
+type StatusEvent struct {
+	TaskID           string    `json:"task_id"`
+	Shard            *int      `json:"shard,omitempty"`
+	Status           string    `json:"status"`
+	RecordsGenerated int       `json:"records_generated"`
+	Error            string    `json:"error,omitempty"`
+	ResultKey        string    `json:"result_key,omitempty"`
+	ResultSize       int64     `json:"result_size,omitempty"`
+	Report           *Report   `json:"report,omitempty"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+const (
+	EventTaskCreated   = "task.created"
+	EventTaskCancelled = "task.cancelled"
+	// EventTaskMerge asks worker-service to assemble the result of a sharded task
+	// once all of its shards have succeeded.
+	EventTaskMerge = "task.merge"
+	// EventTaskFinished announces to notification-service that a task has reached a
+	// terminal status.
+	EventTaskFinished = "task.finished"
+)
+
+// TaskNotification is the payload of a "task.finished" message.
+type TaskNotification struct {
+	TaskID           string    `json:"task_id"`
+	UserID           string    `json:"user_id"`
+	Type             string    `json:"type"`
+	Status           string    `json:"status"`
+	RecordsGenerated int       `json:"records_generated"`
+	ResultSize       int64     `json:"result_size,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	FinishedAt       time.Time `json:"finished_at"`
+}
+
+// CancelCommand asks worker-service to stop generating a task and discard its partial output.
+type CancelCommand struct {
+	TaskID      string    `json:"task_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// OutboxMessage is a Kafka message stored in the same transaction as the change it announces.
+type OutboxMessage struct {
+	ID        int64
+	EventType string
+	Key       string
+	Payload   []byte
+	Attempts  int
+}
@@ -3,12 +3,11 @@ package models
 import (
 	"testing"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateTaskRequest_Validation(t *testing.T) {
-	validate := validator.New()
+	validate := NewValidator()
 
 	tests := []struct {
 		name    string
@@ -17,36 +16,229 @@ func TestCreateTaskRequest_Validation(t *testing.T) {
 	}{
 		{
 			name: "valid input",
+			req: CreateTaskRequest{
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     10,
+				Format:     "json",
+			},
+			isValid: true,
+		},
+		{
+			name: "missing template id",
 			req: CreateTaskRequest{
 				Type:   "generate",
 				Amount: 10,
 				Format: "json",
 			},
-			isValid: true,
+			isValid: false,
 		},
 		{
 			name: "missing type",
 			req: CreateTaskRequest{
-				Amount: 5,
-				Format: "csv",
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "csv",
 			},
 			isValid: false,
 		},
 		{
 			name: "amount less than 1",
 			req: CreateTaskRequest{
-				Type:   "generate",
-				Amount: 0,
-				Format: "csv",
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     0,
+				Format:     "csv",
 			},
 			isValid: false,
 		},
 		{
 			name: "invalid format",
 			req: CreateTaskRequest{
-				Type:   "generate",
-				Amount: 5,
-				Format: "xml",
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "xml",
+			},
+			isValid: false,
+		},
+		{
+			name: "valid sql with dialect",
+			req: CreateTaskRequest{
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "sql",
+				TableName:  "users",
+				SQLDialect: "mysql",
+			},
+			isValid: true,
+		},
+		{
+			name: "valid ndjson",
+			req: CreateTaskRequest{
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "ndjson",
+			},
+			isValid: true,
+		},
+		{
+			name: "valid locale",
+			req: CreateTaskRequest{
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Locale:     "de-AT",
+			},
+			isValid: true,
+		},
+		{
+			name: "unknown locale",
+			req: CreateTaskRequest{
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Locale:     "xx_YY",
+			},
+			isValid: false,
+		},
+		{
+			name: "invalid sql dialect",
+			req: CreateTaskRequest{
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "sql",
+				SQLDialect: "oracle",
+			},
+			isValid: false,
+		},
+		{
+			name: "valid http",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "POST", URL: "https://api.example.com/users", Concurrency: 4, Rate: 10},
+			},
+			isValid: true,
+		},
+		{
+			name: "http without request",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+			},
+			isValid: false,
+		},
+		{
+			name: "http with invalid method",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "TRACE", URL: "https://api.example.com/users"},
+			},
+			isValid: false,
+		},
+		{
+			name: "http with assertions",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request: &HTTPRequest{Method: "GET", URL: "https://api.example.com/users", Assertions: &Assertions{
+					Status:       []int{200},
+					MaxLatencyMs: 500,
+					Headers:      []HeaderAssertion{{Name: "Content-Type", Regex: "json"}},
+					Body:         []BodyAssertion{{JSONPath: "$.id", Equals: 1}, {Regex: "ok"}},
+				}},
+			},
+			isValid: true,
+		},
+		{
+			name: "http with invalid status assertion",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "GET", URL: "https://api.example.com/users", Assertions: &Assertions{Status: []int{42}}},
+			},
+			isValid: false,
+		},
+		{
+			name: "http with body assertion without path or regex",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "GET", URL: "https://api.example.com/users", Assertions: &Assertions{Body: []BodyAssertion{{Equals: 1}}}},
+			},
+			isValid: false,
+		},
+		{
+			name: "http with ramp profile",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "GET", URL: "https://api.example.com/users", Load: &LoadProfile{Type: LoadRamp, StartRate: 1, Rate: 50, DurationSec: 60}},
+			},
+			isValid: true,
+		},
+		{
+			name: "http with soak profile without duration",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "GET", URL: "https://api.example.com/users", Load: &LoadProfile{Type: LoadSoak, Rate: 50}},
+			},
+			isValid: false,
+		},
+		{
+			name: "http with spike profile without base rate",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "GET", URL: "https://api.example.com/users", Load: &LoadProfile{Type: LoadSpike, Rate: 50, SpikeSec: 10}},
+			},
+			isValid: false,
+		},
+		{
+			name: "http with rate and load profile",
+			req: CreateTaskRequest{
+				Type:       TaskTypeHTTP,
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "GET", URL: "https://api.example.com/users", Rate: 10, Load: &LoadProfile{Type: LoadConstant, Rate: 50}},
+			},
+			isValid: false,
+		},
+		{
+			name: "request without http type",
+			req: CreateTaskRequest{
+				Type:       "generate",
+				TemplateID: "tpl",
+				Amount:     5,
+				Format:     "json",
+				Request:    &HTTPRequest{Method: "GET", URL: "https://api.example.com/users"},
 			},
 			isValid: false,
 		},
@@ -63,3 +255,23 @@ func TestCreateTaskRequest_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(StatusPending, StatusQueued))
+	assert.True(t, CanTransition(StatusQueued, StatusRunning))
+	assert.True(t, CanTransition(StatusRunning, StatusRunning))
+	assert.True(t, CanTransition(StatusRunning, StatusSucceeded))
+	assert.True(t, CanTransition(StatusQueued, StatusCancelled))
+
+	assert.False(t, CanTransition(StatusSucceeded, StatusRunning))
+	assert.False(t, CanTransition(StatusCancelled, StatusQueued))
+	assert.False(t, CanTransition(StatusRunning, StatusQueued))
+	assert.False(t, CanTransition(StatusQueued, StatusPending))
+}
+
+func TestPreviousStatuses(t *testing.T) {
+	assert.Equal(t, []string{StatusPending}, PreviousStatuses(StatusQueued))
+	assert.Equal(t, []string{StatusRunning}, PreviousStatuses(StatusSucceeded))
+	assert.Equal(t, []string{StatusPending, StatusQueued, StatusRunning}, PreviousStatuses(StatusFailed))
+	assert.Empty(t, PreviousStatuses(StatusPending))
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"task-service/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// maxOutboxBackoff caps the delay between publish attempts of a single message.
+const maxOutboxBackoff = 5 * time.Minute
+
+type OutboxRepository interface {
+	// ProcessPending locks up to limit due messages and passes each one to publish.
+	// Successfully published messages are marked as such; failed ones are rescheduled
+	// with exponential backoff. It returns the number of published messages.
+	ProcessPending(ctx context.Context, limit int, publish func(ctx context.Context, msg models.OutboxMessage) error) (int, error)
+}
+
+type postgresOutboxRepository struct {
+	db     DB
+	logger *zap.SugaredLogger
+}
+
+func NewOutboxRepository(db DB, logger *zap.SugaredLogger) *postgresOutboxRepository {
+	return &postgresOutboxRepository{db: db, logger: logger}
+}
+
+func insertOutboxMessage(ctx context.Context, tx pgx.Tx, eventType, key string, payload []byte) error {
+	query := `INSERT INTO outbox (event_type, message_key, payload) VALUES ($1, $2, $3)`
+	_, err := tx.Exec(ctx, query, eventType, key, payload)
+	return err
+}
+
+func (r *postgresOutboxRepository) ProcessPending(ctx context.Context, limit int, publish func(ctx context.Context, msg models.OutboxMessage) error) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Failed to begin outbox transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// SKIP LOCKED lets several task-service replicas relay concurrently without double publishing.
+	query := `SELECT id, event_type, message_key, payload, attempts
+              FROM outbox
+              WHERE published_at IS NULL AND next_attempt_at <= NOW()
+              ORDER BY id
+              LIMIT $1
+              FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		r.logger.Errorf("Failed to query outbox: %v", err)
+		return 0, err
+	}
+
+	var messages []models.OutboxMessage
+	for rows.Next() {
+		var msg models.OutboxMessage
+		if err := rows.Scan(&msg.ID, &msg.EventType, &msg.Key, &msg.Payload, &msg.Attempts); err != nil {
+			rows.Close()
+			r.logger.Errorf("Failed to scan outbox row: %v", err)
+			return 0, err
+		}
+		messages = append(messages, msg)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Errorf("Error during outbox iteration: %v", err)
+		return 0, err
+	}
+
+	published := 0
+	for _, msg := range messages {
+		if pubErr := publish(ctx, msg); pubErr != nil {
+			backoff := outboxBackoff(msg.Attempts + 1)
+			r.logger.Warnf("Failed to publish outbox message %d (attempt %d), retrying in %v: %v", msg.ID, msg.Attempts+1, backoff, pubErr)
+			_, err = tx.Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3`,
+				pubErr.Error(), time.Now().Add(backoff), msg.ID)
+		} else {
+			published++
+			_, err = tx.Exec(ctx, `UPDATE outbox SET published_at = $1 WHERE id = $2`, time.Now(), msg.ID)
+		}
+		if err != nil {
+			r.logger.Errorf("Failed to update outbox message %d: %v", msg.ID, err)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Failed to commit outbox transaction: %v", err)
+		return 0, err
+	}
+
+	return published, nil
+}
+
+func outboxBackoff(attempt int) time.Duration {
+	if attempt > 16 {
+		return maxOutboxBackoff
+	}
+	backoff := time.Second << attempt
+	if backoff > maxOutboxBackoff {
+		return maxOutboxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"task-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupOutboxRepository(t *testing.T) (*postgresOutboxRepository, pgxmock.PgxPoolIface) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+
+	repo := NewOutboxRepository(&fakeDB{mock: mock}, zap.NewNop().Sugar())
+	return repo, mock
+}
+
+// TestProcessPending_PublishesAndReschedules проверяет пометку опубликованных и перенос неудачных сообщений.
+func TestProcessPending_PublishesAndReschedules(t *testing.T) {
+	repo, mock := setupOutboxRepository(t)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, event_type, message_key, payload, attempts\s+FROM outbox`).
+		WithArgs(10).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "event_type", "message_key", "payload", "attempts"}).
+			AddRow(int64(1), models.EventTaskCreated, "task-1", []byte(`{}`), 0).
+			AddRow(int64(2), models.EventTaskCreated, "task-2", []byte(`{}`), 2))
+	mock.ExpectExec(`UPDATE outbox SET published_at`).
+		WithArgs(pgxmock.AnyArg(), int64(1)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`UPDATE outbox SET attempts = attempts \+ 1`).
+		WithArgs("kafka unavailable", pgxmock.AnyArg(), int64(2)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	published, err := repo.ProcessPending(context.Background(), 10, func(ctx context.Context, msg models.OutboxMessage) error {
+		if msg.ID == 2 {
+			return errors.New("kafka unavailable")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestProcessPending_QueryError проверяет откат транзакции при ошибке выборки.
+func TestProcessPending_QueryError(t *testing.T) {
+	repo, mock := setupOutboxRepository(t)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, event_type, message_key, payload, attempts`).
+		WithArgs(10).
+		WillReturnError(errors.New("db error"))
+	mock.ExpectRollback()
+
+	_, err := repo.ProcessPending(context.Background(), 10, func(ctx context.Context, msg models.OutboxMessage) error {
+		t.Fatal("publish must not be called")
+		return nil
+	})
+	require.Error(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, outboxBackoff(1))
+	assert.Equal(t, 8*time.Second, outboxBackoff(3))
+	assert.Equal(t, maxOutboxBackoff, outboxBackoff(9))
+	assert.Equal(t, maxOutboxBackoff, outboxBackoff(100))
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"task-service/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type ScheduleRepository interface {
+	CreateSchedule(ctx context.Context, schedule models.Schedule) (int64, error)
+	GetSchedule(ctx context.Context, id int64) (*models.Schedule, error)
+	ListSchedules(ctx context.Context, userID string) ([]models.Schedule, error)
+	UpdateSchedule(ctx context.Context, schedule models.Schedule) error
+	DeleteSchedule(ctx context.Context, id int64) error
+	ListRuns(ctx context.Context, scheduleID int64, limit int) ([]models.ScheduleRun, error)
+
+	// DueSchedules returns up to limit enabled schedules whose next run is at or before now.
+	DueSchedules(ctx context.Context, now time.Time, limit int) ([]models.Schedule, error)
+	// ClaimRun records the run of a due schedule and moves the schedule to its next run
+	// (nil stops it). It returns false if the run has already been claimed, so that a
+	// schedule never fires twice for the same time.
+	ClaimRun(ctx context.Context, schedule models.Schedule, next *time.Time) (int64, bool, error)
+	// FinishRun records the task a run created, or why it failed.
+	FinishRun(ctx context.Context, runID int64, taskID *int64, runErr string) error
+	// AcquireLease takes or renews the named lease for holder until ttl from now. It
+	// returns false while another holder has an unexpired lease.
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+}
+
+type postgresScheduleRepository struct {
+	db     DB
+	logger *zap.SugaredLogger
+}
+
+func NewScheduleRepository(db DB, logger *zap.SugaredLogger) *postgresScheduleRepository {
+	return &postgresScheduleRepository{db: db, logger: logger}
+}
+
+const scheduleColumns = `id, user_id, name, cron, timezone, task, enabled, next_run_at, last_run_at, created_at, updated_at`
+
+func scanSchedule(row pgx.Row) (*models.Schedule, error) {
+	var s models.Schedule
+	err := row.Scan(&s.ID, &s.UserID, &s.Name, &s.Cron, &s.Timezone, &s.Task, &s.Enabled, &s.NextRunAt, &s.LastRunAt, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *postgresScheduleRepository) CreateSchedule(ctx context.Context, schedule models.Schedule) (int64, error) {
+	query := `INSERT INTO schedules (user_id, name, cron, timezone, task, enabled, next_run_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+
+	var id int64
+	err := r.db.QueryRow(ctx, query, schedule.UserID, schedule.Name, schedule.Cron, schedule.Timezone, schedule.Task, schedule.Enabled, schedule.NextRunAt, schedule.CreatedAt, schedule.UpdatedAt).Scan(&id)
+	if err != nil {
+		r.logger.Errorf("Failed to insert schedule: %v", err)
+		return 0, err
+	}
+
+	r.logger.Infof("Schedule created with ID: %d", id)
+	return id, nil
+}
+
+func (r *postgresScheduleRepository) GetSchedule(ctx context.Context, id int64) (*models.Schedule, error) {
+	schedule, err := scanSchedule(r.db.QueryRow(ctx, `SELECT `+scheduleColumns+` FROM schedules WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrScheduleNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to get schedule %d: %v", id, err)
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (r *postgresScheduleRepository) ListSchedules(ctx context.Context, userID string) ([]models.Schedule, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+scheduleColumns+` FROM schedules WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		r.logger.Errorf("Failed to query schedules: %v", err)
+		return nil, err
+	}
+	return collectSchedules(rows)
+}
+
+func collectSchedules(rows pgx.Rows) ([]models.Schedule, error) {
+	defer rows.Close()
+
+	schedules := []models.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// UpdateSchedule replaces the definition of a schedule, including its next run.
+func (r *postgresScheduleRepository) UpdateSchedule(ctx context.Context, schedule models.Schedule) error {
+	query := `UPDATE schedules
+              SET name = $1, cron = $2, timezone = $3, task = $4, enabled = $5, next_run_at = $6, updated_at = $7
+              WHERE id = $8
+              RETURNING id`
+
+	var id int64
+	err := r.db.QueryRow(ctx, query, schedule.Name, schedule.Cron, schedule.Timezone, schedule.Task, schedule.Enabled, schedule.NextRunAt, schedule.UpdatedAt, schedule.ID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrScheduleNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to update schedule %d: %v", schedule.ID, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteSchedule deletes a schedule and its run history; the tasks it created remain.
+func (r *postgresScheduleRepository) DeleteSchedule(ctx context.Context, id int64) error {
+	var deleted int64
+	err := r.db.QueryRow(ctx, `DELETE FROM schedules WHERE id = $1 RETURNING id`, id).Scan(&deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrScheduleNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to delete schedule %d: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// ListRuns returns the latest runs of a schedule, newest first, with the current status of their tasks.
+func (r *postgresScheduleRepository) ListRuns(ctx context.Context, scheduleID int64, limit int) ([]models.ScheduleRun, error) {
+	query := `SELECT r.id, r.schedule_id, r.scheduled_at, r.status, r.task_id, t.status, r.error, r.created_at
+              FROM schedule_runs r LEFT JOIN tasks t ON t.id = r.task_id
+              WHERE r.schedule_id = $1
+              ORDER BY r.scheduled_at DESC
+              LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, scheduleID, limit)
+	if err != nil {
+		r.logger.Errorf("Failed to query runs of schedule %d: %v", scheduleID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []models.ScheduleRun{}
+	for rows.Next() {
+		var run models.ScheduleRun
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.ScheduledAt, &run.Status, &run.TaskID, &run.TaskStatus, &run.Error, &run.CreatedAt); err != nil {
+			r.logger.Errorf("Failed to scan schedule run row: %v", err)
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Errorf("Error during schedule runs iteration: %v", err)
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (r *postgresScheduleRepository) DueSchedules(ctx context.Context, now time.Time, limit int) ([]models.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules
+              WHERE enabled AND next_run_at <= $1
+              ORDER BY next_run_at
+              LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, now, limit)
+	if err != nil {
+		r.logger.Errorf("Failed to query due schedules: %v", err)
+		return nil, err
+	}
+	return collectSchedules(rows)
+}
+
+func (r *postgresScheduleRepository) ClaimRun(ctx context.Context, schedule models.Schedule, next *time.Time) (int64, bool, error) {
+	if schedule.NextRunAt == nil {
+		return 0, false, nil
+	}
+
+	// The run is only inserted if the schedule is still due at the time that was read,
+	// and the unique (schedule_id, scheduled_at) key rejects a second run for the same
+	// time. The schedule moves on only together with the run that was inserted, so a
+	// replica that loses the race leaves it alone.
+	query := `WITH run AS (
+                  INSERT INTO schedule_runs (schedule_id, scheduled_at, status, created_at)
+                  SELECT id, $2, $5, $3 FROM schedules
+                  WHERE id = $4 AND enabled AND next_run_at = $2
+                  FOR UPDATE
+                  ON CONFLICT (schedule_id, scheduled_at) DO NOTHING
+                  RETURNING id, schedule_id
+              ), claimed AS (
+                  UPDATE schedules SET next_run_at = $1, last_run_at = $2, updated_at = $3
+                  WHERE id IN (SELECT schedule_id FROM run)
+              )
+              SELECT id FROM run`
+
+	var runID int64
+	err := r.db.QueryRow(ctx, query, next, *schedule.NextRunAt, time.Now(), schedule.ID, models.RunPending).Scan(&runID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to claim run of schedule %d: %v", schedule.ID, err)
+		return 0, false, err
+	}
+	return runID, true, nil
+}
+
+func (r *postgresScheduleRepository) FinishRun(ctx context.Context, runID int64, taskID *int64, runErr string) error {
+	status := models.RunCreated
+	if runErr != "" {
+		status = models.RunFailed
+	}
+
+	err := r.db.Exec(ctx, `UPDATE schedule_runs SET status = $1, task_id = $2, error = $3 WHERE id = $4`, status, taskID, runErr, runID)
+	if err != nil {
+		r.logger.Errorf("Failed to finish schedule run %d: %v", runID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *postgresScheduleRepository) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	query := `INSERT INTO leases (name, holder, expires_at) VALUES ($1, $2, $3)
+              ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+              WHERE leases.holder = EXCLUDED.holder OR leases.expires_at < $4
+              RETURNING holder`
+
+	now := time.Now()
+	var got string
+	err := r.db.QueryRow(ctx, query, name, holder, now.Add(ttl), now).Scan(&got)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to acquire lease %s: %v", name, err)
+		return false, err
+	}
+	return true, nil
+}
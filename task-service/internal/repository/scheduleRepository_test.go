@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"task-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupScheduleRepository(t *testing.T) (*postgresScheduleRepository, pgxmock.PgxPoolIface) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+
+	repo := NewScheduleRepository(&fakeDB{mock: mock}, zap.NewNop().Sugar())
+	return repo, mock
+}
+
+// TestGetSchedule_NotFound проверяет ошибку для несуществующего расписания.
+func TestGetSchedule_NotFound(t *testing.T) {
+	repo, mock := setupScheduleRepository(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT id, user_id, name, cron, timezone, task, enabled, next_run_at, last_run_at, created_at, updated_at FROM schedules WHERE id = \$1`).
+		WithArgs(int64(7)).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err := repo.GetSchedule(context.Background(), 7)
+	assert.ErrorIs(t, err, models.ErrScheduleNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestClaimRun проверяет захват запуска и повторный захват того же времени.
+func TestClaimRun(t *testing.T) {
+	repo, mock := setupScheduleRepository(t)
+	defer mock.Close()
+
+	at := time.Date(2025, time.March, 14, 10, 0, 0, 0, time.UTC)
+	next := at.Add(time.Hour)
+	schedule := models.Schedule{ID: 3, NextRunAt: &at}
+
+	mock.ExpectQuery(`WITH run AS \(\s+INSERT INTO schedule_runs(.|\s)+WHERE id IN \(SELECT schedule_id FROM run\)`).
+		WithArgs(&next, at, pgxmock.AnyArg(), int64(3), models.RunPending).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(11)))
+	mock.ExpectQuery(`WITH run AS \(\s+INSERT INTO schedule_runs(.|\s)+WHERE id IN \(SELECT schedule_id FROM run\)`).
+		WithArgs(&next, at, pgxmock.AnyArg(), int64(3), models.RunPending).
+		WillReturnError(pgx.ErrNoRows)
+
+	runID, claimed, err := repo.ClaimRun(context.Background(), schedule, &next)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, int64(11), runID)
+
+	_, claimed, err = repo.ClaimRun(context.Background(), schedule, &next)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAcquireLease проверяет, что занятая другим экземпляром аренда не захватывается.
+func TestAcquireLease(t *testing.T) {
+	repo, mock := setupScheduleRepository(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(`INSERT INTO leases`).
+		WithArgs("scheduler", "a", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"holder"}).AddRow("a"))
+	mock.ExpectQuery(`INSERT INTO leases`).
+		WithArgs("scheduler", "b", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(pgx.ErrNoRows)
+
+	ok, err := repo.AcquireLease(context.Background(), "scheduler", "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.AcquireLease(context.Background(), "scheduler", "b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFinishRun проверяет статус запуска в зависимости от ошибки.
+func TestFinishRun(t *testing.T) {
+	repo, mock := setupScheduleRepository(t)
+	defer mock.Close()
+
+	taskID := int64(42)
+	mock.ExpectExec(`UPDATE schedule_runs SET status`).
+		WithArgs(models.RunCreated, &taskID, "", int64(1)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`UPDATE schedule_runs SET status`).
+		WithArgs(models.RunFailed, (*int64)(nil), "template not found", int64(2)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	require.NoError(t, repo.FinishRun(context.Background(), 1, &taskID, ""))
+	require.NoError(t, repo.FinishRun(context.Background(), 2, nil, "template not found"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
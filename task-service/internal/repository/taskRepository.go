@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"task-service/internal/models"
 	"time"
@@ -17,6 +18,7 @@ type DB interface {
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	Exec(ctx context.Context, sql string, args ...interface{}) error
+	Begin(ctx context.Context) (pgx.Tx, error)
 	Ping(ctx context.Context) error
 	Close()
 }
@@ -25,6 +27,10 @@ type TaskRepository interface {
 	CreateNewTask(ctx context.Context, task models.Task) (int64, error)
 	GetTaskByID(ctx context.Context, id int64) (*models.Task, error)
 	ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	UpdateTaskStatus(ctx context.Context, event models.StatusEvent) (int64, error)
+	UpdateShardStatus(ctx context.Context, event models.StatusEvent) (int64, error)
+	GetTaskReport(ctx context.Context, id int64) (*models.TaskReport, error)
+	CancelTask(ctx context.Context, id int64) (string, error)
 }
 
 type postgresTaskRepository struct {
@@ -36,30 +42,87 @@ func NewTaskRepository(db DB, logger *zap.SugaredLogger) *postgresTaskRepository
 	return &postgresTaskRepository{db: db, logger: logger}
 }
 
+// CreateNewTask inserts the task and its "task.created" outbox message in one transaction,
+// so a stored task is always eventually published to Kafka by the outbox relay.
+// A task with a ShardCount gets a task_shards row and a message per shard instead.
 func (r *postgresTaskRepository) CreateNewTask(ctx context.Context, task models.Task) (int64, error) {
-	task.TaskID = uuid.NewString()
-	task.Status = "pending"
+	if task.TaskID == "" {
+		task.TaskID = uuid.NewString()
+	}
+	task.Status = models.StatusPending
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = task.CreatedAt
 
-	query := `INSERT INTO tasks (task_id, user_id, type, template_id, template, amount, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO tasks (task_id, user_id, type, template_id, template_version, template, amount, seed, locale, format, table_name, sql_dialect, request, status, shard_count, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING id`
 
 	var id int64
-	err := r.db.QueryRow(ctx, query, task.TaskID, task.UserID, task.Type, task.TemplateID, task.Template, task.Amount, task.Status, task.CreatedAt, task.UpdatedAt).Scan(&id)
+	err = tx.QueryRow(ctx, query, task.TaskID, task.UserID, task.Type, task.TemplateID, task.TemplateVersion, task.Template, task.Amount, task.Seed, task.Locale, task.Format, task.TableName, task.SQLDialect, task.Request, task.Status, task.ShardCount, task.CreatedAt, task.UpdatedAt).Scan(&id)
 	if err != nil {
 		r.logger.Errorf("Failed to insert task: %v", err)
 		return 0, err
 	}
 
+	task.ID = id
+	if task.ShardCount > 0 {
+		err = r.insertShards(ctx, tx, task)
+	} else {
+		err = insertTaskMessage(ctx, tx, models.EventTaskCreated, task.TaskID, task)
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to insert outbox message for task %d: %v", id, err)
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Failed to commit task %d: %v", id, err)
+		return 0, err
+	}
+
 	r.logger.Infof("Task created with ID: %d", id)
 	return id, nil
 }
 
+// insertShards stores the shards of a task and an outbox message for each of them.
+func (r *postgresTaskRepository) insertShards(ctx context.Context, tx pgx.Tx, task models.Task) error {
+	for _, shard := range models.SplitShards(task.Amount, task.ShardCount) {
+		query := `INSERT INTO task_shards (task_id, shard_index, record_offset, amount, status, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`
+		if _, err := tx.Exec(ctx, query, task.TaskID, shard.Index, shard.Offset, shard.Amount, models.StatusPending, task.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert shard %d: %w", shard.Index, err)
+		}
+
+		msg := task
+		msg.Shard = &shard
+		if err := insertTaskMessage(ctx, tx, models.EventTaskCreated, models.ShardKey(task.TaskID, shard.Index), msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertTaskMessage stores task as the payload of an outbox message.
+func insertTaskMessage(ctx context.Context, tx pgx.Tx, eventType, key string, task models.Task) error {
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return insertOutboxMessage(ctx, tx, eventType, key, payload)
+}
+
 func (r *postgresTaskRepository) GetTaskByID(ctx context.Context, id int64) (*models.Task, error) {
-	query := `SELECT id, task_id, user_id, type, template_id, template, amount, status, created_at, updated_at FROM tasks WHERE id = $1`
+	query := `SELECT id, task_id, user_id, type, template_id, template_version, template, amount, seed, locale, format, table_name, sql_dialect, request, status, records_generated, error, result_key, result_size, shard_count, created_at, updated_at FROM tasks WHERE id = $1`
 
 	var task models.Task
-	err := r.db.QueryRow(ctx, query, id).Scan(&task.ID, &task.TaskID, &task.UserID, &task.Type, &task.TemplateID, &task.Template, &task.Amount, &task.Status, &task.CreatedAt, &task.UpdatedAt)
+	err := r.db.QueryRow(ctx, query, id).Scan(&task.ID, &task.TaskID, &task.UserID, &task.Type, &task.TemplateID, &task.TemplateVersion, &task.Template, &task.Amount, &task.Seed, &task.Locale, &task.Format, &task.TableName, &task.SQLDialect, &task.Request, &task.Status, &task.RecordsGenerated, &task.Error, &task.ResultKey, &task.ResultSize, &task.ShardCount, &task.CreatedAt, &task.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrTaskNotFound
+	}
 	if err != nil {
 		r.logger.Errorf("Failed to get task: %v", err)
 		return nil, err
@@ -70,7 +133,7 @@ func (r *postgresTaskRepository) GetTaskByID(ctx context.Context, id int64) (*mo
 }
 
 func (r *postgresTaskRepository) ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
-	query := `SELECT id, task_id, user_id, type, template_id, template, amount, status, created_at, updated_at 
+	query := `SELECT id, task_id, user_id, type, template_id, template_version, template, amount, seed, locale, format, table_name, sql_dialect, request, status, records_generated, error, result_key, result_size, shard_count, created_at, updated_at 
               FROM tasks WHERE 1=1`
 
 	args := make([]interface{}, 0)
@@ -130,9 +193,21 @@ func (r *postgresTaskRepository) ListTasks(ctx context.Context, filter models.Ta
 			&task.UserID,
 			&task.Type,
 			&task.TemplateID,
+			&task.TemplateVersion,
 			&templateBytes, // Scan JSONB as bytes
 			&task.Amount,
+			&task.Seed,
+			&task.Locale,
+			&task.Format,
+			&task.TableName,
+			&task.SQLDialect,
+			&task.Request,
 			&task.Status,
+			&task.RecordsGenerated,
+			&task.Error,
+			&task.ResultKey,
+			&task.ResultSize,
+			&task.ShardCount,
 			&task.CreatedAt,
 			&task.UpdatedAt,
 		); err != nil {
@@ -158,3 +233,250 @@ func (r *postgresTaskRepository) ListTasks(ctx context.Context, filter models.Ta
 	r.logger.Infof("Retrieved %d tasks", len(tasks))
 	return tasks, nil
 }
+
+// UpdateTaskStatus applies a status event if the task's current status allows the transition.
+// A terminal status also stores a "task.finished" outbox message in the same transaction.
+// It returns the numeric task id, or models.ErrInvalidStatusTransition if the task is missing
+// or already in a status the event cannot move it from.
+func (r *postgresTaskRepository) UpdateTaskStatus(ctx context.Context, event models.StatusEvent) (int64, error) {
+	from := models.PreviousStatuses(event.Status)
+	if len(from) == 0 {
+		return 0, models.ErrInvalidStatusTransition
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE tasks
+              SET status = $1, records_generated = GREATEST(records_generated, $2), error = $3,
+                  result_key = COALESCE(NULLIF($4, ''), result_key), result_size = GREATEST(result_size, $5),
+                  report = COALESCE($6, report), updated_at = $7
+              WHERE task_id = $8 AND status = ANY($9)
+              RETURNING id, user_id, type, records_generated, result_size`
+
+	now := time.Now()
+	var id int64
+	notification := models.TaskNotification{TaskID: event.TaskID, Status: event.Status, Error: event.Error, FinishedAt: now}
+	err = tx.QueryRow(ctx, query, event.Status, event.RecordsGenerated, event.Error, event.ResultKey, event.ResultSize, event.Report, now, event.TaskID, from).
+		Scan(&id, &notification.UserID, &notification.Type, &notification.RecordsGenerated, &notification.ResultSize)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.logger.Warnf("Rejected status %s for task %s", event.Status, event.TaskID)
+		return 0, models.ErrInvalidStatusTransition
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to update status of task %s: %v", event.TaskID, err)
+		return 0, err
+	}
+
+	if models.IsTerminalStatus(event.Status) {
+		if err := insertNotification(ctx, tx, notification); err != nil {
+			r.logger.Errorf("Failed to insert outbox message for task %s: %v", event.TaskID, err)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Failed to commit status of task %s: %v", event.TaskID, err)
+		return 0, err
+	}
+
+	r.logger.Infof("Task %s moved to status %s", event.TaskID, event.Status)
+	return id, nil
+}
+
+// insertNotification stores a "task.finished" outbox message for notification-service.
+func insertNotification(ctx context.Context, tx pgx.Tx, notification models.TaskNotification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return insertOutboxMessage(ctx, tx, models.EventTaskFinished, notification.TaskID, payload)
+}
+
+// GetTaskReport returns the status and the latest report of a task. Report is nil until an
+// http task has reported its first requests.
+func (r *postgresTaskRepository) GetTaskReport(ctx context.Context, id int64) (*models.TaskReport, error) {
+	var taskType string
+	report := models.TaskReport{}
+	err := r.db.QueryRow(ctx, `SELECT task_id, type, status, report FROM tasks WHERE id = $1`, id).
+		Scan(&report.TaskID, &taskType, &report.Status, &report.Report)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrTaskNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to get report of task %d: %v", id, err)
+		return nil, err
+	}
+	if taskType != models.TaskTypeHTTP {
+		return nil, models.ErrReportNotFound
+	}
+	return &report, nil
+}
+
+// UpdateShardStatus applies a status event of one shard and rolls it up into its task:
+// the task records the sum of the shard progress, fails with the first failed shard (the
+// other shards are told to stop and notification-service that the task finished) and,
+// once every shard has succeeded, gets a "task.merge" outbox message asking a worker to
+// assemble the result. The task itself succeeds with the status event of the merge. It
+// returns the numeric task id, or models.ErrInvalidStatusTransition if the task or shard
+// cannot make the transition.
+func (r *postgresTaskRepository) UpdateShardStatus(ctx context.Context, event models.StatusEvent) (int64, error) {
+	from := models.PreviousStatuses(event.Status)
+	if event.Shard == nil || len(from) == 0 {
+		return 0, models.ErrInvalidStatusTransition
+	}
+	shard := *event.Shard
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the task serialises the events of its shards.
+	task := models.Task{TaskID: event.TaskID}
+	err = tx.QueryRow(ctx, `SELECT id, user_id, type, status, amount, format, shard_count FROM tasks WHERE task_id = $1 FOR UPDATE`, event.TaskID).
+		Scan(&task.ID, &task.UserID, &task.Type, &task.Status, &task.Amount, &task.Format, &task.ShardCount)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && models.IsTerminalStatus(task.Status)) {
+		r.logger.Warnf("Rejected status %s for shard %d of task %s", event.Status, shard, event.TaskID)
+		return 0, models.ErrInvalidStatusTransition
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to lock task %s: %v", event.TaskID, err)
+		return 0, err
+	}
+
+	now := time.Now()
+	query := `UPDATE task_shards
+              SET status = $1, records_generated = GREATEST(records_generated, $2), error = $3,
+                  result_key = COALESCE(NULLIF($4, ''), result_key), result_size = GREATEST(result_size, $5), updated_at = $6
+              WHERE task_id = $7 AND shard_index = $8 AND status = ANY($9)
+              RETURNING shard_index`
+	err = tx.QueryRow(ctx, query, event.Status, event.RecordsGenerated, event.Error, event.ResultKey, event.ResultSize, now, event.TaskID, shard, from).Scan(&shard)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.logger.Warnf("Rejected status %s for shard %d of task %s", event.Status, shard, event.TaskID)
+		return 0, models.ErrInvalidStatusTransition
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to update shard %d of task %s: %v", shard, event.TaskID, err)
+		return 0, err
+	}
+
+	var generated, succeeded int
+	err = tx.QueryRow(ctx, `SELECT COALESCE(SUM(records_generated), 0), COUNT(*) FILTER (WHERE status = $1) FROM task_shards WHERE task_id = $2`,
+		models.StatusSucceeded, event.TaskID).Scan(&generated, &succeeded)
+	if err != nil {
+		r.logger.Errorf("Failed to sum shards of task %s: %v", event.TaskID, err)
+		return 0, err
+	}
+
+	status, taskError := models.StatusRunning, ""
+	if event.Status == models.StatusFailed {
+		status, taskError = models.StatusFailed, fmt.Sprintf("shard %d: %s", shard, event.Error)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE tasks SET status = $1, records_generated = $2, error = $3, updated_at = $4 WHERE id = $5`,
+		status, generated, taskError, now, task.ID); err != nil {
+		r.logger.Errorf("Failed to update task %s: %v", event.TaskID, err)
+		return 0, err
+	}
+
+	switch {
+	case status == models.StatusFailed:
+		// Stop the shards that are still running; their results are of no use.
+		payload, err := json.Marshal(models.CancelCommand{TaskID: event.TaskID, RequestedAt: now})
+		if err == nil {
+			err = insertOutboxMessage(ctx, tx, models.EventTaskCancelled, event.TaskID, payload)
+		}
+		if err == nil {
+			err = insertNotification(ctx, tx, models.TaskNotification{
+				TaskID:           event.TaskID,
+				UserID:           task.UserID,
+				Type:             task.Type,
+				Status:           status,
+				RecordsGenerated: generated,
+				Error:            taskError,
+				FinishedAt:       now,
+			})
+		}
+		if err != nil {
+			r.logger.Errorf("Failed to insert outbox message for task %s: %v", event.TaskID, err)
+			return 0, err
+		}
+	case succeeded == task.ShardCount:
+		task.Merge = true
+		if err := insertTaskMessage(ctx, tx, models.EventTaskMerge, event.TaskID, task); err != nil {
+			r.logger.Errorf("Failed to insert outbox message for task %s: %v", event.TaskID, err)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Failed to commit status of shard %d of task %s: %v", shard, event.TaskID, err)
+		return 0, err
+	}
+
+	r.logger.Infof("Shard %d of task %s moved to status %s", shard, event.TaskID, event.Status)
+	return task.ID, nil
+}
+
+// CancelTask moves the task to cancelled and stores "task.cancelled" and "task.finished"
+// outbox messages in the same transaction. It returns the task's TaskID, models.ErrTaskNotFound if there is no such
+// task, or models.ErrInvalidStatusTransition if the task has already finished.
+func (r *postgresTaskRepository) CancelTask(ctx context.Context, id int64) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Failed to begin transaction: %v", err)
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var taskID, status string
+	notification := models.TaskNotification{Status: models.StatusCancelled}
+	err = tx.QueryRow(ctx, `SELECT task_id, user_id, type, status, records_generated FROM tasks WHERE id = $1 FOR UPDATE`, id).
+		Scan(&taskID, &notification.UserID, &notification.Type, &status, &notification.RecordsGenerated)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", models.ErrTaskNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to lock task %d: %v", id, err)
+		return "", err
+	}
+
+	if !models.CanTransition(status, models.StatusCancelled) {
+		r.logger.Warnf("Rejected cancellation of task %d in status %s", id, status)
+		return "", models.ErrInvalidStatusTransition
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(ctx, `UPDATE tasks SET status = $1, updated_at = $2 WHERE id = $3`, models.StatusCancelled, now, id); err != nil {
+		r.logger.Errorf("Failed to cancel task %d: %v", id, err)
+		return "", err
+	}
+
+	payload, err := json.Marshal(models.CancelCommand{TaskID: taskID, RequestedAt: now})
+	if err != nil {
+		return "", err
+	}
+	if err := insertOutboxMessage(ctx, tx, models.EventTaskCancelled, taskID, payload); err != nil {
+		r.logger.Errorf("Failed to insert outbox message for task %d: %v", id, err)
+		return "", err
+	}
+	notification.TaskID, notification.FinishedAt = taskID, now
+	if err := insertNotification(ctx, tx, notification); err != nil {
+		r.logger.Errorf("Failed to insert outbox message for task %d: %v", id, err)
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Failed to commit cancellation of task %d: %v", id, err)
+		return "", err
+	}
+
+	r.logger.Infof("Task %s cancelled", taskID)
+	return taskID, nil
+}
@@ -23,11 +23,19 @@ func (f *fakeDB) QueryRow(ctx context.Context, sql string, args ...interface{})
 	return f.mock.QueryRow(ctx, sql, args...)
 }
 
+func (f *fakeDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return f.mock.Query(ctx, sql, args...)
+}
+
 func (f *fakeDB) Exec(ctx context.Context, sql string, args ...interface{}) error {
 	_, err := f.mock.Exec(ctx, sql, args...)
 	return err
 }
 
+func (f *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return f.mock.Begin(ctx)
+}
+
 func (f *fakeDB) Ping(ctx context.Context) error {
 	return f.mock.Ping(ctx)
 }
@@ -60,11 +68,17 @@ func TestCreateNewTask_Success(t *testing.T) {
 		TemplateID: "template-456",
 		Template:   map[string]interface{}{"name": "{{name}}"},
 		Amount:     100,
+		Format:     "csv",
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(`INSERT INTO tasks`).
-		WithArgs(pgxmock.AnyArg(), "user-123", "test", "template-456", task.Template, 100, "pending", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(pgxmock.AnyArg(), "user-123", "test", "template-456", 0, task.Template, 100, int64(0), "", "csv", "", "", (*models.HTTPRequest)(nil), "pending", 0, pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskCreated, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
 
 	id, err := repo.CreateNewTask(context.Background(), task)
 	require.NoError(t, err)
@@ -84,11 +98,14 @@ func TestCreateNewTask_DBError(t *testing.T) {
 		TemplateID: "template-456",
 		Template:   map[string]interface{}{"name": "{{name}}"},
 		Amount:     100,
+		Format:     "csv",
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(`INSERT INTO tasks`).
-		WithArgs(pgxmock.AnyArg(), "user-123", "test", "template-456", task.Template, 100, "pending", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(pgxmock.AnyArg(), "user-123", "test", "template-456", 0, task.Template, 100, int64(0), "", "csv", "", "", (*models.HTTPRequest)(nil), "pending", 0, pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnError(errors.New("db error"))
+	mock.ExpectRollback()
 
 	id, err := repo.CreateNewTask(context.Background(), task)
 	require.Error(t, err)
@@ -98,28 +115,104 @@ func TestCreateNewTask_DBError(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
-// TestGetTaskByID_Success проверяет успешное получение задачи.
-func TestGetTaskByID_Success(t *testing.T) {
+// TestCreateNewTask_OutboxError проверяет откат задачи, если не удалось записать outbox.
+func TestCreateNewTask_OutboxError(t *testing.T) {
 	repo, mock := setupTaskRepository(t)
 	defer mock.Close()
 
 	task := models.Task{
-		ID:         1,
 		TaskID:     "task-123",
 		UserID:     "user-123",
 		Type:       "test",
 		TemplateID: "template-456",
 		Template:   map[string]interface{}{"name": "{{name}}"},
 		Amount:     100,
-		Status:     "pending",
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		Format:     "json",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO tasks`).
+		WithArgs("task-123", "user-123", "test", "template-456", 0, task.Template, 100, int64(0), "", "json", "", "", (*models.HTTPRequest)(nil), "pending", 0, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskCreated, "task-123", pgxmock.AnyArg()).
+		WillReturnError(errors.New("outbox error"))
+	mock.ExpectRollback()
+
+	id, err := repo.CreateNewTask(context.Background(), task)
+	require.Error(t, err)
+	assert.Equal(t, int64(0), id)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreateNewTask_Sharded проверяет запись шардов и отдельного outbox-сообщения для каждого из них.
+func TestCreateNewTask_Sharded(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	task := models.Task{
+		TaskID:     "task-123",
+		UserID:     "user-123",
+		Type:       "test",
+		TemplateID: "template-456",
+		Template:   map[string]interface{}{"name": "{{name}}"},
+		Amount:     5,
+		Format:     "csv",
+		ShardCount: 2,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO tasks`).
+		WithArgs("task-123", "user-123", "test", "template-456", 0, task.Template, 5, int64(0), "", "csv", "", "", (*models.HTTPRequest)(nil), "pending", 2, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectExec(`INSERT INTO task_shards`).
+		WithArgs("task-123", 0, 0, 3, models.StatusPending, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskCreated, "task-123/0", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`INSERT INTO task_shards`).
+		WithArgs("task-123", 1, 3, 2, models.StatusPending, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskCreated, "task-123/1", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	id, err := repo.CreateNewTask(context.Background(), task)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetTaskByID_Success проверяет успешное получение задачи.
+func TestGetTaskByID_Success(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	task := models.Task{
+		ID:              1,
+		TaskID:          "task-123",
+		UserID:          "user-123",
+		Type:            "test",
+		TemplateID:      "template-456",
+		TemplateVersion: 3,
+		Template:        map[string]interface{}{"name": "{{name}}"},
+		Amount:          100,
+		Format:          "sql",
+		TableName:       "users",
+		SQLDialect:      "postgres",
+		Status:          "pending",
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
-	mock.ExpectQuery(`SELECT id, task_id, user_id, type, template_id, template, amount, status, created_at, updated_at`).
+	mock.ExpectQuery(`SELECT id, task_id, user_id, type, template_id, template_version, template, amount, seed, locale, format, table_name, sql_dialect, request, status, records_generated, error, result_key, result_size, shard_count, created_at, updated_at`).
 		WithArgs(int64(1)).
-		WillReturnRows(pgxmock.NewRows([]string{"id", "task_id", "user_id", "type", "template_id", "template", "amount", "status", "created_at", "updated_at"}).
-			AddRow(task.ID, task.TaskID, task.UserID, task.Type, task.TemplateID, task.Template, task.Amount, task.Status, task.CreatedAt, task.UpdatedAt))
+		WillReturnRows(pgxmock.NewRows([]string{"id", "task_id", "user_id", "type", "template_id", "template_version", "template", "amount", "seed", "locale", "format", "table_name", "sql_dialect", "request", "status", "records_generated", "error", "result_key", "result_size", "shard_count", "created_at", "updated_at"}).
+			AddRow(task.ID, task.TaskID, task.UserID, task.Type, task.TemplateID, task.TemplateVersion, task.Template, task.Amount, task.Seed, task.Locale, task.Format, task.TableName, task.SQLDialect, task.Request, task.Status, task.RecordsGenerated, task.Error, task.ResultKey, task.ResultSize, task.ShardCount, task.CreatedAt, task.UpdatedAt))
 
 	result, err := repo.GetTaskByID(context.Background(), 1)
 	require.NoError(t, err)
@@ -131,6 +224,9 @@ func TestGetTaskByID_Success(t *testing.T) {
 	assert.Equal(t, task.TemplateID, result.TemplateID)
 	assert.Equal(t, task.Template, result.Template)
 	assert.Equal(t, task.Amount, result.Amount)
+	assert.Equal(t, task.Format, result.Format)
+	assert.Equal(t, task.TableName, result.TableName)
+	assert.Equal(t, task.SQLDialect, result.SQLDialect)
 	assert.Equal(t, task.Status, result.Status)
 
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -141,7 +237,7 @@ func TestGetTaskByID_DBError(t *testing.T) {
 	repo, mock := setupTaskRepository(t)
 	defer mock.Close()
 
-	mock.ExpectQuery(`SELECT id, task_id, user_id, type, template_id, template, amount, status, created_at, updated_at`).
+	mock.ExpectQuery(`SELECT id, task_id, user_id, type, template_id, template_version, template, amount, seed, locale, format, table_name, sql_dialect, request, status, records_generated, error, result_key, result_size, shard_count, created_at, updated_at`).
 		WithArgs(int64(1)).
 		WillReturnError(errors.New("db error"))
 
@@ -152,3 +248,279 @@ func TestGetTaskByID_DBError(t *testing.T) {
 
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+// TestGetTaskByID_NotFound проверяет, что отсутствующая задача возвращает ErrTaskNotFound.
+func TestGetTaskByID_NotFound(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT id, task_id, user_id, type, template_id, template_version, template, amount, seed, locale, format, table_name, sql_dialect, request, status, records_generated, error, result_key, result_size, shard_count, created_at, updated_at`).
+		WithArgs(int64(404)).
+		WillReturnError(pgx.ErrNoRows)
+
+	result, err := repo.GetTaskByID(context.Background(), 404)
+	assert.ErrorIs(t, err, models.ErrTaskNotFound)
+	assert.Nil(t, result)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateTaskStatus_Success проверяет успешную смену статуса задачи.
+func TestUpdateTaskStatus_Success(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	report := &models.Report{Total: 100, Passed: 98, Failed: 2}
+	event := models.StatusEvent{TaskID: "task-123", Status: models.StatusSucceeded, RecordsGenerated: 100, ResultKey: "results/task-123.csv", ResultSize: 2048, Report: report}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE tasks`).
+		WithArgs(models.StatusSucceeded, 100, "", "results/task-123.csv", int64(2048), report, pgxmock.AnyArg(), "task-123", []string{models.StatusRunning}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "type", "records_generated", "result_size"}).AddRow(int64(7), "user-1", "generate", 100, int64(2048)))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskFinished, "task-123", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	id, err := repo.UpdateTaskStatus(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateTaskStatus_RejectedTransition проверяет отказ, если задача уже в конечном статусе.
+func TestUpdateTaskStatus_RejectedTransition(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE tasks`).
+		WithArgs(models.StatusRunning, 0, "", "", int64(0), (*models.Report)(nil), pgxmock.AnyArg(), "task-123", []string{models.StatusPending, models.StatusQueued, models.StatusRunning}).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := repo.UpdateTaskStatus(context.Background(), models.StatusEvent{TaskID: "task-123", Status: models.StatusRunning})
+	assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateTaskStatus_ToPending проверяет, что в pending вернуться нельзя.
+func TestUpdateTaskStatus_ToPending(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	_, err := repo.UpdateTaskStatus(context.Background(), models.StatusEvent{TaskID: "task-123", Status: models.StatusPending})
+	assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateShardStatus_Progress проверяет, что задача получает сумму записей своих шардов.
+func TestUpdateShardStatus_Progress(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	shard := 1
+	event := models.StatusEvent{TaskID: "task-123", Shard: &shard, Status: models.StatusRunning, RecordsGenerated: 50}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, type, status, amount, format, shard_count FROM tasks`).
+		WithArgs("task-123").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "type", "status", "amount", "format", "shard_count"}).AddRow(int64(7), "user-1", "generate", models.StatusQueued, 200, "csv", 2))
+	mock.ExpectQuery(`UPDATE task_shards`).
+		WithArgs(models.StatusRunning, 50, "", "", int64(0), pgxmock.AnyArg(), "task-123", 1, []string{models.StatusPending, models.StatusQueued, models.StatusRunning}).
+		WillReturnRows(pgxmock.NewRows([]string{"shard_index"}).AddRow(1))
+	mock.ExpectQuery(`SELECT COALESCE`).
+		WithArgs(models.StatusSucceeded, "task-123").
+		WillReturnRows(pgxmock.NewRows([]string{"sum", "count"}).AddRow(120, 0))
+	mock.ExpectExec(`UPDATE tasks`).
+		WithArgs(models.StatusRunning, 120, "", pgxmock.AnyArg(), int64(7)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	id, err := repo.UpdateShardStatus(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateShardStatus_LastShard проверяет, что после успеха последнего шарда в outbox
+// записывается сообщение о слиянии.
+func TestUpdateShardStatus_LastShard(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	shard := 0
+	event := models.StatusEvent{TaskID: "task-123", Shard: &shard, Status: models.StatusSucceeded, RecordsGenerated: 100, ResultKey: "shards/task-123/0.csv", ResultSize: 512}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, type, status, amount, format, shard_count FROM tasks`).
+		WithArgs("task-123").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "type", "status", "amount", "format", "shard_count"}).AddRow(int64(7), "user-1", "generate", models.StatusRunning, 200, "csv", 2))
+	mock.ExpectQuery(`UPDATE task_shards`).
+		WithArgs(models.StatusSucceeded, 100, "", "shards/task-123/0.csv", int64(512), pgxmock.AnyArg(), "task-123", 0, []string{models.StatusRunning}).
+		WillReturnRows(pgxmock.NewRows([]string{"shard_index"}).AddRow(0))
+	mock.ExpectQuery(`SELECT COALESCE`).
+		WithArgs(models.StatusSucceeded, "task-123").
+		WillReturnRows(pgxmock.NewRows([]string{"sum", "count"}).AddRow(200, 2))
+	mock.ExpectExec(`UPDATE tasks`).
+		WithArgs(models.StatusRunning, 200, "", pgxmock.AnyArg(), int64(7)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskMerge, "task-123", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	_, err := repo.UpdateShardStatus(context.Background(), event)
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateShardStatus_Failed проверяет, что ошибка шарда завершает задачу и отменяет остальные шарды.
+func TestUpdateShardStatus_Failed(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	shard := 1
+	event := models.StatusEvent{TaskID: "task-123", Shard: &shard, Status: models.StatusFailed, Error: "boom"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, type, status, amount, format, shard_count FROM tasks`).
+		WithArgs("task-123").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "type", "status", "amount", "format", "shard_count"}).AddRow(int64(7), "user-1", "generate", models.StatusRunning, 200, "csv", 2))
+	mock.ExpectQuery(`UPDATE task_shards`).
+		WithArgs(models.StatusFailed, 0, "boom", "", int64(0), pgxmock.AnyArg(), "task-123", 1, []string{models.StatusPending, models.StatusQueued, models.StatusRunning}).
+		WillReturnRows(pgxmock.NewRows([]string{"shard_index"}).AddRow(1))
+	mock.ExpectQuery(`SELECT COALESCE`).
+		WithArgs(models.StatusSucceeded, "task-123").
+		WillReturnRows(pgxmock.NewRows([]string{"sum", "count"}).AddRow(40, 0))
+	mock.ExpectExec(`UPDATE tasks`).
+		WithArgs(models.StatusFailed, 40, "shard 1: boom", pgxmock.AnyArg(), int64(7)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskCancelled, "task-123", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskFinished, "task-123", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	_, err := repo.UpdateShardStatus(context.Background(), event)
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateShardStatus_TaskFinished проверяет, что события шардов завершённой задачи отклоняются.
+func TestUpdateShardStatus_TaskFinished(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	shard := 0
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, type, status, amount, format, shard_count FROM tasks`).
+		WithArgs("task-123").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "type", "status", "amount", "format", "shard_count"}).AddRow(int64(7), "user-1", "generate", models.StatusCancelled, 200, "csv", 2))
+	mock.ExpectRollback()
+
+	_, err := repo.UpdateShardStatus(context.Background(), models.StatusEvent{TaskID: "task-123", Shard: &shard, Status: models.StatusRunning})
+	assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetTaskReport проверяет чтение отчёта http-задачи и отказ для задач других типов.
+func TestGetTaskReport(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	report := &models.Report{Total: 10, Passed: 9, Failed: 1}
+	mock.ExpectQuery(`SELECT task_id, type, status, report FROM tasks WHERE id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"task_id", "type", "status", "report"}).AddRow("task-123", models.TaskTypeHTTP, models.StatusRunning, report))
+	mock.ExpectQuery(`SELECT task_id, type, status, report FROM tasks`).
+		WithArgs(int64(2)).
+		WillReturnRows(pgxmock.NewRows([]string{"task_id", "type", "status", "report"}).AddRow("task-456", "generate", models.StatusSucceeded, (*models.Report)(nil)))
+	mock.ExpectQuery(`SELECT task_id, type, status, report FROM tasks`).
+		WithArgs(int64(3)).
+		WillReturnError(pgx.ErrNoRows)
+
+	got, err := repo.GetTaskReport(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "task-123", got.TaskID)
+	assert.Equal(t, models.StatusRunning, got.Status)
+	assert.Equal(t, report, got.Report)
+
+	_, err = repo.GetTaskReport(context.Background(), 2)
+	assert.ErrorIs(t, err, models.ErrReportNotFound)
+
+	_, err = repo.GetTaskReport(context.Background(), 3)
+	assert.ErrorIs(t, err, models.ErrTaskNotFound)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCancelTask_Success проверяет отмену задачи вместе с записью в outbox.
+func TestCancelTask_Success(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT task_id, user_id, type, status, records_generated FROM tasks WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"task_id", "user_id", "type", "status", "records_generated"}).AddRow("task-123", "user-1", "generate", models.StatusRunning, 40))
+	mock.ExpectExec(`UPDATE tasks SET status`).
+		WithArgs(models.StatusCancelled, pgxmock.AnyArg(), int64(1)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskCancelled, "task-123", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`INSERT INTO outbox`).
+		WithArgs(models.EventTaskFinished, "task-123", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	taskID, err := repo.CancelTask(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "task-123", taskID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCancelTask_Finished проверяет, что завершённую задачу нельзя отменить.
+func TestCancelTask_Finished(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT task_id, user_id, type, status, records_generated FROM tasks`).
+		WithArgs(int64(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"task_id", "user_id", "type", "status", "records_generated"}).AddRow("task-123", "user-1", "generate", models.StatusSucceeded, 100))
+	mock.ExpectRollback()
+
+	_, err := repo.CancelTask(context.Background(), 1)
+	assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCancelTask_NotFound проверяет отмену несуществующей задачи.
+func TestCancelTask_NotFound(t *testing.T) {
+	repo, mock := setupTaskRepository(t)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT task_id, user_id, type, status, records_generated FROM tasks`).
+		WithArgs(int64(42)).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := repo.CancelTask(context.Background(), 42)
+	assert.ErrorIs(t, err, models.ErrTaskNotFound)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
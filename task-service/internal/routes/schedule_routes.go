@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"task-service/internal/middleware"
+	"task-service/internal/models"
+	"task-service/internal/transport/http/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+func SetupScheduleRoutes(router *echo.Echo, scheduleHandler *handlers.ScheduleHandler, auth echo.MiddlewareFunc) {
+	read := middleware.RequireScope(models.ScopeTasksRead)
+	write := middleware.RequireScope(models.ScopeTasksWrite)
+
+	api := router.Group("/api/v2/schedules", auth)
+	{
+		api.POST("", scheduleHandler.CreateSchedule, write)
+		api.GET("", scheduleHandler.ListSchedules, read)
+		api.GET("/:id", scheduleHandler.GetSchedule, read)
+		api.PUT("/:id", scheduleHandler.UpdateSchedule, write, scheduleHandler.RequireOwner)
+		api.DELETE("/:id", scheduleHandler.DeleteSchedule, write, scheduleHandler.RequireOwner)
+		api.GET("/:id/runs", scheduleHandler.ListRuns, read, scheduleHandler.RequireOwner)
+	}
+}
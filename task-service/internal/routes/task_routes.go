@@ -1,16 +1,30 @@
 package routes
 
 import (
+	"task-service/internal/middleware"
+	"task-service/internal/models"
 	"task-service/internal/transport/http/handlers"
 
 	"github.com/labstack/echo/v4"
 )
 
-func SetupTaskRoutes(router *echo.Echo, taskHandler *handlers.TaskHandler) {
-	api := router.Group("/api/v2/tasks")
+func SetupTaskRoutes(router *echo.Echo, taskHandler *handlers.TaskHandler, resultHandler *handlers.ResultHandler, auth echo.MiddlewareFunc) {
+	// A signed result URL is a credential of its own, so it is downloaded without a token.
+	router.GET("/api/v2/tasks/:id/result/download", resultHandler.DownloadTaskResult)
+
+	read := middleware.RequireScope(models.ScopeTasksRead)
+	write := middleware.RequireScope(models.ScopeTasksWrite)
+	// The template of a new task is fetched from template-service with the caller's key.
+	readTemplates := middleware.RequireScope(models.ScopeTemplatesRead)
+
+	api := router.Group("/api/v2/tasks", auth)
 	{
-		api.POST("", taskHandler.CreateNewTask)
-		api.GET("/:id", taskHandler.GetTaskByID)
-		api.GET("", taskHandler.ListTasks)
+		api.POST("", taskHandler.CreateNewTask, write, readTemplates)
+		api.GET("/:id", taskHandler.GetTaskByID, read)
+		api.GET("", taskHandler.ListTasks, read)
+		api.POST("/:id/cancel", taskHandler.CancelTask, write, taskHandler.RequireOwner)
+		api.POST("/:id/regenerate", taskHandler.RegenerateTask, write, readTemplates, taskHandler.RequireOwner)
+		api.GET("/:id/report", taskHandler.GetTaskReport, read, taskHandler.RequireOwner)
+		api.GET("/:id/result", resultHandler.GetTaskResult, read, taskHandler.RequireOwner)
 	}
 }
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"task-service/internal/models"
+	"task-service/internal/repository"
+	"task-service/pkg/broker/kafka"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// OutboxRelay publishes messages stored in the outbox table to Kafka.
+type OutboxRelay struct {
+	repo      repository.OutboxRepository
+	producers map[string]kafka.KafkaProducer
+	tasks     TaskService
+	interval  time.Duration
+	batchSize int
+	logger    *zap.SugaredLogger
+}
+
+// NewOutboxRelay creates a relay; producers maps outbox event types to the Kafka producer of their topic.
+func NewOutboxRelay(
+	repo repository.OutboxRepository,
+	producers map[string]kafka.KafkaProducer,
+	tasks TaskService,
+	interval time.Duration,
+	batchSize int,
+	logger *zap.SugaredLogger,
+) *OutboxRelay {
+	return &OutboxRelay{
+		repo:      repo,
+		producers: producers,
+		tasks:     tasks,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Run relays pending messages until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	r.logger.Infof("Outbox relay started, polling every %v", r.interval)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+			// Drain the backlog before waiting for the next tick.
+			for {
+				published, err := r.RelayOnce(ctx)
+				if err != nil {
+					r.logger.Errorf("Outbox relay iteration failed: %v", err)
+					break
+				}
+				if published < r.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// RelayOnce publishes one batch of due outbox messages and returns how many were published.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	return r.repo.ProcessPending(ctx, r.batchSize, r.publish)
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, msg models.OutboxMessage) error {
+	producer, ok := r.producers[msg.EventType]
+	if !ok {
+		return fmt.Errorf("no producer for event type %q", msg.EventType)
+	}
+
+	if err := producer.Produce(ctx, []byte(msg.Key), msg.Payload); err != nil {
+		return err
+	}
+	r.logger.Infof("Outbox message %d (%s) for task %s sent to Kafka", msg.ID, msg.EventType, msg.Key)
+
+	// A sharded task is queued once, with the message of its first shard.
+	if taskID, shard := models.ParseMessageKey(msg.Key); msg.EventType == models.EventTaskCreated && shard <= 0 {
+		if err := r.tasks.UpdateTaskStatus(ctx, models.StatusEvent{TaskID: taskID, Status: models.StatusQueued}); err != nil {
+			// The worker may already have picked the task up and moved it past queued.
+			r.logger.Warnf("Failed to mark task %s as queued: %v", taskID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"task-service/internal/models"
+	"task-service/pkg/broker/kafka"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeOutboxRepository — фейковый outbox, передающий сообщения в publish и запоминающий результат.
+type fakeOutboxRepository struct {
+	messages []models.OutboxMessage
+	failed   []int64
+}
+
+func (f *fakeOutboxRepository) ProcessPending(ctx context.Context, limit int, publish func(ctx context.Context, msg models.OutboxMessage) error) (int, error) {
+	published := 0
+	for _, msg := range f.messages {
+		if err := publish(ctx, msg); err != nil {
+			f.failed = append(f.failed, msg.ID)
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
+
+// recordingTaskService — TaskService, запоминающий смены статуса.
+type recordingTaskService struct {
+	TaskService
+	events []models.StatusEvent
+}
+
+func (r *recordingTaskService) UpdateTaskStatus(ctx context.Context, event models.StatusEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+// TestOutboxRelay_PublishesAndMarksQueued проверяет отправку сообщений и перевод задачи в queued.
+func TestOutboxRelay_PublishesAndMarksQueued(t *testing.T) {
+	var sent []string
+	producer := &fakeKafkaProducer{
+		produceFunc: func(ctx context.Context, key, value []byte) error {
+			sent = append(sent, string(key)+"="+string(value))
+			return nil
+		},
+	}
+	repo := &fakeOutboxRepository{messages: []models.OutboxMessage{
+		{ID: 1, EventType: models.EventTaskCreated, Key: "task-1", Payload: []byte(`{"id":1}`)},
+		{ID: 2, EventType: models.EventTaskCreated, Key: "task-2", Payload: []byte(`{"id":2}`)},
+	}}
+	tasks := &recordingTaskService{}
+
+	relay := NewOutboxRelay(repo, map[string]kafka.KafkaProducer{models.EventTaskCreated: producer}, tasks, 0, 10, zap.NewNop().Sugar())
+
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []string{`task-1={"id":1}`, `task-2={"id":2}`}, sent)
+	require.Len(t, tasks.events, 2)
+	assert.Equal(t, models.StatusEvent{TaskID: "task-1", Status: models.StatusQueued}, tasks.events[0])
+}
+
+// TestOutboxRelay_Shards проверяет, что шардированная задача переводится в queued один раз,
+// а сообщение слияния её статус не меняет.
+func TestOutboxRelay_Shards(t *testing.T) {
+	producer := &fakeKafkaProducer{
+		produceFunc: func(ctx context.Context, key, value []byte) error {
+			return nil
+		},
+	}
+	repo := &fakeOutboxRepository{messages: []models.OutboxMessage{
+		{ID: 1, EventType: models.EventTaskCreated, Key: models.ShardKey("task-1", 0), Payload: []byte(`{}`)},
+		{ID: 2, EventType: models.EventTaskCreated, Key: models.ShardKey("task-1", 1), Payload: []byte(`{}`)},
+		{ID: 3, EventType: models.EventTaskMerge, Key: "task-1", Payload: []byte(`{}`)},
+	}}
+	tasks := &recordingTaskService{}
+	producers := map[string]kafka.KafkaProducer{models.EventTaskCreated: producer, models.EventTaskMerge: producer}
+
+	relay := NewOutboxRelay(repo, producers, tasks, 0, 10, zap.NewNop().Sugar())
+
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, published)
+	assert.Equal(t, []models.StatusEvent{{TaskID: "task-1", Status: models.StatusQueued}}, tasks.events)
+}
+
+// TestOutboxRelay_KafkaError проверяет, что при ошибке Kafka сообщение остаётся для повтора.
+func TestOutboxRelay_KafkaError(t *testing.T) {
+	producer := &fakeKafkaProducer{
+		produceFunc: func(ctx context.Context, key, value []byte) error {
+			return errors.New("circuit breaker is open")
+		},
+	}
+	repo := &fakeOutboxRepository{messages: []models.OutboxMessage{
+		{ID: 1, EventType: models.EventTaskCreated, Key: "task-1", Payload: []byte(`{}`)},
+	}}
+	tasks := &recordingTaskService{}
+
+	relay := NewOutboxRelay(repo, map[string]kafka.KafkaProducer{models.EventTaskCreated: producer}, tasks, 0, 10, zap.NewNop().Sugar())
+
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, published)
+	assert.Equal(t, []int64{1}, repo.failed)
+	assert.Empty(t, tasks.events)
+}
+
+// TestOutboxRelay_UnknownEventType проверяет отказ для сообщения без продюсера.
+func TestOutboxRelay_UnknownEventType(t *testing.T) {
+	repo := &fakeOutboxRepository{messages: []models.OutboxMessage{
+		{ID: 1, EventType: "task.unknown", Key: "task-1", Payload: []byte(`{}`)},
+	}}
+
+	relay := NewOutboxRelay(repo, map[string]kafka.KafkaProducer{}, &recordingTaskService{}, 0, 10, zap.NewNop().Sugar())
+
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, published)
+	assert.Equal(t, []int64{1}, repo.failed)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"task-service/internal/models"
+	"task-service/pkg/storage"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ResultService interface {
+	OpenResult(ctx context.Context, id int64) (*models.TaskResult, error)
+	ResultURL(ctx context.Context, id int64) (string, time.Time, error)
+	VerifyResultURL(id int64, expires int64, signature string) error
+}
+
+type resultService struct {
+	tasks  TaskService
+	store  storage.ArtifactStore
+	secret []byte
+	ttl    time.Duration
+	logger *zap.SugaredLogger
+}
+
+func NewResultService(
+	tasks TaskService,
+	store storage.ArtifactStore,
+	secret []byte,
+	ttl time.Duration,
+	logger *zap.SugaredLogger,
+) ResultService {
+	return &resultService{
+		tasks:  tasks,
+		store:  store,
+		secret: secret,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+func (r *resultService) OpenResult(ctx context.Context, id int64) (*models.TaskResult, error) {
+	task, err := r.readyTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	body, size, err := r.store.Open(ctx, task.ResultKey)
+	if err != nil {
+		r.logger.Errorf("Failed to open result of task %d: %v", id, err)
+		return nil, err
+	}
+
+	return &models.TaskResult{
+		Body:        body,
+		Size:        size,
+		ContentType: task.ResultContentType(),
+		FileName:    task.ResultFileName(),
+	}, nil
+}
+
+// ResultURL returns a short-lived download URL for the task result.
+// Stores that can presign (S3) hand out a direct URL; otherwise the URL points
+// at task-service's own download endpoint and carries an HMAC signature.
+func (r *resultService) ResultURL(ctx context.Context, id int64) (string, time.Time, error) {
+	task, err := r.readyTask(ctx, id)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(r.ttl)
+	if presigner, ok := r.store.(storage.Presigner); ok {
+		url, err := presigner.PresignGet(ctx, task.ResultKey, task.ResultFileName(), r.ttl)
+		if err != nil {
+			r.logger.Errorf("Failed to presign result of task %d: %v", id, err)
+			return "", time.Time{}, err
+		}
+		return url, expiresAt, nil
+	}
+
+	expires := expiresAt.Unix()
+	url := fmt.Sprintf("/api/v2/tasks/%d/result/download?expires=%d&signature=%s", id, expires, r.sign(id, expires))
+	return url, expiresAt, nil
+}
+
+func (r *resultService) VerifyResultURL(id int64, expires int64, signature string) error {
+	if time.Now().Unix() > expires {
+		return models.ErrInvalidSignature
+	}
+
+	expected := r.sign(id, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return models.ErrInvalidSignature
+	}
+	return nil
+}
+
+func (r *resultService) readyTask(ctx context.Context, id int64) (*models.Task, error) {
+	task, err := r.tasks.GetTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != models.StatusSucceeded || task.ResultKey == "" {
+		return nil, models.ErrResultNotReady
+	}
+	return task, nil
+}
+
+func (r *resultService) sign(id int64, expires int64) string {
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write([]byte(strconv.FormatInt(id, 10) + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+	"task-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeTaskService — фейковая реализация TaskService для resultService.
+type fakeTaskService struct {
+	TaskService
+	task *models.Task
+	err  error
+}
+
+func (f *fakeTaskService) GetTaskByID(ctx context.Context, id int64) (*models.Task, error) {
+	return f.task, f.err
+}
+
+// fakeArtifactStore — фейковое хранилище результатов.
+type fakeArtifactStore struct {
+	data map[string]string
+}
+
+func (f *fakeArtifactStore) Open(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	return io.NopCloser(strings.NewReader(f.data[key])), int64(len(f.data[key])), nil
+}
+
+func newTestResultService(task *models.Task) *resultService {
+	return &resultService{
+		tasks:  &fakeTaskService{task: task},
+		store:  &fakeArtifactStore{data: map[string]string{"results/task-123.csv": "id\r\n1\r\n"}},
+		secret: []byte("secret"),
+		ttl:    time.Minute,
+		logger: zap.NewNop().Sugar(),
+	}
+}
+
+// TestOpenResult_Success проверяет получение готового результата.
+func TestOpenResult_Success(t *testing.T) {
+	svc := newTestResultService(&models.Task{
+		ID:        1,
+		TaskID:    "task-123",
+		Format:    "csv",
+		Status:    models.StatusSucceeded,
+		ResultKey: "results/task-123.csv",
+	})
+
+	result, err := svc.OpenResult(context.Background(), 1)
+	require.NoError(t, err)
+	defer result.Body.Close()
+
+	body, _ := io.ReadAll(result.Body)
+	assert.Equal(t, "id\r\n1\r\n", string(body))
+	assert.Equal(t, int64(7), result.Size)
+	assert.Equal(t, "text/csv; charset=utf-8", result.ContentType)
+	assert.Equal(t, "task-123.csv", result.FileName)
+}
+
+// TestOpenResult_NotReady проверяет, что незавершённая задача не отдаёт результат.
+func TestOpenResult_NotReady(t *testing.T) {
+	svc := newTestResultService(&models.Task{ID: 1, TaskID: "task-123", Status: models.StatusRunning})
+
+	_, err := svc.OpenResult(context.Background(), 1)
+	assert.ErrorIs(t, err, models.ErrResultNotReady)
+}
+
+// TestResultURL_SignedRoundTrip проверяет подпись и проверку ссылки на скачивание.
+func TestResultURL_SignedRoundTrip(t *testing.T) {
+	svc := newTestResultService(&models.Task{
+		ID:        1,
+		TaskID:    "task-123",
+		Format:    "csv",
+		Status:    models.StatusSucceeded,
+		ResultKey: "results/task-123.csv",
+	})
+
+	url, expiresAt, err := svc.ResultURL(context.Background(), 1)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(url, "/api/v2/tasks/1/result/download?expires="))
+
+	signature := url[strings.Index(url, "signature=")+len("signature="):]
+	assert.NoError(t, svc.VerifyResultURL(1, expiresAt.Unix(), signature))
+	assert.ErrorIs(t, svc.VerifyResultURL(2, expiresAt.Unix(), signature), models.ErrInvalidSignature)
+	assert.ErrorIs(t, svc.VerifyResultURL(1, expiresAt.Unix()+1, signature), models.ErrInvalidSignature)
+}
+
+// TestVerifyResultURL_Expired проверяет отказ для просроченной ссылки.
+func TestVerifyResultURL_Expired(t *testing.T) {
+	svc := newTestResultService(nil)
+
+	expires := time.Now().Add(-time.Second).Unix()
+	err := svc.VerifyResultURL(1, expires, svc.sign(1, expires))
+	assert.ErrorIs(t, err, models.ErrInvalidSignature)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"task-service/internal/models"
+	"task-service/internal/repository"
+	"task-service/pkg/cron"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultRunsLimit is how many runs of a schedule are listed when the caller sets no limit.
+const defaultRunsLimit = 50
+
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, userID string, req models.ScheduleRequest) (*models.Schedule, error)
+	GetSchedule(ctx context.Context, id int64) (*models.Schedule, error)
+	ListSchedules(ctx context.Context, userID string) ([]models.Schedule, error)
+	UpdateSchedule(ctx context.Context, id int64, req models.ScheduleRequest) (*models.Schedule, error)
+	DeleteSchedule(ctx context.Context, id int64) error
+	ListRuns(ctx context.Context, id int64, limit int) ([]models.ScheduleRun, error)
+}
+
+type scheduleService struct {
+	repo   repository.ScheduleRepository
+	logger *zap.SugaredLogger
+}
+
+func NewScheduleService(repo repository.ScheduleRepository, logger *zap.SugaredLogger) ScheduleService {
+	return &scheduleService{repo: repo, logger: logger}
+}
+
+func (s *scheduleService) CreateSchedule(ctx context.Context, userID string, req models.ScheduleRequest) (*models.Schedule, error) {
+	now := time.Now().UTC()
+	schedule := models.Schedule{UserID: userID, CreatedAt: now}
+	if err := applyScheduleRequest(&schedule, req, now); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.CreateSchedule(ctx, schedule)
+	if err != nil {
+		s.logger.Errorf("Failed to create schedule: %v", err)
+		return nil, err
+	}
+
+	schedule.ID = id
+	s.logger.Infof("Schedule %d created, next run at %v", id, schedule.NextRunAt)
+	return &schedule, nil
+}
+
+func (s *scheduleService) GetSchedule(ctx context.Context, id int64) (*models.Schedule, error) {
+	return s.repo.GetSchedule(ctx, id)
+}
+
+func (s *scheduleService) ListSchedules(ctx context.Context, userID string) ([]models.Schedule, error) {
+	return s.repo.ListSchedules(ctx, userID)
+}
+
+// UpdateSchedule replaces the definition of a schedule; its next run is computed anew.
+func (s *scheduleService) UpdateSchedule(ctx context.Context, id int64, req models.ScheduleRequest) (*models.Schedule, error) {
+	schedule, err := s.repo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyScheduleRequest(schedule, req, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateSchedule(ctx, *schedule); err != nil {
+		s.logger.Errorf("Failed to update schedule %d: %v", id, err)
+		return nil, err
+	}
+
+	s.logger.Infof("Schedule %d updated, next run at %v", id, schedule.NextRunAt)
+	return schedule, nil
+}
+
+func (s *scheduleService) DeleteSchedule(ctx context.Context, id int64) error {
+	if err := s.repo.DeleteSchedule(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Infof("Schedule %d deleted", id)
+	return nil
+}
+
+func (s *scheduleService) ListRuns(ctx context.Context, id int64, limit int) ([]models.ScheduleRun, error) {
+	if _, err := s.repo.GetSchedule(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultRunsLimit
+	}
+	return s.repo.ListRuns(ctx, id, limit)
+}
+
+// applyScheduleRequest copies req into schedule and computes its next run after now.
+func applyScheduleRequest(schedule *models.Schedule, req models.ScheduleRequest, now time.Time) error {
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+
+	schedule.Name = req.Name
+	schedule.Cron = req.Cron
+	schedule.Timezone = timezone
+	schedule.Task = req.Task
+	schedule.Enabled = enabled
+	schedule.UpdatedAt = now
+	schedule.NextRunAt = nil
+
+	next, err := nextRun(*schedule, now)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		schedule.NextRunAt = next
+	}
+	return nil
+}
+
+// nextRun returns the first run of a schedule after now, or nil if its cron expression
+// never fires again.
+func nextRun(schedule models.Schedule, now time.Time) (*time.Time, error) {
+	expr, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidSchedule, err)
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", models.ErrInvalidSchedule, schedule.Timezone)
+	}
+
+	next := expr.Next(now.In(loc))
+	if next.IsZero() {
+		return nil, nil
+	}
+	next = next.UTC()
+	return &next, nil
+}
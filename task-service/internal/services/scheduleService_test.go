@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"task-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func scheduleRequest(expr, timezone string) models.ScheduleRequest {
+	return models.ScheduleRequest{
+		Name:     "nightly",
+		Cron:     expr,
+		Timezone: timezone,
+		Task:     models.CreateTaskRequest{Type: "json", TemplateID: "tpl", Amount: 10, Format: "csv"},
+	}
+}
+
+// TestScheduleService_Create проверяет значения по умолчанию и расчёт следующего запуска.
+func TestScheduleService_Create(t *testing.T) {
+	service := NewScheduleService(newFakeScheduleRepository(), zap.NewNop().Sugar())
+
+	schedule, err := service.CreateSchedule(context.Background(), "user-1", scheduleRequest("0 3 * * *", ""))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), schedule.ID)
+	assert.Equal(t, "UTC", schedule.Timezone)
+	assert.True(t, schedule.Enabled)
+	require.NotNil(t, schedule.NextRunAt)
+	assert.Equal(t, 3, schedule.NextRunAt.Hour())
+	assert.True(t, schedule.NextRunAt.After(time.Now()))
+}
+
+// TestScheduleService_CreateTimezone проверяет запуск по местному времени расписания.
+func TestScheduleService_CreateTimezone(t *testing.T) {
+	service := NewScheduleService(newFakeScheduleRepository(), zap.NewNop().Sugar())
+
+	schedule, err := service.CreateSchedule(context.Background(), "user-1", scheduleRequest("0 3 * * *", "Asia/Tokyo"))
+	require.NoError(t, err)
+	require.NotNil(t, schedule.NextRunAt)
+	assert.Equal(t, 18, schedule.NextRunAt.Hour()) // 03:00 JST = 18:00 UTC
+}
+
+// TestScheduleService_Invalid проверяет отказ для неверного выражения cron.
+func TestScheduleService_Invalid(t *testing.T) {
+	service := NewScheduleService(newFakeScheduleRepository(), zap.NewNop().Sugar())
+
+	_, err := service.CreateSchedule(context.Background(), "user-1", scheduleRequest("0 25 * * *", ""))
+	assert.ErrorIs(t, err, models.ErrInvalidSchedule)
+}
+
+// TestScheduleService_UpdateDisable проверяет, что у выключенного расписания нет следующего запуска.
+func TestScheduleService_UpdateDisable(t *testing.T) {
+	repo := newFakeScheduleRepository()
+	service := NewScheduleService(repo, zap.NewNop().Sugar())
+
+	created, err := service.CreateSchedule(context.Background(), "user-1", scheduleRequest("@hourly", ""))
+	require.NoError(t, err)
+
+	req := scheduleRequest("@hourly", "")
+	disabled := false
+	req.Enabled = &disabled
+	updated, err := service.UpdateSchedule(context.Background(), created.ID, req)
+	require.NoError(t, err)
+	assert.False(t, updated.Enabled)
+	assert.Nil(t, updated.NextRunAt)
+	assert.Equal(t, "user-1", repo.schedules[created.ID].UserID)
+
+	_, err = service.UpdateSchedule(context.Background(), 99, req)
+	assert.ErrorIs(t, err, models.ErrScheduleNotFound)
+}
+
+// TestScheduleService_ListRuns проверяет историю запусков и ошибку для неизвестного расписания.
+func TestScheduleService_ListRuns(t *testing.T) {
+	repo := newFakeScheduleRepository(dueSchedule(1, "* * * * *"))
+	repo.runs = []models.ScheduleRun{{ID: 1, ScheduleID: 1, Status: models.RunCreated}, {ID: 2, ScheduleID: 2}}
+	service := NewScheduleService(repo, zap.NewNop().Sugar())
+
+	runs, err := service.ListRuns(context.Background(), 1, 0)
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+
+	_, err = service.ListRuns(context.Background(), 2, 0)
+	assert.ErrorIs(t, err, models.ErrScheduleNotFound)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"task-service/internal/models"
+	"task-service/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// schedulerLease is the name of the lease held by the task-service replica that fires schedules.
+const schedulerLease = "scheduler"
+
+// Scheduler creates the tasks of due schedules. Every replica runs one, but only the
+// replica holding the scheduler lease fires schedules; another one takes over when the
+// lease of the leader expires.
+type Scheduler struct {
+	repo      repository.ScheduleRepository
+	tasks     TaskService
+	apiKey    string
+	holder    string
+	interval  time.Duration
+	leaseTTL  time.Duration
+	batchSize int
+	logger    *zap.SugaredLogger
+}
+
+// NewScheduler creates a scheduler; holder identifies this replica in the lease. A
+// scheduled task is created without a user request to take a token from, so its
+// template is fetched from template-service with apiKey, an API key of a service user
+// granted the templates:read scope. The key is shared by the schedules of all users, so
+// a scheduled task may only use a template of the owner of its schedule.
+func NewScheduler(
+	repo repository.ScheduleRepository,
+	tasks TaskService,
+	apiKey string,
+	holder string,
+	interval time.Duration,
+	leaseTTL time.Duration,
+	batchSize int,
+	logger *zap.SugaredLogger,
+) *Scheduler {
+	return &Scheduler{
+		repo:      repo,
+		tasks:     tasks,
+		apiKey:    apiKey,
+		holder:    holder,
+		interval:  interval,
+		leaseTTL:  leaseTTL,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Run fires due schedules until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.logger.Infof("Scheduler %s started, polling every %v", s.holder, s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Scheduler stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.RunOnce(ctx); err != nil {
+				s.logger.Errorf("Scheduler iteration failed: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce fires the schedules that are due if this replica holds the lease, and returns
+// how many tasks it created.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	leader, err := s.repo.AcquireLease(ctx, schedulerLease, s.holder, s.leaseTTL)
+	if err != nil || !leader {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	due, err := s.repo.DueSchedules(ctx, now, s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, schedule := range due {
+		if s.fire(ctx, schedule, now) {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// fire creates the task of a due schedule and moves the schedule to its next run. Runs
+// missed while no replica was leader are not made up: a schedule fires once and
+// continues with its first run after now.
+func (s *Scheduler) fire(ctx context.Context, schedule models.Schedule, now time.Time) bool {
+	next, nextErr := nextRun(schedule, now)
+	runID, claimed, err := s.repo.ClaimRun(ctx, schedule, next)
+	if err != nil || !claimed {
+		return false
+	}
+	if nextErr != nil {
+		s.logger.Errorf("Schedule %d stopped: %v", schedule.ID, nextErr)
+		s.finish(ctx, schedule, runID, nil, nextErr.Error())
+		return false
+	}
+
+	task := schedule.Task.NewTask(schedule.UserID)
+	id, err := s.tasks.CreateNewTask(models.WithCaller(ctx, s.caller(schedule)), task)
+	if err != nil {
+		s.logger.Errorf("Failed to create task of schedule %d: %v", schedule.ID, err)
+		s.finish(ctx, schedule, runID, nil, err.Error())
+		return false
+	}
+
+	s.logger.Infof("Schedule %d created task %d (%s), next run at %v", schedule.ID, id, task.TaskID, next)
+	s.finish(ctx, schedule, runID, &id, "")
+	return true
+}
+
+// caller is the caller a schedule creates its task as: the owner of the schedule,
+// on whose behalf the scheduler authenticates to template-service with its API key.
+func (s *Scheduler) caller(schedule models.Schedule) models.Caller {
+	return models.Caller{
+		UserID:   schedule.UserID,
+		Token:    s.apiKey,
+		APIKey:   true,
+		Scopes:   []string{models.ScopeTemplatesRead},
+		OnBehalf: true,
+	}
+}
+
+func (s *Scheduler) finish(ctx context.Context, schedule models.Schedule, runID int64, taskID *int64, runErr string) {
+	if err := s.repo.FinishRun(ctx, runID, taskID, runErr); err != nil {
+		s.logger.Warnf("Failed to record run %d of schedule %d: %v", runID, schedule.ID, err)
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"task-service/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeScheduleRepository — расписания в памяти с арендой и уникальностью запусков, как в Postgres.
+type fakeScheduleRepository struct {
+	schedules map[int64]*models.Schedule
+	runs      []models.ScheduleRun
+	claimed   map[string]bool
+	holder    string
+	leaseErr  error
+}
+
+func newFakeScheduleRepository(schedules ...models.Schedule) *fakeScheduleRepository {
+	f := &fakeScheduleRepository{schedules: map[int64]*models.Schedule{}, claimed: map[string]bool{}}
+	for i := range schedules {
+		f.schedules[schedules[i].ID] = &schedules[i]
+	}
+	return f
+}
+
+func (f *fakeScheduleRepository) CreateSchedule(ctx context.Context, schedule models.Schedule) (int64, error) {
+	schedule.ID = int64(len(f.schedules) + 1)
+	f.schedules[schedule.ID] = &schedule
+	return schedule.ID, nil
+}
+
+func (f *fakeScheduleRepository) GetSchedule(ctx context.Context, id int64) (*models.Schedule, error) {
+	s, ok := f.schedules[id]
+	if !ok {
+		return nil, models.ErrScheduleNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (f *fakeScheduleRepository) ListSchedules(ctx context.Context, userID string) ([]models.Schedule, error) {
+	var out []models.Schedule
+	for _, s := range f.schedules {
+		if s.UserID == userID {
+			out = append(out, *s)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeScheduleRepository) UpdateSchedule(ctx context.Context, schedule models.Schedule) error {
+	if _, ok := f.schedules[schedule.ID]; !ok {
+		return models.ErrScheduleNotFound
+	}
+	f.schedules[schedule.ID] = &schedule
+	return nil
+}
+
+func (f *fakeScheduleRepository) DeleteSchedule(ctx context.Context, id int64) error {
+	if _, ok := f.schedules[id]; !ok {
+		return models.ErrScheduleNotFound
+	}
+	delete(f.schedules, id)
+	return nil
+}
+
+func (f *fakeScheduleRepository) ListRuns(ctx context.Context, scheduleID int64, limit int) ([]models.ScheduleRun, error) {
+	var out []models.ScheduleRun
+	for _, run := range f.runs {
+		if run.ScheduleID == scheduleID && len(out) < limit {
+			out = append(out, run)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeScheduleRepository) DueSchedules(ctx context.Context, now time.Time, limit int) ([]models.Schedule, error) {
+	var out []models.Schedule
+	for _, s := range f.schedules {
+		if s.Enabled && s.NextRunAt != nil && !s.NextRunAt.After(now) {
+			out = append(out, *s)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeScheduleRepository) ClaimRun(ctx context.Context, schedule models.Schedule, next *time.Time) (int64, bool, error) {
+	stored := f.schedules[schedule.ID]
+	if stored == nil || stored.NextRunAt == nil || !stored.NextRunAt.Equal(*schedule.NextRunAt) {
+		return 0, false, nil
+	}
+	key := schedule.NextRunAt.String()
+	if f.claimed[key] {
+		return 0, false, nil
+	}
+	f.claimed[key] = true
+
+	stored.LastRunAt, stored.NextRunAt = schedule.NextRunAt, next
+	f.runs = append(f.runs, models.ScheduleRun{ID: int64(len(f.runs) + 1), ScheduleID: schedule.ID, ScheduledAt: *schedule.NextRunAt, Status: models.RunPending})
+	return int64(len(f.runs)), true, nil
+}
+
+func (f *fakeScheduleRepository) FinishRun(ctx context.Context, runID int64, taskID *int64, runErr string) error {
+	run := &f.runs[runID-1]
+	run.TaskID, run.Error, run.Status = taskID, runErr, models.RunCreated
+	if runErr != "" {
+		run.Status = models.RunFailed
+	}
+	return nil
+}
+
+func (f *fakeScheduleRepository) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	if f.leaseErr != nil {
+		return false, f.leaseErr
+	}
+	if f.holder == "" {
+		f.holder = holder
+	}
+	return f.holder == holder, nil
+}
+
+// creatingTaskService — TaskService, запоминающий созданные задачи.
+type creatingTaskService struct {
+	TaskService
+	tasks []models.Task
+	err   error
+}
+
+func (c *creatingTaskService) CreateNewTask(ctx context.Context, task models.Task) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.tasks = append(c.tasks, task)
+	return int64(100 + len(c.tasks)), nil
+}
+
+func dueSchedule(id int64, expr string) models.Schedule {
+	due := time.Now().UTC().Add(-time.Minute).Truncate(time.Minute)
+	return models.Schedule{
+		ID:        id,
+		UserID:    "user-1",
+		Cron:      expr,
+		Timezone:  "Europe/Berlin",
+		Task:      models.CreateTaskRequest{Type: "json", TemplateID: "tpl", Amount: 10, Format: "csv"},
+		Enabled:   true,
+		NextRunAt: &due,
+	}
+}
+
+// TestScheduler_RunOnce проверяет создание задачи по наступившему расписанию и перенос следующего запуска.
+func TestScheduler_RunOnce(t *testing.T) {
+	repo := newFakeScheduleRepository(dueSchedule(1, "*/5 * * * *"))
+	tasks := &creatingTaskService{}
+	scheduler := NewScheduler(repo, tasks, "", "replica-a", 0, time.Minute, 10, zap.NewNop().Sugar())
+
+	created, err := scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	require.Len(t, tasks.tasks, 1)
+	assert.Equal(t, "user-1", tasks.tasks[0].UserID)
+	assert.Equal(t, "tpl", tasks.tasks[0].TemplateID)
+	assert.Equal(t, 10, tasks.tasks[0].Amount)
+
+	require.Len(t, repo.runs, 1)
+	assert.Equal(t, models.RunCreated, repo.runs[0].Status)
+	assert.Equal(t, int64(101), *repo.runs[0].TaskID)
+
+	next := repo.schedules[1].NextRunAt
+	require.NotNil(t, next)
+	assert.True(t, next.After(time.Now()))
+	assert.Zero(t, next.Minute()%5)
+
+	// Расписание больше не наступило — повторный проход ничего не создаёт.
+	created, err = scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, created)
+	assert.Len(t, tasks.tasks, 1)
+}
+
+// TestScheduler_NotLeader проверяет, что экземпляр без аренды расписания не запускает.
+func TestScheduler_NotLeader(t *testing.T) {
+	repo := newFakeScheduleRepository(dueSchedule(1, "* * * * *"))
+	repo.holder = "replica-a"
+	tasks := &creatingTaskService{}
+	scheduler := NewScheduler(repo, tasks, "", "replica-b", 0, time.Minute, 10, zap.NewNop().Sugar())
+
+	created, err := scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, created)
+	assert.Empty(t, tasks.tasks)
+	assert.Empty(t, repo.runs)
+}
+
+// TestScheduler_AlreadyClaimed проверяет, что запуск, захваченный другим экземпляром, не повторяется.
+func TestScheduler_AlreadyClaimed(t *testing.T) {
+	schedule := dueSchedule(1, "* * * * *")
+	repo := newFakeScheduleRepository(schedule)
+	repo.claimed[schedule.NextRunAt.String()] = true
+	tasks := &creatingTaskService{}
+	scheduler := NewScheduler(repo, tasks, "", "replica-a", 0, time.Minute, 10, zap.NewNop().Sugar())
+
+	created, err := scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, created)
+	assert.Empty(t, tasks.tasks)
+}
+
+// TestScheduler_TaskFailed проверяет запись ошибки создания задачи в историю запусков.
+func TestScheduler_TaskFailed(t *testing.T) {
+	repo := newFakeScheduleRepository(dueSchedule(1, "0 * * * *"))
+	tasks := &creatingTaskService{err: errors.New("template not found")}
+	scheduler := NewScheduler(repo, tasks, "", "replica-a", 0, time.Minute, 10, zap.NewNop().Sugar())
+
+	created, err := scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, created)
+
+	require.Len(t, repo.runs, 1)
+	assert.Equal(t, models.RunFailed, repo.runs[0].Status)
+	assert.Equal(t, "template not found", repo.runs[0].Error)
+	assert.Nil(t, repo.runs[0].TaskID)
+	assert.NotNil(t, repo.schedules[1].NextRunAt)
+}
+
+// TestScheduler_LeaseError проверяет возврат ошибки аренды.
+func TestScheduler_LeaseError(t *testing.T) {
+	repo := newFakeScheduleRepository()
+	repo.leaseErr = errors.New("db down")
+	scheduler := NewScheduler(repo, &creatingTaskService{}, "", "replica-a", 0, time.Minute, 10, zap.NewNop().Sugar())
+
+	_, err := scheduler.RunOnce(context.Background())
+	assert.EqualError(t, err, "db down")
+}
+
+// serverClient — HTTPClient, направляющий запросы к template-service на тестовый сервер.
+type serverClient struct {
+	server *httptest.Server
+}
+
+func (c serverClient) Do(req *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(c.server.URL)
+	req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+	return c.server.Client().Do(req)
+}
+
+// TestScheduler_FetchesTemplateWithAPIKey проверяет, что запуск расписания через настоящий
+// taskService получает шаблон у template-service, требующего аутентификацию.
+func TestScheduler_FetchesTemplateWithAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tsk_scheduler" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/templates/tpl":
+			w.Write([]byte(`{"user_id": "user-1", "version": 2, "content": {"name": "string"}}`))
+		case "/templates/other":
+			w.Write([]byte(`{"user_id": "user-2", "version": 1, "content": {"name": "string"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var created models.Task
+	repo := &fakeTaskRepository{
+		createNewTaskFunc: func(ctx context.Context, task models.Task) (int64, error) {
+			created = task
+			return 7, nil
+		},
+	}
+	tasks := NewTaskService(repo, &fakeRedisClient{}, zap.NewNop().Sugar(), serverClient{server}, models.ShardPolicy{}, nil)
+
+	schedules := newFakeScheduleRepository(dueSchedule(1, "* * * * *"))
+	scheduler := NewScheduler(schedules, tasks, "tsk_scheduler", "replica-a", 0, time.Minute, 10, zap.NewNop().Sugar())
+	count, err := scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.Len(t, schedules.runs, 1)
+	assert.Equal(t, models.RunCreated, schedules.runs[0].Status, schedules.runs[0].Error)
+	assert.Equal(t, "user-1", created.UserID)
+	assert.Equal(t, 2, created.TemplateVersion)
+
+	// Без ключа template-service отвечает 401, и запуск помечается неудачным.
+	schedules = newFakeScheduleRepository(dueSchedule(1, "* * * * *"))
+	scheduler = NewScheduler(schedules, tasks, "", "replica-a", 0, time.Minute, 10, zap.NewNop().Sugar())
+	_, err = scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, schedules.runs, 1)
+	assert.Equal(t, models.RunFailed, schedules.runs[0].Status)
+
+	// Ключ планировщика читает любые шаблоны, но задача расписания использует только
+	// шаблоны владельца расписания.
+	other := dueSchedule(1, "* * * * *")
+	other.Task.TemplateID = "other"
+	schedules = newFakeScheduleRepository(other)
+	scheduler = NewScheduler(schedules, tasks, "tsk_scheduler", "replica-a", 0, time.Minute, 10, zap.NewNop().Sugar())
+	_, err = scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, schedules.runs, 1)
+	assert.Equal(t, models.RunFailed, schedules.runs[0].Status)
+	assert.Contains(t, schedules.runs[0].Error, "belongs to another user")
+}
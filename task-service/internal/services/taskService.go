@@ -2,13 +2,14 @@ package services
 
 import (
 	"context"
+	"egress"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"task-service/internal/models"
 	"task-service/internal/repository"
-	"task-service/pkg/broker/kafka"
 	"time"
 
 	"go.uber.org/zap"
@@ -18,6 +19,7 @@ import (
 type RedisClient interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
+	Del(ctx context.Context, keys ...string) error
 	Close() error
 }
 
@@ -29,62 +31,82 @@ type TaskService interface {
 	CreateNewTask(ctx context.Context, task models.Task) (int64, error)
 	GetTaskByID(ctx context.Context, id int64) (*models.Task, error)
 	ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	UpdateTaskStatus(ctx context.Context, event models.StatusEvent) error
+	CancelTask(ctx context.Context, id int64) (*models.Task, error)
+	GetTaskReport(ctx context.Context, id int64) (*models.TaskReport, error)
 }
 
 type taskService struct {
 	repo           repository.TaskRepository
 	redis          RedisClient
-	kafka          kafka.KafkaProducer
 	logger         *zap.SugaredLogger
 	templateClient HTTPClient
+	sharding       models.ShardPolicy
+	resolver       egress.Resolver
 }
 
 func NewTaskService(
 	repo repository.TaskRepository,
 	redis RedisClient,
-	kafka kafka.KafkaProducer,
 	logger *zap.SugaredLogger,
 	templateClient HTTPClient,
+	sharding models.ShardPolicy,
+	resolver egress.Resolver,
 ) TaskService {
 	return &taskService{
 		repo:           repo,
 		redis:          redis,
-		kafka:          kafka,
 		logger:         logger,
 		templateClient: templateClient,
+		sharding:       sharding,
+		resolver:       resolver,
 	}
 }
 
 func (t *taskService) CreateNewTask(ctx context.Context, task models.Task) (int64, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("http://template-service:8082/template/%s", task.TemplateID), nil)
-	if err != nil {
-		t.logger.Errorf("Failed to create request to template-service: %v", err)
-		return 0, err
+	if task.Type == models.TaskTypeHTTP && task.Request != nil {
+		if err := t.checkRequestURL(ctx, task.Request.URL); err != nil {
+			t.logger.Warnf("Rejected http task of user %s: %v", task.UserID, err)
+			return 0, err
+		}
 	}
 
-	resp, err := t.templateClient.Do(req)
+	// Tasks are pinned to a template revision so that their dataset can be reproduced later.
+	path := "/templates/" + task.TemplateID
+	if task.TemplateVersion > 0 {
+		path = fmt.Sprintf("%s/versions/%d", path, task.TemplateVersion)
+	}
+	template, err := t.fetchTemplate(ctx, path)
 	if err != nil {
-		t.logger.Errorf("Failed to fetch template %s: %v", task.TemplateID, err)
 		return 0, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		t.logger.Errorf("Template %s not found, status: %d", task.TemplateID, resp.StatusCode)
-		return 0, fmt.Errorf("template not found")
-	}
-
-	var template struct {
-		ID      string                 `json:"id"`
-		Name    string                 `json:"name"`
-		Content map[string]interface{} `json:"content"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&template); err != nil {
-		t.logger.Errorf("Failed to decode template response: %v", err)
-		return 0, err
+	// A service acting for the user may read templates the user may not use, so its
+	// tasks only use templates of the user. Revisions carry no owner; the owner of
+	// the template they belong to is checked instead.
+	if caller, ok := models.CallerFromContext(ctx); ok && caller.OnBehalf {
+		owner := template.UserID
+		if task.TemplateVersion > 0 {
+			current, err := t.fetchTemplate(ctx, "/templates/"+task.TemplateID)
+			if err != nil {
+				return 0, err
+			}
+			owner = current.UserID
+		}
+		if owner != caller.UserID {
+			t.logger.Errorf("Template %s of user %q is not available to user %q", task.TemplateID, owner, caller.UserID)
+			return 0, fmt.Errorf("template %s belongs to another user: %w", task.TemplateID, models.ErrForbidden)
+		}
 	}
 
 	task.Template = template.Content
+	task.TemplateVersion = template.Version
+	// Related entities are generated together in one pass, so dataset tasks are never sharded,
+	// and neither are http tasks, whose concurrency and rate apply to the task as a whole.
+	// A regenerated task keeps the shards of the task it reproduces.
+	if _, ok := template.Content["entities"]; !ok && task.Type != models.TaskTypeHTTP && !task.ShardsPinned {
+		task.ShardCount = t.sharding.ShardCount(task.Amount)
+	}
 
 	id, err := t.repo.CreateNewTask(ctx, task)
 	if err != nil {
@@ -92,9 +114,11 @@ func (t *taskService) CreateNewTask(ctx context.Context, task models.Task) (int6
 		return 0, err
 	}
 
-	t.logger.Infof("Task created with ID: %d", id)
+	t.logger.Infof("Task created with ID: %d, it will be sent to Kafka by the outbox relay", id)
+	t.invalidateTaskLists(ctx, task.UserID)
 
 	task.ID = id
+	task.Status = models.StatusPending
 	taskData, err := json.Marshal(task)
 	if err != nil {
 		t.logger.Errorf("Failed to marshal task %d: %v", id, err)
@@ -105,14 +129,64 @@ func (t *taskService) CreateNewTask(ctx context.Context, task models.Task) (int6
 		t.logger.Warnf("Failed to cache task %d: %v", id, err)
 	}
 
-	err = t.kafka.Produce(ctx, []byte(task.TaskID), taskData)
+	return id, nil
+}
+
+// checkRequestURL rejects http tasks whose target resolves to a loopback, private or
+// link-local address. A host filled in from record fields is only known to the worker,
+// which checks every address it connects to, as it does for the hosts checked here.
+func (t *taskService) checkRequestURL(ctx context.Context, rawURL string) error {
+	_, host, _ := strings.Cut(rawURL, "://")
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	if strings.Contains(host, "{{") {
+		return nil
+	}
+	if err := egress.CheckURL(ctx, t.resolver, rawURL); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrForbiddenURL, err)
+	}
+	return nil
+}
+
+// fetchedTemplate is the part of a template-service template or revision a task uses.
+// UserID is empty for revisions.
+type fetchedTemplate struct {
+	UserID  string                 `json:"user_id"`
+	Version int                    `json:"version"`
+	Content map[string]interface{} `json:"content"`
+}
+
+// fetchTemplate reads a template or a revision of one from template-service.
+func (t *taskService) fetchTemplate(ctx context.Context, path string) (*fetchedTemplate, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://template-service:8082"+path, nil)
 	if err != nil {
-		t.logger.Errorf("Failed to send task %d to Kafka: %v", id, err)
-		return id, nil
+		t.logger.Errorf("Failed to create request to template-service: %v", err)
+		return nil, err
+	}
+	// template-service authenticates its callers too, so the caller's token is passed on.
+	if caller, ok := models.CallerFromContext(ctx); ok && caller.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+caller.Token)
 	}
 
-	t.logger.Infof("Task %d sent to Kafka", id)
-	return id, nil
+	resp, err := t.templateClient.Do(req)
+	if err != nil {
+		t.logger.Errorf("Failed to fetch template %s: %v", path, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.logger.Errorf("Template %s not found, status: %d", path, resp.StatusCode)
+		return nil, fmt.Errorf("template not found")
+	}
+
+	var template fetchedTemplate
+	if err := json.NewDecoder(resp.Body).Decode(&template); err != nil {
+		t.logger.Errorf("Failed to decode template response: %v", err)
+		return nil, err
+	}
+	return &template, nil
 }
 
 func (t *taskService) GetTaskByID(ctx context.Context, id int64) (*models.Task, error) {
@@ -147,9 +221,9 @@ func (t *taskService) GetTaskByID(ctx context.Context, id int64) (*models.Task,
 
 func (t *taskService) ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
 	// First try to get cached results if the filter is simple
+	var cacheKey string
 	if filter.IsCacheable() {
-		cacheKey := fmt.Sprintf("tasks:%s:%s:%s:%d:%d",
-			filter.UserID, filter.Type, filter.Status, filter.Page, filter.Limit)
+		cacheKey = t.listCacheKey(ctx, filter)
 
 		cachedData, err := t.redis.Get(ctx, cacheKey)
 		if err == nil {
@@ -168,11 +242,9 @@ func (t *taskService) ListTasks(ctx context.Context, filter models.TaskFilter) (
 		return nil, err
 	}
 
-	// Cache results if appropriate
-	if filter.IsCacheable() && len(tasks) > 0 {
-		cacheKey := fmt.Sprintf("tasks:%s:%s:%s:%d:%d",
-			filter.UserID, filter.Type, filter.Status, filter.Page, filter.Limit)
-
+	// Cache results if appropriate. The key read before the query is reused, so a list
+	// read before a status change is never stored under the generation that follows it.
+	if cacheKey != "" && len(tasks) > 0 {
 		tasksData, err := json.Marshal(tasks)
 		if err == nil {
 			if err := t.redis.Set(ctx, cacheKey, tasksData, 5*time.Minute); err != nil {
@@ -184,3 +256,98 @@ func (t *taskService) ListTasks(ctx context.Context, filter models.TaskFilter) (
 	t.logger.Infof("Retrieved %d tasks", len(tasks))
 	return tasks, nil
 }
+
+// listCacheKey returns the cache key of a task list. The key carries the list generation
+// of the filtered user (or of all users when the filter has none), so bumping the
+// generation in invalidateTaskLists orphans every cached page of that user at once.
+func (t *taskService) listCacheKey(ctx context.Context, filter models.TaskFilter) string {
+	generation, err := t.redis.Get(ctx, listGenerationKey(filter.UserID))
+	if err != nil {
+		generation = "0"
+	}
+	return fmt.Sprintf("tasks:%s:%s:%s:%s:%d:%d",
+		filter.UserID, generation, filter.Type, filter.Status, filter.Page, filter.Limit)
+}
+
+func listGenerationKey(userID string) string {
+	return "tasks:gen:" + userID
+}
+
+// invalidateTaskLists bumps the list generation of the user and the one of the
+// all-users listings. The generation outlives the cached lists, so a generation that
+// expires never brings back a list cached under it.
+func (t *taskService) invalidateTaskLists(ctx context.Context, userID string) {
+	generation := time.Now().UnixNano()
+	for _, key := range []string{listGenerationKey(userID), listGenerationKey("")} {
+		if err := t.redis.Set(ctx, key, generation, time.Hour); err != nil {
+			t.logger.Warnf("Failed to invalidate cached task lists of %q: %v", userID, err)
+		}
+	}
+}
+
+func (t *taskService) UpdateTaskStatus(ctx context.Context, event models.StatusEvent) error {
+	if !models.IsValidStatus(event.Status) {
+		return fmt.Errorf("unknown status %q: %w", event.Status, models.ErrInvalidStatusTransition)
+	}
+
+	var id int64
+	var err error
+	if event.Shard != nil {
+		id, err = t.repo.UpdateShardStatus(ctx, event)
+	} else {
+		id, err = t.repo.UpdateTaskStatus(ctx, event)
+	}
+	if err != nil {
+		t.logger.Errorf("Failed to update status of task %s: %v", event.TaskID, err)
+		return err
+	}
+
+	if err := t.redis.Del(ctx, "task:"+strconv.FormatInt(id, 10)); err != nil {
+		t.logger.Warnf("Failed to invalidate cached task %d: %v", id, err)
+	}
+	// The status event carries no owner, so the task is read back (and cached again)
+	// to find the user whose task lists are stale now.
+	if task, err := t.GetTaskByID(ctx, id); err == nil {
+		t.invalidateTaskLists(ctx, task.UserID)
+	}
+
+	t.logger.Infof("Task %d status updated to %s (%d records)", id, event.Status, event.RecordsGenerated)
+	return nil
+}
+
+// CancelTask cancels an unfinished task; the worker is told to stop through the outbox.
+func (t *taskService) CancelTask(ctx context.Context, id int64) (*models.Task, error) {
+	taskID, err := t.repo.CancelTask(ctx, id)
+	if err != nil {
+		t.logger.Errorf("Failed to cancel task %d: %v", id, err)
+		return nil, err
+	}
+
+	if err := t.redis.Del(ctx, "task:"+strconv.FormatInt(id, 10)); err != nil {
+		t.logger.Warnf("Failed to invalidate cached task %d: %v", id, err)
+	}
+
+	t.logger.Infof("Task %d (%s) cancelled", id, taskID)
+	task, err := t.GetTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.invalidateTaskLists(ctx, task.UserID)
+	return task, nil
+}
+
+// GetTaskReport returns the request report of an http task. The report is not cached,
+// since it changes with every progress event of a running task.
+func (t *taskService) GetTaskReport(ctx context.Context, id int64) (*models.TaskReport, error) {
+	report, err := t.repo.GetTaskReport(ctx, id)
+	if err != nil {
+		t.logger.Errorf("Failed to get report of task %d: %v", id, err)
+		return nil, err
+	}
+	if report.Report == nil {
+		return nil, models.ErrReportNotReady
+	}
+
+	report.SuccessRate = report.Report.SuccessRate()
+	return report, nil
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/netip"
 	"task-service/internal/models"
 	"testing"
 	"time"
@@ -20,6 +22,11 @@ import (
 type fakeTaskRepository struct {
 	createNewTaskFunc func(ctx context.Context, task models.Task) (int64, error)
 	getTaskByIDFunc   func(ctx context.Context, id int64) (*models.Task, error)
+	listTasksFunc     func(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	updateStatusFunc  func(ctx context.Context, event models.StatusEvent) (int64, error)
+	updateShardFunc   func(ctx context.Context, event models.StatusEvent) (int64, error)
+	cancelTaskFunc    func(ctx context.Context, id int64) (string, error)
+	getReportFunc     func(ctx context.Context, id int64) (*models.TaskReport, error)
 }
 
 func (f *fakeTaskRepository) CreateNewTask(ctx context.Context, task models.Task) (int64, error) {
@@ -27,27 +34,105 @@ func (f *fakeTaskRepository) CreateNewTask(ctx context.Context, task models.Task
 }
 
 func (f *fakeTaskRepository) GetTaskByID(ctx context.Context, id int64) (*models.Task, error) {
+	if f.getTaskByIDFunc == nil {
+		return nil, models.ErrTaskNotFound
+	}
 	return f.getTaskByIDFunc(ctx, id)
 }
 
+func (f *fakeTaskRepository) ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	return f.listTasksFunc(ctx, filter)
+}
+
+func (f *fakeTaskRepository) UpdateTaskStatus(ctx context.Context, event models.StatusEvent) (int64, error) {
+	if f.updateStatusFunc == nil {
+		return 1, nil
+	}
+	return f.updateStatusFunc(ctx, event)
+}
+
+func (f *fakeTaskRepository) UpdateShardStatus(ctx context.Context, event models.StatusEvent) (int64, error) {
+	return f.updateShardFunc(ctx, event)
+}
+
+func (f *fakeTaskRepository) CancelTask(ctx context.Context, id int64) (string, error) {
+	return f.cancelTaskFunc(ctx, id)
+}
+
+func (f *fakeTaskRepository) GetTaskReport(ctx context.Context, id int64) (*models.TaskReport, error) {
+	return f.getReportFunc(ctx, id)
+}
+
 // fakeRedisClient — фейковая реализация RedisClient.
 type fakeRedisClient struct {
 	setFunc func(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	getFunc func(ctx context.Context, key string) (string, error)
+	delFunc func(ctx context.Context, keys ...string) error
 }
 
 func (f *fakeRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if f.setFunc == nil {
+		return nil
+	}
 	return f.setFunc(ctx, key, value, expiration)
 }
 
 func (f *fakeRedisClient) Get(ctx context.Context, key string) (string, error) {
+	if f.getFunc == nil {
+		return "", errors.New("cache miss")
+	}
 	return f.getFunc(ctx, key)
 }
 
+func (f *fakeRedisClient) Del(ctx context.Context, keys ...string) error {
+	if f.delFunc == nil {
+		return nil
+	}
+	return f.delFunc(ctx, keys...)
+}
+
 func (f *fakeRedisClient) Close() error {
 	return nil
 }
 
+// memoryRedisClient — RedisClient в памяти для тестов, которым важно содержимое кэша.
+type memoryRedisClient struct {
+	values map[string]string
+}
+
+func newMemoryRedisClient() *memoryRedisClient {
+	return &memoryRedisClient{values: map[string]string{}}
+}
+
+func (m *memoryRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	switch v := value.(type) {
+	case []byte:
+		m.values[key] = string(v)
+	default:
+		m.values[key] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+func (m *memoryRedisClient) Get(ctx context.Context, key string) (string, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return "", errors.New("cache miss")
+	}
+	return value, nil
+}
+
+func (m *memoryRedisClient) Del(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(m.values, key)
+	}
+	return nil
+}
+
+func (m *memoryRedisClient) Close() error {
+	return nil
+}
+
 // fakeKafkaProducer — фейковая реализация KafkaProducer.
 type fakeKafkaProducer struct {
 	produceFunc func(ctx context.Context, key, value []byte) error
@@ -89,15 +174,6 @@ func TestCreateNewTask_Success(t *testing.T) {
 		},
 	}
 
-	kafkaProducer := &fakeKafkaProducer{
-		produceFunc: func(ctx context.Context, key, value []byte) error {
-			return nil
-		},
-		closeFunc: func() error {
-			return nil
-		},
-	}
-
 	templateResponse := `{"id":"template-456","name":"Test Template","content":{"name":"{{name}}","age":"{{age}}"}}`
 	templateClient := &fakeTemplateClient{
 		doFunc: func(req *http.Request) (*http.Response, error) {
@@ -112,7 +188,6 @@ func TestCreateNewTask_Success(t *testing.T) {
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
 		logger:         sugaredLogger,
 		templateClient: templateClient,
 	}
@@ -129,53 +204,248 @@ func TestCreateNewTask_Success(t *testing.T) {
 	assert.Equal(t, int64(1), id)
 }
 
-// TestCreateNewTask_TemplateClientError проверяет ошибку при запросе к template-service.
-func TestCreateNewTask_TemplateClientError(t *testing.T) {
-	logger, _ := zap.NewDevelopment()
-	sugaredLogger := logger.Sugar()
+// TestCreateNewTask_Sharded проверяет, что большая задача делится на шарды, а задача
+// со связанными сущностями — нет.
+func TestCreateNewTask_Sharded(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     int
+	}{
+		{"fields", `{"content":{"name":"{{name}}"}}`, 4},
+		{"entities", `{"content":{"entities":{"users":{"count":10,"fields":{"name":"{{name}}"}}}}}`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored models.Task
+			repo := &fakeTaskRepository{
+				createNewTaskFunc: func(ctx context.Context, task models.Task) (int64, error) {
+					stored = task
+					return 1, nil
+				},
+			}
+			redisClient := &fakeRedisClient{
+				setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+					return nil
+				},
+			}
+			templateClient := &fakeTemplateClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewReader([]byte(tt.template))),
+						Header:     make(http.Header),
+					}, nil
+				},
+			}
+			policy := models.ShardPolicy{Threshold: 1000, Size: 500, MaxShards: 8}
+			svc := NewTaskService(repo, redisClient, zap.NewNop().Sugar(), templateClient, policy, nil)
 
-	repo := &fakeTaskRepository{}
+			_, err := svc.CreateNewTask(context.Background(), models.Task{TaskID: "task-123", TemplateID: "template-456", Amount: 2000})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stored.ShardCount)
+		})
+	}
+}
+
+// TestCreateNewTask_RegeneratedKeepsShards проверяет, что повторная генерация делится на
+// те же шарды, что и исходная задача, даже если политика шардирования изменилась.
+func TestCreateNewTask_RegeneratedKeepsShards(t *testing.T) {
+	tests := []struct {
+		name   string
+		shards int
+		policy models.ShardPolicy
+	}{
+		{"smaller shards", 4, models.ShardPolicy{Threshold: 1000, Size: 250, MaxShards: 16}},
+		{"sharding disabled", 4, models.ShardPolicy{}},
+		{"sharding enabled", 0, models.ShardPolicy{Threshold: 1000, Size: 500, MaxShards: 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored models.Task
+			repo := &fakeTaskRepository{
+				createNewTaskFunc: func(ctx context.Context, task models.Task) (int64, error) {
+					stored = task
+					return 2, nil
+				},
+			}
+			templateClient := &fakeTemplateClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewReader([]byte(`{"version":1,"content":{"name":"{{name}}"}}`))),
+						Header:     make(http.Header),
+					}, nil
+				},
+			}
+			svc := NewTaskService(repo, &fakeRedisClient{}, zap.NewNop().Sugar(), templateClient, tt.policy, nil)
+
+			source := models.Task{TaskID: "task-123", UserID: "user-1", TemplateID: "7", TemplateVersion: 1, Amount: 2000, Seed: 42, ShardCount: tt.shards}
+			_, err := svc.CreateNewTask(context.Background(), source.Regenerate("user-1"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.shards, stored.ShardCount)
+			assert.Equal(t, source.Seed, stored.Seed)
+		})
+	}
+}
+
+// fakeResolver — резолвер с фиксированными адресами хостов.
+type fakeResolver map[string]string
+
+func (f fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	addr, ok := f[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+	return []netip.Addr{netip.MustParseAddr(addr)}, nil
+}
+
+// TestCreateNewTask_RequestURL проверяет, что http-задача с адресом во внутренней сети
+// отклоняется до обращения к template-service.
+func TestCreateNewTask_RequestURL(t *testing.T) {
+	resolver := fakeResolver{"api.example.com": "93.184.216.34", "db.internal": "10.0.0.5"}
+	tests := []struct {
+		url       string
+		forbidden bool
+	}{
+		{"https://api.example.com/users/{{id}}?q={{name}}", false},
+		{"https://{{host}}/users", false},
+		{"http://db.internal:5432/", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"http://localhost.localdomain/", true},
+		{"http://[::1]:8080/introspect", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			fetched := false
+			repo := &fakeTaskRepository{
+				createNewTaskFunc: func(ctx context.Context, task models.Task) (int64, error) {
+					return 1, nil
+				},
+			}
+			templateClient := &fakeTemplateClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					fetched = true
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewReader([]byte(`{"version":1,"content":{"id":"uuid"}}`))),
+						Header:     make(http.Header),
+					}, nil
+				},
+			}
+			svc := NewTaskService(repo, &fakeRedisClient{}, zap.NewNop().Sugar(), templateClient, models.ShardPolicy{}, resolver)
+
+			_, err := svc.CreateNewTask(context.Background(), models.Task{
+				TaskID:     "task-123",
+				Type:       models.TaskTypeHTTP,
+				TemplateID: "7",
+				Amount:     10,
+				Request:    &models.HTTPRequest{Method: "GET", URL: tt.url},
+			})
+			if tt.forbidden {
+				assert.ErrorIs(t, err, models.ErrForbiddenURL)
+				assert.False(t, fetched)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestCreateNewTask_PinnedTemplateVersion проверяет, что задача с указанной версией шаблона
+// запрашивает именно эту ревизию и сохраняет её номер.
+func TestCreateNewTask_PinnedTemplateVersion(t *testing.T) {
+	var saved models.Task
+	repo := &fakeTaskRepository{
+		createNewTaskFunc: func(ctx context.Context, task models.Task) (int64, error) {
+			saved = task
+			return 1, nil
+		},
+	}
 	redisClient := &fakeRedisClient{
 		setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 			return nil
 		},
 	}
-	kafkaProducer := &fakeKafkaProducer{}
+
+	var requestedPath string
 	templateClient := &fakeTemplateClient{
 		doFunc: func(req *http.Request) (*http.Response, error) {
-			return nil, errors.New("network error")
+			requestedPath = req.URL.Path
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"id":7,"version":2,"content":{"name":"{{name}}"}}`))),
+				Header:     make(http.Header),
+			}, nil
 		},
 	}
 
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
-		logger:         sugaredLogger,
+		logger:         zap.NewNop().Sugar(),
 		templateClient: templateClient,
 	}
 
-	ctx := context.Background()
-	task := models.Task{
-		TaskID:     "task-123",
-		TemplateID: "template-456",
-		Amount:     100,
-	}
-
-	id, err := svc.CreateNewTask(ctx, task)
-	require.Error(t, err)
-	assert.Equal(t, int64(0), id)
-	assert.Contains(t, err.Error(), "network error")
+	_, err := svc.CreateNewTask(context.Background(), models.Task{
+		TaskID:          "task-123",
+		TemplateID:      "7",
+		TemplateVersion: 2,
+		Amount:          10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/templates/7/versions/2", requestedPath)
+	assert.Equal(t, 2, saved.TemplateVersion)
+	assert.Equal(t, map[string]interface{}{"name": "{{name}}"}, saved.Template)
 }
 
-// TestCreateNewTask_RepoError проверяет ошибку при сохранении задачи в репозиторий.
-func TestCreateNewTask_RepoError(t *testing.T) {
-	logger, _ := zap.NewDevelopment()
-	sugaredLogger := logger.Sugar()
+// TestCreateNewTask_OnBehalfPinnedVersion проверяет, что для закреплённой ревизии
+// владелец проверяется по самому шаблону, когда задачу создаёт сервис от имени пользователя.
+func TestCreateNewTask_OnBehalfPinnedVersion(t *testing.T) {
+	for _, owner := range []string{"user-1", "user-2"} {
+		t.Run(owner, func(t *testing.T) {
+			repo := &fakeTaskRepository{
+				createNewTaskFunc: func(ctx context.Context, task models.Task) (int64, error) {
+					return 1, nil
+				},
+			}
+			templateClient := &fakeTemplateClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					body := fmt.Sprintf(`{"user_id":%q,"version":3,"content":{"name":"{{name}}"}}`, owner)
+					if req.URL.Path == "/templates/7/versions/2" {
+						body = `{"id":7,"version":2,"created_by":"user-1","content":{"name":"{{name}}"}}`
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+						Header:     make(http.Header),
+					}, nil
+				},
+			}
+			svc := NewTaskService(repo, &fakeRedisClient{}, zap.NewNop().Sugar(), templateClient, models.ShardPolicy{}, nil)
+
+			ctx := models.WithCaller(context.Background(), models.Caller{UserID: "user-1", Token: "tsk_scheduler", APIKey: true, OnBehalf: true})
+			_, err := svc.CreateNewTask(ctx, models.Task{TaskID: "task-123", UserID: "user-1", TemplateID: "7", TemplateVersion: 2, Amount: 10})
+			if owner == "user-1" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, models.ErrForbidden)
+			}
+		})
+	}
+}
 
+// TestCreateNewTask_ForwardsCallerToken проверяет, что токен вызывающего передаётся в template-service.
+func TestCreateNewTask_ForwardsCallerToken(t *testing.T) {
 	repo := &fakeTaskRepository{
 		createNewTaskFunc: func(ctx context.Context, task models.Task) (int64, error) {
-			return 0, errors.New("repo error")
+			return 1, nil
 		},
 	}
 	redisClient := &fakeRedisClient{
@@ -183,12 +453,14 @@ func TestCreateNewTask_RepoError(t *testing.T) {
 			return nil
 		},
 	}
-	kafkaProducer := &fakeKafkaProducer{}
+
+	var authorization string
 	templateClient := &fakeTemplateClient{
 		doFunc: func(req *http.Request) (*http.Response, error) {
+			authorization = req.Header.Get("Authorization")
 			return &http.Response{
 				StatusCode: http.StatusOK,
-				Body:       io.NopCloser(bytes.NewReader([]byte(`{"id":"template-456","name":"Test Template","content":{"name":"{{name}}","age":"{{age}}"}}`))),
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"version":1,"content":{"name":"{{name}}"}}`))),
 				Header:     make(http.Header),
 			}, nil
 		},
@@ -197,7 +469,36 @@ func TestCreateNewTask_RepoError(t *testing.T) {
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
+		logger:         zap.NewNop().Sugar(),
+		templateClient: templateClient,
+	}
+
+	ctx := models.WithCaller(context.Background(), models.Caller{UserID: "user-1", Token: "token-1"})
+	_, err := svc.CreateNewTask(ctx, models.Task{TaskID: "task-123", TemplateID: "7", Amount: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", authorization)
+}
+
+// TestCreateNewTask_TemplateClientError проверяет ошибку при запросе к template-service.
+func TestCreateNewTask_TemplateClientError(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	sugaredLogger := logger.Sugar()
+
+	repo := &fakeTaskRepository{}
+	redisClient := &fakeRedisClient{
+		setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+			return nil
+		},
+	}
+	templateClient := &fakeTemplateClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("network error")
+		},
+	}
+
+	svc := &taskService{
+		repo:           repo,
+		redis:          redisClient,
 		logger:         sugaredLogger,
 		templateClient: templateClient,
 	}
@@ -212,17 +513,17 @@ func TestCreateNewTask_RepoError(t *testing.T) {
 	id, err := svc.CreateNewTask(ctx, task)
 	require.Error(t, err)
 	assert.Equal(t, int64(0), id)
-	assert.Contains(t, err.Error(), "repo error")
+	assert.Contains(t, err.Error(), "network error")
 }
 
-// TestCreateNewTask_KafkaError проверяет ошибку при отправке в Kafka.
-func TestCreateNewTask_KafkaError(t *testing.T) {
+// TestCreateNewTask_RepoError проверяет ошибку при сохранении задачи в репозиторий.
+func TestCreateNewTask_RepoError(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	sugaredLogger := logger.Sugar()
 
 	repo := &fakeTaskRepository{
 		createNewTaskFunc: func(ctx context.Context, task models.Task) (int64, error) {
-			return 1, nil
+			return 0, errors.New("repo error")
 		},
 	}
 	redisClient := &fakeRedisClient{
@@ -230,14 +531,6 @@ func TestCreateNewTask_KafkaError(t *testing.T) {
 			return nil
 		},
 	}
-	kafkaProducer := &fakeKafkaProducer{
-		produceFunc: func(ctx context.Context, key, value []byte) error {
-			return errors.New("kafka error")
-		},
-		closeFunc: func() error {
-			return nil
-		},
-	}
 	templateClient := &fakeTemplateClient{
 		doFunc: func(req *http.Request) (*http.Response, error) {
 			return &http.Response{
@@ -251,7 +544,6 @@ func TestCreateNewTask_KafkaError(t *testing.T) {
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
 		logger:         sugaredLogger,
 		templateClient: templateClient,
 	}
@@ -264,8 +556,9 @@ func TestCreateNewTask_KafkaError(t *testing.T) {
 	}
 
 	id, err := svc.CreateNewTask(ctx, task)
-	require.NoError(t, err) // Ошибка Kafka не влияет на результат
-	assert.Equal(t, int64(1), id)
+	require.Error(t, err)
+	assert.Equal(t, int64(0), id)
+	assert.Contains(t, err.Error(), "repo error")
 }
 
 // TestCreateNewTask_RedisError проверяет ошибку при сохранении в Redis.
@@ -283,14 +576,6 @@ func TestCreateNewTask_RedisError(t *testing.T) {
 			return errors.New("redis error")
 		},
 	}
-	kafkaProducer := &fakeKafkaProducer{
-		produceFunc: func(ctx context.Context, key, value []byte) error {
-			return nil
-		},
-		closeFunc: func() error {
-			return nil
-		},
-	}
 	templateClient := &fakeTemplateClient{
 		doFunc: func(req *http.Request) (*http.Response, error) {
 			return &http.Response{
@@ -304,7 +589,6 @@ func TestCreateNewTask_RedisError(t *testing.T) {
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
 		logger:         sugaredLogger,
 		templateClient: templateClient,
 	}
@@ -338,13 +622,11 @@ func TestGetTaskByID_FromCache(t *testing.T) {
 			return string(data), nil
 		},
 	}
-	kafkaProducer := &fakeKafkaProducer{}
 	templateClient := &fakeTemplateClient{}
 
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
 		logger:         sugaredLogger,
 		templateClient: templateClient,
 	}
@@ -379,13 +661,11 @@ func TestGetTaskByID_FromCache_InvalidData(t *testing.T) {
 			return nil
 		},
 	}
-	kafkaProducer := &fakeKafkaProducer{}
 	templateClient := &fakeTemplateClient{}
 
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
 		logger:         sugaredLogger,
 		templateClient: templateClient,
 	}
@@ -420,13 +700,11 @@ func TestGetTaskByID_FromRepo(t *testing.T) {
 			return nil
 		},
 	}
-	kafkaProducer := &fakeKafkaProducer{}
 	templateClient := &fakeTemplateClient{}
 
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
 		logger:         sugaredLogger,
 		templateClient: templateClient,
 	}
@@ -454,13 +732,11 @@ func TestGetTaskByID_RepoError(t *testing.T) {
 			return "", errors.New("not found")
 		},
 	}
-	kafkaProducer := &fakeKafkaProducer{}
 	templateClient := &fakeTemplateClient{}
 
 	svc := &taskService{
 		repo:           repo,
 		redis:          redisClient,
-		kafka:          kafkaProducer,
 		logger:         sugaredLogger,
 		templateClient: templateClient,
 	}
@@ -471,3 +747,231 @@ func TestGetTaskByID_RepoError(t *testing.T) {
 	assert.Nil(t, task)
 	assert.Contains(t, err.Error(), "repo error")
 }
+
+// TestUpdateTaskStatus_InvalidatesCache проверяет сброс кеша задачи после смены статуса.
+func TestUpdateTaskStatus_InvalidatesCache(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	sugaredLogger := logger.Sugar()
+
+	var applied models.StatusEvent
+	repo := &fakeTaskRepository{
+		updateStatusFunc: func(ctx context.Context, event models.StatusEvent) (int64, error) {
+			applied = event
+			return 42, nil
+		},
+	}
+
+	var deleted []string
+	redisClient := &fakeRedisClient{
+		delFunc: func(ctx context.Context, keys ...string) error {
+			deleted = append(deleted, keys...)
+			return nil
+		},
+	}
+
+	svc := &taskService{
+		repo:   repo,
+		redis:  redisClient,
+		logger: sugaredLogger,
+	}
+
+	event := models.StatusEvent{TaskID: "task-123", Status: models.StatusRunning, RecordsGenerated: 10}
+	err := svc.UpdateTaskStatus(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, event, applied)
+	assert.Equal(t, []string{"task:42"}, deleted)
+}
+
+// TestUpdateTaskStatus_UnknownStatus проверяет отказ для неизвестного статуса.
+func TestUpdateTaskStatus_UnknownStatus(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	svc := &taskService{
+		repo:   &fakeTaskRepository{},
+		redis:  &fakeRedisClient{},
+		logger: logger.Sugar(),
+	}
+
+	err := svc.UpdateTaskStatus(context.Background(), models.StatusEvent{TaskID: "task-123", Status: "done"})
+	assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+}
+
+// TestUpdateTaskStatus_RepoError проверяет, что кеш не сбрасывается при ошибке репозитория.
+func TestUpdateTaskStatus_RepoError(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	repo := &fakeTaskRepository{
+		updateStatusFunc: func(ctx context.Context, event models.StatusEvent) (int64, error) {
+			return 0, models.ErrInvalidStatusTransition
+		},
+	}
+	redisClient := &fakeRedisClient{
+		delFunc: func(ctx context.Context, keys ...string) error {
+			t.Fatal("cache must not be invalidated")
+			return nil
+		},
+	}
+
+	svc := &taskService{
+		repo:   repo,
+		redis:  redisClient,
+		logger: logger.Sugar(),
+	}
+
+	err := svc.UpdateTaskStatus(context.Background(), models.StatusEvent{TaskID: "task-123", Status: models.StatusSucceeded})
+	assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+}
+
+// TestUpdateTaskStatus_Shard проверяет, что событие шарда применяется к шарду, а не к задаче.
+func TestUpdateTaskStatus_Shard(t *testing.T) {
+	var applied models.StatusEvent
+	repo := &fakeTaskRepository{
+		updateStatusFunc: func(ctx context.Context, event models.StatusEvent) (int64, error) {
+			t.Fatal("task status must not be updated directly")
+			return 0, nil
+		},
+		updateShardFunc: func(ctx context.Context, event models.StatusEvent) (int64, error) {
+			applied = event
+			return 42, nil
+		},
+	}
+
+	var deleted []string
+	redisClient := &fakeRedisClient{
+		delFunc: func(ctx context.Context, keys ...string) error {
+			deleted = append(deleted, keys...)
+			return nil
+		},
+	}
+
+	svc := &taskService{
+		repo:   repo,
+		redis:  redisClient,
+		logger: zap.NewNop().Sugar(),
+	}
+
+	shard := 2
+	event := models.StatusEvent{TaskID: "task-123", Shard: &shard, Status: models.StatusSucceeded, RecordsGenerated: 10}
+	require.NoError(t, svc.UpdateTaskStatus(context.Background(), event))
+	assert.Equal(t, event, applied)
+	assert.Equal(t, []string{"task:42"}, deleted)
+}
+
+// TestCancelTask_Success проверяет отмену задачи и сброс кэша.
+func TestCancelTask_Success(t *testing.T) {
+	var deleted []string
+	repo := &fakeTaskRepository{
+		cancelTaskFunc: func(ctx context.Context, id int64) (string, error) {
+			return "task-123", nil
+		},
+		getTaskByIDFunc: func(ctx context.Context, id int64) (*models.Task, error) {
+			return &models.Task{ID: id, TaskID: "task-123", Status: models.StatusCancelled}, nil
+		},
+	}
+	redis := &fakeRedisClient{
+		getFunc: func(ctx context.Context, key string) (string, error) {
+			return "", errors.New("cache miss")
+		},
+		setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+			return nil
+		},
+		delFunc: func(ctx context.Context, keys ...string) error {
+			deleted = append(deleted, keys...)
+			return nil
+		},
+	}
+	service := NewTaskService(repo, redis, zap.NewNop().Sugar(), &fakeTemplateClient{}, models.ShardPolicy{}, nil)
+
+	task, err := service.CancelTask(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, task.Status)
+	assert.Equal(t, []string{"task:1"}, deleted)
+}
+
+// TestCancelTask_AlreadyFinished проверяет отказ в отмене завершённой задачи.
+func TestCancelTask_AlreadyFinished(t *testing.T) {
+	repo := &fakeTaskRepository{
+		cancelTaskFunc: func(ctx context.Context, id int64) (string, error) {
+			return "", models.ErrInvalidStatusTransition
+		},
+	}
+	redis := &fakeRedisClient{
+		delFunc: func(ctx context.Context, keys ...string) error {
+			t.Fatal("cache must not be invalidated")
+			return nil
+		},
+	}
+	service := NewTaskService(repo, redis, zap.NewNop().Sugar(), &fakeTemplateClient{}, models.ShardPolicy{}, nil)
+
+	_, err := service.CancelTask(context.Background(), 1)
+	assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+}
+
+// TestListTasks_StatusFilterAfterTransition проверяет, что список по статусу не отдаётся
+// из кэша после смены статуса задачи.
+func TestListTasks_StatusFilterAfterTransition(t *testing.T) {
+	task := models.Task{ID: 42, TaskID: "task-123", UserID: "user-1", Status: models.StatusRunning}
+	repo := &fakeTaskRepository{
+		listTasksFunc: func(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+			if task.Status != filter.Status {
+				return []models.Task{}, nil
+			}
+			return []models.Task{task}, nil
+		},
+		updateStatusFunc: func(ctx context.Context, event models.StatusEvent) (int64, error) {
+			task.Status = event.Status
+			return task.ID, nil
+		},
+		getTaskByIDFunc: func(ctx context.Context, id int64) (*models.Task, error) {
+			found := task
+			return &found, nil
+		},
+	}
+	svc := NewTaskService(repo, newMemoryRedisClient(), zap.NewNop().Sugar(), &fakeTemplateClient{}, models.ShardPolicy{}, nil)
+
+	running := models.TaskFilter{UserID: "user-1", Status: models.StatusRunning, Page: 1, Limit: 10}
+	tasks, err := svc.ListTasks(context.Background(), running)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	event := models.StatusEvent{TaskID: task.TaskID, Status: models.StatusSucceeded}
+	require.NoError(t, svc.UpdateTaskStatus(context.Background(), event))
+
+	tasks, err = svc.ListTasks(context.Background(), running)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	succeeded := models.TaskFilter{UserID: "user-1", Status: models.StatusSucceeded, Page: 1, Limit: 10}
+	tasks, err = svc.ListTasks(context.Background(), succeeded)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, models.StatusSucceeded, tasks[0].Status)
+}
+
+// TestGetTaskReport_Success проверяет расчёт доли успешных запросов.
+func TestGetTaskReport_Success(t *testing.T) {
+	repo := &fakeTaskRepository{
+		getReportFunc: func(ctx context.Context, id int64) (*models.TaskReport, error) {
+			return &models.TaskReport{TaskID: "task-123", Status: models.StatusSucceeded, Report: &models.Report{Total: 8, Passed: 6, Failed: 2}}, nil
+		},
+	}
+	service := NewTaskService(repo, &fakeRedisClient{}, zap.NewNop().Sugar(), &fakeTemplateClient{}, models.ShardPolicy{}, nil)
+
+	report, err := service.GetTaskReport(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 75.0, report.SuccessRate)
+	assert.Equal(t, 2, report.Failed)
+}
+
+// TestGetTaskReport_NotReady проверяет ответ для http-задачи, ещё не отправившей запросов.
+func TestGetTaskReport_NotReady(t *testing.T) {
+	repo := &fakeTaskRepository{
+		getReportFunc: func(ctx context.Context, id int64) (*models.TaskReport, error) {
+			return &models.TaskReport{TaskID: "task-123", Status: models.StatusQueued}, nil
+		},
+	}
+	service := NewTaskService(repo, &fakeRedisClient{}, zap.NewNop().Sugar(), &fakeTemplateClient{}, models.ShardPolicy{}, nil)
+
+	_, err := service.GetTaskReport(context.Background(), 1)
+	assert.ErrorIs(t, err, models.ErrReportNotReady)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"task-service/internal/middleware"
+	"task-service/internal/models"
+	"task-service/pkg/storage"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type ResultService interface {
+	OpenResult(ctx context.Context, id int64) (*models.TaskResult, error)
+	ResultURL(ctx context.Context, id int64) (string, time.Time, error)
+	VerifyResultURL(id int64, expires int64, signature string) error
+}
+
+type ResultHandler struct {
+	service ResultService
+	logger  *zap.SugaredLogger
+}
+
+func NewResultHandler(service ResultService, logger *zap.SugaredLogger) *ResultHandler {
+	return &ResultHandler{service: service, logger: logger}
+}
+
+// GetTaskResult streams the task result, or returns a signed download URL when ?signed_url=true.
+func (h *ResultHandler) GetTaskResult(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid task ID"})
+	}
+
+	if signed, _ := strconv.ParseBool(c.QueryParam("signed_url")); signed {
+		url, expiresAt, err := h.service.ResultURL(c.Request().Context(), id)
+		if err != nil {
+			return h.resultError(c, id, err)
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"url":        url,
+			"expires_at": expiresAt.UTC(),
+		})
+	}
+
+	return h.stream(c, id)
+}
+
+// DownloadTaskResult streams the task result to holders of a valid signed URL.
+func (h *ResultHandler) DownloadTaskResult(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid task ID"})
+	}
+
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": models.ErrInvalidSignature.Error()})
+	}
+	if err := h.service.VerifyResultURL(id, expires, c.QueryParam("signature")); err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	return h.stream(c, id)
+}
+
+func (h *ResultHandler) stream(c echo.Context, id int64) error {
+	result, err := h.service.OpenResult(c.Request().Context(), id)
+	if err != nil {
+		return h.resultError(c, id, err)
+	}
+	defer result.Body.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", result.FileName))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(result.Size, 10))
+	return c.Stream(http.StatusOK, result.ContentType, result.Body)
+}
+
+func (h *ResultHandler) resultError(c echo.Context, id int64, err error) error {
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
+	case errors.Is(err, models.ErrResultNotReady):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, storage.ErrNotFound):
+		logger.Errorf("Result of task %d is missing from storage", id)
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "task result not found"})
+	default:
+		logger.Errorf("Failed to get result of task %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get task result"})
+	}
+}
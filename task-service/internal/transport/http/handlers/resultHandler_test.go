@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task-service/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockResultService struct {
+	mock.Mock
+}
+
+func (m *MockResultService) OpenResult(ctx context.Context, id int64) (*models.TaskResult, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskResult), args.Error(1)
+}
+
+func (m *MockResultService) ResultURL(ctx context.Context, id int64) (string, time.Time, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockResultService) VerifyResultURL(id int64, expires int64, signature string) error {
+	args := m.Called(id, expires, signature)
+	return args.Error(0)
+}
+
+func newResultContext(target string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	return c, rec
+}
+
+func TestResultHandler_GetTaskResult_Stream(t *testing.T) {
+	service := new(MockResultService)
+	handler := NewResultHandler(service, zap.NewNop().Sugar())
+	c, rec := newResultContext("/api/v2/tasks/1/result")
+
+	service.On("OpenResult", mock.Anything, int64(1)).Return(&models.TaskResult{
+		Body:        io.NopCloser(strings.NewReader("INSERT INTO x VALUES (1);\n")),
+		Size:        26,
+		ContentType: "application/sql",
+		FileName:    "task-123.sql",
+	}, nil)
+
+	require.NoError(t, handler.GetTaskResult(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/sql", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `attachment; filename="task-123.sql"`, rec.Header().Get(echo.HeaderContentDisposition))
+	assert.Equal(t, "INSERT INTO x VALUES (1);\n", rec.Body.String())
+}
+
+func TestResultHandler_GetTaskResult_NotReady(t *testing.T) {
+	service := new(MockResultService)
+	handler := NewResultHandler(service, zap.NewNop().Sugar())
+	c, rec := newResultContext("/api/v2/tasks/1/result")
+
+	service.On("OpenResult", mock.Anything, int64(1)).Return(nil, models.ErrResultNotReady)
+
+	require.NoError(t, handler.GetTaskResult(c))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+// TestResultHandler_GetTaskResult_Errors проверяет, что 404 возвращается только для
+// отсутствующей задачи, а прочие ошибки дают 500.
+func TestResultHandler_GetTaskResult_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{models.ErrTaskNotFound, http.StatusNotFound},
+		{errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		service := new(MockResultService)
+		handler := NewResultHandler(service, zap.NewNop().Sugar())
+		c, rec := newResultContext("/api/v2/tasks/1/result")
+
+		service.On("OpenResult", mock.Anything, int64(1)).Return(nil, tt.err)
+
+		require.NoError(t, handler.GetTaskResult(c))
+		assert.Equal(t, tt.want, rec.Code, tt.err.Error())
+	}
+}
+
+func TestResultHandler_GetTaskResult_SignedURL(t *testing.T) {
+	service := new(MockResultService)
+	handler := NewResultHandler(service, zap.NewNop().Sugar())
+	c, rec := newResultContext("/api/v2/tasks/1/result?signed_url=true")
+
+	expiresAt := time.Now().Add(time.Minute)
+	service.On("ResultURL", mock.Anything, int64(1)).Return("/download?signature=abc", expiresAt, nil)
+
+	require.NoError(t, handler.GetTaskResult(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"url":"/download?signature=abc"`)
+}
+
+func TestResultHandler_DownloadTaskResult_InvalidSignature(t *testing.T) {
+	service := new(MockResultService)
+	handler := NewResultHandler(service, zap.NewNop().Sugar())
+	c, rec := newResultContext("/api/v2/tasks/1/result/download?expires=100&signature=bad")
+
+	service.On("VerifyResultURL", int64(1), int64(100), "bad").Return(models.ErrInvalidSignature)
+
+	require.NoError(t, handler.DownloadTaskResult(c))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	service.AssertNotCalled(t, "OpenResult", mock.Anything, mock.Anything)
+}
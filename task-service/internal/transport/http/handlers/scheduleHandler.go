@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"task-service/internal/middleware"
+	"task-service/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, userID string, req models.ScheduleRequest) (*models.Schedule, error)
+	GetSchedule(ctx context.Context, id int64) (*models.Schedule, error)
+	ListSchedules(ctx context.Context, userID string) ([]models.Schedule, error)
+	UpdateSchedule(ctx context.Context, id int64, req models.ScheduleRequest) (*models.Schedule, error)
+	DeleteSchedule(ctx context.Context, id int64) error
+	ListRuns(ctx context.Context, id int64, limit int) ([]models.ScheduleRun, error)
+}
+
+type ScheduleHandler struct {
+	service ScheduleService
+	logger  *zap.SugaredLogger
+}
+
+func NewScheduleHandler(service ScheduleService, logger *zap.SugaredLogger) *ScheduleHandler {
+	return &ScheduleHandler{service: service, logger: logger}
+}
+
+func (h *ScheduleHandler) CreateSchedule(c echo.Context) error {
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	req, err := bindScheduleRequest(c)
+	if err != nil {
+		logger.Errorf("Invalid schedule request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	schedule, err := h.service.CreateSchedule(c.Request().Context(), caller(c).UserID, req)
+	switch {
+	case errors.Is(err, models.ErrInvalidSchedule):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case err != nil:
+		logger.Errorf("Failed to create schedule: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to create schedule"})
+	}
+
+	return c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *ScheduleHandler) GetSchedule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid schedule ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	schedule, err := h.service.GetSchedule(c.Request().Context(), id)
+	switch {
+	case errors.Is(err, models.ErrScheduleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "schedule not found"})
+	case err != nil:
+		logger.Errorf("Failed to get schedule %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get schedule"})
+	case !caller(c).CanAccess(schedule.UserID):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "schedule not found"})
+	}
+
+	return c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) ListSchedules(c echo.Context) error {
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	schedules, err := h.service.ListSchedules(c.Request().Context(), caller(c).UserID)
+	if err != nil {
+		logger.Errorf("Failed to list schedules: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to retrieve schedules"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": schedules})
+}
+
+func (h *ScheduleHandler) UpdateSchedule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid schedule ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	req, err := bindScheduleRequest(c)
+	if err != nil {
+		logger.Errorf("Invalid schedule request: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	schedule, err := h.service.UpdateSchedule(c.Request().Context(), id, req)
+	switch {
+	case errors.Is(err, models.ErrScheduleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "schedule not found"})
+	case errors.Is(err, models.ErrInvalidSchedule):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case err != nil:
+		logger.Errorf("Failed to update schedule %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to update schedule"})
+	}
+
+	return c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) DeleteSchedule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid schedule ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	err = h.service.DeleteSchedule(c.Request().Context(), id)
+	switch {
+	case errors.Is(err, models.ErrScheduleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "schedule not found"})
+	case err != nil:
+		logger.Errorf("Failed to delete schedule %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to delete schedule"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListRuns returns the latest runs of a schedule with the tasks they created.
+func (h *ScheduleHandler) ListRuns(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid schedule ID"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit > 100 {
+		limit = 100
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	runs, err := h.service.ListRuns(c.Request().Context(), id, limit)
+	switch {
+	case errors.Is(err, models.ErrScheduleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "schedule not found"})
+	case err != nil:
+		logger.Errorf("Failed to list runs of schedule %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to retrieve schedule runs"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": runs})
+}
+
+// RequireOwner lets a request on the schedule in the :id path parameter through only for
+// the owner of the schedule or an admin. Schedules of other users are reported as not found.
+func (h *ScheduleHandler) RequireOwner(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid schedule ID"})
+		}
+
+		schedule, err := h.service.GetSchedule(c.Request().Context(), id)
+		switch {
+		case errors.Is(err, models.ErrScheduleNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "schedule not found"})
+		case err != nil:
+			middleware.GetLoggerFromCtx(c.Request().Context()).Errorf("Failed to get schedule %d: %v", id, err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get schedule"})
+		case !caller(c).CanAccess(schedule.UserID):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "schedule not found"})
+		}
+
+		return next(c)
+	}
+}
+
+func bindScheduleRequest(c echo.Context) (models.ScheduleRequest, error) {
+	var req models.ScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return req, errors.New("invalid request")
+	}
+	if err := models.NewValidator().Struct(req); err != nil {
+		return req, err
+	}
+	return req, nil
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"task-service/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockScheduleService) CreateSchedule(ctx context.Context, userID string, req models.ScheduleRequest) (*models.Schedule, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) GetSchedule(ctx context.Context, id int64) (*models.Schedule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) ListSchedules(ctx context.Context, userID string) ([]models.Schedule, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) UpdateSchedule(ctx context.Context, id int64, req models.ScheduleRequest) (*models.Schedule, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) DeleteSchedule(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockScheduleService) ListRuns(ctx context.Context, id int64, limit int) ([]models.ScheduleRun, error) {
+	args := m.Called(ctx, id, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ScheduleRun), args.Error(1)
+}
+
+func newScheduleContext(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	withCaller(c, "user-1", "")
+	return c, rec
+}
+
+const scheduleBody = `{"name":"nightly","cron":"0 3 * * *","timezone":"Europe/Berlin","task":{"type":"json","template_id":"tpl","amount":10,"format":"csv"}}`
+
+func TestScheduleHandler_CreateSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "created", body: scheduleBody, wantStatus: http.StatusCreated},
+		{name: "invalid cron", body: scheduleBody, err: models.ErrInvalidSchedule, wantStatus: http.StatusBadRequest},
+		{name: "db error", body: scheduleBody, err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+		{name: "missing task", body: `{"cron":"0 3 * * *"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown timezone", body: strings.Replace(scheduleBody, "Europe/Berlin", "Mars/Olympus", 1), wantStatus: http.StatusBadRequest},
+		{name: "invalid json", body: `{invalid`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockScheduleService)
+			handler := NewScheduleHandler(service, zap.NewNop().Sugar())
+			c, rec := newScheduleContext(http.MethodPost, "/api/v2/schedules", tt.body)
+
+			if tt.wantStatus == http.StatusCreated || tt.err != nil {
+				call := service.On("CreateSchedule", mock.Anything, "user-1", mock.MatchedBy(func(req models.ScheduleRequest) bool {
+					return req.Cron == "0 3 * * *" && req.Task.TemplateID == "tpl"
+				}))
+				if tt.err != nil {
+					call.Return(nil, tt.err)
+				} else {
+					call.Return(&models.Schedule{ID: 1, Cron: "0 3 * * *"}, nil)
+				}
+			}
+
+			require.NoError(t, handler.CreateSchedule(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			service.AssertExpectations(t)
+		})
+	}
+}
+
+func TestScheduleHandler_GetSchedule(t *testing.T) {
+	service := new(MockScheduleService)
+	handler := NewScheduleHandler(service, zap.NewNop().Sugar())
+
+	service.On("GetSchedule", mock.Anything, int64(1)).Return(&models.Schedule{ID: 1, UserID: "user-1"}, nil)
+	service.On("GetSchedule", mock.Anything, int64(2)).Return(nil, models.ErrScheduleNotFound)
+	service.On("GetSchedule", mock.Anything, int64(3)).Return(&models.Schedule{ID: 3, UserID: "user-2"}, nil)
+
+	for id, want := range map[string]int{"1": http.StatusOK, "2": http.StatusNotFound, "3": http.StatusNotFound, "x": http.StatusBadRequest} {
+		c, rec := newScheduleContext(http.MethodGet, "/api/v2/schedules/"+id, "")
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+
+		require.NoError(t, handler.GetSchedule(c))
+		assert.Equal(t, want, rec.Code, id)
+	}
+}
+
+func TestScheduleHandler_DeleteSchedule(t *testing.T) {
+	service := new(MockScheduleService)
+	handler := NewScheduleHandler(service, zap.NewNop().Sugar())
+
+	service.On("DeleteSchedule", mock.Anything, int64(1)).Return(nil)
+	service.On("DeleteSchedule", mock.Anything, int64(2)).Return(models.ErrScheduleNotFound)
+
+	for id, want := range map[string]int{"1": http.StatusNoContent, "2": http.StatusNotFound} {
+		c, rec := newScheduleContext(http.MethodDelete, "/api/v2/schedules/"+id, "")
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+
+		require.NoError(t, handler.DeleteSchedule(c))
+		assert.Equal(t, want, rec.Code, id)
+	}
+}
+
+func TestScheduleHandler_ListRuns(t *testing.T) {
+	service := new(MockScheduleService)
+	handler := NewScheduleHandler(service, zap.NewNop().Sugar())
+
+	taskID := int64(42)
+	service.On("ListRuns", mock.Anything, int64(1), 100).
+		Return([]models.ScheduleRun{{ID: 1, ScheduleID: 1, Status: models.RunCreated, TaskID: &taskID}}, nil)
+
+	c, rec := newScheduleContext(http.MethodGet, "/api/v2/schedules/1/runs?limit=500", "")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	require.NoError(t, handler.ListRuns(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"task_id":42`)
+	service.AssertExpectations(t)
+}
@@ -2,13 +2,12 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"task-service/internal/middleware"
 	"task-service/internal/models"
 
-	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 )
@@ -17,6 +16,8 @@ type TaskService interface {
 	CreateNewTask(ctx context.Context, task models.Task) (int64, error)
 	GetTaskByID(ctx context.Context, id int64) (*models.Task, error)
 	ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	CancelTask(ctx context.Context, id int64) (*models.Task, error)
+	GetTaskReport(ctx context.Context, id int64) (*models.TaskReport, error)
 }
 
 type TaskHandler struct {
@@ -38,23 +39,18 @@ func (t *TaskHandler) CreateNewTask(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
 
-	validate := validator.New()
-	if err := validate.Struct(req); err != nil {
+	if err := models.NewValidator().Struct(req); err != nil {
 		ctxLogger.Errorf("Validation failed: %v", err)
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	task := models.Task{
-		TaskID:     uuid.NewString(), // Генерируем UUID для TaskID
-		UserID:     c.Get("user_id").(string),
-		Type:       req.Type,
-		TemplateID: req.TemplateID,
-		Template:   req.Template,
-		Amount:     req.Amount,
-	}
+	task := req.NewTask(caller(c).UserID)
 
 	id, err := t.service.CreateNewTask(c.Request().Context(), task)
-	if err != nil {
+	switch {
+	case errors.Is(err, models.ErrForbiddenURL):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case err != nil:
 		ctxLogger.Errorf("Failed to create task: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to create task"})
 	}
@@ -79,8 +75,14 @@ func (t *TaskHandler) GetTaskByID(c echo.Context) error {
 	}
 
 	task, err := t.service.GetTaskByID(c.Request().Context(), intID)
-	if err != nil {
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
+	case err != nil:
 		logger.Errorf("Failed to get task %s: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get task"})
+	// Tasks of other users are not found rather than forbidden, so that their IDs leak nothing.
+	case !caller(c).CanAccess(task.UserID):
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
 	}
 
@@ -91,10 +93,23 @@ func (t *TaskHandler) ListTasks(c echo.Context) error {
 	ctx := c.Request().Context()
 	logger := middleware.GetLoggerFromCtx(ctx)
 
-	// Get query parameters for filtering
+	// Users list their own tasks; only admins may list those of another user, or of
+	// all users by leaving user_id out.
+	caller := caller(c)
 	userID := c.QueryParam("user_id")
+	if !caller.IsAdmin() {
+		if userID != "" && userID != caller.UserID {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "cannot list tasks of another user"})
+		}
+		userID = caller.UserID
+	}
+
+	// Get query parameters for filtering
 	taskType := c.QueryParam("type")
 	status := c.QueryParam("status")
+	if status != "" && !models.IsValidStatus(status) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	}
 
 	// Extract pagination parameters
 	page, _ := strconv.Atoi(c.QueryParam("page"))
@@ -137,3 +152,117 @@ func (t *TaskHandler) ListTasks(c echo.Context) error {
 		"limit": limit,
 	})
 }
+
+// CancelTask stops an unfinished task. Finished tasks cannot be cancelled.
+func (t *TaskHandler) CancelTask(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid task ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	task, err := t.service.CancelTask(c.Request().Context(), id)
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
+	case errors.Is(err, models.ErrInvalidStatusTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "task is already finished"})
+	case err != nil:
+		logger.Errorf("Failed to cancel task %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to cancel task"})
+	}
+
+	logger.Infof("Task %d cancelled", id)
+	return c.JSON(http.StatusOK, task)
+}
+
+// RegenerateTask creates a new task that reproduces the task in the :id path parameter
+// byte for byte: same template revision, amount, seed, locale and format.
+func (t *TaskHandler) RegenerateTask(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid task ID"})
+	}
+
+	ctx := c.Request().Context()
+	logger := middleware.GetLoggerFromCtx(ctx)
+
+	source, err := t.service.GetTaskByID(ctx, id)
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
+	case err != nil:
+		logger.Errorf("Failed to get task %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get task"})
+	}
+
+	task := source.Regenerate(caller(c).UserID)
+	newID, err := t.service.CreateNewTask(ctx, task)
+	switch {
+	case errors.Is(err, models.ErrForbiddenURL):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case err != nil:
+		logger.Errorf("Failed to regenerate task %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to create task"})
+	}
+
+	task.ID = newID
+	logger.Infof("Task %d regenerated as %d, TaskID: %s", id, newID, task.TaskID)
+	return c.JSON(http.StatusCreated, task)
+}
+
+// GetTaskReport returns the totals, latency percentiles and failure samples of an http task.
+func (t *TaskHandler) GetTaskReport(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid task ID"})
+	}
+
+	logger := middleware.GetLoggerFromCtx(c.Request().Context())
+
+	report, err := t.service.GetTaskReport(c.Request().Context(), id)
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
+	case errors.Is(err, models.ErrReportNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrReportNotReady):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		logger.Errorf("Failed to get report of task %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get task report"})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// RequireOwner lets a request on the task in the :id path parameter through only for
+// the owner of the task or an admin. Tasks of other users are reported as not found.
+func (t *TaskHandler) RequireOwner(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid task ID"})
+		}
+
+		task, err := t.service.GetTaskByID(c.Request().Context(), id)
+		switch {
+		case errors.Is(err, models.ErrTaskNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
+		case err != nil:
+			middleware.GetLoggerFromCtx(c.Request().Context()).Errorf("Failed to get task %d: %v", id, err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get task"})
+		case !caller(c).CanAccess(task.UserID):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
+		}
+
+		return next(c)
+	}
+}
+
+// caller returns the user authenticated by AuthMiddleware.
+func caller(c echo.Context) models.Caller {
+	caller, _ := models.CallerFromContext(c.Request().Context())
+	return caller
+}
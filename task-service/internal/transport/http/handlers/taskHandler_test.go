@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -36,6 +38,30 @@ func (m *MockTaskService) GetTaskByID(ctx context.Context, id int64) (*models.Ta
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
+func (m *MockTaskService) ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) CancelTask(ctx context.Context, id int64) (*models.Task, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetTaskReport(ctx context.Context, id int64) (*models.TaskReport, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskReport), args.Error(1)
+}
+
 func setupTestHandler() (*TaskHandler, *MockTaskService, echo.Context, *httptest.ResponseRecorder) {
 	logger := zap.NewNop().Sugar()
 	service := new(MockTaskService)
@@ -52,6 +78,13 @@ func setupTestHandler() (*TaskHandler, *MockTaskService, echo.Context, *httptest
 	return handler, service, c, rec
 }
 
+// withCaller authenticates the request of c as AuthMiddleware would.
+func withCaller(c echo.Context, userID, role string) {
+	ctx := models.WithCaller(c.Request().Context(), models.Caller{UserID: userID, Role: role})
+	c.SetRequest(c.Request().WithContext(ctx))
+	c.Set("user_id", userID)
+}
+
 func TestTaskHandler_CreateNewTask_Success(t *testing.T) {
 	handler, service, _, _ := setupTestHandler()
 
@@ -59,7 +92,6 @@ func TestTaskHandler_CreateNewTask_Success(t *testing.T) {
 	reqBody := models.CreateTaskRequest{
 		Type:       "test",
 		TemplateID: "template-123",
-		Template:   map[string]interface{}{"field": "value"},
 		Amount:     5,
 		Format:     "json",
 	}
@@ -69,7 +101,7 @@ func TestTaskHandler_CreateNewTask_Success(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	c.Set("user_id", "user-123")
+	withCaller(c, "user-123", "")
 
 	service.On("CreateNewTask", c.Request().Context(), mock.AnythingOfType("models.Task")).
 		Return(int64(1), nil)
@@ -86,6 +118,39 @@ func TestTaskHandler_CreateNewTask_Success(t *testing.T) {
 	service.AssertExpectations(t)
 }
 
+// TestTaskHandler_CreateNewTask_Seed проверяет, что явный seed передаётся в задачу,
+// а при его отсутствии задаче назначается случайный.
+func TestTaskHandler_CreateNewTask_Seed(t *testing.T) {
+	handler, service, _, _ := setupTestHandler()
+	e := echo.New()
+
+	var seeds []int64
+	service.On("CreateNewTask", mock.Anything, mock.AnythingOfType("models.Task")).
+		Run(func(args mock.Arguments) { seeds = append(seeds, args.Get(1).(models.Task).Seed) }).
+		Return(int64(1), nil)
+
+	for _, body := range []string{
+		`{"type":"test","template_id":"1","amount":5,"format":"json","seed":0}`,
+		`{"type":"test","template_id":"1","amount":5,"format":"json","seed":42}`,
+		`{"type":"test","template_id":"1","amount":5,"format":"json"}`,
+		`{"type":"test","template_id":"1","amount":5,"format":"json"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		withCaller(c, "user-123", "")
+
+		require.NoError(t, handler.CreateNewTask(c))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	require.Len(t, seeds, 4)
+	assert.Equal(t, int64(0), seeds[0])
+	assert.Equal(t, int64(42), seeds[1])
+	assert.NotEqual(t, seeds[2], seeds[3])
+}
+
 func TestTaskHandler_CreateNewTask_InvalidJSON(t *testing.T) {
 	handler, _, _, _ := setupTestHandler()
 
@@ -105,6 +170,66 @@ func TestTaskHandler_CreateNewTask_InvalidJSON(t *testing.T) {
 	assert.Equal(t, "invalid request", response["error"])
 }
 
+// TestTaskHandler_CreateNewTask_MissingTemplateID проверяет, что задача без шаблона
+// отклоняется до обращения к template-service.
+func TestTaskHandler_CreateNewTask_MissingTemplateID(t *testing.T) {
+	handler, service, _, _ := setupTestHandler()
+
+	e := echo.New()
+	body := `{"type":"test","amount":5,"format":"json","template":{"field":"value"}}`
+	req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader([]byte(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	withCaller(c, "user-123", "")
+
+	err := handler.CreateNewTask(c)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "TemplateID")
+	service.AssertNotCalled(t, "CreateNewTask", mock.Anything, mock.Anything)
+}
+
+// TestTaskHandler_CreateNewTask_UnknownLocale проверяет, что задача с неизвестной
+// локалью отклоняется при создании, а не падает позже в worker-service.
+func TestTaskHandler_CreateNewTask_UnknownLocale(t *testing.T) {
+	handler, service, _, _ := setupTestHandler()
+
+	e := echo.New()
+	body := `{"type":"test","template_id":"template-456","amount":5,"format":"json","locale":"xx_YY"}`
+	req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader([]byte(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	withCaller(c, "user-123", "")
+
+	err := handler.CreateNewTask(c)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Locale")
+	service.AssertNotCalled(t, "CreateNewTask", mock.Anything, mock.Anything)
+}
+
+// TestTaskHandler_CreateNewTask_ForbiddenURL проверяет, что http-задача с адресом во
+// внутренней сети отклоняется с кодом 400.
+func TestTaskHandler_CreateNewTask_ForbiddenURL(t *testing.T) {
+	handler, service, _, _ := setupTestHandler()
+	service.On("CreateNewTask", mock.Anything, mock.AnythingOfType("models.Task")).
+		Return(int64(0), fmt.Errorf("%w: 169.254.169.254 is not public", models.ErrForbiddenURL))
+
+	e := echo.New()
+	body := `{"type":"http","template_id":"template-456","amount":5,"format":"json","request":{"method":"GET","url":"http://169.254.169.254/latest/meta-data"}}`
+	req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader([]byte(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	withCaller(c, "user-123", "")
+
+	require.NoError(t, handler.CreateNewTask(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "public address")
+}
+
 func TestTaskHandler_GetTaskByID_Success(t *testing.T) {
 	handler, service, _, _ := setupTestHandler()
 
@@ -115,6 +240,7 @@ func TestTaskHandler_GetTaskByID_Success(t *testing.T) {
 	c.SetPath("/tasks/:id")
 	c.SetParamNames("id")
 	c.SetParamValues("1")
+	withCaller(c, "user-123", "")
 
 	expectedTask := &models.Task{
 		ID:        1,
@@ -141,3 +267,245 @@ func TestTaskHandler_GetTaskByID_Success(t *testing.T) {
 
 	service.AssertExpectations(t)
 }
+
+func TestTaskHandler_CancelTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		task       *models.Task
+		err        error
+		wantStatus int
+	}{
+		{name: "cancelled", task: &models.Task{ID: 1, TaskID: "task-123", Status: models.StatusCancelled}, wantStatus: http.StatusOK},
+		{name: "not found", err: models.ErrTaskNotFound, wantStatus: http.StatusNotFound},
+		{name: "finished", err: models.ErrInvalidStatusTransition, wantStatus: http.StatusConflict},
+		{name: "db error", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service, _, _ := setupTestHandler()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tasks/1/cancel", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tasks/:id/cancel")
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			if tt.task != nil {
+				service.On("CancelTask", c.Request().Context(), int64(1)).Return(tt.task, nil)
+			} else {
+				service.On("CancelTask", c.Request().Context(), int64(1)).Return(nil, tt.err)
+			}
+
+			require.NoError(t, handler.CancelTask(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			service.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskHandler_GetTaskReport(t *testing.T) {
+	report := &models.TaskReport{
+		TaskID:      "task-123",
+		Status:      models.StatusSucceeded,
+		SuccessRate: 50,
+		Report: &models.Report{
+			Total:    2,
+			Passed:   1,
+			Failed:   1,
+			Latency:  models.LatencySummary{P50: 12.5, P95: 40, P99: 40},
+			Failures: []models.RequestFailure{{Index: 1, Status: 500, Reasons: []string{"status 500"}}},
+		},
+	}
+	tests := []struct {
+		name       string
+		report     *models.TaskReport
+		err        error
+		wantStatus int
+	}{
+		{name: "ready", report: report, wantStatus: http.StatusOK},
+		{name: "not found", err: models.ErrTaskNotFound, wantStatus: http.StatusNotFound},
+		{name: "not http", err: models.ErrReportNotFound, wantStatus: http.StatusNotFound},
+		{name: "not ready", err: models.ErrReportNotReady, wantStatus: http.StatusConflict},
+		{name: "db error", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service, _, _ := setupTestHandler()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/tasks/1/report", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tasks/:id/report")
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			if tt.report != nil {
+				service.On("GetTaskReport", c.Request().Context(), int64(1)).Return(tt.report, nil)
+			} else {
+				service.On("GetTaskReport", c.Request().Context(), int64(1)).Return(nil, tt.err)
+			}
+
+			require.NoError(t, handler.GetTaskReport(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			service.AssertExpectations(t)
+
+			if tt.report != nil {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, 50.0, body["success_rate"])
+				assert.Equal(t, 2.0, body["total"])
+				assert.Equal(t, 40.0, body["latency"].(map[string]interface{})["p95_ms"])
+				assert.Len(t, body["failures"], 1)
+			}
+		})
+	}
+}
+
+// TestTaskHandler_GetTaskByID_OtherUser проверяет, что чужая задача не находится, а администратору доступна.
+func TestTaskHandler_GetTaskByID_OtherUser(t *testing.T) {
+	handler, service, _, _ := setupTestHandler()
+	service.On("GetTaskByID", mock.Anything, int64(1)).Return(&models.Task{ID: 1, UserID: "user-123"}, nil)
+
+	for role, want := range map[string]int{"": http.StatusNotFound, models.RoleAdmin: http.StatusOK} {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/tasks/1", nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		withCaller(c, "user-456", role)
+
+		require.NoError(t, handler.GetTaskByID(c))
+		assert.Equal(t, want, rec.Code, role)
+	}
+}
+
+// TestTaskHandler_GetTaskByID_Errors проверяет, что 404 возвращается только для
+// отсутствующей задачи, а прочие ошибки сервиса дают 500.
+func TestTaskHandler_GetTaskByID_Errors(t *testing.T) {
+	handler, service, _, _ := setupTestHandler()
+	service.On("GetTaskByID", mock.Anything, int64(1)).Return(nil, models.ErrTaskNotFound)
+	service.On("GetTaskByID", mock.Anything, int64(2)).Return(nil, errors.New("connection refused"))
+
+	for id, want := range map[string]int{"1": http.StatusNotFound, "2": http.StatusInternalServerError} {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/tasks/"+id, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		withCaller(c, "user-123", "")
+
+		require.NoError(t, handler.GetTaskByID(c))
+		assert.Equal(t, want, rec.Code, id)
+	}
+}
+
+// TestTaskHandler_ListTasks_Scope проверяет, что пользователь видит только свои задачи,
+// а чужие по user_id может запросить только администратор.
+func TestTaskHandler_ListTasks_Scope(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		role       string
+		wantStatus int
+		wantUserID string
+	}{
+		{name: "own tasks by default", query: "", wantStatus: http.StatusOK, wantUserID: "user-123"},
+		{name: "own user_id", query: "?user_id=user-123", wantStatus: http.StatusOK, wantUserID: "user-123"},
+		{name: "other user_id", query: "?user_id=user-456", wantStatus: http.StatusForbidden},
+		{name: "admin other user_id", query: "?user_id=user-456", role: models.RoleAdmin, wantStatus: http.StatusOK, wantUserID: "user-456"},
+		{name: "admin all users", query: "", role: models.RoleAdmin, wantStatus: http.StatusOK, wantUserID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service, _, _ := setupTestHandler()
+			service.On("ListTasks", mock.Anything, mock.MatchedBy(func(f models.TaskFilter) bool {
+				return f.UserID == tt.wantUserID
+			})).Return([]models.Task{}, nil)
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/tasks"+tt.query, nil), rec)
+			withCaller(c, "user-123", tt.role)
+
+			require.NoError(t, handler.ListTasks(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				service.AssertExpectations(t)
+			} else {
+				service.AssertNotCalled(t, "ListTasks", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// TestTaskHandler_RequireOwner проверяет доступ к операциям над задачей только для владельца и администратора.
+func TestTaskHandler_RequireOwner(t *testing.T) {
+	handler, service, _, _ := setupTestHandler()
+	service.On("GetTaskByID", mock.Anything, int64(1)).Return(&models.Task{ID: 1, UserID: "user-123"}, nil)
+	service.On("GetTaskByID", mock.Anything, int64(2)).Return(nil, models.ErrTaskNotFound)
+
+	tests := []struct {
+		id, userID, role string
+		want             int
+	}{
+		{"1", "user-123", "", http.StatusOK},
+		{"1", "user-456", "", http.StatusNotFound},
+		{"1", "user-456", models.RoleAdmin, http.StatusOK},
+		{"2", "user-123", "", http.StatusNotFound},
+		{"x", "user-123", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/tasks/"+tt.id+"/cancel", nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(tt.id)
+		withCaller(c, tt.userID, tt.role)
+
+		next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+		require.NoError(t, handler.RequireOwner(next)(c))
+		assert.Equal(t, tt.want, rec.Code, tt)
+	}
+}
+
+// TestTaskHandler_RegenerateTask проверяет, что копия задачи получает тот же seed и
+// закреплённую версию шаблона, но новый идентификатор.
+func TestTaskHandler_RegenerateTask(t *testing.T) {
+	handler, service, _, _ := setupTestHandler()
+	source := &models.Task{
+		ID: 1, TaskID: "task-123", UserID: "user-123", Type: "test",
+		TemplateID: "template-456", TemplateVersion: 3, Template: map[string]interface{}{"field": "value"},
+		Amount: 5, Seed: 42, Locale: "de_DE", Format: "csv", Status: models.StatusSucceeded,
+	}
+	service.On("GetTaskByID", mock.Anything, int64(1)).Return(source, nil)
+
+	var created models.Task
+	service.On("CreateNewTask", mock.Anything, mock.AnythingOfType("models.Task")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(models.Task) }).
+		Return(int64(2), nil)
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/tasks/1/regenerate", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	withCaller(c, "user-123", "")
+
+	require.NoError(t, handler.RequireOwner(handler.RegenerateTask)(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	assert.Equal(t, source.Seed, created.Seed)
+	assert.Equal(t, source.TemplateID, created.TemplateID)
+	assert.Equal(t, source.TemplateVersion, created.TemplateVersion)
+	assert.Equal(t, source.Amount, created.Amount)
+	assert.Equal(t, source.Format, created.Format)
+	assert.Equal(t, source.Locale, created.Locale)
+	assert.Equal(t, "user-123", created.UserID)
+	assert.NotEqual(t, source.TaskID, created.TaskID)
+
+	var response models.Task
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, int64(2), response.ID)
+	assert.Equal(t, int64(42), response.Seed)
+}
@@ -0,0 +1,4 @@
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS sql_dialect,
+    DROP COLUMN IF EXISTS table_name,
+    DROP COLUMN IF EXISTS format;
@@ -0,0 +1,4 @@
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS format VARCHAR(10) NOT NULL DEFAULT 'json',
+    ADD COLUMN IF NOT EXISTS table_name VARCHAR(63) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS sql_dialect VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_status;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS error,
+    DROP COLUMN IF EXISTS records_generated;
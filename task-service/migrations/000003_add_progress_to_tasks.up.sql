@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS records_generated INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS error TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks (status);
@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS result_size,
+    DROP COLUMN IF EXISTS result_key;
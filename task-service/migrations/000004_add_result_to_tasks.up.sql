@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS result_key TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS result_size BIGINT NOT NULL DEFAULT 0;
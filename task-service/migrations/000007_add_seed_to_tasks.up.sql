@@ -0,0 +1,2 @@
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS seed BIGINT NOT NULL DEFAULT 0;
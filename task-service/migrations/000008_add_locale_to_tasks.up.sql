@@ -0,0 +1,2 @@
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(16) NOT NULL DEFAULT '';
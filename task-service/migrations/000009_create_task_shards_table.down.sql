@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS task_shards;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS shard_count;
@@ -0,0 +1,16 @@
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS shard_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS task_shards (
+    task_id VARCHAR(36) NOT NULL REFERENCES tasks (task_id) ON DELETE CASCADE,
+    shard_index INTEGER NOT NULL,
+    record_offset INTEGER NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    records_generated INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    result_key TEXT NOT NULL DEFAULT '',
+    result_size BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, shard_index)
+);
@@ -0,0 +1,117 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"task-service/internal/config"
+	"task-service/internal/models"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+// StatusHandler applies task status events received from worker-service.
+type StatusHandler interface {
+	UpdateTaskStatus(ctx context.Context, event models.StatusEvent) error
+}
+
+// StatusConsumer consumes task status events from Kafka.
+type StatusConsumer interface {
+	Consume(ctx context.Context) error
+	Close() error
+}
+
+type statusConsumer struct {
+	reader  *kafka.Reader
+	logger  *zap.SugaredLogger
+	cb      *gobreaker.CircuitBreaker
+	config  config.KafkaConfig
+	handler StatusHandler
+}
+
+// NewStatusConsumer creates a consumer for the task status topic.
+func NewStatusConsumer(cfg config.KafkaConfig, handler StatusHandler, logger *zap.SugaredLogger) StatusConsumer {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "kafka-status-consumer",
+		MaxRequests: 1,
+		Interval:    30 * time.Second,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= 3
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			logger.Infof("circuit breaker %s state changed from %s to %s", name, from.String(), to.String())
+		},
+	})
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     strings.Split(cfg.Brokers, ","),
+		Topic:       cfg.StatusTopic,
+		GroupID:     "task-service-status-group",
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     1 * time.Second,
+		StartOffset: kafka.FirstOffset,
+	})
+
+	return &statusConsumer{
+		reader:  reader,
+		logger:  logger,
+		cb:      cb,
+		config:  cfg,
+		handler: handler,
+	}
+}
+
+func (s *statusConsumer) Consume(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping status consumer due to context cancellation")
+			return nil
+		default:
+			_, err := s.cb.Execute(func() (interface{}, error) {
+				msg, err := s.reader.ReadMessage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read message: %w", err)
+				}
+
+				var event models.StatusEvent
+				if err := json.Unmarshal(msg.Value, &event); err != nil {
+					s.logger.Errorf("Failed to unmarshal status event: %v", err)
+					return nil, nil // Skip bad messages
+				}
+
+				if err := s.handler.UpdateTaskStatus(ctx, event); err != nil {
+					if errors.Is(err, models.ErrInvalidStatusTransition) {
+						s.logger.Warnf("Ignoring status %s for task %s: %v", event.Status, event.TaskID, err)
+						return nil, nil
+					}
+					return nil, fmt.Errorf("failed to apply status event for task %s: %w", event.TaskID, err)
+				}
+				return nil, nil
+			})
+
+			if err != nil && ctx.Err() == nil {
+				s.logger.Errorf("Error consuming status event: %v", err)
+				time.Sleep(time.Duration(s.config.RetryDelay) * time.Second)
+			}
+		}
+	}
+}
+
+func (s *statusConsumer) Close() error {
+	if s.reader != nil {
+		if err := s.reader.Close(); err != nil {
+			s.logger.Errorf("Failed to close status consumer: %v", err)
+			return err
+		}
+		s.logger.Info("Status consumer connection closed")
+	}
+	return nil
+}
@@ -56,7 +56,7 @@ func NewKafkaProducer(ctx context.Context, cfg config.KafkaConfig, logger *zap.S
 		writer = &kafka.Writer{
 			Addr:                   kafka.TCP(brokerList...),
 			Topic:                  cfg.Topic,
-			Balancer:               &kafka.LeastBytes{},
+			Balancer:               &kafka.Hash{},    // messages are spread over partitions by key
 			RequiredAcks:           kafka.RequireAll, // Equivalent to "acks": "all"
 			MaxAttempts:            3,                // Equivalent to "retries": 3
 			BatchTimeout:           1 * time.Second,  // Equivalent to "retry.backoff.ms": 1000
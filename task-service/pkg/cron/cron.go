@@ -0,0 +1,223 @@
+// Package cron parses standard five-field cron expressions and computes their run times.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression. Each field is a bit set of the values it matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record an unrestricted day of month or week: when both days are
+	// restricted, a time matches if either of them does, as in Vixie cron.
+	domStar, dowStar bool
+}
+
+// searchYears bounds the search for the next run of a schedule that never matches,
+// such as the 30th of February.
+const searchYears = 5
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7.
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression of the fields minute, hour, day of month, month and day
+// of week. Fields accept *, values, ranges a-b, lists and steps /n; months and days of
+// week also accept their three-letter English names. The macros @yearly, @monthly,
+// @weekly, @daily and @hourly are supported too.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if s.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parse parses one field into a bit set.
+func (f field) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, part)
+			}
+			rangeExpr, step = part[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if lo, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, part)
+			}
+		default:
+			v, err := f.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			if step > 1 {
+				// a/n means every n-th value starting at a.
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q, expected %d-%d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first run time of the schedule after t, in the location of t, or the
+// zero time if there is none. Run times follow the wall clock of the location: a run time
+// skipped by a daylight saving change moves forward by the length of the change, and a
+// repeated hour runs only once.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	for {
+		if wall = s.next(wall); wall.IsZero() {
+			return wall
+		}
+		if run := local(wall, loc); run.After(t) {
+			return run
+		}
+	}
+}
+
+// next returns the first wall time after wall that the schedule matches. Wall times are
+// kept in UTC, which has no daylight saving changes.
+func (s *Schedule) next(wall time.Time) time.Time {
+	t := wall.Add(time.Minute)
+	limit := t.Year() + searchYears
+
+	// Each loop moves t to the next value of its field, resetting the smaller fields;
+	// when a field wraps around, the larger fields have to be matched again.
+wrap:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+	for s.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
+
+// local returns the time with the wall clock of wall in loc.
+func local(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+	// time.Date moves a wall time skipped by a daylight saving change backwards.
+	if shown := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC); shown.Before(wall) {
+		t = t.Add(wall.Sub(shown))
+	}
+	return t
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
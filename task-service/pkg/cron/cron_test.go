@@ -0,0 +1,75 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchedule_Next проверяет расчёт следующего запуска для типичных выражений.
+func TestSchedule_Next(t *testing.T) {
+	from := time.Date(2025, time.March, 14, 10, 17, 42, 0, time.UTC) // пятница
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.March, 14, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, time.March, 14, 10, 30, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, time.March, 15, 3, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, time.March, 14, 11, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2025, time.March, 17, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * 5", time.Date(2025, time.March, 14, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"5,10 8-9 * dec 7", time.Date(2025, time.December, 7, 8, 5, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(from))
+		})
+	}
+}
+
+// TestSchedule_NextTimezone проверяет запуск по местному времени, включая переход на летнее время.
+func TestSchedule_NextTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	s, err := Parse("30 2 * * *")
+	require.NoError(t, err)
+
+	// 9 марта 2025 года 02:30 не существует: часы переводятся с 02:00 на 03:00.
+	next := s.Next(time.Date(2025, time.March, 9, 0, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2025, time.March, 9, 7, 30, 0, 0, time.UTC), next.UTC())
+
+	// 2 ноября 2025 года час 01:00–02:00 повторяется, но запуск выполняется один раз.
+	s, err = Parse("30 1 * * *")
+	require.NoError(t, err)
+	first := s.Next(time.Date(2025, time.November, 2, 0, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2025, time.November, 2, 5, 30, 0, 0, time.UTC), first.UTC())
+	assert.Equal(t, time.Date(2025, time.November, 3, 1, 30, 0, 0, loc), s.Next(first))
+
+	next = s.Next(time.Date(2025, time.June, 1, 12, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2025, time.June, 2, 5, 30, 0, 0, time.UTC), next.UTC())
+}
+
+// TestSchedule_NextNever проверяет выражение, которое никогда не срабатывает.
+func TestSchedule_NextNever(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			assert.Error(t, err)
+		})
+	}
+}
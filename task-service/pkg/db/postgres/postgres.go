@@ -103,6 +103,16 @@ func (db *DB) Exec(ctx context.Context, sql string, args ...interface{}) error {
 	return err
 }
 
+func (db *DB) Begin(ctx context.Context) (pgx.Tx, error) {
+	result, err := db.cb.Execute(func() (interface{}, error) {
+		return db.pool.Begin(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(pgx.Tx), nil
+}
+
 type errorRow struct {
 	err error
 }
@@ -13,6 +13,7 @@ import (
 type RedisClient interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Del(ctx context.Context, keys ...string) error
 	Close() error
 }
 
@@ -49,3 +50,7 @@ func (r *Redis) Get(ctx context.Context, key string) (string, error) {
 func (r *Redis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	return r.Client.Set(ctx, key, value, expiration).Err()
 }
+
+func (r *Redis) Del(ctx context.Context, keys ...string) error {
+	return r.Client.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type localStore struct {
+	dir    string
+	logger *zap.SugaredLogger
+}
+
+// NewLocalStore creates an ArtifactStore reading from a directory shared with worker-service.
+func NewLocalStore(dir string, logger *zap.SugaredLogger) ArtifactStore {
+	return &localStore{dir: dir, logger: logger}
+}
+
+func (s *localStore) Open(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	clean := filepath.Clean("/" + key)
+	if strings.Contains(key, "..") || clean == "/" {
+		return nil, 0, fmt.Errorf("invalid artifact key %q", key)
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, clean))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open artifact %s: %w", key, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("failed to stat artifact %s: %w", key, err)
+	}
+
+	return f, info.Size(), nil
+}
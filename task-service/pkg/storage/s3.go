@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"task-service/internal/config"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.uber.org/zap"
+)
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+	logger *zap.SugaredLogger
+}
+
+// NewS3Store creates an ArtifactStore backed by an S3-compatible bucket (AWS S3, MinIO, ...).
+func NewS3Store(ctx context.Context, cfg config.StorageConfig, logger *zap.SugaredLogger) (ArtifactStore, error) {
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	if _, err := client.BucketExists(ctx, cfg.S3Bucket); err != nil {
+		return nil, fmt.Errorf("failed to check bucket %s: %w", cfg.S3Bucket, err)
+	}
+
+	return &s3Store{client: client, bucket: cfg.S3Bucket, logger: logger}, nil
+}
+
+func (s *s3Store) Open(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get artifact %s: %w", key, err)
+	}
+
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, 0, ErrNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to stat artifact %s: %w", key, err)
+	}
+
+	return obj, info.Size, nil
+}
+
+func (s *s3Store) PresignGet(ctx context.Context, key, fileName string, ttl time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, ttl, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign artifact %s: %w", key, err)
+	}
+	return u.String(), nil
+}
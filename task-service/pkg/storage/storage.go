@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"task-service/internal/config"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrNotFound is returned when an artifact does not exist.
+var ErrNotFound = errors.New("artifact not found")
+
+// ArtifactStore reads task results written by worker-service.
+type ArtifactStore interface {
+	Open(ctx context.Context, key string) (io.ReadCloser, int64, error)
+}
+
+// Presigner is implemented by stores that can issue short-lived download URLs themselves.
+type Presigner interface {
+	PresignGet(ctx context.Context, key, fileName string, ttl time.Duration) (string, error)
+}
+
+// New creates the artifact store selected by cfg.Backend.
+func New(ctx context.Context, cfg config.StorageConfig, logger *zap.SugaredLogger) (ArtifactStore, error) {
+	switch cfg.Backend {
+	case config.StorageLocal:
+		return NewLocalStore(cfg.LocalDir, logger), nil
+	case config.StorageS3:
+		return NewS3Store(ctx, cfg, logger)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
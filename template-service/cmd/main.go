@@ -1,12 +1,14 @@
 package main
 
 import (
+	"authclient"
 	"context"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"template-service/internal/config"
+	"template-service/internal/middleware"
 	"template-service/internal/migrations"
 	"template-service/internal/repository"
 	"template-service/internal/routes"
@@ -57,6 +59,13 @@ func main() {
 	}
 	defer redisClient.Close()
 
+	//init auth client
+	authClient := authclient.New(
+		cfg.Auth.IntrospectURL,
+		&http.Client{Timeout: time.Duration(cfg.Auth.Timeout) * time.Second},
+		time.Duration(cfg.Auth.CacheTTL)*time.Second,
+	)
+
 	//init router
 	routerConfig := http_transport.NewRouterConfig(cfg)
 	router := http_transport.NewRouter(routerConfig, log)
@@ -71,7 +80,7 @@ func main() {
 	taskHandler := handlers.NewTemplateHandler(taskService, log.SugaredLogger)
 
 	//init routes
-	routes.SetupTemplateRoutes(router.Echo(), taskHandler)
+	routes.SetupTemplateRoutes(router.Echo(), taskHandler, middleware.AuthMiddleware(authClient))
 
 	//run server
 	go func() {
@@ -38,11 +38,20 @@ type RedisConfig struct {
 	RetryDelay int    `yaml:"retry_delay" env:"REDIS_RETRY_DELAY" env-default:"3" validate:"gte=1"`
 }
 
+// AuthConfig points at the token introspection endpoint of auth-service. Active tokens
+// are cached for CacheTTL seconds, so a logout takes up to that long to take effect.
+type AuthConfig struct {
+	IntrospectURL string `yaml:"introspect_url" env:"AUTH_INTROSPECT_URL" env-default:"http://auth-service:8080/auth/introspect" validate:"required,url"`
+	Timeout       int    `yaml:"timeout" env:"AUTH_TIMEOUT" env-default:"5" validate:"gte=1"`
+	CacheTTL      int    `yaml:"cache_ttl" env:"AUTH_CACHE_TTL" env-default:"30" validate:"gte=0"`
+}
+
 type Config struct {
 	Env        string         `yaml:"env" env:"ENV" env-default:"prod" validate:"oneof=dev prod test"`
 	HTTPServer HTTPServer     `yaml:"http_server" validate:"required"`
 	Postgres   PostgresConfig `yaml:"postgres" validate:"required"`
 	Redis      RedisConfig    `yaml:"redis" validate:"required"`
+	Auth       AuthConfig     `yaml:"auth" validate:"required"`
 }
 
 func New() (*Config, error) {
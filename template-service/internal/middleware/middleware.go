@@ -1,65 +1,66 @@
 package middleware
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"net/http"
 
+	"authclient"
+
 	"github.com/labstack/echo"
-	"github.com/stretchr/testify/mock"
 )
 
-type ValidateTokenResponse struct {
-	UserID string `json:"user_id"`
-	Valid  bool   `json:"valid"`
-}
-
-type HTTPClient interface {
-	Get(url string) (*http.Response, error)
-}
-
-type mockHTTPClient struct {
-	mock.Mock
-}
-
-func (m *mockHTTPClient) Get(url string) (*http.Response, error) {
-	args := m.Called(url)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*http.Response), args.Error(1)
-}
+// Scopes of personal API keys used by template-service. Access tokens of a login have no
+// scopes and may do everything their user may.
+const (
+	ScopeTemplatesRead  = "templates:read"
+	ScopeTemplatesWrite = "templates:write"
+)
 
-func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return AuthMiddlewareWithClient(next, http.DefaultClient)
+// TokenIntrospector checks an access token with auth-service.
+type TokenIntrospector interface {
+	Introspect(ctx context.Context, token string) (*authclient.Introspection, error)
 }
 
-func AuthMiddlewareWithClient(next echo.HandlerFunc, client HTTPClient) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		token := c.Request().Header.Get("Authorization")
-		if token == "" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Missing Authorization header"})
-		}
-
-		resp, err := client.Get("http://auth-service:8080/auth/validate?token=" + token)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
-		}
-		defer resp.Body.Close()
+// AuthMiddleware accepts requests bearing an access token or a personal API key that
+// auth-service reports as active. It stores the user it was issued to as "user_id" and
+// the introspection as "auth" for RequireScope.
+func AuthMiddleware(introspector TokenIntrospector) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := authclient.TokenFromHeader(c.Request().Header)
+			if token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, map[string]string{"error": "Missing bearer token or API key"})
+			}
 
-		if resp.StatusCode != http.StatusOK {
-			return echo.NewHTTPError(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
-		}
+			introspection, err := introspector.Introspect(c.Request().Context(), token)
+			switch {
+			case errors.Is(err, authclient.ErrInactive):
+				return echo.NewHTTPError(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+			case err != nil:
+				c.Logger().Errorf("Failed to introspect token: %v", err)
+				return echo.NewHTTPError(http.StatusServiceUnavailable, map[string]string{"error": "Failed to verify token"})
+			case introspection.UserID == "":
+				return echo.NewHTTPError(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+			}
 
-		var validateResp ValidateTokenResponse
-		if err := json.NewDecoder(resp.Body).Decode(&validateResp); err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, map[string]string{"error": "Failed to parse token validation response"})
+			c.Set("user_id", introspection.UserID)
+			c.Set("auth", introspection)
+			return next(c)
 		}
+	}
+}
 
-		if !validateResp.Valid {
-			return echo.NewHTTPError(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+// RequireScope rejects requests made with an API key that was not granted scope. It must
+// run after AuthMiddleware.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			introspection, ok := c.Get("auth").(*authclient.Introspection)
+			if !ok || !introspection.Allows(scope) {
+				return echo.NewHTTPError(http.StatusForbidden, map[string]string{"error": "API key lacks scope " + scope})
+			}
+			return next(c)
 		}
-
-		c.Set("user_id", validateResp.UserID)
-		return next(c)
 	}
 }
@@ -1,112 +1,100 @@
 package middleware
 
 import (
-	"bytes"
+	"context"
 	"errors"
-	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"authclient"
+
 	"github.com/labstack/echo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+type mockIntrospector struct {
+	mock.Mock
+}
+
+func (m *mockIntrospector) Introspect(ctx context.Context, token string) (*authclient.Introspection, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authclient.Introspection), args.Error(1)
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	e := echo.New()
 
 	tests := []struct {
 		name           string
+		header         string
 		token          string
-		mockResponse   *http.Response
-		mockError      error
-		expectCall     bool
+		introspection  *authclient.Introspection
+		introspectErr  error
 		expectedStatus int
 		expectedBody   string
 	}{
 		{
 			name:           "missing token",
-			token:          "",
-			expectCall:     false,
-			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"Missing Authorization header"}`,
-		},
-		{
-			name:           "auth service error",
-			token:          "valid.token.here",
-			mockError:      errors.New("service unavailable"),
-			expectCall:     true,
+			header:         "",
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"Invalid token"}`,
+			expectedBody:   `{"error":"Missing bearer token or API key"}`,
 		},
 		{
-			name:           "invalid token response",
-			token:          "invalid.token.here",
-			mockResponse:   &http.Response{StatusCode: http.StatusUnauthorized},
-			expectCall:     true,
+			name:           "not a bearer token",
+			header:         "valid.token",
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"Invalid token"}`,
+			expectedBody:   `{"error":"Missing bearer token or API key"}`,
 		},
 		{
-			name:  "invalid json response",
-			token: "bad.json.token",
-			mockResponse: &http.Response{
-				StatusCode: http.StatusOK,
-				Body:       io.NopCloser(bytes.NewBufferString("invalid json")),
-			},
-			expectCall:     true,
-			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"Failed to parse token validation response"}`,
+			name:           "auth service error",
+			header:         "Bearer valid.token.here",
+			token:          "valid.token.here",
+			introspectErr:  errors.New("service unavailable"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"Failed to verify token"}`,
 		},
 		{
-			name:  "invalid token",
-			token: "invalid.token",
-			mockResponse: &http.Response{
-				StatusCode: http.StatusOK,
-				Body:       io.NopCloser(bytes.NewBufferString(`{"user_id":"123","valid":false}`)),
-			},
-			expectCall:     true,
+			name:           "inactive token",
+			header:         "Bearer invalid.token",
+			token:          "invalid.token",
+			introspectErr:  authclient.ErrInactive,
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   `{"error":"Invalid token"}`,
 		},
 		{
-			name:  "valid token",
-			token: "valid.token",
-			mockResponse: &http.Response{
-				StatusCode: http.StatusOK,
-				Body:       io.NopCloser(bytes.NewBufferString(`{"user_id":"123","valid":true}`)),
-			},
-			expectCall:     true,
+			name:           "valid token",
+			header:         "Bearer valid.token",
+			token:          "valid.token",
+			introspection:  &authclient.Introspection{Active: true, UserID: "123"},
 			expectedStatus: http.StatusOK,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockClient := new(mockHTTPClient)
-
-			if tt.expectCall {
-				mockClient.On("Get", "http://auth-service:8080/auth/validate?token="+tt.token).
-					Return(tt.mockResponse, tt.mockError)
+			introspector := new(mockIntrospector)
+			if tt.token != "" {
+				introspector.On("Introspect", tt.token).Return(tt.introspection, tt.introspectErr)
 			}
 
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
-			if tt.token != "" {
-				req.Header.Set("Authorization", tt.token)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
 			}
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 
 			testHandler := func(c echo.Context) error {
-				if tt.expectedStatus == http.StatusOK {
-					userID := c.Get("user_id").(string)
-					assert.Equal(t, "123", userID)
-				}
+				assert.Equal(t, "123", c.Get("user_id"))
 				return c.String(http.StatusOK, "OK")
 			}
 
-			handler := AuthMiddlewareWithClient(testHandler, mockClient)
-			err := handler(c)
+			err := AuthMiddleware(introspector)(testHandler)(c)
 
 			if tt.expectedStatus != http.StatusOK {
 				if assert.Error(t, err) {
@@ -125,8 +113,106 @@ func TestAuthMiddleware(t *testing.T) {
 				assert.Equal(t, http.StatusOK, rec.Code)
 			}
 
-			if tt.expectCall {
-				mockClient.AssertExpectations(t)
+			introspector.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthMiddleware_APIKeyHeader(t *testing.T) {
+	e := echo.New()
+	introspection := &authclient.Introspection{Active: true, UserID: "123", TokenType: authclient.TokenTypeAPIKey, Scope: "templates:read"}
+	introspector := new(mockIntrospector)
+	introspector.On("Introspect", "tsk_ci").Return(introspection, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(authclient.APIKeyHeader, "tsk_ci")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := AuthMiddleware(introspector)(func(c echo.Context) error {
+		assert.Equal(t, "123", c.Get("user_id"))
+		assert.Equal(t, introspection, c.Get("auth"))
+		return c.String(http.StatusOK, "OK")
+	})(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	introspector.AssertExpectations(t)
+}
+
+func TestAuthMiddleware_APIKeyWithNonBearerAuthorization(t *testing.T) {
+	e := echo.New()
+	introspection := &authclient.Introspection{Active: true, UserID: "123", TokenType: authclient.TokenTypeAPIKey, Scope: "templates:read"}
+	introspector := new(mockIntrospector)
+	introspector.On("Introspect", "tsk_ci").Return(introspection, nil)
+
+	// a proxy in front of the service may add its own Basic credentials
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	req.Header.Set(authclient.APIKeyHeader, "tsk_ci")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := AuthMiddleware(introspector)(func(c echo.Context) error {
+		assert.Equal(t, "123", c.Get("user_id"))
+		return c.String(http.StatusOK, "OK")
+	})(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	introspector.AssertExpectations(t)
+}
+
+func TestRequireScope(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name          string
+		introspection *authclient.Introspection
+		expectedCode  int
+	}{
+		{
+			name:          "login token",
+			introspection: &authclient.Introspection{Active: true, UserID: "123", TokenType: "Bearer"},
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name:          "api key with scope",
+			introspection: &authclient.Introspection{Active: true, UserID: "123", TokenType: authclient.TokenTypeAPIKey, Scope: "tasks:write templates:read"},
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name:          "api key without scope",
+			introspection: &authclient.Introspection{Active: true, UserID: "123", TokenType: authclient.TokenTypeAPIKey, Scope: "tasks:write"},
+			expectedCode:  http.StatusForbidden,
+		},
+		{
+			name:         "not authenticated",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.introspection != nil {
+				c.Set("auth", tt.introspection)
+			}
+
+			err := RequireScope(ScopeTemplatesRead)(func(c echo.Context) error {
+				return c.String(http.StatusOK, "OK")
+			})(c)
+
+			if tt.expectedCode == http.StatusOK {
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+			he, ok := err.(*echo.HTTPError)
+			if assert.True(t, ok, "Expected HTTPError") {
+				assert.Equal(t, tt.expectedCode, he.Code)
 			}
 		})
 	}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_templates_user_id;
+
+ALTER TABLE templates DROP COLUMN IF EXISTS deleted_at;
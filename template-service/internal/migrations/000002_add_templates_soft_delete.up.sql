@@ -0,0 +1,3 @@
+ALTER TABLE templates ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_templates_user_id ON templates (user_id, created_at DESC) WHERE deleted_at IS NULL;
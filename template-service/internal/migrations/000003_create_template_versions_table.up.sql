@@ -0,0 +1,16 @@
+ALTER TABLE templates ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS template_versions (
+  template_id BIGINT NOT NULL REFERENCES templates (id),
+  version INTEGER NOT NULL,
+  title VARCHAR(36),
+  content JSONB NOT NULL,
+  created_by VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (template_id, version)
+);
+
+-- Existing templates become revision 1.
+INSERT INTO template_versions (template_id, version, title, content, created_by, created_at)
+SELECT id, version, title, content, user_id, updated_at FROM templates
+ON CONFLICT DO NOTHING;
@@ -1,6 +1,15 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrVersionNotFound  = errors.New("template version not found")
+	ErrForbidden        = errors.New("template belongs to another user")
+)
 
 type Template struct {
 	ID         int64                  `json:"id" db:"id"`
@@ -8,11 +17,36 @@ type Template struct {
 	UserID     string                 `json:"user_id" db:"user_id"`
 	Title      string                 `json:"title" db:"title"`
 	Content    map[string]interface{} `json:"content" db:"content"`
+	Version    int                    `json:"version" db:"version"`
 	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time              `json:"updated_at" db:"updated_at"`
 }
 
+// TemplateVersion is an immutable revision of a template. Every create and
+// update of a template stores a new revision; tasks pin the revision they used.
+type TemplateVersion struct {
+	ID        int64                  `json:"id" db:"template_id"`
+	Version   int                    `json:"version" db:"version"`
+	Title     string                 `json:"title" db:"title"`
+	Content   map[string]interface{} `json:"content,omitempty" db:"content"`
+	CreatedBy string                 `json:"created_by" db:"created_by"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+}
+
 type CreateTemplateRequest struct {
-	Title   string                 `json:"title" validate:"required"`
+	Title   string                 `json:"title" validate:"required,max=36"`
 	Content map[string]interface{} `json:"content" validate:"required"`
 }
+
+// PatchTemplateRequest changes only the fields that are present.
+type PatchTemplateRequest struct {
+	Title   *string                `json:"title" validate:"omitempty,min=1,max=36"`
+	Content map[string]interface{} `json:"content"`
+}
+
+type TemplateFilter struct {
+	UserID string
+	Search string
+	Page   int
+	Limit  int
+}
@@ -2,15 +2,25 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"template-service/internal/models"
 	"template-service/pkg/db/postgres"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
 type TemplateRepository interface {
 	GetTemplateByID(ctx context.Context, id int64) (*models.Template, error)
 	CreateNewTemplate(ctx context.Context, template models.Template) (int64, error)
+	UpdateTemplate(ctx context.Context, template models.Template) (int, error)
+	DeleteTemplate(ctx context.Context, id int64) error
+	ListTemplates(ctx context.Context, filter models.TemplateFilter) ([]models.Template, int, error)
+	ListVersions(ctx context.Context, id int64) ([]models.TemplateVersion, error)
+	GetVersion(ctx context.Context, id int64, version int) (*models.TemplateVersion, error)
 }
 
 type templateRepository struct {
@@ -22,31 +32,63 @@ func NewTemplateRepository(pool *postgres.DB, logger *zap.SugaredLogger) *templa
 	return &templateRepository{db: pool, logger: logger}
 }
 
+// CreateNewTemplate stores the template together with its first revision.
 func (t *templateRepository) CreateNewTemplate(ctx context.Context, template models.Template) (int64, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		t.logger.Errorf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO templates (template_id, user_id, title, content, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO templates (template_id, user_id, title, content, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 1, $5, $6)
 		RETURNING id
 		`
 	var id int64
-	err := t.db.QueryRow(ctx, query, template.TemplateID, template.UserID, template.Title, template.Content, template.CreatedAt, template.UpdatedAt).Scan(&id)
+	err = tx.QueryRow(ctx, query, template.TemplateID, template.UserID, template.Title, template.Content, template.CreatedAt, template.UpdatedAt).Scan(&id)
 	if err != nil {
 		t.logger.Errorf("Failed to insert template: %v", err)
 		return 0, err
 	}
 
+	template.ID = id
+	template.Version = 1
+	if err := insertVersion(ctx, tx, template); err != nil {
+		t.logger.Errorf("Failed to insert first version of template %d: %v", id, err)
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.logger.Errorf("Failed to commit template %d: %v", id, err)
+		return 0, err
+	}
+
 	return id, nil
 }
 
+func insertVersion(ctx context.Context, tx pgx.Tx, template models.Template) error {
+	query := `
+		INSERT INTO template_versions (template_id, version, title, content, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		`
+	_, err := tx.Exec(ctx, query, template.ID, template.Version, template.Title, template.Content, template.UserID, template.UpdatedAt)
+	return err
+}
+
 func (t *templateRepository) GetTemplateByID(ctx context.Context, id int64) (*models.Template, error) {
 	query := `
-		SELECT id, template_id, user_id, title, content, created_at, updated_at
+		SELECT id, template_id, user_id, title, content, version, created_at, updated_at
 		FROM templates
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 		`
 
 	var template models.Template
-	err := t.db.QueryRow(ctx, query, id).Scan(&template.ID, &template.TemplateID, &template.UserID, &template.Title, &template.Content, &template.CreatedAt, &template.UpdatedAt)
+	err := t.db.QueryRow(ctx, query, id).Scan(&template.ID, &template.TemplateID, &template.UserID, &template.Title, &template.Content, &template.Version, &template.CreatedAt, &template.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrTemplateNotFound
+	}
 	if err != nil {
 		t.logger.Errorf("Failed to get template: %v", err)
 		return nil, err
@@ -54,3 +96,172 @@ func (t *templateRepository) GetTemplateByID(ctx context.Context, id int64) (*mo
 
 	return &template, nil
 }
+
+// UpdateTemplate stores the template's title and content as a new revision
+// and makes it the current one. It returns the new version number.
+func (t *templateRepository) UpdateTemplate(ctx context.Context, template models.Template) (int, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		t.logger.Errorf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE templates
+		SET title = $1, content = $2, version = version + 1, updated_at = $3
+		WHERE id = $4 AND deleted_at IS NULL
+		RETURNING version
+		`
+
+	err = tx.QueryRow(ctx, query, template.Title, template.Content, template.UpdatedAt, template.ID).Scan(&template.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, models.ErrTemplateNotFound
+	}
+	if err != nil {
+		t.logger.Errorf("Failed to update template %d: %v", template.ID, err)
+		return 0, err
+	}
+
+	if err := insertVersion(ctx, tx, template); err != nil {
+		t.logger.Errorf("Failed to insert version %d of template %d: %v", template.Version, template.ID, err)
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.logger.Errorf("Failed to commit template %d: %v", template.ID, err)
+		return 0, err
+	}
+
+	return template.Version, nil
+}
+
+// DeleteTemplate soft-deletes a template: it disappears from reads but stays in the table.
+func (t *templateRepository) DeleteTemplate(ctx context.Context, id int64) error {
+	query := `
+		UPDATE templates
+		SET deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING id
+		`
+
+	var deleted int64
+	err := t.db.QueryRow(ctx, query, time.Now(), id).Scan(&deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrTemplateNotFound
+	}
+	if err != nil {
+		t.logger.Errorf("Failed to delete template %d: %v", id, err)
+		return err
+	}
+
+	return nil
+}
+
+// ListTemplates returns one page of templates matching the filter and the total number of matches.
+func (t *templateRepository) ListTemplates(ctx context.Context, filter models.TemplateFilter) ([]models.Template, int, error) {
+	query := `
+		SELECT id, template_id, user_id, title, content, version, created_at, updated_at, COUNT(*) OVER()
+		FROM templates
+		WHERE deleted_at IS NULL`
+
+	args := make([]interface{}, 0, 4)
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if filter.Search != "" {
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+		query += fmt.Sprintf(" AND title ILIKE $%d", len(args))
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := t.db.Query(ctx, query, args...)
+	if err != nil {
+		t.logger.Errorf("Failed to query templates: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	templates := []models.Template{}
+	total := 0
+	for rows.Next() {
+		var template models.Template
+		if err := rows.Scan(&template.ID, &template.TemplateID, &template.UserID, &template.Title, &template.Content, &template.Version, &template.CreatedAt, &template.UpdatedAt, &total); err != nil {
+			t.logger.Errorf("Failed to scan template row: %v", err)
+			return nil, 0, err
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		t.logger.Errorf("Error during template iteration: %v", err)
+		return nil, 0, err
+	}
+
+	return templates, total, nil
+}
+
+// ListVersions returns the revisions of a template, newest first, without their content.
+// Revisions of deleted templates stay readable so that old tasks can be reproduced.
+func (t *templateRepository) ListVersions(ctx context.Context, id int64) ([]models.TemplateVersion, error) {
+	query := `
+		SELECT template_id, version, title, created_by, created_at
+		FROM template_versions
+		WHERE template_id = $1
+		ORDER BY version DESC
+		`
+
+	rows, err := t.db.Query(ctx, query, id)
+	if err != nil {
+		t.logger.Errorf("Failed to query versions of template %d: %v", id, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.TemplateVersion{}
+	for rows.Next() {
+		var v models.TemplateVersion
+		if err := rows.Scan(&v.ID, &v.Version, &v.Title, &v.CreatedBy, &v.CreatedAt); err != nil {
+			t.logger.Errorf("Failed to scan template version row: %v", err)
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		t.logger.Errorf("Error during template version iteration: %v", err)
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, models.ErrTemplateNotFound
+	}
+	return versions, nil
+}
+
+func (t *templateRepository) GetVersion(ctx context.Context, id int64, version int) (*models.TemplateVersion, error) {
+	query := `
+		SELECT template_id, version, title, content, created_by, created_at
+		FROM template_versions
+		WHERE template_id = $1 AND version = $2
+		`
+
+	var v models.TemplateVersion
+	err := t.db.QueryRow(ctx, query, id, version).Scan(&v.ID, &v.Version, &v.Title, &v.Content, &v.CreatedBy, &v.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrVersionNotFound
+	}
+	if err != nil {
+		t.logger.Errorf("Failed to get version %d of template %d: %v", version, id, err)
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
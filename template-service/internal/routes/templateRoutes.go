@@ -7,10 +7,19 @@ import (
 	"github.com/labstack/echo"
 )
 
-func SetupTemplateRoutes(router *echo.Echo, templateHandler *handlers.TemplateHandler) {
-	group := router.Group("/templates", middleware.AuthMiddleware)
+func SetupTemplateRoutes(router *echo.Echo, templateHandler *handlers.TemplateHandler, auth echo.MiddlewareFunc) {
+	read := middleware.RequireScope(middleware.ScopeTemplatesRead)
+	write := middleware.RequireScope(middleware.ScopeTemplatesWrite)
+
+	group := router.Group("/templates", auth)
 	{
-		group.POST("", templateHandler.CreateNewTemplate)
-		group.GET("/:id", templateHandler.GetTemplateByID)
+		group.POST("", templateHandler.CreateNewTemplate, write)
+		group.GET("", templateHandler.ListTemplates, read)
+		group.GET("/:id", templateHandler.GetTemplateByID, read)
+		group.PUT("/:id", templateHandler.UpdateTemplate, write)
+		group.PATCH("/:id", templateHandler.UpdateTemplate, write)
+		group.DELETE("/:id", templateHandler.DeleteTemplate, write)
+		group.GET("/:id/versions", templateHandler.ListVersions, read)
+		group.GET("/:id/versions/:version", templateHandler.GetVersion, read)
 	}
 }
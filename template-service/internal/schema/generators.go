@@ -0,0 +1,240 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// argsRule validates the arguments of a generator spec.
+type argsRule func(args []string) error
+
+// Generators maps the generator names known to worker-service to their argument rules.
+var Generators = map[string]argsRule{
+	"uuid":        noArgs,
+	"name":        localeArgs,
+	"first_name":  localeArgs,
+	"last_name":   localeArgs,
+	"patronymic":  localeArgs,
+	"city":        localeArgs,
+	"street":      localeArgs,
+	"postal_code": localeArgs,
+	"address":     localeArgs,
+	"phone":       localeArgs,
+	"company":     localeArgs,
+	"email":       noArgs,
+	"word":        noArgs,
+	"bool":        noArgs,
+	"int":         rangeArgs(parseInt),
+	"float":       floatArgs,
+	"string":      lengthArgs,
+	"enum":        enumArgs,
+	"date":        rangeArgs(parseDate),
+	"datetime":    rangeArgs(parseDate),
+	"inn":         identifierArgs(oneOf("10", "12")),
+	"snils":       identifierArgs(noArgs),
+	"ogrn":        identifierArgs(noArgs),
+	"ogrnip":      identifierArgs(noArgs),
+	"passport":    identifierArgs(noArgs),
+	"iban":        identifierArgs(oneOf("ch", "de", "es", "fr", "gb", "it", "nl")),
+	"card":        identifierArgs(oneOf("amex", "discover", "mastercard", "mir", "unionpay", "visa")),
+	"ssn":         identifierArgs(noArgs),
+	"ean13":       identifierArgs(eanArgs),
+}
+
+// Locales lists the locale packs shipped with worker-service. Regional packs fall back
+// to the main pack of their language and every language falls back to en_US.
+var Locales = []string{"en_US", "en_GB", "ru_RU", "de_DE", "de_AT"}
+
+// ValidLocale reports whether code names a known locale. Codes are case-insensitive,
+// may use '-' instead of '_' and may be a bare language such as "ru".
+func ValidLocale(code string) bool {
+	code = strings.ReplaceAll(strings.TrimSpace(code), "-", "_")
+	for _, locale := range Locales {
+		lang, _, _ := strings.Cut(locale, "_")
+		if strings.EqualFold(code, locale) || strings.EqualFold(code, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSpec checks a generator spec such as "name", "int(1,100)" or the legacy "{{name}}".
+func ValidateSpec(raw string) error {
+	name, args, err := parseSpec(raw)
+	if err != nil {
+		return err
+	}
+	rule, ok := Generators[name]
+	if !ok {
+		return fmt.Errorf("unknown generator %q", name)
+	}
+	if err := rule(args); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func parseSpec(raw string) (string, []string, error) {
+	s := strings.TrimSpace(raw)
+	if strings.HasPrefix(s, "{{") && strings.HasSuffix(s, "}}") {
+		s = strings.TrimSpace(s[2 : len(s)-2])
+	}
+	if s == "" {
+		return "", nil, fmt.Errorf("empty generator spec")
+	}
+
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		return strings.ToLower(s), nil, nil
+	}
+	if !strings.HasSuffix(s, ")") {
+		return "", nil, fmt.Errorf("malformed generator spec %q: missing closing parenthesis", raw)
+	}
+	name := strings.ToLower(strings.TrimSpace(s[:open]))
+	if name == "" {
+		return "", nil, fmt.Errorf("malformed generator spec %q: missing generator name", raw)
+	}
+
+	var args []string
+	if inner := strings.TrimSpace(s[open+1 : len(s)-1]); inner != "" {
+		for _, arg := range strings.Split(inner, ",") {
+			args = append(args, strings.TrimSpace(arg))
+		}
+	}
+	return name, args, nil
+}
+
+func noArgs(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("takes no arguments, got %d", len(args))
+	}
+	return nil
+}
+
+// localeArgs accepts either no arguments or a single locale, as in "name(ru_RU)".
+func localeArgs(args []string) error {
+	switch len(args) {
+	case 0:
+		return nil
+	case 1:
+		if !ValidLocale(args[0]) {
+			return fmt.Errorf("unknown locale %q", args[0])
+		}
+		return nil
+	default:
+		return fmt.Errorf("expects (locale), got %d arguments", len(args))
+	}
+}
+
+// identifierArgs accepts a trailing "invalid" argument, which asks for identifiers
+// that fail validation, followed by the arguments accepted by rule.
+func identifierArgs(rule argsRule) argsRule {
+	return func(args []string) error {
+		if len(args) > 0 && strings.EqualFold(args[len(args)-1], "invalid") {
+			args = args[:len(args)-1]
+		}
+		return rule(args)
+	}
+}
+
+// oneOf accepts either no arguments or one of values, case-insensitively.
+func oneOf(values ...string) argsRule {
+	return func(args []string) error {
+		switch len(args) {
+		case 0:
+			return nil
+		case 1:
+			for _, v := range values {
+				if strings.EqualFold(args[0], v) {
+					return nil
+				}
+			}
+			return fmt.Errorf("expects one of %s, got %q", strings.Join(values, ", "), args[0])
+		default:
+			return fmt.Errorf("expects at most one argument, got %d", len(args))
+		}
+	}
+}
+
+// eanArgs accepts an optional prefix of 1 to 12 digits.
+func eanArgs(args []string) error {
+	switch len(args) {
+	case 0:
+		return nil
+	case 1:
+		if len(args[0]) == 0 || len(args[0]) > 12 || strings.Trim(args[0], "0123456789") != "" {
+			return fmt.Errorf("invalid prefix %q, expected 1 to 12 digits", args[0])
+		}
+		return nil
+	default:
+		return fmt.Errorf("expects (prefix), got %d arguments", len(args))
+	}
+}
+
+func parseInt(s string) (float64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	return float64(n), err
+}
+
+func parseDate(s string) (float64, error) {
+	t, err := time.Parse(dateLayout, s)
+	return float64(t.Unix()), err
+}
+
+// rangeArgs accepts either no arguments or (min,max) parsed by parse.
+func rangeArgs(parse func(string) (float64, error)) argsRule {
+	return func(args []string) error {
+		switch len(args) {
+		case 0:
+			return nil
+		case 2:
+		default:
+			return fmt.Errorf("expects (min,max), got %d arguments", len(args))
+		}
+		min, err := parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid min %q", args[0])
+		}
+		max, err := parse(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid max %q", args[1])
+		}
+		if min > max {
+			return fmt.Errorf("min %s is greater than max %s", args[0], args[1])
+		}
+		return nil
+	}
+}
+
+func floatArgs(args []string) error {
+	if len(args) == 3 {
+		if p, err := strconv.Atoi(args[2]); err != nil || p < 0 || p > maxPrecision {
+			return fmt.Errorf("invalid precision %q", args[2])
+		}
+		args = args[:2]
+	}
+	return rangeArgs(func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })(args)
+}
+
+func lengthArgs(args []string) error {
+	switch len(args) {
+	case 0:
+		return nil
+	case 1:
+		if n, err := strconv.Atoi(args[0]); err != nil || n <= 0 {
+			return fmt.Errorf("invalid length %q", args[0])
+		}
+		return nil
+	default:
+		return fmt.Errorf("expects (length), got %d arguments", len(args))
+	}
+}
+
+func enumArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expects at least one value")
+	}
+	return nil
+}
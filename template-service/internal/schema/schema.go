@@ -0,0 +1,647 @@
+// Package schema validates template content against the template schema DSL.
+//
+// Version 1 of the DSL describes a record as a set of named fields:
+//
+//	{
+//	  "schema_version": 1,
+//	  "fields": {
+//	    "id":      "uuid",
+//	    "age":     {"type": "int", "min": 18, "max": 65},
+//	    "email":   {"type": "string", "generator": "email", "nullable": 0.1},
+//	    "code":    {"type": "string", "regex": "[A-Z]{3}-\\d{4}"},
+//	    "role":    {"type": "string", "enum": ["admin", "user"]},
+//	    "address": {"type": "object", "fields": {"city": "word"}},
+//	    "tags":    {"type": "array", "items": "word", "min_items": 1, "max_items": 3}
+//	  }
+//	}
+//
+// A field is either a generator spec string ("name", "int(1,100)", see Generators)
+// or a definition object. Every definition has a "type" and may set "nullable",
+// the share of records (0..1) in which the field is null, and "locale", the locale
+// of the localized generators it contains (see Locales). Other keys depend on the type:
+//
+//	string    generator | regex | enum (strings) | min_length, max_length
+//	int       min, max (integers) | enum (integers)
+//	float     min, max, precision (0..10)
+//	bool, uuid
+//	date      min, max as YYYY-MM-DD
+//	datetime  min, max as YYYY-MM-DD or RFC 3339
+//	object    fields (required)
+//	array     items (required, a field), min_items, max_items (up to 1000)
+//	const     value
+//
+// generator, regex, enum and lengths are mutually exclusive. Unknown keys are rejected.
+// The document itself may set a default "locale"; the locale of a task takes precedence.
+//
+// Instead of "fields" a document may describe a dataset of related "entities":
+//
+//	"entities": {
+//	  "users":  {"count": 100, "fields": {"id": "uuid"}},
+//	  "orders": {
+//	    "parent": "users",
+//	    "per_parent": {"min": 0, "max": 5, "distribution": "poisson", "mean": 2},
+//	    "fields": {"id": "uuid", "user_id": {"type": "ref", "entity": "users", "field": "id"}}
+//	  }
+//	}
+//
+// An entity has fields and either a "count" (defaults to the task amount) or a "parent"
+// and "per_parent": an integer or {min, max, distribution, mean, stddev} with a uniform,
+// poisson or normal distribution. The ref type, only allowed inside entities, copies a
+// top-level field of another entity. Parents and refs must not form a cycle.
+// worker-service compiles the same documents into data generators.
+package schema
+
+import (
+	"fmt"
+	"math"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CurrentVersion is the latest schema_version accepted by Validate.
+const CurrentVersion = 1
+
+const (
+	maxArrayItems  = 1000
+	maxPrecision   = 10
+	maxRegexLength = 256
+	maxDepth       = 8
+	maxRecords     = 10_000_000
+	maxPerParent   = 10_000
+	dateLayout     = "2006-01-02"
+)
+
+// FieldError points at an invalid part of a template.
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every problem found in a template.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Path + ": " + fe.Message
+	}
+	return "invalid template: " + strings.Join(msgs, "; ")
+}
+
+// allowedKeys lists the definition keys accepted for each field type.
+var allowedKeys = map[string][]string{
+	"string":   {"generator", "regex", "enum", "min_length", "max_length"},
+	"int":      {"min", "max", "enum"},
+	"float":    {"min", "max", "precision"},
+	"bool":     {},
+	"uuid":     {},
+	"date":     {"min", "max"},
+	"datetime": {"min", "max"},
+	"object":   {"fields"},
+	"array":    {"items", "min_items", "max_items"},
+	"const":    {"value"},
+	"ref":      {"entity", "field"},
+}
+
+// entityKeys lists the keys accepted in an entity of a dataset document.
+var entityKeys = []string{"count", "parent", "per_parent", "fields", "locale"}
+
+// perParentKeys lists the keys accepted in a per_parent object.
+var perParentKeys = []string{"min", "max", "distribution", "mean", "stddev"}
+
+// Validate checks template content and returns a *ValidationError describing
+// every invalid field, or nil if the content is a valid schema document.
+func Validate(content map[string]interface{}) error {
+	v := &validator{}
+	v.document(content)
+	if len(v.errs) > 0 {
+		return &ValidationError{Errors: v.errs}
+	}
+	return nil
+}
+
+type validator struct {
+	errs []FieldError
+
+	// entities maps every entity of a dataset document to its fields, entity is the
+	// entity being validated and deps collects the parents and refs of each entity.
+	entities map[string]map[string]interface{}
+	entity   string
+	deps     map[string][]string
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) document(content map[string]interface{}) {
+	raw, ok := content["schema_version"]
+	if !ok {
+		v.fail("schema_version", "is required, current version is %d", CurrentVersion)
+	} else if version, ok := integer(raw); !ok || version != CurrentVersion {
+		v.fail("schema_version", "unsupported version %v, current version is %d", raw, CurrentVersion)
+	}
+
+	for _, key := range sortedKeys(content) {
+		if key != "schema_version" && key != "fields" && key != "entities" && key != "locale" {
+			v.fail(key, "unknown key")
+		}
+	}
+	v.locale(content, "locale")
+
+	_, hasFields := content["fields"]
+	_, hasEntities := content["entities"]
+	switch {
+	case hasFields && hasEntities:
+		v.fail("entities", "fields and entities are mutually exclusive")
+	case hasEntities:
+		v.dataset(content["entities"], "entities")
+	default:
+		v.fields(content["fields"], "fields", 0)
+	}
+}
+
+func (v *validator) dataset(raw interface{}, path string) {
+	defs, ok := raw.(map[string]interface{})
+	if !ok || len(defs) == 0 {
+		v.fail(path, "must be a non-empty object")
+		return
+	}
+
+	// Collect every entity first so that parents and refs may point forward.
+	v.entities = make(map[string]map[string]interface{}, len(defs))
+	v.deps = make(map[string][]string, len(defs))
+	for name, raw := range defs {
+		if def, ok := raw.(map[string]interface{}); ok {
+			fields, _ := def["fields"].(map[string]interface{})
+			v.entities[name] = fields
+		}
+	}
+
+	for _, name := range sortedKeys(defs) {
+		v.entityDef(name, defs[name], path+"."+name)
+	}
+	v.cycles(path)
+}
+
+func (v *validator) entityDef(name string, raw interface{}, path string) {
+	def, ok := raw.(map[string]interface{})
+	if !ok {
+		v.fail(path, "must be an entity definition object")
+		return
+	}
+	if strings.TrimSpace(name) == "" {
+		v.fail(path, "entity name must not be empty")
+		return
+	}
+
+	for _, key := range sortedKeys(def) {
+		if !contains(entityKeys, key) {
+			v.fail(path+"."+key, "unknown key")
+		}
+	}
+	v.locale(def, path+".locale")
+
+	_, hasCount := def["count"]
+	if raw, ok := def["parent"]; ok {
+		parent, _ := raw.(string)
+		if _, ok := v.entities[parent]; !ok || parent == name {
+			v.fail(path+".parent", "must name another entity")
+		} else {
+			v.deps[name] = append(v.deps[name], parent)
+		}
+		v.perParent(def["per_parent"], path+".per_parent")
+		if hasCount {
+			v.fail(path+".count", "is not allowed for an entity with a parent, use per_parent")
+		}
+	} else {
+		if _, ok := def["per_parent"]; ok {
+			v.fail(path+".per_parent", "requires a parent")
+		}
+		if hasCount {
+			if n, ok := integer(def["count"]); !ok || n < 1 || n > maxRecords {
+				v.fail(path+".count", "must be an integer between 1 and %d", maxRecords)
+			}
+		}
+	}
+
+	v.entity = name
+	v.fields(def["fields"], path+".fields", 0)
+	v.entity = ""
+}
+
+func (v *validator) perParent(raw interface{}, path string) {
+	if raw == nil {
+		v.fail(path, "is required for an entity with a parent")
+		return
+	}
+	def, ok := raw.(map[string]interface{})
+	if !ok {
+		if n, ok := integer(raw); !ok || n < 0 || n > maxPerParent {
+			v.fail(path, "must be an integer between 0 and %d or an object", maxPerParent)
+		}
+		return
+	}
+
+	for _, key := range sortedKeys(def) {
+		if !contains(perParentKeys, key) {
+			v.fail(path+"."+key, "unknown key")
+		}
+	}
+
+	lo, hasMin := int64(0), true
+	if raw, ok := def["min"]; ok {
+		if lo, hasMin = integer(raw); !hasMin || lo < 0 || lo > maxPerParent {
+			v.fail(path+".min", "must be an integer between 0 and %d", maxPerParent)
+		}
+	}
+	hi, hasMax := int64(0), false
+	if raw, ok := def["max"]; !ok {
+		v.fail(path+".max", "is required")
+	} else if hi, hasMax = integer(raw); !hasMax || hi < 0 || hi > maxPerParent {
+		v.fail(path+".max", "must be an integer between 0 and %d", maxPerParent)
+	}
+	if hasMin && hasMax && lo > hi {
+		v.fail(path+".min", "must not be greater than max")
+	}
+
+	if raw, ok := def["distribution"]; ok {
+		if d, _ := raw.(string); d != "uniform" && d != "poisson" && d != "normal" {
+			v.fail(path+".distribution", "must be one of uniform, poisson, normal")
+		}
+	}
+	for _, key := range []string{"mean", "stddev"} {
+		if raw, ok := def[key]; ok {
+			if n, ok := number(raw); !ok || n < 0 {
+				v.fail(path+"."+key, "must be a non-negative number")
+			}
+		}
+	}
+}
+
+// cycles reports entities whose parents and refs lead back to themselves.
+func (v *validator) cycles(path string) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	marks := make(map[string]int, len(v.deps))
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		switch marks[name] {
+		case visited:
+			return true
+		case visiting:
+			return false
+		}
+		marks[name] = visiting
+		for _, dep := range v.deps[name] {
+			if !visit(dep) {
+				return false
+			}
+		}
+		marks[name] = visited
+		return true
+	}
+
+	for _, name := range sortedKeys(v.deps) {
+		if !visit(name) {
+			v.fail(path+"."+name, "parents and refs form a dependency cycle")
+			return
+		}
+	}
+}
+
+func (v *validator) fields(raw interface{}, path string, depth int) {
+	fields, ok := raw.(map[string]interface{})
+	if !ok || len(fields) == 0 {
+		v.fail(path, "must be a non-empty object")
+		return
+	}
+	if depth >= maxDepth {
+		v.fail(path, "objects may be nested at most %d levels deep", maxDepth)
+		return
+	}
+
+	for _, name := range sortedKeys(fields) {
+		fieldPath := path + "." + name
+		if strings.TrimSpace(name) == "" {
+			v.fail(fieldPath, "field name must not be empty")
+			continue
+		}
+		v.field(fields[name], fieldPath, depth)
+	}
+}
+
+func (v *validator) field(raw interface{}, path string, depth int) {
+	switch def := raw.(type) {
+	case string:
+		if err := ValidateSpec(def); err != nil {
+			v.fail(path, "%v", err)
+		}
+	case map[string]interface{}:
+		v.definition(def, path, depth)
+	default:
+		v.fail(path, "must be a generator spec string or a field definition object")
+	}
+}
+
+func (v *validator) definition(def map[string]interface{}, path string, depth int) {
+	typ, _ := def["type"].(string)
+	keys, ok := allowedKeys[typ]
+	if !ok {
+		v.fail(path+".type", "must be one of %s", strings.Join(sortedKeys(allowedKeys), ", "))
+		return
+	}
+
+	for _, key := range sortedKeys(def) {
+		if key != "type" && key != "nullable" && key != "locale" && !contains(keys, key) {
+			v.fail(path+"."+key, "is not allowed for type %s", typ)
+		}
+	}
+	v.locale(def, path+".locale")
+
+	if raw, ok := def["nullable"]; ok {
+		if ratio, ok := number(raw); !ok || ratio < 0 || ratio > 1 {
+			v.fail(path+".nullable", "must be a number between 0 and 1")
+		}
+	}
+
+	switch typ {
+	case "string":
+		v.stringField(def, path)
+	case "int":
+		v.intField(def, path)
+	case "float":
+		v.numberRange(def, path, false)
+		if raw, ok := def["precision"]; ok {
+			if p, ok := integer(raw); !ok || p < 0 || p > maxPrecision {
+				v.fail(path+".precision", "must be an integer between 0 and %d", maxPrecision)
+			}
+		}
+	case "date", "datetime":
+		v.dateRange(def, path, typ == "datetime")
+	case "object":
+		v.fields(def["fields"], path+".fields", depth+1)
+	case "array":
+		v.arrayField(def, path, depth)
+	case "ref":
+		v.ref(def, path)
+	}
+}
+
+func (v *validator) ref(def map[string]interface{}, path string) {
+	if v.entities == nil {
+		v.fail(path+".type", "ref is only allowed in the fields of an entity")
+		return
+	}
+	target, _ := def["entity"].(string)
+	fields, ok := v.entities[target]
+	switch {
+	case !ok:
+		v.fail(path+".entity", "must name an entity")
+	case target == v.entity:
+		v.fail(path+".entity", "an entity cannot reference itself")
+	default:
+		v.deps[v.entity] = append(v.deps[v.entity], target)
+		if field, _ := def["field"].(string); fields[field] == nil {
+			v.fail(path+".field", "must name a field of entity %s", target)
+		}
+	}
+}
+
+// locale checks the optional "locale" key of a document or definition.
+func (v *validator) locale(def map[string]interface{}, path string) {
+	raw, ok := def["locale"]
+	if !ok {
+		return
+	}
+	if code, ok := raw.(string); !ok || !ValidLocale(code) {
+		v.fail(path, "must be one of %s", strings.Join(Locales, ", "))
+	}
+}
+
+func (v *validator) stringField(def map[string]interface{}, path string) {
+	modes := 0
+	for _, key := range []string{"generator", "regex", "enum"} {
+		if _, ok := def[key]; ok {
+			modes++
+		}
+	}
+	_, hasMin := def["min_length"]
+	_, hasMax := def["max_length"]
+	if hasMin || hasMax {
+		modes++
+	}
+	if modes > 1 {
+		v.fail(path, "generator, regex, enum and min_length/max_length are mutually exclusive")
+	}
+
+	if raw, ok := def["generator"]; ok {
+		if name, ok := raw.(string); !ok {
+			v.fail(path+".generator", "must be a string")
+		} else if err := ValidateSpec(name); err != nil {
+			v.fail(path+".generator", "%v", err)
+		}
+	}
+
+	if raw, ok := def["regex"]; ok {
+		pattern, ok := raw.(string)
+		switch {
+		case !ok || pattern == "":
+			v.fail(path+".regex", "must be a non-empty string")
+		case len(pattern) > maxRegexLength:
+			v.fail(path+".regex", "must be at most %d characters", maxRegexLength)
+		default:
+			if _, err := syntax.Parse(pattern, syntax.Perl); err != nil {
+				v.fail(path+".regex", "invalid regular expression: %v", err)
+			}
+		}
+	}
+
+	if raw, ok := def["enum"]; ok {
+		v.enum(raw, path+".enum", func(value interface{}) bool {
+			_, ok := value.(string)
+			return ok
+		}, "strings")
+	}
+
+	minLen, maxLen := int64(0), int64(math.MaxInt64)
+	if hasMin {
+		if minLen, hasMin = integer(def["min_length"]); !hasMin || minLen < 0 {
+			v.fail(path+".min_length", "must be a non-negative integer")
+		}
+	}
+	if hasMax {
+		if maxLen, hasMax = integer(def["max_length"]); !hasMax || maxLen < 1 {
+			v.fail(path+".max_length", "must be a positive integer")
+		}
+	}
+	if hasMin && hasMax && minLen > maxLen {
+		v.fail(path+".min_length", "must not be greater than max_length")
+	}
+}
+
+func (v *validator) intField(def map[string]interface{}, path string) {
+	if raw, ok := def["enum"]; ok {
+		if _, hasMin := def["min"]; hasMin {
+			v.fail(path, "enum and min/max are mutually exclusive")
+		} else if _, hasMax := def["max"]; hasMax {
+			v.fail(path, "enum and min/max are mutually exclusive")
+		}
+		v.enum(raw, path+".enum", func(value interface{}) bool {
+			_, ok := integer(value)
+			return ok
+		}, "integers")
+		return
+	}
+	v.numberRange(def, path, true)
+}
+
+func (v *validator) numberRange(def map[string]interface{}, path string, integral bool) {
+	kind := "a number"
+	if integral {
+		kind = "an integer"
+	}
+
+	values := make(map[string]float64, 2)
+	for _, key := range []string{"min", "max"} {
+		raw, ok := def[key]
+		if !ok {
+			continue
+		}
+		n, ok := number(raw)
+		if ok && integral {
+			_, ok = integer(raw)
+		}
+		if !ok {
+			v.fail(path+"."+key, "must be %s", kind)
+			continue
+		}
+		values[key] = n
+	}
+
+	min, hasMin := values["min"]
+	max, hasMax := values["max"]
+	if hasMin && hasMax && min > max {
+		v.fail(path+".min", "must not be greater than max")
+	}
+}
+
+func (v *validator) dateRange(def map[string]interface{}, path string, withTime bool) {
+	values := make(map[string]time.Time, 2)
+	for _, key := range []string{"min", "max"} {
+		raw, ok := def[key]
+		if !ok {
+			continue
+		}
+		s, _ := raw.(string)
+		t, err := time.Parse(dateLayout, s)
+		if err != nil && withTime {
+			t, err = time.Parse(time.RFC3339, s)
+		}
+		if err != nil {
+			if withTime {
+				v.fail(path+"."+key, "must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+			} else {
+				v.fail(path+"."+key, "must be a date (YYYY-MM-DD)")
+			}
+			continue
+		}
+		values[key] = t
+	}
+
+	min, hasMin := values["min"]
+	max, hasMax := values["max"]
+	if hasMin && hasMax && min.After(max) {
+		v.fail(path+".min", "must not be after max")
+	}
+}
+
+func (v *validator) arrayField(def map[string]interface{}, path string, depth int) {
+	if items, ok := def["items"]; !ok {
+		v.fail(path+".items", "is required")
+	} else {
+		v.field(items, path+".items", depth+1)
+	}
+
+	minItems, maxItems := int64(0), int64(maxArrayItems)
+	var hasMin, hasMax bool
+	if raw, ok := def["min_items"]; ok {
+		if minItems, hasMin = integer(raw); !hasMin || minItems < 0 || minItems > maxArrayItems {
+			v.fail(path+".min_items", "must be an integer between 0 and %d", maxArrayItems)
+		}
+	}
+	if raw, ok := def["max_items"]; ok {
+		if maxItems, hasMax = integer(raw); !hasMax || maxItems < 0 || maxItems > maxArrayItems {
+			v.fail(path+".max_items", "must be an integer between 0 and %d", maxArrayItems)
+		}
+	}
+	if hasMin && hasMax && minItems > maxItems {
+		v.fail(path+".min_items", "must not be greater than max_items")
+	}
+}
+
+func (v *validator) enum(raw interface{}, path string, valid func(interface{}) bool, kind string) {
+	values, ok := raw.([]interface{})
+	if !ok || len(values) == 0 {
+		v.fail(path, "must be a non-empty array")
+		return
+	}
+	for i, value := range values {
+		if !valid(value) {
+			v.fail(fmt.Sprintf("%s[%d]", path, i), "enum values must be %s", kind)
+		}
+	}
+}
+
+// number reports whether raw is a JSON number and returns it.
+func number(raw interface{}) (float64, bool) {
+	switch n := raw.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// integer reports whether raw is a whole JSON number and returns it.
+func integer(raw interface{}) (int64, bool) {
+	switch n := raw.(type) {
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) > 1<<53 {
+			return 0, false
+		}
+		return int64(n), true
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,169 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, content string) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(content), &m))
+	return m
+}
+
+func paths(err error) []string {
+	var out []string
+	for _, fe := range err.(*ValidationError).Errors {
+		out = append(out, fe.Path)
+	}
+	return out
+}
+
+// TestValidate_Valid проверяет, что корректный шаблон проходит валидацию.
+func TestValidate_Valid(t *testing.T) {
+	content := decode(t, `{
+		"schema_version": 1,
+		"locale": "ru_RU",
+		"fields": {
+			"id": "uuid",
+			"name": "{{name}}",
+			"patronymic": "patronymic",
+			"us_phone": "phone(en-US)",
+			"office": {"type": "object", "locale": "de_DE", "fields": {"address": "address", "company": "company"}},
+			"age": {"type": "int", "min": 18, "max": 65},
+			"score": {"type": "float", "min": 0, "max": 10, "precision": 1},
+			"email": {"type": "string", "generator": "email", "nullable": 0.1},
+			"code": {"type": "string", "regex": "[A-Z]{3}-\\d{4}"},
+			"role": {"type": "string", "enum": ["admin", "user"]},
+			"level": {"type": "int", "enum": [1, 2, 3]},
+			"nick": {"type": "string", "min_length": 3, "max_length": 8},
+			"born": {"type": "date", "min": "1990-01-01", "max": "1999-12-31"},
+			"seen": {"type": "datetime", "min": "2024-01-01T00:00:00Z"},
+			"address": {"type": "object", "fields": {"city": "word", "zip": "string(6)"}},
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["a", "b"]}, "min_items": 0, "max_items": 3},
+			"version": {"type": "const", "value": 2}
+		}
+	}`)
+
+	assert.NoError(t, Validate(content))
+}
+
+// TestValidate_FieldErrors проверяет пути ошибок для некорректных полей.
+func TestValidate_FieldErrors(t *testing.T) {
+	content := decode(t, `{
+		"schema_version": 1,
+		"fields": {
+			"a": "nope",
+			"b": "int(10,1)",
+			"c": {"type": "money"},
+			"d": {"type": "int", "min": 1.5},
+			"e": {"type": "string", "regex": "("},
+			"f": {"type": "string", "enum": []},
+			"g": {"type": "string", "generator": "email", "regex": "x"},
+			"h": {"type": "bool", "nullable": 1.5},
+			"i": {"type": "date", "min": "2020-13-01"},
+			"j": {"type": "object", "fields": {"k": {"type": "int", "min": 5, "max": 1}}},
+			"l": {"type": "array", "items": 42, "max_items": 5000},
+			"m": {"type": "uuid", "max": 3},
+			"n": 7
+		}
+	}`)
+
+	err := Validate(content)
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"fields.a",
+		"fields.b",
+		"fields.c.type",
+		"fields.d.min",
+		"fields.e.regex",
+		"fields.f.enum",
+		"fields.g",
+		"fields.h.nullable",
+		"fields.i.min",
+		"fields.j.fields.k.min",
+		"fields.l.items",
+		"fields.l.max_items",
+		"fields.m.max",
+		"fields.n",
+	}, paths(err))
+}
+
+// TestValidate_Document проверяет версию схемы и структуру документа.
+func TestValidate_Document(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		paths   []string
+	}{
+		{name: "missing version", content: `{"fields": {"a": "uuid"}}`, paths: []string{"schema_version"}},
+		{name: "future version", content: `{"schema_version": 2, "fields": {"a": "uuid"}}`, paths: []string{"schema_version"}},
+		{name: "legacy template", content: `{"name": "{{name}}"}`, paths: []string{"schema_version", "name", "fields"}},
+		{name: "empty fields", content: `{"schema_version": 1, "fields": {}}`, paths: []string{"fields"}},
+		{name: "unknown locale", content: `{"schema_version": 1, "locale": "xx_XX", "fields": {"a": {"type": "string", "generator": "name", "locale": 1}}}`, paths: []string{"locale", "fields.a.locale"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(decode(t, tt.content))
+			require.Error(t, err)
+			assert.Equal(t, tt.paths, paths(err))
+		})
+	}
+}
+
+// TestValidate_Dataset проверяет шаблоны со связанными сущностями.
+func TestValidate_Dataset(t *testing.T) {
+	content := decode(t, `{
+		"schema_version": 1,
+		"entities": {
+			"items": {
+				"parent": "orders",
+				"per_parent": {"min": 1, "max": 4, "distribution": "poisson", "mean": 2},
+				"fields": {"order_id": {"type": "ref", "entity": "orders", "field": "id"}, "qty": "int(1,5)"}
+			},
+			"orders": {
+				"parent": "users",
+				"per_parent": 2,
+				"fields": {"id": "uuid", "user": {"type": "object", "fields": {"id": {"type": "ref", "entity": "users", "field": "id"}}}}
+			},
+			"users": {"count": 10, "locale": "ru_RU", "fields": {"id": "uuid", "name": "name"}}
+		}
+	}`)
+	assert.NoError(t, Validate(content))
+
+	tests := []struct {
+		name    string
+		content string
+		paths   []string
+	}{
+		{name: "fields and entities", content: `{"schema_version": 1, "fields": {"a": "uuid"}, "entities": {"a": {"fields": {"a": "uuid"}}}}`, paths: []string{"entities"}},
+		{name: "ref outside entities", content: `{"schema_version": 1, "fields": {"a": {"type": "ref", "entity": "b", "field": "id"}}}`, paths: []string{"fields.a.type"}},
+		{name: "entity keys", content: `{"schema_version": 1, "entities": {"a": {"count": 0, "per_parent": 1, "size": 1, "fields": {}}}}`, paths: []string{"entities.a.size", "entities.a.per_parent", "entities.a.count", "entities.a.fields"}},
+		{name: "parent", content: `{"schema_version": 1, "entities": {"a": {"fields": {"x": "uuid"}}, "b": {"parent": "c", "count": 3, "fields": {"x": "uuid"}}}}`, paths: []string{"entities.b.parent", "entities.b.per_parent", "entities.b.count"}},
+		{name: "per_parent", content: `{"schema_version": 1, "entities": {"a": {"fields": {"x": "uuid"}}, "b": {"parent": "a", "per_parent": {"min": 5, "max": 2, "distribution": "zipf", "mean": -1, "step": 1}, "fields": {"x": "uuid"}}}}`, paths: []string{"entities.b.per_parent.step", "entities.b.per_parent.min", "entities.b.per_parent.distribution", "entities.b.per_parent.mean"}},
+		{name: "refs", content: `{"schema_version": 1, "entities": {"a": {"fields": {"id": "uuid", "b": {"type": "ref", "entity": "c", "field": "id"}, "c": {"type": "ref", "entity": "a", "field": "id"}}}, "d": {"fields": {"x": {"type": "ref", "entity": "a", "field": "nope"}}}}}`, paths: []string{"entities.a.fields.b.entity", "entities.a.fields.c.entity", "entities.d.fields.x.field"}},
+		{name: "cycle", content: `{"schema_version": 1, "entities": {"a": {"parent": "b", "per_parent": 1, "fields": {"id": "uuid"}}, "b": {"fields": {"id": {"type": "ref", "entity": "a", "field": "id"}}}}}`, paths: []string{"entities.a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(decode(t, tt.content))
+			require.Error(t, err)
+			assert.Equal(t, tt.paths, paths(err))
+		})
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	for _, spec := range []string{"uuid", "{{email}}", "INT(1, 100)", "float(0,1,3)", "date(2020-01-01,2020-12-31)", "enum(a,b)", "string(5)", "name(ru_RU)", "city(de)", "address(EN-gb)", "inn(12)", "inn(invalid)", "card(visa,invalid)", "iban(GB)", "ean13(460)", "snils"} {
+		assert.NoError(t, ValidateSpec(spec), spec)
+	}
+	for _, spec := range []string{"", "nope", "uuid(1)", "int(a,b)", "int(1)", "float(0,1,-1)", "string(0)", "enum()", "date(2020-12-31,2020-01-01)", "int(1,2", "name(xx_XX)", "phone(ru,de)", "inn(11)", "card(diners)", "ssn(1)", "ean13(abc)"} {
+		assert.Error(t, ValidateSpec(spec), spec)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"template-service/internal/models"
 	"template-service/internal/repository"
@@ -16,6 +17,11 @@ import (
 type TemplateService interface {
 	CreateNewTemplate(ctx context.Context, template models.Template) (int64, error)
 	GetTemplateByID(ctx context.Context, id int64) (*models.Template, error)
+	UpdateTemplate(ctx context.Context, id int64, userID string, patch models.PatchTemplateRequest) (*models.Template, error)
+	DeleteTemplate(ctx context.Context, id int64, userID string) error
+	ListTemplates(ctx context.Context, filter models.TemplateFilter) ([]models.Template, int, error)
+	ListVersions(ctx context.Context, id int64) ([]models.TemplateVersion, error)
+	GetVersion(ctx context.Context, id int64, version int) (*models.TemplateVersion, error)
 }
 
 type templateService struct {
@@ -39,6 +45,8 @@ func (t *templateService) CreateNewTemplate(ctx context.Context, template models
 		return 0, err
 	}
 
+	template.ID = id
+	template.Version = 1
 	templateData, err := json.Marshal(template)
 	if err != nil {
 		t.logger.Errorf("Failed to marshal template data: %v", err)
@@ -82,3 +90,110 @@ func (t *templateService) GetTemplateByID(ctx context.Context, id int64) (*model
 	t.logger.Infof("Template retrieved with ID: %d", id)
 	return template, nil
 }
+
+// UpdateTemplate applies the present fields of patch to a template owned by userID.
+func (t *templateService) UpdateTemplate(ctx context.Context, id int64, userID string, patch models.PatchTemplateRequest) (*models.Template, error) {
+	template, err := t.ownedTemplate(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Title != nil {
+		template.Title = *patch.Title
+	}
+	if patch.Content != nil {
+		template.Content = patch.Content
+	}
+	template.UpdatedAt = time.Now()
+
+	version, err := t.repo.UpdateTemplate(ctx, *template)
+	if err != nil {
+		t.logger.Errorf("Failed to update template %d: %v", id, err)
+		return nil, err
+	}
+	template.Version = version
+	t.invalidate(ctx, id)
+
+	t.logger.Infof("Template %d updated to version %d", id, version)
+	return template, nil
+}
+
+// DeleteTemplate soft-deletes a template owned by userID.
+func (t *templateService) DeleteTemplate(ctx context.Context, id int64, userID string) error {
+	if _, err := t.ownedTemplate(ctx, id, userID); err != nil {
+		return err
+	}
+
+	if err := t.repo.DeleteTemplate(ctx, id); err != nil {
+		t.logger.Errorf("Failed to delete template %d: %v", id, err)
+		return err
+	}
+	t.invalidate(ctx, id)
+
+	t.logger.Infof("Template %d deleted", id)
+	return nil
+}
+
+func (t *templateService) ListTemplates(ctx context.Context, filter models.TemplateFilter) ([]models.Template, int, error) {
+	templates, total, err := t.repo.ListTemplates(ctx, filter)
+	if err != nil {
+		t.logger.Errorf("Failed to list templates: %v", err)
+		return nil, 0, err
+	}
+
+	t.logger.Infof("Retrieved %d of %d templates", len(templates), total)
+	return templates, total, nil
+}
+
+func (t *templateService) ListVersions(ctx context.Context, id int64) ([]models.TemplateVersion, error) {
+	versions, err := t.repo.ListVersions(ctx, id)
+	if err != nil {
+		t.logger.Errorf("Failed to list versions of template %d: %v", id, err)
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetVersion returns one revision of a template. Revisions never change, so they are cached for a day.
+func (t *templateService) GetVersion(ctx context.Context, id int64, version int) (*models.TemplateVersion, error) {
+	cacheKey := fmt.Sprintf("template:%d:v%d", id, version)
+	if data, err := t.redis.Get(ctx, cacheKey); err == nil {
+		var v models.TemplateVersion
+		if err := json.Unmarshal([]byte(data), &v); err == nil {
+			t.logger.Debugf("Version %d of template %d found in Redis", version, id)
+			return &v, nil
+		}
+	}
+
+	v, err := t.repo.GetVersion(ctx, id, version)
+	if err != nil {
+		t.logger.Errorf("Failed to get version %d of template %d: %v", version, id, err)
+		return nil, err
+	}
+
+	if data, err := json.Marshal(v); err == nil {
+		if err := t.redis.Set(ctx, cacheKey, data, 24*time.Hour); err != nil {
+			t.logger.Warnf("Failed to cache version %d of template %d: %v", version, id, err)
+		}
+	}
+
+	return v, nil
+}
+
+// ownedTemplate loads a template from the database, bypassing the cache, and checks its owner.
+func (t *templateService) ownedTemplate(ctx context.Context, id int64, userID string) (*models.Template, error) {
+	template, err := t.repo.GetTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, models.ErrForbidden
+	}
+	return template, nil
+}
+
+func (t *templateService) invalidate(ctx context.Context, id int64) {
+	if err := t.redis.Del(ctx, "template:"+strconv.FormatInt(id, 10)); err != nil {
+		t.logger.Warnf("Failed to invalidate cached template %d: %v", id, err)
+	}
+}
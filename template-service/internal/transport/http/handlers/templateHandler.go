@@ -3,9 +3,11 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"template-service/internal/models"
+	"template-service/internal/schema"
 	"template-service/internal/services"
 
 	"github.com/go-playground/validator/v10"
@@ -16,6 +18,11 @@ import (
 type TemplateService interface {
 	CreateNewTemplate(ctx context.Context, template models.Template) (int64, error)
 	GetTemplateByID(ctx context.Context, id int64) (*models.Template, error)
+	UpdateTemplate(ctx context.Context, id int64, userID string, patch models.PatchTemplateRequest) (*models.Template, error)
+	DeleteTemplate(ctx context.Context, id int64, userID string) error
+	ListTemplates(ctx context.Context, filter models.TemplateFilter) ([]models.Template, int, error)
+	ListVersions(ctx context.Context, id int64) ([]models.TemplateVersion, error)
+	GetVersion(ctx context.Context, id int64, version int) (*models.TemplateVersion, error)
 }
 
 type TemplateHandler struct {
@@ -40,7 +47,12 @@ func (h *TemplateHandler) CreateNewTemplate(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	if err := schema.Validate(req.Content); err != nil {
+		return h.invalidContent(c, err)
+	}
+
 	template := models.Template{
+		UserID:  currentUser(c),
 		Title:   req.Title,
 		Content: req.Content,
 	}
@@ -69,3 +81,160 @@ func (h *TemplateHandler) GetTemplateByID(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, template)
 }
+
+// UpdateTemplate handles PUT, which replaces the title and content, and PATCH,
+// which changes only the fields present in the body.
+func (h *TemplateHandler) UpdateTemplate(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid template ID"})
+	}
+
+	var patch models.PatchTemplateRequest
+	validate := validator.New()
+	if c.Request().Method == http.MethodPut {
+		var req models.CreateTemplateRequest
+		if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+		if err := validate.Struct(req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		patch = models.PatchTemplateRequest{Title: &req.Title, Content: req.Content}
+	} else {
+		if err := json.NewDecoder(c.Request().Body).Decode(&patch); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+		if err := validate.Struct(patch); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	if patch.Content != nil {
+		if err := schema.Validate(patch.Content); err != nil {
+			return h.invalidContent(c, err)
+		}
+	}
+
+	template, err := h.service.UpdateTemplate(c.Request().Context(), id, currentUser(c), patch)
+	if err != nil {
+		return h.mutationError(c, id, err)
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+func (h *TemplateHandler) DeleteTemplate(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid template ID"})
+	}
+
+	if err := h.service.DeleteTemplate(c.Request().Context(), id, currentUser(c)); err != nil {
+		return h.mutationError(c, id, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListTemplates supports ?page, ?limit, ?search (title substring) and ?owner (a user ID or "me").
+func (h *TemplateHandler) ListTemplates(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	owner := c.QueryParam("owner")
+	if owner == "me" {
+		owner = currentUser(c)
+	}
+
+	templates, total, err := h.service.ListTemplates(c.Request().Context(), models.TemplateFilter{
+		UserID: owner,
+		Search: c.QueryParam("search"),
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to list templates: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve templates"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":  templates,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
+
+func (h *TemplateHandler) ListVersions(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid template ID"})
+	}
+
+	versions, err := h.service.ListVersions(c.Request().Context(), id)
+	if errors.Is(err, models.ErrTemplateNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Template not found"})
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to list versions of template %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve template versions"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": versions})
+}
+
+func (h *TemplateHandler) GetVersion(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid template ID"})
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid template version"})
+	}
+
+	v, err := h.service.GetVersion(c.Request().Context(), id, version)
+	if errors.Is(err, models.ErrVersionNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Template version not found"})
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to get version %d of template %d: %v", version, id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve template version"})
+	}
+
+	return c.JSON(http.StatusOK, v)
+}
+
+func (h *TemplateHandler) invalidContent(c echo.Context, err error) error {
+	h.logger.Infof("Rejected template content: %v", err)
+	return c.JSON(http.StatusBadRequest, map[string]interface{}{
+		"error":   "Invalid template content",
+		"details": err.(*schema.ValidationError).Errors,
+	})
+}
+
+func (h *TemplateHandler) mutationError(c echo.Context, id int64, err error) error {
+	switch {
+	case errors.Is(err, models.ErrTemplateNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Template not found"})
+	case errors.Is(err, models.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Template belongs to another user"})
+	default:
+		h.logger.Errorf("Failed to modify template %d: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to modify template"})
+	}
+}
+
+// currentUser returns the user ID set by AuthMiddleware.
+func currentUser(c echo.Context) string {
+	userID, _ := c.Get("user_id").(string)
+	return userID
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"template-service/internal/models"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockTemplateService struct {
+	mock.Mock
+}
+
+func (m *MockTemplateService) CreateNewTemplate(ctx context.Context, template models.Template) (int64, error) {
+	args := m.Called(ctx, template)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTemplateService) GetTemplateByID(ctx context.Context, id int64) (*models.Template, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Template), args.Error(1)
+}
+
+func (m *MockTemplateService) UpdateTemplate(ctx context.Context, id int64, userID string, patch models.PatchTemplateRequest) (*models.Template, error) {
+	args := m.Called(ctx, id, userID, patch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Template), args.Error(1)
+}
+
+func (m *MockTemplateService) DeleteTemplate(ctx context.Context, id int64, userID string) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockTemplateService) ListTemplates(ctx context.Context, filter models.TemplateFilter) ([]models.Template, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Template), args.Int(1), args.Error(2)
+}
+
+func (m *MockTemplateService) ListVersions(ctx context.Context, id int64) ([]models.TemplateVersion, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TemplateVersion), args.Error(1)
+}
+
+func (m *MockTemplateService) GetVersion(ctx context.Context, id int64, version int) (*models.TemplateVersion, error) {
+	args := m.Called(ctx, id, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TemplateVersion), args.Error(1)
+}
+
+func newTestContext(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.Set("user_id", "user-1")
+	return c, rec
+}
+
+const validContent = `{"schema_version": 1, "fields": {"id": "uuid"}}`
+
+func TestTemplateHandler_CreateNewTemplate_InvalidContent(t *testing.T) {
+	service := new(MockTemplateService)
+	handler := NewTemplateHandler(service, zap.NewNop().Sugar())
+
+	c, rec := newTestContext(http.MethodPost, "/templates", `{"title": "users", "content": {"schema_version": 1, "fields": {"age": {"type": "int", "min": 9, "max": 1}}}}`)
+
+	require.NoError(t, handler.CreateNewTemplate(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp struct {
+		Details []struct {
+			Path string `json:"path"`
+		} `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Details, 1)
+	assert.Equal(t, "fields.age.min", resp.Details[0].Path)
+	service.AssertNotCalled(t, "CreateNewTemplate")
+}
+
+func TestTemplateHandler_CreateNewTemplate_SetsOwner(t *testing.T) {
+	service := new(MockTemplateService)
+	handler := NewTemplateHandler(service, zap.NewNop().Sugar())
+
+	c, rec := newTestContext(http.MethodPost, "/templates", `{"title": "users", "content": `+validContent+`}`)
+	service.On("CreateNewTemplate", mock.Anything, mock.MatchedBy(func(t models.Template) bool {
+		return t.UserID == "user-1" && t.Title == "users"
+	})).Return(int64(7), nil)
+
+	require.NoError(t, handler.CreateNewTemplate(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	service.AssertExpectations(t)
+}
+
+func TestTemplateHandler_UpdateTemplate(t *testing.T) {
+	title := "renamed"
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		patch      *models.PatchTemplateRequest
+		err        error
+		wantStatus int
+	}{
+		{name: "patch title", method: http.MethodPatch, body: `{"title": "renamed"}`, patch: &models.PatchTemplateRequest{Title: &title}, wantStatus: http.StatusOK},
+		{name: "put requires content", method: http.MethodPut, body: `{"title": "renamed"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid content", method: http.MethodPatch, body: `{"content": {"fields": {}}}`, wantStatus: http.StatusBadRequest},
+		{name: "not owner", method: http.MethodPatch, body: `{"title": "renamed"}`, patch: &models.PatchTemplateRequest{Title: &title}, err: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "not found", method: http.MethodPatch, body: `{"title": "renamed"}`, patch: &models.PatchTemplateRequest{Title: &title}, err: models.ErrTemplateNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockTemplateService)
+			handler := NewTemplateHandler(service, zap.NewNop().Sugar())
+
+			c, rec := newTestContext(tt.method, "/templates/3", tt.body)
+			c.SetParamNames("id")
+			c.SetParamValues("3")
+
+			if tt.patch != nil {
+				if tt.err != nil {
+					service.On("UpdateTemplate", mock.Anything, int64(3), "user-1", *tt.patch).Return(nil, tt.err)
+				} else {
+					service.On("UpdateTemplate", mock.Anything, int64(3), "user-1", *tt.patch).Return(&models.Template{ID: 3, Title: title}, nil)
+				}
+			}
+
+			require.NoError(t, handler.UpdateTemplate(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			service.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTemplateHandler_DeleteTemplate(t *testing.T) {
+	service := new(MockTemplateService)
+	handler := NewTemplateHandler(service, zap.NewNop().Sugar())
+
+	c, rec := newTestContext(http.MethodDelete, "/templates/3", "")
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+	service.On("DeleteTemplate", mock.Anything, int64(3), "user-1").Return(nil)
+
+	require.NoError(t, handler.DeleteTemplate(c))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	service.AssertExpectations(t)
+}
+
+func TestTemplateHandler_ListTemplates(t *testing.T) {
+	service := new(MockTemplateService)
+	handler := NewTemplateHandler(service, zap.NewNop().Sugar())
+
+	c, rec := newTestContext(http.MethodGet, "/templates?owner=me&search=user&page=2&limit=500", "")
+	service.On("ListTemplates", mock.Anything, models.TemplateFilter{UserID: "user-1", Search: "user", Page: 2, Limit: 10}).
+		Return([]models.Template{{ID: 1, Title: "users"}}, 11, nil)
+
+	require.NoError(t, handler.ListTemplates(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Data  []models.Template `json:"data"`
+		Total int               `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, 11, resp.Total)
+	service.AssertExpectations(t)
+}
+
+func TestTemplateHandler_GetVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		result     *models.TemplateVersion
+		err        error
+		wantStatus int
+	}{
+		{name: "found", version: "2", result: &models.TemplateVersion{ID: 3, Version: 2}, wantStatus: http.StatusOK},
+		{name: "missing", version: "9", err: models.ErrVersionNotFound, wantStatus: http.StatusNotFound},
+		{name: "invalid", version: "0", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockTemplateService)
+			handler := NewTemplateHandler(service, zap.NewNop().Sugar())
+
+			c, rec := newTestContext(http.MethodGet, "/templates/3/versions/"+tt.version, "")
+			c.SetParamNames("id", "version")
+			c.SetParamValues("3", tt.version)
+
+			if tt.result != nil {
+				service.On("GetVersion", mock.Anything, int64(3), 2).Return(tt.result, nil)
+			} else if tt.err != nil {
+				service.On("GetVersion", mock.Anything, int64(3), 9).Return(nil, tt.err)
+			}
+
+			require.NoError(t, handler.GetVersion(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			service.AssertExpectations(t)
+		})
+	}
+}
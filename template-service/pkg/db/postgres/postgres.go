@@ -79,6 +79,17 @@ func (db *DB) Ping(ctx context.Context) error {
 	return err
 }
 
+func (db *DB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	result, err := db.cb.Execute(func() (interface{}, error) {
+		return db.pool.Query(ctx, sql, args...)
+	})
+	if err != nil {
+		db.logger.Errorf("Circuit Breaker rejected Query: %v", err)
+		return nil, err
+	}
+	return result.(pgx.Rows), nil
+}
+
 func (db *DB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
 	result, err := db.cb.Execute(func() (interface{}, error) {
 		return db.pool.QueryRow(ctx, sql, args...), nil
@@ -98,6 +109,17 @@ func (db *DB) Exec(ctx context.Context, sql string, args ...interface{}) error {
 	return err
 }
 
+func (db *DB) Begin(ctx context.Context) (pgx.Tx, error) {
+	result, err := db.cb.Execute(func() (interface{}, error) {
+		return db.pool.Begin(ctx)
+	})
+	if err != nil {
+		db.logger.Errorf("Circuit Breaker rejected Begin: %v", err)
+		return nil, err
+	}
+	return result.(pgx.Tx), nil
+}
+
 type errorRow struct {
 	err error
 }
@@ -88,3 +88,13 @@ func (r *Redis) Set(ctx context.Context, key string, value interface{}, expirati
 	}
 	return err
 }
+
+func (r *Redis) Del(ctx context.Context, keys ...string) error {
+	_, err := r.cb.Execute(func() (interface{}, error) {
+		return nil, r.Client.Del(ctx, keys...).Err()
+	})
+	if err != nil {
+		r.logger.Errorf("Circuit Breaker rejected Del: %v", err)
+	}
+	return err
+}
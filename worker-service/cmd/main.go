@@ -8,9 +8,14 @@ import (
 	"syscall"
 	"time"
 	"worker-service/internal/config"
+	"worker-service/internal/generator"
+	"worker-service/internal/output"
+	"worker-service/internal/services"
 	http_transport "worker-service/internal/transport/http"
 
+	consumer "worker-service/pkg/broker/kafka"
 	"worker-service/pkg/logger"
+	"worker-service/pkg/storage"
 )
 
 func main() {
@@ -32,6 +37,57 @@ func main() {
 	routerConfig := http_transport.NewRouterConfig(cfg)
 	router := http_transport.NewRouter(routerConfig, log)
 
+	//init services
+	engine := generator.NewEngine()
+	storeCtx, storeCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	store, err := storage.New(storeCtx, cfg.Storage, log.SugaredLogger)
+	storeCancel()
+	if err != nil {
+		log.Fatal("Failed to initialize artifact store: ", err)
+	}
+	sink := output.NewArtifactSink(store, cfg.Storage.ChunkSize, log.SugaredLogger)
+	statusProducer := consumer.NewStatusProducer(cfg.Kafka, log.SugaredLogger)
+	defer func() {
+		if err := statusProducer.Close(); err != nil {
+			log.Errorf("Failed to close Kafka status producer: %v", err)
+		}
+	}()
+	taskProcessor := services.NewTaskProcessor(engine, sink, statusProducer, log.SugaredLogger)
+
+	//init kafka consumer
+	kafkaConsumer, err := consumer.NewKafkaConsumer(cfg.Kafka, taskProcessor, log.SugaredLogger)
+	if err != nil {
+		log.Fatal("Failed to initialize Kafka consumer: ", err)
+	}
+	defer func() {
+		if err := kafkaConsumer.Close(); err != nil {
+			log.Errorf("Failed to close Kafka consumer: %v", err)
+		}
+	}()
+
+	cancelConsumer, err := consumer.NewCancelConsumer(cfg.Kafka, taskProcessor, log.SugaredLogger)
+	if err != nil {
+		log.Fatal("Failed to initialize Kafka cancel consumer: ", err)
+	}
+	defer func() {
+		if err := cancelConsumer.Close(); err != nil {
+			log.Errorf("Failed to close Kafka cancel consumer: %v", err)
+		}
+	}()
+
+	consumeCtx, stopConsuming := context.WithCancel(context.Background())
+	defer stopConsuming()
+	go func() {
+		if err := kafkaConsumer.Consume(consumeCtx); err != nil {
+			log.Errorf("Kafka consumer stopped: %v", err)
+		}
+	}()
+	go func() {
+		if err := cancelConsumer.Consume(consumeCtx); err != nil {
+			log.Errorf("Kafka cancel consumer stopped: %v", err)
+		}
+	}()
+
 	//run server
 	go func() {
 		maxRetries := cfg.HTTPServer.MaxRetries
@@ -53,6 +109,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Info("Received shutdown signal, shutting down gracefully...")
+	stopConsuming()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -15,17 +15,39 @@ type HTTPServer struct {
 }
 
 type KafkaConfig struct {
-	Brokers    string `yaml:"brokers" env:"KAFKA_BROKERS" validate:"required"`
-	Topic      string `yaml:"topic" env:"KAFKA_TOPIC" validate:"required"`
-	MaxRetries int    `yaml:"max_retries" env:"KAFKA_MAX_RETRIES" env-default:"5" validate:"gte=1"`
-	RetryDelay int    `yaml:"retry_delay" env:"KAFKA_RETRY_DELAY" env-default:"3" validate:"gte=1"`
-	Timeout    int    `yaml:"timeout" env:"KAFKA_TIMEOUT" env-default:"5" validate:"gte=1"`
+	Brokers     string `yaml:"brokers" env:"KAFKA_BROKERS" validate:"required"`
+	Topic       string `yaml:"topic" env:"KAFKA_TOPIC" validate:"required"`
+	StatusTopic string `yaml:"status_topic" env:"KAFKA_STATUS_TOPIC" env-default:"task-status" validate:"required"`
+	CancelTopic string `yaml:"cancel_topic" env:"KAFKA_CANCEL_TOPIC" env-default:"task-cancel" validate:"required"`
+	MaxRetries  int    `yaml:"max_retries" env:"KAFKA_MAX_RETRIES" env-default:"5" validate:"gte=1"`
+	RetryDelay  int    `yaml:"retry_delay" env:"KAFKA_RETRY_DELAY" env-default:"3" validate:"gte=1"`
+	Timeout     int    `yaml:"timeout" env:"KAFKA_TIMEOUT" env-default:"5" validate:"gte=1"`
+}
+
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+type StorageConfig struct {
+	Backend     string `yaml:"backend" env:"STORAGE_BACKEND" env-default:"local" validate:"oneof=local s3"`
+	LocalDir    string `yaml:"local_dir" env:"STORAGE_LOCAL_DIR" env-default:"./data" validate:"required_if=Backend local"`
+	S3Endpoint  string `yaml:"s3_endpoint" env:"S3_ENDPOINT" validate:"required_if=Backend s3"`
+	S3Bucket    string `yaml:"s3_bucket" env:"S3_BUCKET" env-default:"fakeid-results" validate:"required_if=Backend s3"`
+	S3AccessKey string `yaml:"s3_access_key" env:"S3_ACCESS_KEY"`
+	S3SecretKey string `yaml:"s3_secret_key" env:"S3_SECRET_KEY"`
+	S3Region    string `yaml:"s3_region" env:"S3_REGION" env-default:"us-east-1"`
+	S3UseSSL    bool   `yaml:"s3_use_ssl" env:"S3_USE_SSL" env-default:"false"`
+	// ChunkSize is the size in bytes of the parts a streamed result is uploaded in.
+	// S3 requires parts of at least 5 MiB.
+	ChunkSize int `yaml:"chunk_size" env:"STORAGE_CHUNK_SIZE" env-default:"8388608" validate:"gte=5242880"`
 }
 
 type Config struct {
-	Env        string      `yaml:"env" env:"ENV" env-default:"prod" validate:"oneof=dev prod test"`
-	HTTPServer HTTPServer  `yaml:"http_server" validate:"required"`
-	Kafka      KafkaConfig `yaml:"kafka" validate:"required"`
+	Env        string        `yaml:"env" env:"ENV" env-default:"prod" validate:"oneof=dev prod test"`
+	HTTPServer HTTPServer    `yaml:"http_server" validate:"required"`
+	Kafka      KafkaConfig   `yaml:"kafka" validate:"required"`
+	Storage    StorageConfig `yaml:"storage" validate:"required"`
 }
 
 func New() (*Config, error) {
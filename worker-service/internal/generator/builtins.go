@@ -0,0 +1,199 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	alphabet   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+var (
+	defaultDateFrom = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaultDateTo   = time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	emailHosts = []string{"example.com", "example.org", "example.net", "test.local"}
+	loremWords = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua"}
+)
+
+func registerBuiltins(e *Engine) {
+	e.Register("uuid", noArgs(genUUID))
+	e.Register("email", noArgs(genEmail))
+	e.Register("word", noArgs(func(r *rand.Rand) interface{} { return pick(r, loremWords) }))
+	e.Register("bool", noArgs(func(r *rand.Rand) interface{} { return r.IntN(2) == 1 }))
+	e.Register("int", newInt)
+	e.Register("float", newFloat)
+	e.Register("string", newString)
+	e.Register("enum", newEnum)
+	e.Register("date", newDate(dateLayout))
+	e.Register("datetime", newDate(time.RFC3339))
+	registerIdentifiers(e)
+}
+
+func noArgs(gen Func) Factory {
+	return func(args []string) (Func, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("takes no arguments, got %d", len(args))
+		}
+		return gen, nil
+	}
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.IntN(len(values))]
+}
+
+// fill replaces every '#' in format with a random decimal digit and every '?' with a random upper-case letter.
+func fill(r *rand.Rand, format string) string {
+	b := []byte(format)
+	for i, c := range b {
+		switch c {
+		case '#':
+			b[i] = byte('0' + r.IntN(10))
+		case '?':
+			b[i] = byte('A' + r.IntN(26))
+		}
+	}
+	return string(b)
+}
+
+func genUUID(r *rand.Rand) interface{} {
+	var b [16]byte
+	for i := 0; i < 16; i += 8 {
+		v := r.Uint64()
+		for j := 0; j < 8; j++ {
+			b[i+j] = byte(v >> (8 * j))
+		}
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func genEmail(r *rand.Rand) interface{} {
+	// Addresses are always ASCII, whatever the locale of the other fields.
+	local := strings.ToLower(pick(r, enUS.FirstNames[r.IntN(2)]) + "." + pick(r, enUS.LastNames[male]))
+	return fmt.Sprintf("%s%d@%s", local, r.IntN(1000), pick(r, emailHosts))
+}
+
+func newInt(args []string) (Func, error) {
+	min, max := int64(0), int64(100)
+	switch len(args) {
+	case 0:
+	case 2:
+		var err error
+		if min, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid min %q", args[0])
+		}
+		if max, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid max %q", args[1])
+		}
+	default:
+		return nil, fmt.Errorf("expects (min,max), got %d arguments", len(args))
+	}
+	if min > max {
+		return nil, fmt.Errorf("min %d is greater than max %d", min, max)
+	}
+
+	span := uint64(max - min)
+	return func(r *rand.Rand) interface{} {
+		if span == math.MaxUint64 {
+			return int64(r.Uint64())
+		}
+		return min + int64(r.Uint64N(span+1))
+	}, nil
+}
+
+func newFloat(args []string) (Func, error) {
+	min, max, precision := 0.0, 1.0, 2
+	switch len(args) {
+	case 0:
+	case 2, 3:
+		var err error
+		if min, err = strconv.ParseFloat(args[0], 64); err != nil {
+			return nil, fmt.Errorf("invalid min %q", args[0])
+		}
+		if max, err = strconv.ParseFloat(args[1], 64); err != nil {
+			return nil, fmt.Errorf("invalid max %q", args[1])
+		}
+		if len(args) == 3 {
+			if precision, err = strconv.Atoi(args[2]); err != nil || precision < 0 {
+				return nil, fmt.Errorf("invalid precision %q", args[2])
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expects (min,max[,precision]), got %d arguments", len(args))
+	}
+	if min > max {
+		return nil, fmt.Errorf("min %g is greater than max %g", min, max)
+	}
+
+	scale := math.Pow(10, float64(precision))
+	return func(r *rand.Rand) interface{} {
+		return math.Round((min+r.Float64()*(max-min))*scale) / scale
+	}, nil
+}
+
+func newString(args []string) (Func, error) {
+	length := 10
+	switch len(args) {
+	case 0:
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid length %q", args[0])
+		}
+		length = n
+	default:
+		return nil, fmt.Errorf("expects (length), got %d arguments", len(args))
+	}
+
+	return func(r *rand.Rand) interface{} {
+		b := make([]byte, length)
+		for i := range b {
+			b[i] = alphabet[r.IntN(len(alphabet))]
+		}
+		return string(b)
+	}, nil
+}
+
+func newEnum(args []string) (Func, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expects at least one value")
+	}
+	values := append([]string(nil), args...)
+	return func(r *rand.Rand) interface{} { return pick(r, values) }, nil
+}
+
+func newDate(layout string) Factory {
+	return func(args []string) (Func, error) {
+		from, to := defaultDateFrom, defaultDateTo
+		switch len(args) {
+		case 0:
+		case 2:
+			var err error
+			if from, err = time.Parse(dateLayout, args[0]); err != nil {
+				return nil, fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", args[0])
+			}
+			if to, err = time.Parse(dateLayout, args[1]); err != nil {
+				return nil, fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", args[1])
+			}
+		default:
+			return nil, fmt.Errorf("expects (from,to), got %d arguments", len(args))
+		}
+		if from.After(to) {
+			return nil, fmt.Errorf("from date is after to date")
+		}
+
+		span := to.Unix() - from.Unix()
+		return func(r *rand.Rand) interface{} {
+			return time.Unix(from.Unix()+r.Int64N(span+1), 0).UTC().Format(layout)
+		}, nil
+	}
+}
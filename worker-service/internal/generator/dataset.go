@@ -0,0 +1,416 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// A dataset template describes several related entities instead of a single record:
+//
+//	{
+//	  "schema_version": 1,
+//	  "entities": {
+//	    "users":  {"count": 100, "fields": {"id": "uuid", "name": "name"}},
+//	    "orders": {
+//	      "parent": "users",
+//	      "per_parent": {"min": 0, "max": 5, "distribution": "poisson", "mean": 2},
+//	      "fields": {"id": "uuid", "user_id": {"type": "ref", "entity": "users", "field": "id"}}
+//	    }
+//	  }
+//	}
+//
+// An entity without a parent is a root and gets "count" records, or the task amount when
+// count is omitted. A child entity gets per_parent records for every record of its parent:
+// either a fixed number or {"min", "max", "distribution", "mean", "stddev"} where the
+// distribution is uniform (default), poisson or normal, clamped to [min, max].
+//
+// A "ref" field copies a top-level field of another entity: of the current parent record
+// when it references the parent, of a random record otherwise. Entities are generated in
+// dependency order, so referenced values always exist.
+
+const (
+	// maxDatasetRecords bounds the number of records a dataset may produce across all entities.
+	maxDatasetRecords = 10_000_000
+	// maxPerParent bounds the number of child records generated for one parent record.
+	maxPerParent = 10_000
+)
+
+// IsDataset reports whether template describes several related entities.
+func IsDataset(template map[string]interface{}) bool {
+	_, ok := template["entities"]
+	return ok
+}
+
+// Collection holds the records generated for one entity of a dataset.
+type Collection struct {
+	Name    string
+	Fields  []string
+	Records []Record
+}
+
+// Dataset is a compiled multi-entity template. A Dataset must not be generated concurrently.
+type Dataset struct {
+	entities []*entity // in dependency order
+	state    *datasetState
+}
+
+type entity struct {
+	name      string
+	count     int
+	parent    string
+	perParent cardinality
+	schema    *Schema
+	refs      []entityRef
+	state     *datasetState
+}
+
+// entityRef is a "ref" field waiting to be checked once every entity is compiled.
+type entityRef struct {
+	path, entity, field string
+}
+
+// datasetState is shared by the ref fields of a dataset while it is generated.
+type datasetState struct {
+	parent  Record
+	records map[string][]Record
+}
+
+// CompileDataset compiles a dataset template. A non-empty locale takes precedence over the template's.
+func (e *Engine) CompileDataset(template map[string]interface{}, locale string) (*Dataset, error) {
+	version, ok, err := number(template, "schema_version")
+	if err != nil || !ok || version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema_version %v", template["schema_version"])
+	}
+	defs, ok := template["entities"].(map[string]interface{})
+	if !ok || len(defs) == 0 {
+		return nil, fmt.Errorf("entities must be a non-empty object")
+	}
+	if locale != "" {
+		if _, err := e.locale(locale); err != nil {
+			return nil, err
+		}
+	} else if locale, err = e.localeOf(template, "", "document"); err != nil {
+		return nil, err
+	}
+
+	state := &datasetState{}
+	entities := make(map[string]*entity, len(defs))
+	for _, name := range sortedKeys(defs) {
+		def, ok := defs[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("entity %q: must be an object", name)
+		}
+		en, err := e.compileEntity(name, def, locale, state)
+		if err != nil {
+			return nil, err
+		}
+		entities[name] = en
+	}
+
+	for _, en := range entities {
+		if en.parent != "" && entities[en.parent] == nil {
+			return nil, fmt.Errorf("entity %q: unknown parent %q", en.name, en.parent)
+		}
+		for _, ref := range en.refs {
+			target, ok := entities[ref.entity]
+			if !ok {
+				return nil, fmt.Errorf("field %q: unknown entity %q", ref.path, ref.entity)
+			}
+			if !slices.Contains(target.schema.Fields(), ref.field) {
+				return nil, fmt.Errorf("field %q: entity %q has no field %q", ref.path, ref.entity, ref.field)
+			}
+		}
+	}
+
+	order, err := orderEntities(entities)
+	if err != nil {
+		return nil, err
+	}
+	return &Dataset{entities: order, state: state}, nil
+}
+
+func (e *Engine) compileEntity(name string, def map[string]interface{}, locale string, state *datasetState) (*entity, error) {
+	en := &entity{name: name, state: state}
+	fail := func(format string, args ...interface{}) (*entity, error) {
+		return nil, fmt.Errorf("entity %q: %s", name, fmt.Sprintf(format, args...))
+	}
+
+	if raw, ok := def["parent"]; ok {
+		if en.parent, _ = raw.(string); en.parent == "" || en.parent == name {
+			return fail("parent must name another entity")
+		}
+		perParent, err := parseCardinality(def["per_parent"])
+		if err != nil {
+			return fail("per_parent: %v", err)
+		}
+		en.perParent = perParent
+	} else if _, ok := def["per_parent"]; ok {
+		return fail("per_parent requires a parent")
+	}
+
+	count, hasCount, err := number(def, "count")
+	if err != nil || (hasCount && (count < 1 || count > maxDatasetRecords || count != math.Trunc(count))) {
+		return fail("count must be an integer between 1 and %d", maxDatasetRecords)
+	}
+	if hasCount && en.parent != "" {
+		return fail("count is not allowed for an entity with a parent, use per_parent")
+	}
+	en.count = int(count)
+
+	fields, ok := def["fields"].(map[string]interface{})
+	if !ok || len(fields) == 0 {
+		return fail("fields must be a non-empty object")
+	}
+	if locale, err = e.localeOf(def, locale, fmt.Sprintf("entity %q", name)); err != nil {
+		return nil, err
+	}
+	if en.schema, err = e.compileFields(fields, name+".", scope{locale: locale, entity: en}); err != nil {
+		return nil, err
+	}
+	return en, nil
+}
+
+// ref compiles a "ref" field definition of the entity.
+func (en *entity) ref(def map[string]interface{}, path string) (Func, error) {
+	target, _ := def["entity"].(string)
+	name, _ := def["field"].(string)
+	if target == "" || name == "" {
+		return nil, fmt.Errorf("field %q: ref requires entity and field", path)
+	}
+	if target == en.name {
+		return nil, fmt.Errorf("field %q: an entity cannot reference itself", path)
+	}
+	en.refs = append(en.refs, entityRef{path: path, entity: target, field: name})
+
+	state := en.state
+	if target == en.parent {
+		return func(*rand.Rand) interface{} { return state.parent[name] }, nil
+	}
+	return func(r *rand.Rand) interface{} {
+		records := state.records[target]
+		if len(records) == 0 {
+			return nil
+		}
+		return records[r.IntN(len(records))][name]
+	}, nil
+}
+
+// orderEntities sorts entities so that every entity follows its parent and the entities it references.
+func orderEntities(entities map[string]*entity) ([]*entity, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	marks := make(map[string]int, len(entities))
+	order := make([]*entity, 0, len(entities))
+
+	var visit func(en *entity) error
+	visit = func(en *entity) error {
+		switch marks[en.name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("entity %q: dependency cycle", en.name)
+		}
+		marks[en.name] = visiting
+
+		deps := make([]string, 0, len(en.refs)+1)
+		if en.parent != "" {
+			deps = append(deps, en.parent)
+		}
+		for _, ref := range en.refs {
+			deps = append(deps, ref.entity)
+		}
+		slices.Sort(deps)
+		for _, dep := range slices.Compact(deps) {
+			if err := visit(entities[dep]); err != nil {
+				return err
+			}
+		}
+
+		marks[en.name] = visited
+		order = append(order, en)
+		return nil
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(entities)) {
+		if err := visit(entities[name]); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Entities returns the entity names in generation order.
+func (d *Dataset) Entities() []string {
+	names := make([]string, len(d.entities))
+	for i, en := range d.entities {
+		names[i] = en.name
+	}
+	return names
+}
+
+// Generate produces every entity of the dataset, parents before children. Root entities
+// without a count get amount records. If progress is not nil it is called periodically
+// with the number of records generated so far across all entities.
+func (d *Dataset) Generate(ctx context.Context, r *rand.Rand, amount int, progress func(generated int)) ([]Collection, error) {
+	d.state.records = make(map[string][]Record, len(d.entities))
+	defer func() { d.state.parent, d.state.records = nil, nil }()
+
+	total := 0
+	next := func() error {
+		if total%progressStep == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if progress != nil && total > 0 {
+				progress(total)
+			}
+		}
+		if total++; total > maxDatasetRecords {
+			return fmt.Errorf("dataset exceeds %d records", maxDatasetRecords)
+		}
+		return nil
+	}
+
+	collections := make([]Collection, 0, len(d.entities))
+	for _, en := range d.entities {
+		var records []Record
+		if en.parent == "" {
+			n := en.count
+			if n == 0 {
+				n = amount
+			}
+			records = make([]Record, 0, n)
+			for i := 0; i < n; i++ {
+				if err := next(); err != nil {
+					return nil, err
+				}
+				records = append(records, en.schema.Generate(r))
+			}
+		} else {
+			for _, parent := range d.state.records[en.parent] {
+				d.state.parent = parent
+				for i, n := 0, en.perParent.sample(r); i < n; i++ {
+					if err := next(); err != nil {
+						return nil, err
+					}
+					records = append(records, en.schema.Generate(r))
+				}
+			}
+			d.state.parent = nil
+		}
+
+		d.state.records[en.name] = records
+		collections = append(collections, Collection{Name: en.name, Fields: en.schema.Fields(), Records: records})
+	}
+	return collections, nil
+}
+
+// cardinality is the number of child records generated per parent record.
+type cardinality struct {
+	min, max     int
+	distribution string
+	mean, stddev float64
+}
+
+func parseCardinality(raw interface{}) (cardinality, error) {
+	if raw == nil {
+		return cardinality{}, fmt.Errorf("is required")
+	}
+	def, ok := raw.(map[string]interface{})
+	if !ok {
+		// A plain number is a fixed count.
+		n, ok := numeric(raw)
+		if !ok || n < 0 || n > maxPerParent || n != math.Trunc(n) {
+			return cardinality{}, fmt.Errorf("must be an integer between 0 and %d or an object", maxPerParent)
+		}
+		return cardinality{min: int(n), max: int(n), distribution: "uniform"}, nil
+	}
+
+	lo, hasMin, err := number(def, "min")
+	if err != nil {
+		return cardinality{}, err
+	}
+	hi, hasMax, err := number(def, "max")
+	if err != nil {
+		return cardinality{}, err
+	}
+	if !hasMax {
+		return cardinality{}, fmt.Errorf("max is required")
+	}
+	if !hasMin {
+		lo = 0
+	}
+	if lo < 0 || hi < lo || hi > maxPerParent || lo != math.Trunc(lo) || hi != math.Trunc(hi) {
+		return cardinality{}, fmt.Errorf("must satisfy 0 <= min <= max <= %d with integer bounds", maxPerParent)
+	}
+
+	c := cardinality{min: int(lo), max: int(hi), distribution: "uniform"}
+	if raw, ok := def["distribution"]; ok {
+		c.distribution, _ = raw.(string)
+	}
+	mean, hasMean, err := number(def, "mean")
+	if err != nil {
+		return cardinality{}, err
+	}
+	stddev, hasStddev, err := number(def, "stddev")
+	if err != nil {
+		return cardinality{}, err
+	}
+	if !hasMean {
+		mean = (lo + hi) / 2
+	}
+	if !hasStddev {
+		stddev = (hi - lo) / 4
+	}
+	c.mean, c.stddev = mean, stddev
+
+	switch c.distribution {
+	case "uniform":
+	case "poisson":
+		if c.mean < 0 {
+			return cardinality{}, fmt.Errorf("mean must not be negative")
+		}
+	case "normal":
+		if c.stddev < 0 {
+			return cardinality{}, fmt.Errorf("stddev must not be negative")
+		}
+	default:
+		return cardinality{}, fmt.Errorf("distribution must be uniform, poisson or normal")
+	}
+	return c, nil
+}
+
+func (c cardinality) sample(r *rand.Rand) int {
+	var n int
+	switch c.distribution {
+	case "poisson":
+		n = poisson(r, c.mean)
+	case "normal":
+		n = int(math.Round(r.NormFloat64()*c.stddev + c.mean))
+	default:
+		return c.min + r.IntN(c.max-c.min+1)
+	}
+	return min(max(n, c.min), c.max)
+}
+
+// poisson samples a Poisson distribution, using Knuth's method for small means
+// and a normal approximation for large ones.
+func poisson(r *rand.Rand, mean float64) int {
+	if mean > 30 {
+		return int(math.Round(r.NormFloat64()*math.Sqrt(mean) + mean))
+	}
+	limit, k, p := math.Exp(-mean), 0, 1.0
+	for {
+		p *= r.Float64()
+		if p <= limit {
+			return k
+		}
+		k++
+	}
+}
@@ -0,0 +1,367 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// SchemaVersion is the version of the template schema DSL understood by the engine.
+//
+// A versioned template looks like
+//
+//	{"schema_version": 1, "locale": "ru_RU", "fields": {"age": {"type": "int", "min": 18, "max": 65}, "id": "uuid"}}
+//
+// Each field is either a generator spec string or a definition object with a "type"
+// (string, int, float, bool, uuid, date, datetime, object, array or const) and the
+// constraints of that type. The optional "locale" of the document, used unless the
+// task sets one, and of a definition select the locale of localized generators.
+// template-service validates templates against the same rules.
+const SchemaVersion = 1
+
+const (
+	defaultMaxItems = 5
+	maxArrayItems   = 1000
+)
+
+// compileDocument compiles a versioned template. A non-empty locale takes precedence over the document's.
+func (e *Engine) compileDocument(doc map[string]interface{}, locale string) (*Schema, error) {
+	version, ok, err := number(doc, "schema_version")
+	if err != nil || !ok || version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema_version %v", doc["schema_version"])
+	}
+	fields, ok := doc["fields"].(map[string]interface{})
+	if !ok || len(fields) == 0 {
+		return nil, fmt.Errorf("fields must be a non-empty object")
+	}
+	if locale == "" {
+		if locale, err = e.localeOf(doc, "", "document"); err != nil {
+			return nil, err
+		}
+	}
+	return e.compileFields(fields, "", scope{locale: locale})
+}
+
+// scope carries what a field inherits from the definitions that enclose it.
+type scope struct {
+	locale string
+	// entity is the dataset entity being compiled, if any; it resolves "ref" fields.
+	entity *entity
+}
+
+// localeOf returns the "locale" key of def, or locale if def has none.
+func (e *Engine) localeOf(def map[string]interface{}, locale, path string) (string, error) {
+	raw, ok := def["locale"]
+	if !ok {
+		return locale, nil
+	}
+	code, _ := raw.(string)
+	if _, err := e.locale(code); err != nil || code == "" {
+		return "", fmt.Errorf("%s: %w %v", path, ErrUnknownLocale, raw)
+	}
+	return code, nil
+}
+
+func (e *Engine) compileFields(fields map[string]interface{}, prefix string, sc scope) (*Schema, error) {
+	names := sortedKeys(fields)
+	schema := &Schema{fields: make([]field, 0, len(names))}
+	for _, name := range names {
+		path := prefix + name
+		gen, err := e.compileField(fields[name], path, sc)
+		if err != nil {
+			return nil, err
+		}
+		schema.fields = append(schema.fields, field{name: name, gen: gen})
+	}
+	return schema, nil
+}
+
+func (e *Engine) compileField(raw interface{}, path string, sc scope) (Func, error) {
+	switch v := raw.(type) {
+	case string:
+		gen, err := e.build(v, sc.locale)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", path, err)
+		}
+		return gen, nil
+	case map[string]interface{}:
+		locale, err := e.localeOf(v, sc.locale, fmt.Sprintf("field %q", path))
+		if err != nil {
+			return nil, err
+		}
+		sc.locale = locale
+		gen, err := e.compileDefinition(v, path, sc)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(v, gen, path)
+	default:
+		return nil, fmt.Errorf("field %q: must be a generator spec or a field definition", path)
+	}
+}
+
+func (e *Engine) compileDefinition(def map[string]interface{}, path string, sc scope) (Func, error) {
+	typ, _ := def["type"].(string)
+	fail := func(err error) (Func, error) {
+		return nil, fmt.Errorf("field %q: %w", path, err)
+	}
+
+	switch typ {
+	case "string":
+		if values, ok := def["enum"].([]interface{}); ok {
+			return enumOf(values, path)
+		}
+		if pattern, ok := def["regex"].(string); ok {
+			gen, err := newRegex(pattern)
+			if err != nil {
+				return fail(err)
+			}
+			return gen, nil
+		}
+		if name, ok := def["generator"].(string); ok {
+			gen, err := e.build(name, sc.locale)
+			if err != nil {
+				return fail(err)
+			}
+			return gen, nil
+		}
+		return lengthString(def, path)
+	case "int":
+		if values, ok := def["enum"].([]interface{}); ok {
+			return enumOf(values, path)
+		}
+		min, max, err := bounds(def, 0, 100)
+		if err != nil {
+			return fail(err)
+		}
+		gen, err := newInt([]string{fmt.Sprint(int64(min)), fmt.Sprint(int64(max))})
+		if err != nil {
+			return fail(err)
+		}
+		return gen, nil
+	case "float":
+		min, max, err := bounds(def, 0, 1)
+		if err != nil {
+			return fail(err)
+		}
+		precision, _, err := number(def, "precision")
+		if err != nil {
+			return fail(err)
+		}
+		if _, ok := def["precision"]; !ok {
+			precision = 2
+		}
+		gen, err := newFloat([]string{fmt.Sprint(min), fmt.Sprint(max), fmt.Sprint(int(precision))})
+		if err != nil {
+			return fail(err)
+		}
+		return gen, nil
+	case "bool":
+		return e.build("bool", sc.locale)
+	case "uuid":
+		return e.build("uuid", sc.locale)
+	case "date", "datetime":
+		return dateRange(def, typ, path)
+	case "object":
+		fields, ok := def["fields"].(map[string]interface{})
+		if !ok || len(fields) == 0 {
+			return fail(fmt.Errorf("object fields must be a non-empty object"))
+		}
+		nested, err := e.compileFields(fields, path+".", sc)
+		if err != nil {
+			return nil, err
+		}
+		return func(r *rand.Rand) interface{} { return nested.Generate(r) }, nil
+	case "array":
+		return e.compileArray(def, path, sc)
+	case "const":
+		value := def["value"]
+		return func(*rand.Rand) interface{} { return value }, nil
+	case "ref":
+		if sc.entity == nil {
+			return fail(fmt.Errorf("ref fields are only allowed inside dataset entities"))
+		}
+		return sc.entity.ref(def, path)
+	default:
+		return fail(fmt.Errorf("unknown type %q", typ))
+	}
+}
+
+func (e *Engine) compileArray(def map[string]interface{}, path string, sc scope) (Func, error) {
+	items, ok := def["items"]
+	if !ok {
+		return nil, fmt.Errorf("field %q: array items are required", path)
+	}
+	item, err := e.compileField(items, path+"[]", sc)
+	if err != nil {
+		return nil, err
+	}
+
+	minItems, _, err := number(def, "min_items")
+	if err != nil {
+		return nil, fmt.Errorf("field %q: %w", path, err)
+	}
+	maxItems, ok, err := number(def, "max_items")
+	if err != nil {
+		return nil, fmt.Errorf("field %q: %w", path, err)
+	}
+	if !ok {
+		maxItems = max(minItems, defaultMaxItems)
+	}
+	if minItems < 0 || maxItems < minItems || maxItems > maxArrayItems {
+		return nil, fmt.Errorf("field %q: array size must satisfy 0 <= min_items <= max_items <= %d", path, maxArrayItems)
+	}
+
+	lo, hi := int(minItems), int(maxItems)
+	return func(r *rand.Rand) interface{} {
+		n := lo + r.IntN(hi-lo+1)
+		values := make([]interface{}, n)
+		for i := range values {
+			values[i] = item(r)
+		}
+		return values
+	}, nil
+}
+
+// nullable wraps gen so that it yields nil with the probability given by the "nullable" key.
+func nullable(def map[string]interface{}, gen Func, path string) (Func, error) {
+	ratio, ok, err := number(def, "nullable")
+	if err != nil || ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("field %q: nullable must be a number between 0 and 1", path)
+	}
+	if !ok || ratio == 0 {
+		return gen, nil
+	}
+	return func(r *rand.Rand) interface{} {
+		if r.Float64() < ratio {
+			return nil
+		}
+		return gen(r)
+	}, nil
+}
+
+func enumOf(values []interface{}, path string) (Func, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("field %q: enum must not be empty", path)
+	}
+	values = append([]interface{}(nil), values...)
+	return func(r *rand.Rand) interface{} { return values[r.IntN(len(values))] }, nil
+}
+
+func lengthString(def map[string]interface{}, path string) (Func, error) {
+	minLen, hasMin, err := number(def, "min_length")
+	if err != nil {
+		return nil, fmt.Errorf("field %q: %w", path, err)
+	}
+	maxLen, hasMax, err := number(def, "max_length")
+	if err != nil {
+		return nil, fmt.Errorf("field %q: %w", path, err)
+	}
+	switch {
+	case !hasMin && !hasMax:
+		minLen, maxLen = 10, 10
+	case !hasMin:
+		minLen = min(1, maxLen)
+	case !hasMax:
+		maxLen = minLen
+	}
+	if minLen < 0 || maxLen < minLen {
+		return nil, fmt.Errorf("field %q: length must satisfy 0 <= min_length <= max_length", path)
+	}
+
+	lo, hi := int(minLen), int(maxLen)
+	return func(r *rand.Rand) interface{} {
+		b := make([]byte, lo+r.IntN(hi-lo+1))
+		for i := range b {
+			b[i] = alphabet[r.IntN(len(alphabet))]
+		}
+		return string(b)
+	}, nil
+}
+
+func dateRange(def map[string]interface{}, typ, path string) (Func, error) {
+	layout := dateLayout
+	if typ == "datetime" {
+		layout = time.RFC3339
+	}
+
+	from, to := defaultDateFrom, defaultDateTo
+	for key, target := range map[string]*time.Time{"min": &from, "max": &to} {
+		raw, ok := def[key]
+		if !ok {
+			continue
+		}
+		s, _ := raw.(string)
+		t, err := parseDate(s)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: invalid %s %v, expected YYYY-MM-DD or RFC 3339", path, key, raw)
+		}
+		*target = t
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("field %q: min is after max", path)
+	}
+
+	span := to.Unix() - from.Unix()
+	return func(r *rand.Rand) interface{} {
+		return time.Unix(from.Unix()+r.Int64N(span+1), 0).UTC().Format(layout)
+	}, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func bounds(def map[string]interface{}, defaultMin, defaultMax float64) (float64, float64, error) {
+	min, hasMin, err := number(def, "min")
+	if err != nil {
+		return 0, 0, err
+	}
+	max, hasMax, err := number(def, "max")
+	if err != nil {
+		return 0, 0, err
+	}
+	if !hasMin {
+		min = defaultMin
+	}
+	if !hasMax {
+		max = defaultMax
+	}
+	if min > max {
+		return 0, 0, fmt.Errorf("min %g is greater than max %g", min, max)
+	}
+	return min, max, nil
+}
+
+// number reads a numeric key, accepting the types produced by encoding/json and Go literals.
+func number(def map[string]interface{}, key string) (float64, bool, error) {
+	raw, ok := def[key]
+	if !ok {
+		return 0, false, nil
+	}
+	f, ok := numeric(raw)
+	if !ok {
+		return 0, false, fmt.Errorf("%s must be a number", key)
+	}
+	return f, true, nil
+}
+
+func numeric(raw interface{}) (float64, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+)
+
+// ErrUnknownGenerator is returned when a template references a generator that is not registered.
+var ErrUnknownGenerator = errors.New("unknown generator")
+
+// Record is a single generated row keyed by template field name.
+type Record map[string]interface{}
+
+// Func produces one value using the supplied source of randomness.
+type Func func(r *rand.Rand) interface{}
+
+// Factory builds a Func from the arguments of a generator spec.
+type Factory func(args []string) (Func, error)
+
+// Engine compiles task templates into schemas using a registry of generators and locale packs.
+type Engine struct {
+	factories map[string]Factory
+	localized map[string]LocalizedFactory
+	locales   map[string]*Locale
+}
+
+// NewEngine creates an engine with all built-in generators and locale packs registered.
+func NewEngine() *Engine {
+	e := &Engine{
+		factories: make(map[string]Factory),
+		localized: make(map[string]LocalizedFactory),
+		locales:   make(map[string]*Locale),
+	}
+	registerBuiltins(e)
+	registerLocalizedBuiltins(e)
+	return e
+}
+
+// Register adds or replaces a generator factory under the given name.
+func (e *Engine) Register(name string, factory Factory) {
+	e.factories[name] = factory
+}
+
+// Compile turns a template into a Schema. Templates with a "schema_version"
+// key use the schema DSL (see SchemaVersion). Otherwise the template maps field
+// names to generator specs: string values are parsed as generator specs, nested
+// maps become nested objects and any other value is emitted as a constant.
+func (e *Engine) Compile(template map[string]interface{}) (*Schema, error) {
+	return e.CompileLocale(template, "")
+}
+
+// CompileLocale is like Compile but makes locale the default for localized generators.
+// An empty locale leaves the choice to the template, then to DefaultLocale.
+func (e *Engine) CompileLocale(template map[string]interface{}, locale string) (*Schema, error) {
+	if len(template) == 0 {
+		return nil, fmt.Errorf("template is empty")
+	}
+	if IsDataset(template) {
+		return nil, fmt.Errorf("template describes a dataset, compile it with CompileDataset")
+	}
+	if locale != "" {
+		if _, err := e.locale(locale); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := template["schema_version"]; ok {
+		return e.compileDocument(template, locale)
+	}
+	return e.compile(template, "", locale)
+}
+
+func (e *Engine) compile(template map[string]interface{}, prefix, locale string) (*Schema, error) {
+	names := sortedKeys(template)
+
+	schema := &Schema{fields: make([]field, 0, len(names))}
+	for _, name := range names {
+		path := prefix + name
+		f := field{name: name}
+
+		switch v := template[name].(type) {
+		case string:
+			gen, err := e.build(v, locale)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", path, err)
+			}
+			f.gen = gen
+		case map[string]interface{}:
+			nested, err := e.compile(v, path+".", locale)
+			if err != nil {
+				return nil, err
+			}
+			f.nested = nested
+		default:
+			f.value = v
+		}
+
+		schema.fields = append(schema.fields, f)
+	}
+
+	return schema, nil
+}
+
+func (e *Engine) build(raw, locale string) (Func, error) {
+	spec, err := ParseSpec(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if localized, ok := e.localized[spec.Name]; ok {
+		switch len(spec.Args) {
+		case 0:
+		case 1:
+			locale = spec.Args[0]
+		default:
+			return nil, fmt.Errorf("%s: expects (locale), got %d arguments", spec, len(spec.Args))
+		}
+		gen, err := e.localize(localized, locale)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", spec, err)
+		}
+		return gen, nil
+	}
+
+	factory, ok := e.factories[spec.Name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownGenerator, spec.Name)
+	}
+
+	gen, err := factory(spec.Args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", spec, err)
+	}
+	return gen, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Schema is a compiled template ready to produce records.
+type Schema struct {
+	fields []field
+}
+
+type field struct {
+	name   string
+	gen    Func
+	nested *Schema
+	value  interface{}
+}
+
+// Fields returns the top-level field names in output order.
+func (s *Schema) Fields() []string {
+	names := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// Generate produces a single record.
+func (s *Schema) Generate(r *rand.Rand) Record {
+	record := make(Record, len(s.fields))
+	for _, f := range s.fields {
+		switch {
+		case f.gen != nil:
+			record[f.name] = f.gen(r)
+		case f.nested != nil:
+			record[f.name] = f.nested.Generate(r)
+		default:
+			record[f.name] = f.value
+		}
+	}
+	return record
+}
+
+// progressStep is how many records are generated between context checks and progress callbacks.
+const progressStep = 1000
+
+// GenerateN produces amount records, stopping early if ctx is cancelled.
+// If progress is not nil it is called periodically with the number of records generated so far.
+func (s *Schema) GenerateN(ctx context.Context, r *rand.Rand, amount int, progress func(generated int)) ([]Record, error) {
+	records := make([]Record, 0, amount)
+	for i := 0; i < amount; i++ {
+		if i%progressStep == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if progress != nil && i > 0 {
+				progress(i)
+			}
+		}
+		records = append(records, s.Generate(r))
+	}
+	return records, nil
+}
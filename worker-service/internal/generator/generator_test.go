@@ -0,0 +1,454 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Spec
+		wantErr bool
+	}{
+		{raw: "name", want: Spec{Name: "name"}},
+		{raw: "{{email}}", want: Spec{Name: "email"}},
+		{raw: " INT( 1 , 100 ) ", want: Spec{Name: "int", Args: []string{"1", "100"}}},
+		{raw: "enum()", want: Spec{Name: "enum"}},
+		{raw: "", wantErr: true},
+		{raw: "int(1,2", wantErr: true},
+		{raw: "(1,2)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseSpec(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompile_UnknownGenerator(t *testing.T) {
+	_, err := NewEngine().Compile(map[string]interface{}{"x": "nope"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnknownGenerator)
+	assert.Contains(t, err.Error(), `field "x"`)
+}
+
+func TestCompile_InvalidArguments(t *testing.T) {
+	engine := NewEngine()
+	for _, spec := range []string{"int(10,1)", "int(a,b)", "uuid(1)", "date(2020-01-01)", "string(0)", "enum()"} {
+		_, err := engine.Compile(map[string]interface{}{"f": spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCompile_EmptyTemplate(t *testing.T) {
+	_, err := NewEngine().Compile(nil)
+	assert.Error(t, err)
+}
+
+func TestSchema_Generate(t *testing.T) {
+	schema, err := NewEngine().Compile(map[string]interface{}{
+		"id":      "uuid",
+		"name":    "name",
+		"email":   "email",
+		"age":     "int(18,65)",
+		"score":   "float(0,10,1)",
+		"born":    "date(1990-01-01,1999-12-31)",
+		"role":    "enum(admin,user)",
+		"active":  "bool",
+		"version": 2,
+		"address": map[string]interface{}{"zip": "string(6)"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"active", "address", "age", "born", "email", "id", "name", "role", "score", "version"}, schema.Fields())
+
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		rec := schema.Generate(r)
+
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), rec["id"])
+		assert.Contains(t, rec["email"], "@")
+		assert.GreaterOrEqual(t, rec["age"].(int64), int64(18))
+		assert.LessOrEqual(t, rec["age"].(int64), int64(65))
+		assert.GreaterOrEqual(t, rec["score"].(float64), 0.0)
+		assert.LessOrEqual(t, rec["score"].(float64), 10.0)
+		assert.Regexp(t, `^199\d-\d{2}-\d{2}$`, rec["born"])
+		assert.Contains(t, []string{"admin", "user"}, rec["role"])
+		assert.IsType(t, true, rec["active"])
+		assert.Equal(t, 2, rec["version"])
+		assert.Len(t, rec["address"].(Record)["zip"], 6)
+	}
+}
+
+func TestSchema_GenerateN(t *testing.T) {
+	schema, err := NewEngine().Compile(map[string]interface{}{"n": "int"})
+	require.NoError(t, err)
+
+	var reported []int
+	records, err := schema.GenerateN(context.Background(), newTestRand(), 2500, func(generated int) {
+		reported = append(reported, generated)
+	})
+	require.NoError(t, err)
+	assert.Len(t, records, 2500)
+	assert.Equal(t, []int{1000, 2000}, reported)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = schema.GenerateN(ctx, newTestRand(), 25, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCompile_SchemaDocument(t *testing.T) {
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schema_version": 1,
+		"fields": {
+			"id": "uuid",
+			"age": {"type": "int", "min": 18, "max": 65},
+			"code": {"type": "string", "regex": "^[A-Z]{3}-\\d{2,4}$"},
+			"role": {"type": "string", "enum": ["admin", "user"]},
+			"nick": {"type": "string", "min_length": 3, "max_length": 5, "nullable": 0.5},
+			"born": {"type": "date", "min": "1990-01-01", "max": "1990-12-31"},
+			"address": {"type": "object", "fields": {"city": {"type": "string", "generator": "word"}}},
+			"tags": {"type": "array", "items": {"type": "int", "enum": [1, 2]}, "min_items": 1, "max_items": 3},
+			"kind": {"type": "const", "value": "person"}
+		}
+	}`), &template))
+
+	schema, err := NewEngine().Compile(template)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"address", "age", "born", "code", "id", "kind", "nick", "role", "tags"}, schema.Fields())
+
+	r := newTestRand()
+	nulls := 0
+	for i := 0; i < 200; i++ {
+		rec := schema.Generate(r)
+
+		assert.GreaterOrEqual(t, rec["age"].(int64), int64(18))
+		assert.LessOrEqual(t, rec["age"].(int64), int64(65))
+		assert.Regexp(t, `^[A-Z]{3}-\d{2,4}$`, rec["code"])
+		assert.Contains(t, []interface{}{"admin", "user"}, rec["role"])
+		assert.Regexp(t, `^1990-\d{2}-\d{2}$`, rec["born"])
+		assert.Contains(t, loremWords, rec["address"].(Record)["city"])
+		assert.Equal(t, "person", rec["kind"])
+
+		tags := rec["tags"].([]interface{})
+		assert.True(t, len(tags) >= 1 && len(tags) <= 3)
+
+		if rec["nick"] == nil {
+			nulls++
+		} else {
+			assert.Regexp(t, `^[A-Za-z0-9]{3,5}$`, rec["nick"])
+		}
+	}
+	assert.InDelta(t, 100, nulls, 30)
+}
+
+func TestCompile_SchemaDocumentErrors(t *testing.T) {
+	engine := NewEngine()
+	for name, template := range map[string]map[string]interface{}{
+		"version":     {"schema_version": 2, "fields": map[string]interface{}{"a": "uuid"}},
+		"no fields":   {"schema_version": 1},
+		"type":        {"schema_version": 1, "fields": map[string]interface{}{"a": map[string]interface{}{"type": "money"}}},
+		"range":       {"schema_version": 1, "fields": map[string]interface{}{"a": map[string]interface{}{"type": "int", "min": 5, "max": 1}}},
+		"regex":       {"schema_version": 1, "fields": map[string]interface{}{"a": map[string]interface{}{"type": "string", "regex": "("}}},
+		"nullable":    {"schema_version": 1, "fields": map[string]interface{}{"a": map[string]interface{}{"type": "bool", "nullable": 2}}},
+		"array items": {"schema_version": 1, "fields": map[string]interface{}{"a": map[string]interface{}{"type": "array"}}},
+	} {
+		_, err := engine.Compile(template)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRegexGenerator(t *testing.T) {
+	for _, pattern := range []string{`[a-f0-9]{8}`, `(foo|bar)+baz?`, `\w+@\w+\.(com|org)`, `[^a-z]{4}`, `(?i)abc`, `.{3}`} {
+		gen, err := newRegex(pattern)
+		require.NoError(t, err, pattern)
+
+		re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		r := newTestRand()
+		for i := 0; i < 50; i++ {
+			assert.Regexp(t, re, gen(r), pattern)
+		}
+	}
+}
+
+func TestCompileLocale(t *testing.T) {
+	schema, err := NewEngine().CompileLocale(map[string]interface{}{
+		"name":    "name",
+		"phone":   "phone",
+		"zip":     "postal_code",
+		"company": "company",
+		"us_name": "name(en_US)",
+	}, "ru-ru")
+	require.NoError(t, err)
+
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		rec := schema.Generate(r)
+
+		parts := strings.Fields(rec["name"].(string))
+		require.Len(t, parts, 3)
+		// Фамилия и отчество согласованы по роду.
+		assert.Equal(t, strings.HasSuffix(parts[0], "а"), strings.HasSuffix(parts[2], "на"), rec["name"])
+		assert.Regexp(t, `^(\+7|8) \(\d{3}\) \d{3}-\d{2}-\d{2}$`, rec["phone"])
+		assert.Regexp(t, `^\d{6}$`, rec["zip"])
+		assert.Regexp(t, `^(ООО|АО|ПАО) «.+»$`, rec["company"])
+		assert.Regexp(t, `^[A-Za-z]+ [A-Za-z]+$`, rec["us_name"])
+	}
+}
+
+func TestCompileLocale_Fallback(t *testing.T) {
+	schema, err := NewEngine().CompileLocale(map[string]interface{}{
+		"name":    "name",
+		"address": "address",
+		"gb_zip":  "postal_code(en_GB)",
+		"gb_name": "first_name(en_GB)",
+	}, "de_AT")
+	require.NoError(t, err)
+
+	r := newTestRand()
+	for i := 0; i < 50; i++ {
+		rec := schema.Generate(r)
+
+		// de_AT has no names of its own and falls back to de_DE.
+		first, last, _ := strings.Cut(rec["name"].(string), " ")
+		assert.Contains(t, append(deDE.FirstNames[male], deDE.FirstNames[female]...), first)
+		assert.Contains(t, deDE.LastNames[male], last)
+		assert.Regexp(t, `, \d{4} (Wien|Graz|Linz|Salzburg|Innsbruck|Klagenfurt|Villach|Wels)$`, rec["address"])
+		assert.Regexp(t, `^[A-Z]{2}\d [A-Z\d][A-Z]{2}$`, rec["gb_zip"])
+		assert.Contains(t, append(enUS.FirstNames[male], enUS.FirstNames[female]...), rec["gb_name"])
+	}
+}
+
+func TestCompileLocale_Errors(t *testing.T) {
+	engine := NewEngine()
+
+	_, err := engine.CompileLocale(map[string]interface{}{"a": "name"}, "xx_XX")
+	assert.ErrorIs(t, err, ErrUnknownLocale)
+
+	_, err = engine.Compile(map[string]interface{}{"a": "name(xx)"})
+	assert.ErrorIs(t, err, ErrUnknownLocale)
+
+	_, err = engine.Compile(map[string]interface{}{"a": "patronymic"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not available for locale en_US")
+
+	_, err = engine.Compile(map[string]interface{}{"schema_version": 1, "locale": "xx", "fields": map[string]interface{}{"a": "name"}})
+	assert.ErrorIs(t, err, ErrUnknownLocale)
+}
+
+func TestCompileLocale_Precedence(t *testing.T) {
+	template := map[string]interface{}{
+		"schema_version": 1,
+		"locale":         "ru_RU",
+		"fields": map[string]interface{}{
+			"city":    "city",
+			"de_city": map[string]interface{}{"type": "string", "generator": "city", "locale": "de_DE"},
+		},
+	}
+	engine := NewEngine()
+	r := newTestRand()
+
+	schema, err := engine.Compile(template)
+	require.NoError(t, err)
+	rec := schema.Generate(r)
+	assert.Contains(t, ruRU.Cities, rec["city"])
+	assert.Contains(t, deDE.Cities, rec["de_city"])
+
+	// The task locale wins over the template default but not over a field's own locale.
+	schema, err = engine.CompileLocale(template, "en_GB")
+	require.NoError(t, err)
+	rec = schema.Generate(r)
+	assert.Contains(t, enGB.Cities, rec["city"])
+	assert.Contains(t, deDE.Cities, rec["de_city"])
+}
+
+func TestIdentifiers(t *testing.T) {
+	engine := NewEngine()
+	specs := map[string][]string{
+		"inn":      {"inn", "inn(12)"},
+		"snils":    {"snils"},
+		"ogrn":     {"ogrn"},
+		"ogrnip":   {"ogrnip"},
+		"passport": {"passport"},
+		"iban":     {"iban", "iban(GB)", "iban(fr)", "iban(it)"},
+		"card":     {"card", "card(visa)", "card(amex)", "card(mir)"},
+		"ssn":      {"ssn"},
+		"ean13":    {"ean13", "ean13(460)"},
+	}
+	require.Len(t, specs, len(Identifiers))
+
+	r := newTestRand()
+	for name, variants := range specs {
+		valid := Identifiers[name].Valid
+		for _, spec := range variants {
+			gen, err := engine.build(spec, "")
+			require.NoError(t, err, spec)
+			invalid, err := engine.build(invalidSpec(spec), "")
+			require.NoError(t, err, spec)
+
+			for i := 0; i < 200; i++ {
+				v := gen(r).(string)
+				assert.True(t, valid(v), "%s: %s", spec, v)
+				w := invalid(r).(string)
+				assert.False(t, valid(w), "%s invalid: %s", spec, w)
+			}
+		}
+	}
+}
+
+// invalidSpec appends the "invalid" argument: "inn" becomes "inn(invalid)", "inn(12)" becomes "inn(12,invalid)".
+func invalidSpec(spec string) string {
+	if base, ok := strings.CutSuffix(spec, ")"); ok {
+		return base + ",invalid)"
+	}
+	return spec + "(invalid)"
+}
+
+func TestValidPassport_YearOfIssue(t *testing.T) {
+	year := time.Now().Year() % 100
+	assert.True(t, ValidPassport(fmt.Sprintf("45%02d 123456", year)))
+	assert.False(t, ValidPassport(fmt.Sprintf("45%02d 123456", year+1)))
+}
+
+func TestIdentifierValidators(t *testing.T) {
+	for _, tt := range []struct {
+		valid func(string) bool
+		good  []string
+		bad   []string
+	}{
+		{ValidINN, []string{"7707083893", "500100732259"}, []string{"7707083894", "500100732258", "770708389", "77070838931"}},
+		{ValidSNILS, []string{"112-233-445 95", "11223344595"}, []string{"112-233-445 96", "001-001-998 00", "112-233-44595"}},
+		{ValidOGRN, []string{"1027700132195"}, []string{"1027700132196", "3027700132195"}},
+		{ValidOGRNIP, []string{"304500116000157"}, []string{"304500116000158", "104500116000157"}},
+		{ValidPassport, []string{"4508 123456"}, []string{"0008 123456", "4550 123456", "4508123456", "4508 000001"}},
+		{ValidIBAN, []string{"DE89370400440532013000", "GB82 WEST 1234 5698 7654 32"}, []string{"DE88370400440532013000", "DE8937040044053201300"}},
+		{ValidCard, []string{"4111111111111111", "3782 822463 10005"}, []string{"4111111111111112", "41111"}},
+		{ValidSSN, []string{"123-45-6789"}, []string{"000-45-6789", "666-45-6789", "900-45-6789", "123-00-6789", "123-45-0000", "123456789"}},
+		{ValidEAN13, []string{"4006381333931"}, []string{"4006381333932", "400638133393"}},
+	} {
+		for _, s := range tt.good {
+			assert.True(t, tt.valid(s), s)
+		}
+		for _, s := range tt.bad {
+			assert.False(t, tt.valid(s), s)
+		}
+	}
+}
+
+func TestIdentifiers_InvalidArguments(t *testing.T) {
+	engine := NewEngine()
+	for _, spec := range []string{"inn(11)", "iban(xx)", "card(diners)", "snils(1)", "ean13(abc)", "ean13(1234567890123)", "ssn(1,invalid)"} {
+		_, err := engine.build(spec, "")
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCompileDataset(t *testing.T) {
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schema_version": 1,
+		"entities": {
+			"order_items": {
+				"parent": "orders",
+				"per_parent": {"min": 1, "max": 4, "distribution": "poisson", "mean": 2},
+				"fields": {
+					"order_id": {"type": "ref", "entity": "orders", "field": "id"},
+					"product_id": {"type": "ref", "entity": "products", "field": "sku"},
+					"qty": "int(1,5)"
+				}
+			},
+			"orders": {
+				"parent": "users",
+				"per_parent": {"min": 0, "max": 3},
+				"fields": {"id": "uuid", "user_id": {"type": "ref", "entity": "users", "field": "id"}}
+			},
+			"products": {"count": 7, "fields": {"sku": "ean13"}},
+			"users": {"fields": {"id": "uuid", "name": "name"}}
+		}
+	}`), &template))
+
+	dataset, err := NewEngine().CompileDataset(template, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users", "orders", "products", "order_items"}, dataset.Entities())
+
+	collections, err := dataset.Generate(context.Background(), newTestRand(), 50, nil)
+	require.NoError(t, err)
+	require.Len(t, collections, 4)
+
+	ids := func(c Collection, field string) map[interface{}]bool {
+		set := make(map[interface{}]bool)
+		for _, rec := range c.Records {
+			set[rec[field]] = true
+		}
+		return set
+	}
+	users, orders, products, items := collections[0], collections[1], collections[2], collections[3]
+	assert.Len(t, products.Records, 7)
+	assert.Len(t, users.Records, 50)
+	assert.Equal(t, []string{"id", "user_id"}, orders.Fields)
+
+	userIDs, orderIDs, skus := ids(users, "id"), ids(orders, "id"), ids(products, "sku")
+	perUser := make(map[interface{}]int)
+	for _, order := range orders.Records {
+		assert.True(t, userIDs[order["user_id"]])
+		perUser[order["user_id"]]++
+	}
+	for _, n := range perUser {
+		assert.LessOrEqual(t, n, 3)
+	}
+
+	perOrder := make(map[interface{}]int)
+	for _, item := range items.Records {
+		assert.True(t, orderIDs[item["order_id"]])
+		assert.True(t, skus[item["product_id"]])
+		perOrder[item["order_id"]]++
+	}
+	assert.Len(t, perOrder, len(orders.Records))
+	for _, n := range perOrder {
+		assert.True(t, n >= 1 && n <= 4)
+	}
+}
+
+func TestCompileDataset_Errors(t *testing.T) {
+	engine := NewEngine()
+	ref := func(entity, field string) map[string]interface{} {
+		return map[string]interface{}{"type": "ref", "entity": entity, "field": field}
+	}
+	for name, entities := range map[string]map[string]interface{}{
+		"unknown parent": {"a": map[string]interface{}{"parent": "b", "per_parent": 1, "fields": map[string]interface{}{"x": "uuid"}}},
+		"no per_parent":  {"a": map[string]interface{}{"fields": map[string]interface{}{"x": "uuid"}}, "b": map[string]interface{}{"parent": "a", "fields": map[string]interface{}{"x": "uuid"}}},
+		"unknown entity": {"a": map[string]interface{}{"fields": map[string]interface{}{"x": ref("b", "id")}}},
+		"unknown field":  {"a": map[string]interface{}{"fields": map[string]interface{}{"id": "uuid"}}, "b": map[string]interface{}{"fields": map[string]interface{}{"x": ref("a", "nope")}}},
+		"self reference": {"a": map[string]interface{}{"fields": map[string]interface{}{"id": "uuid", "x": ref("a", "id")}}},
+		"cycle":          {"a": map[string]interface{}{"fields": map[string]interface{}{"id": ref("b", "id")}}, "b": map[string]interface{}{"fields": map[string]interface{}{"id": ref("a", "id")}}},
+		"distribution":   {"a": map[string]interface{}{"fields": map[string]interface{}{"id": "uuid"}}, "b": map[string]interface{}{"parent": "a", "per_parent": map[string]interface{}{"max": 2, "distribution": "zipf"}, "fields": map[string]interface{}{"x": "uuid"}}},
+	} {
+		_, err := engine.CompileDataset(map[string]interface{}{"schema_version": 1, "entities": entities}, "")
+		assert.Error(t, err, name)
+	}
+
+	_, err := engine.Compile(map[string]interface{}{"schema_version": 1, "fields": map[string]interface{}{"x": ref("a", "id")}})
+	assert.Error(t, err)
+}
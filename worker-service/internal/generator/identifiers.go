@@ -0,0 +1,525 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// invalidArg is the trailing spec argument that asks an identifier generator for
+// values that look right but fail validation, as in "inn(12,invalid)".
+const invalidArg = "invalid"
+
+// Identifier generates checksum-valid test identifiers of one kind and validates them.
+type Identifier struct {
+	// New builds a generator of valid identifiers from the spec arguments.
+	New func(args []string) (func(r *rand.Rand) string, error)
+	// Valid reports whether s is a well-formed identifier with a correct checksum.
+	Valid func(s string) bool
+	// Invalidate turns a valid identifier into an invalid one. When nil, digits are
+	// changed at random until validation fails.
+	Invalidate func(r *rand.Rand, s string) string
+}
+
+// Identifiers are the identifier generators registered by NewEngine, keyed by generator name.
+var Identifiers = map[string]Identifier{
+	"inn":      {New: newINN, Valid: ValidINN},
+	"snils":    {New: fixed(genSNILS), Valid: ValidSNILS},
+	"ogrn":     {New: fixed(genOGRN), Valid: ValidOGRN},
+	"ogrnip":   {New: fixed(genOGRNIP), Valid: ValidOGRNIP},
+	"passport": {New: fixed(genPassport), Valid: ValidPassport, Invalidate: invalidatePassport},
+	"iban":     {New: newIBAN, Valid: ValidIBAN},
+	"card":     {New: newCard, Valid: ValidCard},
+	"ssn":      {New: fixed(genSSN), Valid: ValidSSN, Invalidate: invalidateSSN},
+	"ean13":    {New: newEAN13, Valid: ValidEAN13},
+}
+
+func registerIdentifiers(e *Engine) {
+	for name, id := range Identifiers {
+		e.Register(name, identifierFactory(id))
+	}
+}
+
+func identifierFactory(id Identifier) Factory {
+	return func(args []string) (Func, error) {
+		invalid := len(args) > 0 && strings.EqualFold(args[len(args)-1], invalidArg)
+		if invalid {
+			args = args[:len(args)-1]
+		}
+		gen, err := id.New(args)
+		if err != nil {
+			return nil, err
+		}
+		if !invalid {
+			return func(r *rand.Rand) interface{} { return gen(r) }, nil
+		}
+
+		invalidate := id.Invalidate
+		if invalidate == nil {
+			invalidate = func(r *rand.Rand, s string) string { return changeDigits(r, s, id.Valid) }
+		}
+		return func(r *rand.Rand) interface{} { return invalidate(r, gen(r)) }, nil
+	}
+}
+
+// maxInvalidateAttempts bounds changeDigits; a single changed digit is caught by every
+// checksum used here with high probability, so the bound is never reached in practice.
+const maxInvalidateAttempts = 100
+
+// changeDigits replaces one random digit of s at a time until valid rejects the result.
+func changeDigits(r *rand.Rand, s string, valid func(string) bool) string {
+	var positions []int
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			positions = append(positions, i)
+		}
+	}
+	b := []byte(s)
+	for attempt := 0; attempt < maxInvalidateAttempts && valid(string(b)); attempt++ {
+		b = []byte(s)
+		i := positions[r.IntN(len(positions))]
+		b[i] = byte('0' + (int(b[i]-'0')+1+r.IntN(9))%10)
+	}
+	return string(b)
+}
+
+func fixed(gen func(r *rand.Rand) string) func(args []string) (func(r *rand.Rand) string, error) {
+	return func(args []string) (func(r *rand.Rand) string, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("takes no arguments besides %q, got %d", invalidArg, len(args))
+		}
+		return gen, nil
+	}
+}
+
+// oneOfArg returns the single optional argument, which must be one of allowed, or def.
+func oneOfArg(args []string, def string, allowed []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return def, nil
+	case 1:
+		if !slices.Contains(allowed, strings.ToLower(args[0])) {
+			return "", fmt.Errorf("expects one of %s, got %q", strings.Join(allowed, ", "), args[0])
+		}
+		return strings.ToLower(args[0]), nil
+	default:
+		return "", fmt.Errorf("expects at most one argument besides %q, got %d", invalidArg, len(args))
+	}
+}
+
+func randomDigits(r *rand.Rand, n int) []int {
+	d := make([]int, n)
+	for i := range d {
+		d[i] = r.IntN(10)
+	}
+	return d
+}
+
+func digitString(d []int) string {
+	b := make([]byte, len(d))
+	for i, v := range d {
+		b[i] = byte('0' + v)
+	}
+	return string(b)
+}
+
+// parseDigits returns the digits of s, or nil if s contains anything else.
+func parseDigits(s string) []int {
+	if s == "" {
+		return nil
+	}
+	d := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return nil
+		}
+		d[i] = int(s[i] - '0')
+	}
+	return d
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// weighted returns the sum of d[i]*weights[i].
+func weighted(d []int, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += d[i] * w
+	}
+	return sum
+}
+
+// --- Russian taxpayer number (INN) ---
+
+var (
+	inn10Weights = []int{2, 4, 10, 3, 5, 9, 4, 6, 8}
+	inn11Weights = []int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}
+	inn12Weights = []int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}
+)
+
+func innCheckDigit(d []int, weights []int) int {
+	return weighted(d, weights) % 11 % 10
+}
+
+// newINN accepts an optional length: 10 for organisations (default) or 12 for individuals.
+func newINN(args []string) (func(r *rand.Rand) string, error) {
+	kind, err := oneOfArg(args, "10", []string{"10", "12"})
+	if err != nil {
+		return nil, err
+	}
+	return func(r *rand.Rand) string {
+		if kind == "10" {
+			d := append(regionPrefix(r), randomDigits(r, 7)...)
+			return digitString(append(d, innCheckDigit(d, inn10Weights)))
+		}
+		d := append(regionPrefix(r), randomDigits(r, 8)...)
+		d = append(d, innCheckDigit(d, inn11Weights))
+		return digitString(append(d, innCheckDigit(d, inn12Weights)))
+	}, nil
+}
+
+// regionPrefix returns a two-digit region code between 01 and 99.
+func regionPrefix(r *rand.Rand) []int {
+	code := 1 + r.IntN(99)
+	return []int{code / 10, code % 10}
+}
+
+// ValidINN checks a 10- or 12-digit Russian INN.
+func ValidINN(s string) bool {
+	d := parseDigits(s)
+	switch len(d) {
+	case 10:
+		return d[9] == innCheckDigit(d, inn10Weights)
+	case 12:
+		return d[10] == innCheckDigit(d, inn11Weights) && d[11] == innCheckDigit(d, inn12Weights)
+	default:
+		return false
+	}
+}
+
+// --- Russian insurance number (SNILS) ---
+
+func snilsChecksum(d []int) int {
+	sum := weighted(d, []int{9, 8, 7, 6, 5, 4, 3, 2, 1})
+	if sum > 101 {
+		sum %= 101
+	}
+	if sum == 100 || sum == 101 {
+		return 0
+	}
+	return sum
+}
+
+// genSNILS produces a SNILS formatted as "123-456-789 64". Numbers up to 001-001-998
+// have no checksum and are not issued, so they are skipped.
+func genSNILS(r *rand.Rand) string {
+	n := 1001999 + r.IntN(999999999-1001999+1)
+	d := parseDigits(fmt.Sprintf("%09d", n))
+	return fmt.Sprintf("%s-%s-%s %02d", digitString(d[0:3]), digitString(d[3:6]), digitString(d[6:9]), snilsChecksum(d))
+}
+
+// ValidSNILS checks a SNILS given either as "123-456-789 64" or as 11 digits.
+func ValidSNILS(s string) bool {
+	if len(s) == 14 && s[3] == '-' && s[7] == '-' && s[11] == ' ' {
+		s = s[0:3] + s[4:7] + s[8:11] + s[12:14]
+	}
+	d := parseDigits(s)
+	if len(d) != 11 {
+		return false
+	}
+	n, _ := strconv.Atoi(digitString(d[:9]))
+	return n > 1001998 && d[9]*10+d[10] == snilsChecksum(d)
+}
+
+// --- Russian state registration numbers (OGRN, OGRNIP) ---
+
+// registrationNumber builds sign, two-digit year, region code and random digits, then the check digit
+// (the remainder of the number divided by mod, taken modulo 10).
+func registrationNumber(r *rand.Rand, sign, length int, mod int64) string {
+	year := 2 + r.IntN(24)
+	d := []int{sign, year / 10, year % 10}
+	d = append(d, regionPrefix(r)...)
+	d = append(d, randomDigits(r, length-1-len(d))...)
+	return digitString(d) + strconv.FormatInt(registrationCheck(digitString(d), mod), 10)
+}
+
+func registrationCheck(body string, mod int64) int64 {
+	n, _ := strconv.ParseInt(body, 10, 64)
+	return n % mod % 10
+}
+
+func validRegistration(s string, length int, mod int64, signs string) bool {
+	if len(s) != length || parseDigits(s) == nil || !strings.ContainsRune(signs, rune(s[0])) {
+		return false
+	}
+	return int64(s[length-1]-'0') == registrationCheck(s[:length-1], mod)
+}
+
+func genOGRN(r *rand.Rand) string {
+	sign := 1
+	if r.IntN(2) == 1 {
+		sign = 5
+	}
+	return registrationNumber(r, sign, 13, 11)
+}
+
+func genOGRNIP(r *rand.Rand) string {
+	return registrationNumber(r, 3, 15, 13)
+}
+
+// ValidOGRN checks a 13-digit OGRN of a legal entity.
+func ValidOGRN(s string) bool {
+	return validRegistration(s, 13, 11, "15")
+}
+
+// ValidOGRNIP checks a 15-digit OGRNIP of an individual entrepreneur.
+func ValidOGRNIP(s string) bool {
+	return validRegistration(s, 15, 13, "3")
+}
+
+// --- Russian internal passport ---
+
+// passportRegions are the OKATO region codes used as the first two digits of a passport series.
+var passportRegions = []string{
+	"01", "03", "04", "05", "07", "08", "10", "11", "12", "14", "15", "17", "18", "19", "20", "22", "24", "25", "26", "27",
+	"28", "29", "30", "32", "33", "34", "35", "36", "37", "38", "40", "41", "42", "44", "45", "46", "47", "49", "50", "52",
+	"53", "54", "56", "57", "58", "60", "61", "63", "64", "65", "66", "67", "68", "69", "70", "71", "73", "75", "76", "77",
+	"78", "79", "80", "81", "82", "83", "84", "85", "86", "87", "88", "89", "90", "91", "92", "93", "94", "95", "96", "97",
+	"98", "99",
+}
+
+var passportPattern = regexp.MustCompile(`^(\d{2})(\d{2}) (\d{6})$`)
+
+// genPassport produces "SSYY NNNNNN": region code, two-digit year of issue since 1997 and number.
+// The years end at 2025 rather than the current year, so seeded output does not change with the calendar.
+func genPassport(r *rand.Rand) string {
+	year := (97 + r.IntN(29)) % 100
+	return fmt.Sprintf("%s%02d %06d", pick(r, passportRegions), year, 101+r.IntN(999999-101+1))
+}
+
+// ValidPassport checks the series and number of a Russian internal passport.
+// The series has no checksum, so the region code, the year of issue (1997 up to the current year)
+// and the number range are checked.
+func ValidPassport(s string) bool {
+	m := passportPattern.FindStringSubmatch(s)
+	if m == nil || !slices.Contains(passportRegions, m[1]) {
+		return false
+	}
+	year, _ := strconv.Atoi(m[2])
+	number, _ := strconv.Atoi(m[3])
+	return (year >= 97 || year <= time.Now().Year()%100) && number >= 101
+}
+
+func invalidatePassport(r *rand.Rand, s string) string {
+	unused := []string{"00", "02", "06", "09", "13", "16", "21", "23", "31", "39", "43", "48", "51", "55", "59", "62", "72", "74"}
+	return pick(r, unused) + s[2:]
+}
+
+// --- IBAN ---
+
+// ibanFormats are BBAN patterns by country, where '#' is a digit and '?' an upper-case letter.
+var ibanFormats = map[string]string{
+	"de": "##################",
+	"gb": "????##############",
+	"fr": "#######################",
+	"nl": "????##########",
+	"es": "####################",
+	"it": "?######################",
+	"ch": "#################",
+}
+
+// newIBAN accepts an optional country code; IBANs are German by default.
+func newIBAN(args []string) (func(r *rand.Rand) string, error) {
+	country, err := oneOfArg(args, "de", sortedStrings(ibanFormats))
+	if err != nil {
+		return nil, err
+	}
+	prefix, format := strings.ToUpper(country), ibanFormats[country]
+	return func(r *rand.Rand) string {
+		bban := fill(r, format)
+		return prefix + fmt.Sprintf("%02d", 98-ibanRemainder(bban+prefix+"00")) + bban
+	}, nil
+}
+
+// ibanRemainder returns the ISO 7064 MOD 97-10 remainder of s with letters expanded to 10..35.
+func ibanRemainder(s string) int {
+	rem := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isDigit(c):
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A') + 10) % 97
+		default:
+			return -1
+		}
+	}
+	return rem
+}
+
+// ValidIBAN checks the length of an IBAN for its country, when known, and its check digits.
+// Spaces are ignored.
+func ValidIBAN(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 || !isDigit(s[2]) || !isDigit(s[3]) {
+		return false
+	}
+	if format, ok := ibanFormats[strings.ToLower(s[:2])]; ok && len(s) != len(format)+4 {
+		return false
+	}
+	return ibanRemainder(s[4:]+s[:4]) == 1
+}
+
+// --- Payment cards ---
+
+// cardNetwork describes the BIN ranges and number length of a card network.
+type cardNetwork struct {
+	prefixes [][2]int // inclusive ranges of leading digits
+	length   int
+}
+
+var cardNetworks = map[string]cardNetwork{
+	"visa":       {prefixes: [][2]int{{4, 4}}, length: 16},
+	"mastercard": {prefixes: [][2]int{{51, 55}, {2221, 2720}}, length: 16},
+	"amex":       {prefixes: [][2]int{{34, 34}, {37, 37}}, length: 15},
+	"mir":        {prefixes: [][2]int{{2200, 2204}}, length: 16},
+	"unionpay":   {prefixes: [][2]int{{62, 62}}, length: 16},
+	"discover":   {prefixes: [][2]int{{6011, 6011}, {644, 649}, {65, 65}}, length: 16},
+}
+
+// newCard accepts an optional network; without one the network is chosen at random per value.
+func newCard(args []string) (func(r *rand.Rand) string, error) {
+	names := sortedStrings(cardNetworks)
+	network, err := oneOfArg(args, "", names)
+	if err != nil {
+		return nil, err
+	}
+	return func(r *rand.Rand) string {
+		name := network
+		if name == "" {
+			name = pick(r, names)
+		}
+		n := cardNetworks[name]
+		bin := n.prefixes[r.IntN(len(n.prefixes))]
+		d := parseDigits(strconv.Itoa(bin[0] + r.IntN(bin[1]-bin[0]+1)))
+		d = append(d, randomDigits(r, n.length-1-len(d))...)
+		return digitString(append(d, luhnCheckDigit(d)))
+	}, nil
+}
+
+// luhnCheckDigit returns the digit that makes d followed by it pass the Luhn check.
+func luhnCheckDigit(d []int) int {
+	sum := 0
+	for i := len(d) - 1; i >= 0; i -= 2 {
+		v := d[i] * 2
+		if v > 9 {
+			v -= 9
+		}
+		sum += v
+		if i > 0 {
+			sum += d[i-1]
+		}
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidCard checks that a card number has 12 to 19 digits and passes the Luhn check. Spaces are ignored.
+func ValidCard(s string) bool {
+	d := parseDigits(strings.ReplaceAll(s, " ", ""))
+	if len(d) < 12 || len(d) > 19 {
+		return false
+	}
+	return d[len(d)-1] == luhnCheckDigit(d[:len(d)-1])
+}
+
+// --- US Social Security number ---
+
+var ssnPattern = regexp.MustCompile(`^(\d{3})-(\d{2})-(\d{4})$`)
+
+// genSSN produces "AAA-GG-SSSS" following the SSA rules: the area is never 000, 666 or 900-999,
+// the group never 00 and the serial never 0000.
+func genSSN(r *rand.Rand) string {
+	area := 1 + r.IntN(898)
+	if area >= 666 {
+		area++
+	}
+	return fmt.Sprintf("%03d-%02d-%04d", area, 1+r.IntN(99), 1+r.IntN(9999))
+}
+
+// ValidSSN checks the format of a US Social Security number.
+func ValidSSN(s string) bool {
+	m := ssnPattern.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	return m[1] != "000" && m[1] != "666" && m[1][0] != '9' && m[2] != "00" && m[3] != "0000"
+}
+
+func invalidateSSN(r *rand.Rand, s string) string {
+	switch r.IntN(4) {
+	case 0:
+		return pick(r, []string{"000", "666", fmt.Sprintf("9%02d", r.IntN(100))}) + s[3:]
+	case 1:
+		return s[:4] + "00" + s[6:]
+	case 2:
+		return s[:7] + "0000"
+	default:
+		return strings.ReplaceAll(s, "-", "")
+	}
+}
+
+// --- EAN-13 ---
+
+var eanPrefixPattern = regexp.MustCompile(`^\d{1,12}$`)
+
+// newEAN13 accepts an optional leading digit prefix, such as a GS1 country prefix ("460" for Russia).
+func newEAN13(args []string) (func(r *rand.Rand) string, error) {
+	prefix := ""
+	switch len(args) {
+	case 0:
+	case 1:
+		if !eanPrefixPattern.MatchString(args[0]) {
+			return nil, fmt.Errorf("invalid prefix %q, expected 1 to 12 digits", args[0])
+		}
+		prefix = args[0]
+	default:
+		return nil, fmt.Errorf("expects at most one argument besides %q, got %d", invalidArg, len(args))
+	}
+	return func(r *rand.Rand) string {
+		d := append(parseDigits(prefix), randomDigits(r, 12-len(prefix))...)
+		return digitString(append(d, eanCheckDigit(d)))
+	}, nil
+}
+
+func eanCheckDigit(d []int) int {
+	sum := 0
+	for i, v := range d {
+		if i%2 == 1 {
+			v *= 3
+		}
+		sum += v
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidEAN13 checks the length and check digit of an EAN-13 barcode.
+func ValidEAN13(s string) bool {
+	d := parseDigits(s)
+	return len(d) == 13 && d[12] == eanCheckDigit(d[:12])
+}
+
+func sortedStrings[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
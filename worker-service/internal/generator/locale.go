@@ -0,0 +1,260 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when neither the task nor the template selects a locale.
+const DefaultLocale = "en_US"
+
+// ErrUnknownLocale is returned when a task or template references a locale that is not registered.
+var ErrUnknownLocale = errors.New("unknown locale")
+
+const (
+	male = iota
+	female
+)
+
+// Locale is a pack of locale-specific data for the localized generators
+// (name, first_name, last_name, patronymic, city, street, postal_code, address,
+// phone and company). A generator whose data is missing from a locale is
+// looked up in the Fallback locale, then in its fallback and so on.
+type Locale struct {
+	Code     string
+	Fallback string
+
+	// Name parts are indexed by gender (male, female). An empty female list
+	// means the male forms are used for both.
+	FirstNames  [2][]string
+	LastNames   [2][]string
+	Patronymics [2][]string
+	// NameFormat lays out a full name from {first}, {last} and {patronymic}.
+	NameFormat string
+
+	Cities  []string
+	Regions []string
+	Streets []string
+	// AddressFormat lays out an address from {street}, {building}, {city},
+	// {region} and {postal_code}.
+	AddressFormat string
+	// PostalCode is a pattern where '#' is a digit and '?' an upper-case letter.
+	PostalCode string
+
+	// PhoneFormats are patterns where '#' is a digit.
+	PhoneFormats []string
+
+	// CompanyFormats lay out a company name from {name} (one of CompanyNames) and {last}.
+	CompanyFormats []string
+	CompanyNames   []string
+}
+
+// LocalizedFactory builds a Func from the data of a single locale. It returns nil
+// when the locale lacks that data, so that the next locale of the fallback chain is tried.
+type LocalizedFactory func(l *Locale) Func
+
+// RegisterLocale adds or replaces a locale pack. Codes are case-insensitive and may use
+// '-' or '_'; the first locale registered for a language also answers to the bare
+// language code, so "ru" selects ru_RU.
+func (e *Engine) RegisterLocale(l *Locale) {
+	code := normalizeLocale(l.Code)
+	e.locales[code] = l
+	if lang, _, ok := strings.Cut(code, "_"); ok {
+		if _, taken := e.locales[lang]; !taken {
+			e.locales[lang] = l
+		}
+	}
+}
+
+// RegisterLocalized adds or replaces a generator whose output depends on the locale.
+// Its spec accepts an optional locale argument, as in "name(ru_RU)".
+func (e *Engine) RegisterLocalized(name string, factory LocalizedFactory) {
+	e.localized[name] = factory
+}
+
+// locale resolves a locale code, falling back to DefaultLocale for an empty code.
+func (e *Engine) locale(code string) (*Locale, error) {
+	if code == "" {
+		code = DefaultLocale
+	}
+	l, ok := e.locales[normalizeLocale(code)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownLocale, code)
+	}
+	return l, nil
+}
+
+// localize builds a localized generator for the first locale of the fallback chain that supports it.
+func (e *Engine) localize(factory LocalizedFactory, code string) (Func, error) {
+	l, err := e.locale(code)
+	if err != nil {
+		return nil, err
+	}
+	requested := l.Code
+	// The hop limit guards against fallback cycles in custom packs.
+	for hops := 0; l != nil && hops <= len(e.locales); hops++ {
+		if gen := factory(l); gen != nil {
+			return gen, nil
+		}
+		l = e.locales[normalizeLocale(l.Fallback)]
+	}
+	return nil, fmt.Errorf("not available for locale %s", requested)
+}
+
+// normalizeLocale turns "ru-ru" or "RU_ru" into "ru_RU".
+func normalizeLocale(code string) string {
+	lang, region, ok := strings.Cut(strings.ReplaceAll(strings.TrimSpace(code), "-", "_"), "_")
+	if !ok {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "_" + strings.ToUpper(region)
+}
+
+func registerLocalizedBuiltins(e *Engine) {
+	for _, l := range locales {
+		e.RegisterLocale(l)
+	}
+
+	e.RegisterLocalized("first_name", func(l *Locale) Func {
+		if len(l.FirstNames[male]) == 0 {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} { return pick(r, gendered(l.FirstNames, r.IntN(2))) }
+	})
+	e.RegisterLocalized("last_name", func(l *Locale) Func {
+		if len(l.LastNames[male]) == 0 {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} { return pick(r, gendered(l.LastNames, r.IntN(2))) }
+	})
+	e.RegisterLocalized("patronymic", func(l *Locale) Func {
+		if len(l.Patronymics[male]) == 0 {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} { return pick(r, gendered(l.Patronymics, r.IntN(2))) }
+	})
+	e.RegisterLocalized("name", func(l *Locale) Func {
+		if len(l.FirstNames[male]) == 0 || len(l.LastNames[male]) == 0 || l.NameFormat == "" {
+			return nil
+		}
+		if strings.Contains(l.NameFormat, "{patronymic}") && len(l.Patronymics[male]) == 0 {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} {
+			// All parts of one name agree in gender.
+			g := r.IntN(2)
+			return expand(l.NameFormat, func(key string) string {
+				switch key {
+				case "first":
+					return pick(r, gendered(l.FirstNames, g))
+				case "last":
+					return pick(r, gendered(l.LastNames, g))
+				case "patronymic":
+					return pick(r, gendered(l.Patronymics, g))
+				}
+				return ""
+			})
+		}
+	})
+	e.RegisterLocalized("city", func(l *Locale) Func {
+		if len(l.Cities) == 0 {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} { return pick(r, l.Cities) }
+	})
+	e.RegisterLocalized("street", func(l *Locale) Func {
+		if len(l.Streets) == 0 {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} { return pick(r, l.Streets) }
+	})
+	e.RegisterLocalized("postal_code", func(l *Locale) Func {
+		if l.PostalCode == "" {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} { return fill(r, l.PostalCode) }
+	})
+	e.RegisterLocalized("address", func(l *Locale) Func {
+		if l.AddressFormat == "" || len(l.Streets) == 0 || len(l.Cities) == 0 || l.PostalCode == "" {
+			return nil
+		}
+		if strings.Contains(l.AddressFormat, "{region}") && len(l.Regions) == 0 {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} {
+			return expand(l.AddressFormat, func(key string) string {
+				switch key {
+				case "street":
+					return pick(r, l.Streets)
+				case "building":
+					return strconv.Itoa(1 + r.IntN(199))
+				case "city":
+					return pick(r, l.Cities)
+				case "region":
+					return pick(r, l.Regions)
+				case "postal_code":
+					return fill(r, l.PostalCode)
+				}
+				return ""
+			})
+		}
+	})
+	e.RegisterLocalized("phone", func(l *Locale) Func {
+		if len(l.PhoneFormats) == 0 {
+			return nil
+		}
+		return func(r *rand.Rand) interface{} { return fill(r, pick(r, l.PhoneFormats)) }
+	})
+	e.RegisterLocalized("company", func(l *Locale) Func {
+		if len(l.CompanyFormats) == 0 || len(l.CompanyNames) == 0 {
+			return nil
+		}
+		for _, format := range l.CompanyFormats {
+			if strings.Contains(format, "{last}") && len(l.LastNames[male]) == 0 {
+				return nil
+			}
+		}
+		return func(r *rand.Rand) interface{} {
+			return expand(pick(r, l.CompanyFormats), func(key string) string {
+				switch key {
+				case "name":
+					return pick(r, l.CompanyNames)
+				case "last":
+					return pick(r, l.LastNames[male])
+				}
+				return ""
+			})
+		}
+	})
+}
+
+// gendered returns the forms for gender g, using the male forms when a locale does not distinguish them.
+func gendered(forms [2][]string, g int) []string {
+	if len(forms[g]) == 0 {
+		return forms[male]
+	}
+	return forms[g]
+}
+
+// expand replaces every {key} in format with value(key).
+func expand(format string, value func(key string) string) string {
+	var sb strings.Builder
+	for {
+		open := strings.IndexByte(format, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(format[open:], '}')
+		if end < 0 {
+			break
+		}
+		sb.WriteString(format[:open])
+		sb.WriteString(value(format[open+1 : open+end]))
+		format = format[open+end+1:]
+	}
+	sb.WriteString(format)
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package generator
+
+// locales are the locale packs registered by NewEngine. Regional packs fall back to the
+// main pack of their language and every language falls back to en_US.
+var locales = []*Locale{enUS, enGB, ruRU, deDE, deAT}
+
+var enUS = &Locale{
+	Code: "en_US",
+	FirstNames: [2][]string{
+		{"James", "John", "Robert", "Michael", "William", "David", "Richard", "Joseph", "Thomas", "Charles"},
+		{"Mary", "Patricia", "Jennifer", "Linda", "Elizabeth", "Barbara", "Susan", "Jessica", "Sarah", "Karen"},
+	},
+	LastNames: [2][]string{
+		{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin"},
+	},
+	NameFormat:     "{first} {last}",
+	Cities:         []string{"New York", "Los Angeles", "Chicago", "Houston", "Phoenix", "Philadelphia", "San Antonio", "San Diego", "Dallas", "Austin", "Seattle", "Denver", "Boston", "Portland", "Atlanta"},
+	Regions:        []string{"CA", "TX", "NY", "FL", "IL", "PA", "OH", "GA", "WA", "CO", "MA", "OR"},
+	Streets:        []string{"Main St", "Oak St", "Pine St", "Maple Ave", "Cedar St", "Elm St", "Washington Ave", "Lake St", "Hill St", "Park Ave", "Sunset Blvd", "River Rd"},
+	AddressFormat:  "{building} {street}, {city}, {region} {postal_code}",
+	PostalCode:     "#####",
+	PhoneFormats:   []string{"+1 (###) ###-####"},
+	CompanyFormats: []string{"{last} Inc.", "{last} LLC", "{last} & {last} LLP", "{name} Corp."},
+	CompanyNames:   []string{"Acme", "Globex", "Initech", "Umbrella", "Vandelay", "Hooli", "Soylent", "Cyberdyne", "Wonka", "Oscorp"},
+}
+
+var enGB = &Locale{
+	Code:           "en_GB",
+	Fallback:       "en_US",
+	Cities:         []string{"London", "Manchester", "Birmingham", "Leeds", "Glasgow", "Liverpool", "Bristol", "Sheffield", "Edinburgh", "Cardiff"},
+	Streets:        []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Green Lane", "Manor Road", "Park Road", "Queen Street"},
+	AddressFormat:  "{building} {street}, {city} {postal_code}",
+	PostalCode:     "??# #??",
+	PhoneFormats:   []string{"+44 7### ######", "+44 20 #### ####"},
+	CompanyFormats: []string{"{name} Ltd", "{name} Holdings Ltd", "{name} plc"},
+	CompanyNames:   []string{"Albion", "Crown", "Thames", "Sovereign", "Pennine", "Highland", "Britannia", "Kingsway"},
+}
+
+var ruRU = &Locale{
+	Code:     "ru_RU",
+	Fallback: "en_US",
+	FirstNames: [2][]string{
+		{"Александр", "Дмитрий", "Максим", "Сергей", "Андрей", "Алексей", "Артём", "Илья", "Кирилл", "Михаил", "Никита", "Иван", "Егор", "Владимир", "Павел"},
+		{"Анна", "Мария", "Елена", "Ольга", "Наталья", "Татьяна", "Ирина", "Екатерина", "Светлана", "Юлия", "Анастасия", "Дарья", "Ксения", "Виктория", "Полина"},
+	},
+	LastNames: [2][]string{
+		{"Иванов", "Смирнов", "Кузнецов", "Попов", "Васильев", "Петров", "Соколов", "Михайлов", "Новиков", "Фёдоров", "Морозов", "Волков", "Алексеев", "Лебедев", "Семёнов"},
+		{"Иванова", "Смирнова", "Кузнецова", "Попова", "Васильева", "Петрова", "Соколова", "Михайлова", "Новикова", "Фёдорова", "Морозова", "Волкова", "Алексеева", "Лебедева", "Семёнова"},
+	},
+	Patronymics: [2][]string{
+		{"Александрович", "Дмитриевич", "Сергеевич", "Андреевич", "Алексеевич", "Михайлович", "Иванович", "Владимирович", "Николаевич", "Петрович", "Юрьевич", "Викторович"},
+		{"Александровна", "Дмитриевна", "Сергеевна", "Андреевна", "Алексеевна", "Михайловна", "Ивановна", "Владимировна", "Николаевна", "Петровна", "Юрьевна", "Викторовна"},
+	},
+	NameFormat:     "{last} {first} {patronymic}",
+	Cities:         []string{"Москва", "Санкт-Петербург", "Новосибирск", "Екатеринбург", "Казань", "Нижний Новгород", "Челябинск", "Самара", "Омск", "Ростов-на-Дону", "Уфа", "Красноярск", "Воронеж", "Пермь", "Волгоград"},
+	Streets:        []string{"Ленина", "Советская", "Мира", "Садовая", "Школьная", "Лесная", "Молодёжная", "Центральная", "Гагарина", "Пушкина", "Набережная", "Победы", "Зелёная", "Октябрьская", "Кирова"},
+	AddressFormat:  "{postal_code}, г. {city}, ул. {street}, д. {building}",
+	PostalCode:     "######",
+	PhoneFormats:   []string{"+7 (9##) ###-##-##", "+7 (4##) ###-##-##", "8 (9##) ###-##-##"},
+	CompanyFormats: []string{"ООО «{name}»", "АО «{name}»", "ПАО «{name}»", "ООО «{last} и партнёры»"},
+	CompanyNames:   []string{"Вектор", "Альфа", "Гранит", "Северный ветер", "Технопарк", "Горизонт", "Стройресурс", "Меридиан", "Импульс", "Сфера", "Восход", "Прогресс"},
+}
+
+var deDE = &Locale{
+	Code:     "de_DE",
+	Fallback: "en_US",
+	FirstNames: [2][]string{
+		{"Lukas", "Leon", "Finn", "Jonas", "Paul", "Felix", "Maximilian", "Elias", "Noah", "Ben"},
+		{"Mia", "Emma", "Hannah", "Sofia", "Lena", "Anna", "Lea", "Marie", "Laura", "Clara"},
+	},
+	LastNames: [2][]string{
+		{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann", "Schäfer", "Koch", "Bauer", "Richter", "Klein"},
+	},
+	NameFormat:     "{first} {last}",
+	Cities:         []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt am Main", "Stuttgart", "Düsseldorf", "Leipzig", "Dortmund", "Essen", "Bremen", "Dresden"},
+	Streets:        []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Dorfstraße", "Bergstraße", "Birkenweg", "Lindenstraße", "Kirchstraße", "Waldstraße"},
+	AddressFormat:  "{street} {building}, {postal_code} {city}",
+	PostalCode:     "#####",
+	PhoneFormats:   []string{"+49 15# ########", "+49 30 ########"},
+	CompanyFormats: []string{"{last} GmbH", "{last} & {last} KG", "{name} AG"},
+	CompanyNames:   []string{"Nordwind", "Sonnenschein", "Weitblick", "Lichtwerk", "Bergland", "Rheintal", "Eichenhof", "Silberpfeil"},
+}
+
+var deAT = &Locale{
+	Code:          "de_AT",
+	Fallback:      "de_DE",
+	Cities:        []string{"Wien", "Graz", "Linz", "Salzburg", "Innsbruck", "Klagenfurt", "Villach", "Wels"},
+	Streets:       []string{"Hauptstraße", "Bahnhofstraße", "Kirchengasse", "Schulgasse", "Mariahilfer Straße", "Ringstraße", "Landstraße"},
+	AddressFormat: "{street} {building}, {postal_code} {city}",
+	PostalCode:    "####",
+	PhoneFormats:  []string{"+43 6## #######", "+43 1 #######"},
+}
@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxRegexRepeat bounds unbounded quantifiers such as * and + when generating.
+	maxRegexRepeat = 8
+	// maxRegexLength is the longest pattern accepted by newRegex.
+	maxRegexLength = 256
+)
+
+// newRegex builds a generator producing strings that match pattern.
+// Anchors and word boundaries are accepted but ignored; negated and "any"
+// classes are narrowed to printable ASCII where possible.
+func newRegex(pattern string) (Func, error) {
+	if len(pattern) > maxRegexLength {
+		return nil, fmt.Errorf("regex is longer than %d characters", maxRegexLength)
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	re = re.Simplify()
+
+	return func(r *rand.Rand) interface{} {
+		var sb strings.Builder
+		writeRegex(r, &sb, re)
+		return sb.String()
+	}, nil
+}
+
+func writeRegex(r *rand.Rand, sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && r.IntN(2) == 1 {
+				c = unicode.SimpleFold(c)
+			}
+			sb.WriteRune(c)
+		}
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 {
+			sb.WriteRune(pickRune(r, re.Rune))
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(rune(' ' + r.IntN('~'-' '+1)))
+	case syntax.OpCapture:
+		writeRegex(r, sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeRegex(r, sb, sub)
+		}
+	case syntax.OpAlternate:
+		writeRegex(r, sb, re.Sub[r.IntN(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatBounds(re)
+		n := min
+		if max > min {
+			n += r.IntN(max - min + 1)
+		}
+		for i := 0; i < n; i++ {
+			writeRegex(r, sb, re.Sub[0])
+		}
+	}
+}
+
+func repeatBounds(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxRegexRepeat
+	case syntax.OpPlus:
+		return 1, maxRegexRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	if re.Max < 0 {
+		return re.Min, re.Min + maxRegexRepeat
+	}
+	return re.Min, re.Max
+}
+
+// pickRune chooses a rune uniformly from a class given as [lo, hi] pairs,
+// preferring the part of the class that lies in printable ASCII.
+func pickRune(r *rand.Rand, ranges []rune) rune {
+	if printable := clampRanges(ranges, ' ', '~'); len(printable) > 0 {
+		ranges = printable
+	}
+
+	total := 0
+	for i := 0; i < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	n := r.IntN(total)
+	for i := 0; i < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n)
+		}
+		n -= size
+	}
+	return ranges[0]
+}
+
+func clampRanges(ranges []rune, lo, hi rune) []rune {
+	var out []rune
+	for i := 0; i < len(ranges); i += 2 {
+		a, b := max(ranges[i], lo), min(ranges[i+1], hi)
+		if a <= b {
+			out = append(out, a, b)
+		}
+	}
+	return out
+}
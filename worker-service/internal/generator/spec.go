@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spec is a parsed generator specification such as "int(1,100)".
+type Spec struct {
+	Name string
+	Args []string
+}
+
+// ParseSpec parses a template field value into a Spec.
+// Accepted forms are "name", "name(arg1,arg2)" and the legacy "{{name}}".
+func ParseSpec(raw string) (Spec, error) {
+	s := strings.TrimSpace(raw)
+	if strings.HasPrefix(s, "{{") && strings.HasSuffix(s, "}}") {
+		s = strings.TrimSpace(s[2 : len(s)-2])
+	}
+	if s == "" {
+		return Spec{}, fmt.Errorf("empty generator spec")
+	}
+
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		return Spec{Name: strings.ToLower(s)}, nil
+	}
+	if !strings.HasSuffix(s, ")") {
+		return Spec{}, fmt.Errorf("malformed generator spec %q: missing closing parenthesis", raw)
+	}
+
+	name := strings.ToLower(strings.TrimSpace(s[:open]))
+	if name == "" {
+		return Spec{}, fmt.Errorf("malformed generator spec %q: missing generator name", raw)
+	}
+
+	inner := strings.TrimSpace(s[open+1 : len(s)-1])
+	var args []string
+	if inner != "" {
+		for _, arg := range strings.Split(inner, ",") {
+			args = append(args, strings.TrimSpace(arg))
+		}
+	}
+
+	return Spec{Name: name, Args: args}, nil
+}
+
+func (s Spec) String() string {
+	if len(s.Args) == 0 {
+		return s.Name
+	}
+	return fmt.Sprintf("%s(%s)", s.Name, strings.Join(s.Args, ","))
+}
@@ -0,0 +1,42 @@
+package models
+
+// Report summarizes the requests of an http task.
+type Report struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// Errors counts the failed requests that got no response at all.
+	Errors      int              `json:"errors"`
+	StatusCodes map[string]int   `json:"status_codes,omitempty"`
+	Latency     LatencySummary   `json:"latency"`
+	Throughput  *Throughput      `json:"throughput,omitempty"`
+	Failures    []RequestFailure `json:"failures,omitempty"`
+}
+
+// Throughput compares the request rate a task achieved with the rate its load profile
+// asked for. TargetRPS is 0 for tasks without a rate limit.
+type Throughput struct {
+	ElapsedSec  float64 `json:"elapsed_sec"`
+	TargetRPS   float64 `json:"target_rps,omitempty"`
+	AchievedRPS float64 `json:"achieved_rps"`
+}
+
+// LatencySummary describes the distribution of request latencies in milliseconds.
+type LatencySummary struct {
+	Min  float64 `json:"min_ms"`
+	Mean float64 `json:"mean_ms"`
+	P50  float64 `json:"p50_ms"`
+	P95  float64 `json:"p95_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+}
+
+// RequestFailure is a sample of a failed request.
+type RequestFailure struct {
+	Index     int      `json:"index"`
+	URL       string   `json:"url"`
+	Status    int      `json:"status"`
+	LatencyMs float64  `json:"latency_ms"`
+	Reasons   []string `json:"reasons"`
+	Response  string   `json:"response,omitempty"`
+}
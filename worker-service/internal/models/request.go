@@ -0,0 +1,62 @@
+package models
+
+// TaskTypeHTTP is the type of tasks that send every generated record to a target API.
+const TaskTypeHTTP = "http"
+
+// HTTPRequest describes the request an http task sends for every generated record.
+type HTTPRequest struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Params      map[string]string `json:"params,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	Concurrency int               `json:"concurrency,omitempty"`
+	Rate        float64           `json:"rate,omitempty"`
+	Load        *LoadProfile      `json:"load,omitempty"`
+	TimeoutMs   int               `json:"timeout_ms,omitempty"`
+	Assertions  *Assertions       `json:"assertions,omitempty"`
+}
+
+// Load profile types.
+const (
+	LoadConstant = "constant"
+	LoadRamp     = "ramp"
+	LoadStep     = "step"
+	LoadSpike    = "spike"
+	LoadSoak     = "soak"
+)
+
+// LoadProfile shapes the request rate of an http task over time.
+type LoadProfile struct {
+	Type        string  `json:"type"`
+	Rate        float64 `json:"rate"`
+	StartRate   float64 `json:"start_rate,omitempty"`
+	DurationSec int     `json:"duration_sec,omitempty"`
+	Steps       int     `json:"steps,omitempty"`
+	SpikeAtSec  int     `json:"spike_at_sec,omitempty"`
+	SpikeSec    int     `json:"spike_sec,omitempty"`
+	Burst       int     `json:"burst,omitempty"`
+}
+
+// Assertions are the checks a response must pass for its request to count as passed.
+type Assertions struct {
+	Status       []int             `json:"status,omitempty"`
+	MaxLatencyMs int               `json:"max_latency_ms,omitempty"`
+	Headers      []HeaderAssertion `json:"headers,omitempty"`
+	Body         []BodyAssertion   `json:"body,omitempty"`
+}
+
+// HeaderAssertion requires a response header, optionally with a value or matching a regex.
+type HeaderAssertion struct {
+	Name   string `json:"name"`
+	Equals string `json:"equals,omitempty"`
+	Regex  string `json:"regex,omitempty"`
+}
+
+// BodyAssertion checks the value at JSONPath of a JSON response body, or the whole body
+// against Regex if JSONPath is empty.
+type BodyAssertion struct {
+	JSONPath string      `json:"json_path,omitempty"`
+	Equals   interface{} `json:"equals,omitempty"`
+	Regex    string      `json:"regex,omitempty"`
+}
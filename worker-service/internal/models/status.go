@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// StatusEvent reports task progress back to task-service.
+// Events of a shard carry its index in Shard.
+type StatusEvent struct {
+	TaskID           string    `json:"task_id"`
+	Shard            *int      `json:"shard,omitempty"`
+	Status           string    `json:"status"`
+	RecordsGenerated int       `json:"records_generated"`
+	Error            string    `json:"error,omitempty"`
+	ResultKey        string    `json:"result_key,omitempty"`
+	ResultSize       int64     `json:"result_size,omitempty"`
+	Report           *Report   `json:"report,omitempty"`
+	Timestamp        time.Time `json:"timestamp"`
+}
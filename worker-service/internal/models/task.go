@@ -1,6 +1,46 @@
 package models
 
+import "time"
+
+// Task is the message published by task-service to Kafka.
 type Task struct {
-	ID      int64                  `json:"id"`
-	Content map[string]interface{} `json:"content"`
+	ID              int64                  `json:"id"`
+	TaskID          string                 `json:"task_id"`
+	UserID          string                 `json:"user_id"`
+	Type            string                 `json:"type"`
+	TemplateID      string                 `json:"template_id"`
+	TemplateVersion int                    `json:"template_version"`
+	Template        map[string]interface{} `json:"template"`
+	Amount          int                    `json:"amount"`
+	Seed            int64                  `json:"seed"`
+	Locale          string                 `json:"locale,omitempty"`
+	Format          string                 `json:"format"`
+	TableName       string                 `json:"table_name,omitempty"`
+	SQLDialect      string                 `json:"sql_dialect,omitempty"`
+	Request         *HTTPRequest           `json:"request,omitempty"`
+	Status          string                 `json:"status"`
+	ShardCount      int                    `json:"shard_count,omitempty"`
+	// Shard is set on the messages of the individual shards of a sharded task, and
+	// Merge on the message asking to assemble its result once all shards have succeeded.
+	Shard *Shard `json:"shard,omitempty"`
+	Merge bool   `json:"merge,omitempty"`
+}
+
+// Shard is one part of a sharded task: Amount records starting at record Offset.
+type Shard struct {
+	Index  int `json:"index"`
+	Count  int `json:"count"`
+	Offset int `json:"offset"`
+	Amount int `json:"amount"`
+}
+
+// Last reports whether s is the last shard of its task.
+func (s *Shard) Last() bool {
+	return s.Index == s.Count-1
+}
+
+// CancelCommand is published by task-service when a user cancels a task.
+type CancelCommand struct {
+	TaskID      string    `json:"task_id"`
+	RequestedAt time.Time `json:"requested_at"`
 }
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"worker-service/internal/generator"
+)
+
+// csvWriter renders records as RFC 4180 CSV with a header row.
+type csvWriter struct {
+	w      *csv.Writer
+	fields []string
+	row    []string
+}
+
+// newCSVWriter creates a CSV writer; the header row is omitted when continuing earlier output.
+func newCSVWriter(w io.Writer, fields []string, continued bool) (*csvWriter, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("csv output requires at least one field")
+	}
+
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	if !continued {
+		if err := cw.Write(fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return &csvWriter{w: cw, fields: fields, row: make([]string, len(fields))}, nil
+}
+
+func (c *csvWriter) Write(record generator.Record) error {
+	for i, name := range c.fields {
+		value, err := formatCell(record[name])
+		if err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+		c.row[i] = value
+	}
+	return c.w.Write(c.row)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+// formatCell converts a generated value into its textual CSV form.
+// Nested values are embedded as JSON.
+func formatCell(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int:
+		return strconv.Itoa(val), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
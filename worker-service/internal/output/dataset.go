@@ -0,0 +1,83 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"worker-service/internal/generator"
+)
+
+// RenderDataset renders the collections of a multi-entity dataset, which are in dependency order:
+//
+//   - sql: the INSERTs of every entity in turn, into a table named after the entity
+//     (qualified with Options.TableName as schema when set), so parents precede children;
+//   - json: an object mapping each entity to the array of its records;
+//   - ndjson: one {"entity": ..., "record": ...} line per record.
+//
+// CSV holds a single table and cannot represent a dataset.
+func RenderDataset(ctx context.Context, format string, w io.Writer, collections []generator.Collection, opts Options) error {
+	switch normalizeFormat(format) {
+	case FormatSQL:
+		for _, c := range collections {
+			table := c.Name
+			if opts.TableName != "" {
+				table = opts.TableName + "." + c.Name
+			}
+			rw, err := newSQLWriter(w, c.Fields, Options{TableName: table, SQLDialect: opts.SQLDialect})
+			if err != nil {
+				return fmt.Errorf("entity %q: %w", c.Name, err)
+			}
+			if err := writeRecords(ctx, rw, c.Records); err != nil {
+				return fmt.Errorf("entity %q: %w", c.Name, err)
+			}
+		}
+		return nil
+	case FormatJSON:
+		for i, c := range collections {
+			sep := ",\n"
+			if i == 0 {
+				sep = "{\n"
+			}
+			name, err := json.Marshal(c.Name)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s%s: ", sep, name); err != nil {
+				return err
+			}
+			if err := writeRecords(ctx, newJSONWriter(w, 0, false), c.Records); err != nil {
+				return fmt.Errorf("entity %q: %w", c.Name, err)
+			}
+		}
+		if len(collections) == 0 {
+			_, err := io.WriteString(w, "{}\n")
+			return err
+		}
+		_, err := io.WriteString(w, "}\n")
+		return err
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, c := range collections {
+			for i, record := range c.Records {
+				if i%renderCheckStep == 0 {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+				}
+				line := struct {
+					Entity string           `json:"entity"`
+					Record generator.Record `json:"record"`
+				}{c.Name, record}
+				if err := enc.Encode(line); err != nil {
+					return fmt.Errorf("entity %q: failed to render record: %w", c.Name, err)
+				}
+			}
+		}
+		return nil
+	case FormatCSV:
+		return fmt.Errorf("csv output supports a single entity, use json, ndjson or sql for datasets")
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
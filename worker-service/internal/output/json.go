@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"worker-service/internal/generator"
+)
+
+// jsonWriter renders records as a single JSON array, one element per line.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+	open  bool
+}
+
+// newJSONWriter creates a writer continuing an array that already holds offset records.
+// An open writer does not close the array, so that another writer can continue it.
+func newJSONWriter(w io.Writer, offset int, open bool) *jsonWriter {
+	return &jsonWriter{w: w, count: offset, open: open}
+}
+
+func (j *jsonWriter) Write(record generator.Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Flush() error {
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	if j.open {
+		return nil
+	}
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+// ndjsonWriter renders records as newline-delimited JSON.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{enc: json.NewEncoder(w)}
+}
+
+func (n *ndjsonWriter) Write(record generator.Record) error {
+	return n.enc.Encode(record)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,183 @@
+package output
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+	"worker-service/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// renderCheckStep is how many records are rendered between context checks.
+const renderCheckStep = 1000
+
+// Artifact describes a stored task result.
+type Artifact struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Sink receives the records generated for a task.
+type Sink interface {
+	// Stream renders the batches of a task result as they arrive, uploading the output in
+	// chunks and checkpointing after every chunk. It continues the output recorded in from,
+	// if not nil, and returns once batches is closed and the result is stored.
+	Stream(ctx context.Context, task models.Task, fields []string, from *Checkpoint, batches <-chan Batch) (Artifact, error)
+	// Checkpoint returns the last checkpoint stored for the task, or nil if it has none.
+	Checkpoint(ctx context.Context, task models.Task) (*Checkpoint, error)
+	// Discard removes the chunks and checkpoint of a task that will not be resumed.
+	Discard(ctx context.Context, task models.Task) error
+	// WriteDataset stores the collections of a multi-entity dataset in one artifact.
+	WriteDataset(ctx context.Context, task models.Task, collections []generator.Collection) (Artifact, error)
+	// Merge assembles the shard results of a sharded task into the task result and
+	// removes them.
+	Merge(ctx context.Context, task models.Task) (Artifact, error)
+}
+
+type artifactSink struct {
+	store     storage.ArtifactStore
+	chunkSize int
+	logger    *zap.SugaredLogger
+}
+
+// NewArtifactSink creates a Sink that renders records in the task's format and uploads
+// the result to store under results/<task_id>.<ext>. Streamed results are uploaded in
+// chunks of about chunkSize bytes.
+func NewArtifactSink(store storage.ArtifactStore, chunkSize int, logger *zap.SugaredLogger) Sink {
+	return &artifactSink{store: store, chunkSize: chunkSize, logger: logger}
+}
+
+// ResultKey returns the artifact key for a task result. The result of a shard is kept
+// under shards/<task_id>/<index>.<ext> until the shards are merged.
+func ResultKey(task models.Task) string {
+	if task.Shard != nil {
+		return "shards/" + partName(task) + "." + Extension(task.Format)
+	}
+	return "results/" + task.TaskID + "." + Extension(task.Format)
+}
+
+// partName names the output of a task, or of one shard of it, in storage keys.
+func partName(task models.Task) string {
+	if task.Shard == nil {
+		return task.TaskID
+	}
+	return fmt.Sprintf("%s/%d", task.TaskID, task.Shard.Index)
+}
+
+// shardTask returns the message of the shard index of a sharded task.
+func shardTask(task models.Task, index int) models.Task {
+	task.Shard = &models.Shard{Index: index, Count: task.ShardCount}
+	task.Merge = false
+	return task
+}
+
+func (s *artifactSink) Merge(ctx context.Context, task models.Task) (Artifact, error) {
+	if task.ShardCount < 1 {
+		return Artifact{}, fmt.Errorf("task %s has no shards to merge", task.TaskID)
+	}
+	parts := make([]string, task.ShardCount)
+	for i := range parts {
+		parts[i] = ResultKey(shardTask(task, i))
+	}
+
+	artifact := Artifact{Key: ResultKey(task), ContentType: ContentType(task.Format)}
+	size, err := s.store.Compose(ctx, artifact.Key, parts, artifact.ContentType)
+	if err != nil {
+		return Artifact{}, fmt.Errorf("failed to merge shards: %w", err)
+	}
+	artifact.Size = size
+
+	for _, part := range parts {
+		if err := s.store.Delete(ctx, part); err != nil {
+			s.logger.Warnf("Task %s: failed to remove shard result %s: %v", task.TaskID, part, err)
+		}
+	}
+
+	s.logger.Infof("Task %s: merged %d shards into %s", task.TaskID, len(parts), artifact.Key)
+	return artifact, nil
+}
+
+func (s *artifactSink) WriteDataset(ctx context.Context, task models.Task, collections []generator.Collection) (Artifact, error) {
+	artifact, err := s.upload(ctx, task, func(w io.Writer) error {
+		return RenderDataset(ctx, task.Format, w, collections, taskOptions(task))
+	})
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	s.logger.Infof("Task %s: stored %d entities as %s", task.TaskID, len(collections), artifact.Key)
+	return artifact, nil
+}
+
+func taskOptions(task models.Task) Options {
+	return Options{
+		TableName:  task.TableName,
+		SQLDialect: task.SQLDialect,
+	}
+}
+
+// writeRecords renders records with rw and closes it, checking ctx every renderCheckStep records.
+func writeRecords(ctx context.Context, rw RecordWriter, records []generator.Record) error {
+	for i, record := range records {
+		if i%renderCheckStep == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if err := rw.Write(record); err != nil {
+			return fmt.Errorf("failed to render record: %w", err)
+		}
+	}
+	if err := rw.Close(); err != nil {
+		return fmt.Errorf("failed to finish output: %w", err)
+	}
+	return nil
+}
+
+// upload renders the task result with render and stores it as the task artifact.
+func (s *artifactSink) upload(ctx context.Context, task models.Task, render func(w io.Writer) error) (Artifact, error) {
+	// Render into a temporary file so the upload knows its size up front.
+	tmp, err := os.CreateTemp("", "fakeid-"+task.TaskID+"-*")
+	if err != nil {
+		return Artifact{}, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	buf := bufio.NewWriter(tmp)
+	if err := render(buf); err != nil {
+		return Artifact{}, err
+	}
+	if err := buf.Flush(); err != nil {
+		return Artifact{}, fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return Artifact{}, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return Artifact{}, err
+	}
+
+	// Do not publish a result for a task that was stopped while rendering.
+	if err := ctx.Err(); err != nil {
+		return Artifact{}, err
+	}
+
+	artifact := Artifact{
+		Key:         ResultKey(task),
+		Size:        size,
+		ContentType: ContentType(task.Format),
+	}
+	if err := s.store.Put(ctx, artifact.Key, tmp, artifact.Size, artifact.ContentType); err != nil {
+		return Artifact{}, err
+	}
+	return artifact, nil
+}
@@ -0,0 +1,139 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"worker-service/internal/generator"
+)
+
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+	DialectMSSQL    = "mssql"
+
+	defaultTableName = "records"
+)
+
+// dialect describes the quoting rules of a SQL flavour.
+type dialect struct {
+	quoteIdent  func(string) string
+	escapeBytes bool // backslash is an escape character inside string literals
+	trueLit     string
+	falseLit    string
+}
+
+var dialects = map[string]dialect{
+	DialectPostgres: {quoteIdent: quoteWith(`"`, `"`), trueLit: "TRUE", falseLit: "FALSE"},
+	DialectMySQL:    {quoteIdent: quoteWith("`", "`"), escapeBytes: true, trueLit: "TRUE", falseLit: "FALSE"},
+	DialectSQLite:   {quoteIdent: quoteWith(`"`, `"`), trueLit: "1", falseLit: "0"},
+	DialectMSSQL:    {quoteIdent: quoteWith("[", "]"), trueLit: "1", falseLit: "0"},
+}
+
+// sqlWriter renders each record as a single INSERT statement.
+type sqlWriter struct {
+	w       io.Writer
+	dialect dialect
+	fields  []string
+	prefix  string
+}
+
+func newSQLWriter(w io.Writer, fields []string, opts Options) (*sqlWriter, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("sql output requires at least one field")
+	}
+
+	name := opts.SQLDialect
+	if name == "" {
+		name = DialectPostgres
+	}
+	d, ok := dialects[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sql dialect %q", opts.SQLDialect)
+	}
+
+	table := opts.TableName
+	if table == "" {
+		table = defaultTableName
+	}
+
+	// Schema-qualified names are quoted part by part.
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = d.quoteIdent(p)
+	}
+
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = d.quoteIdent(f)
+	}
+
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", strings.Join(parts, "."), strings.Join(columns, ", "))
+	return &sqlWriter{w: w, dialect: d, fields: fields, prefix: prefix}, nil
+}
+
+func (s *sqlWriter) Write(record generator.Record) error {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	for i, name := range s.fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		literal, err := s.literal(record[name])
+		if err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+		b.WriteString(literal)
+	}
+	b.WriteString(");\n")
+
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+func (s *sqlWriter) Flush() error {
+	return nil
+}
+
+func (s *sqlWriter) Close() error {
+	return nil
+}
+
+func (s *sqlWriter) literal(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if val {
+			return s.dialect.trueLit, nil
+		}
+		return s.dialect.falseLit, nil
+	case int:
+		return strconv.Itoa(val), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	default:
+		text, err := formatCell(val)
+		if err != nil {
+			return "", err
+		}
+		return s.quoteString(text), nil
+	}
+}
+
+func (s *sqlWriter) quoteString(v string) string {
+	if s.dialect.escapeBytes {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+func quoteWith(open, close string) func(string) string {
+	return func(ident string) string {
+		return open + strings.ReplaceAll(ident, close, close+close) + close
+	}
+}
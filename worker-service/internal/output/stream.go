@@ -0,0 +1,200 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+	"worker-service/pkg/storage"
+)
+
+// Batch is a run of consecutive records of a streamed task result.
+type Batch struct {
+	Records []generator.Record
+	// Generated counts the records of the task up to and including this batch and
+	// State is whatever else the producer needs to go on after it; together they let
+	// a worker resume generation right after the batch.
+	Generated int
+	State     []byte
+}
+
+// Checkpoint records how much of a streamed task result has been uploaded.
+// It is stored next to the chunks, so any worker that picks the task up again
+// continues from it instead of starting over.
+type Checkpoint struct {
+	TaskID string `json:"task_id"`
+	// Seed, Amount and Format identify the result the chunks belong to.
+	Seed   int64  `json:"seed"`
+	Amount int    `json:"amount"`
+	Format string `json:"format"`
+
+	Records   int       `json:"records"`
+	Chunks    int       `json:"chunks"`
+	Size      int64     `json:"size"`
+	State     []byte    `json:"state"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointKey returns the key of the checkpoint of a streamed task result.
+func CheckpointKey(task models.Task) string {
+	return "checkpoints/" + partName(task) + ".json"
+}
+
+// chunkKey returns the key of the n-th chunk (counting from 1) of a streamed task result.
+func chunkKey(task models.Task, n int) string {
+	return fmt.Sprintf("chunks/%s/%06d", partName(task), n)
+}
+
+func (s *artifactSink) Checkpoint(ctx context.Context, task models.Task) (*Checkpoint, error) {
+	r, err := s.store.Get(ctx, CheckpointKey(task))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var cp Checkpoint
+	if err := json.NewDecoder(r).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	// A task re-created with different parameters cannot reuse the chunks.
+	if cp.TaskID != task.TaskID || cp.Seed != task.Seed || cp.Amount != task.Amount || cp.Format != task.Format {
+		s.logger.Warnf("Task %s: ignoring checkpoint of a different result", task.TaskID)
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+func (s *artifactSink) Stream(ctx context.Context, task models.Task, fields []string, from *Checkpoint, batches <-chan Batch) (Artifact, error) {
+	cp := Checkpoint{TaskID: task.TaskID, Seed: task.Seed, Amount: task.Amount, Format: task.Format}
+	if from != nil {
+		cp = *from
+	}
+
+	opts := taskOptions(task)
+	opts.Offset = cp.Records
+	if task.Shard != nil {
+		// A shard continues the output of the shards before it and, unless it is the
+		// last one, is continued by the shards after it.
+		opts.Offset += task.Shard.Offset
+		opts.Open = !task.Shard.Last()
+	}
+	var chunk bytes.Buffer
+	rw, err := NewRecordWriter(task.Format, &chunk, fields, opts)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	pending := cp
+	for batch := range batches {
+		for _, record := range batch.Records {
+			if err := rw.Write(record); err != nil {
+				return Artifact{}, fmt.Errorf("failed to render record: %w", err)
+			}
+		}
+		if err := rw.Flush(); err != nil {
+			return Artifact{}, fmt.Errorf("failed to render records: %w", err)
+		}
+		pending.Records, pending.State = batch.Generated, batch.State
+
+		if chunk.Len() < s.chunkSize {
+			continue
+		}
+		if err := s.uploadChunk(ctx, task, &pending, &chunk); err != nil {
+			return Artifact{}, err
+		}
+		if err := s.saveCheckpoint(ctx, task, pending); err != nil {
+			return Artifact{}, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return Artifact{}, err
+	}
+
+	if err := rw.Close(); err != nil {
+		return Artifact{}, fmt.Errorf("failed to finish output: %w", err)
+	}
+	if chunk.Len() > 0 || pending.Chunks == 0 {
+		if err := s.uploadChunk(ctx, task, &pending, &chunk); err != nil {
+			return Artifact{}, err
+		}
+	}
+
+	parts := make([]string, pending.Chunks)
+	for i := range parts {
+		parts[i] = chunkKey(task, i+1)
+	}
+	artifact := Artifact{Key: ResultKey(task), ContentType: ContentType(task.Format)}
+	if artifact.Size, err = s.store.Compose(ctx, artifact.Key, parts, artifact.ContentType); err != nil {
+		return Artifact{}, err
+	}
+	if err := s.Discard(ctx, task); err != nil {
+		s.logger.Warnf("Task %s: failed to remove chunks: %v", task.TaskID, err)
+	}
+
+	s.logger.Infof("Task %s: stored %d records in %d chunks as %s", task.TaskID, pending.Records, pending.Chunks, artifact.Key)
+	return artifact, nil
+}
+
+// uploadChunk stores the rendered chunk as the next chunk of cp and empties it.
+func (s *artifactSink) uploadChunk(ctx context.Context, task models.Task, cp *Checkpoint, chunk *bytes.Buffer) error {
+	size := int64(chunk.Len())
+	key := chunkKey(task, cp.Chunks+1)
+	if err := s.store.Put(ctx, key, bytes.NewReader(chunk.Bytes()), size, ContentType(task.Format)); err != nil {
+		return err
+	}
+	chunk.Reset()
+	cp.Chunks++
+	cp.Size += size
+	return nil
+}
+
+func (s *artifactSink) saveCheckpoint(ctx context.Context, task models.Task, cp Checkpoint) error {
+	cp.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Put(ctx, CheckpointKey(task), bytes.NewReader(data), int64(len(data)), "application/json"); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *artifactSink) Discard(ctx context.Context, task models.Task) error {
+	cp, err := s.Checkpoint(ctx, task)
+	if err != nil {
+		return err
+	}
+	// Chunks past the checkpoint may exist if a worker stopped between uploading
+	// a chunk and saving the checkpoint, so remove until the first missing one.
+	chunks := 0
+	if cp != nil {
+		chunks = cp.Chunks
+	}
+	if err := s.store.Delete(ctx, CheckpointKey(task)); err != nil {
+		return err
+	}
+	for n := 1; ; n++ {
+		key := chunkKey(task, n)
+		if n > chunks {
+			r, err := s.store.Get(ctx, key)
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			r.Close()
+		}
+		if err := s.store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"worker-service/internal/generator"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatSQL    = "sql"
+)
+
+// Options tune how records are rendered.
+type Options struct {
+	TableName  string
+	SQLDialect string
+	// Offset is the number of records already rendered into the same output by an
+	// earlier writer. A writer with an offset continues that output: it repeats no
+	// header and separates its first record from the previous ones.
+	Offset int
+	// Open leaves the output open for a later writer to continue: Close writes no trailer.
+	Open bool
+}
+
+// RecordWriter renders records in a particular output format.
+// Close must be called to flush any trailing bytes; it does not close the underlying writer.
+type RecordWriter interface {
+	Write(record generator.Record) error
+	// Flush writes any buffered records to the underlying writer.
+	Flush() error
+	Close() error
+}
+
+// NewRecordWriter creates a RecordWriter for format writing to w.
+// fields fixes the column order for tabular formats.
+func NewRecordWriter(format string, w io.Writer, fields []string, opts Options) (RecordWriter, error) {
+	switch normalizeFormat(format) {
+	case FormatJSON:
+		return newJSONWriter(w, opts.Offset, opts.Open), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case FormatCSV:
+		return newCSVWriter(w, fields, opts.Offset > 0)
+	case FormatSQL:
+		return newSQLWriter(w, fields, opts)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// ContentType returns the MIME type for format.
+func ContentType(format string) string {
+	switch normalizeFormat(format) {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatSQL:
+		return "application/sql"
+	default:
+		return "application/json"
+	}
+}
+
+// Extension returns the file extension (without dot) for format.
+func Extension(format string) string {
+	switch f := normalizeFormat(format); f {
+	case FormatNDJSON, FormatCSV, FormatSQL:
+		return f
+	default:
+		return FormatJSON
+	}
+}
+
+// normalizeFormat maps an empty format to JSON, the default.
+func normalizeFormat(format string) string {
+	f := strings.ToLower(strings.TrimSpace(format))
+	if f == "" {
+		return FormatJSON
+	}
+	return f
+}
@@ -0,0 +1,272 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+	"worker-service/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testRecords = []generator.Record{
+	{"id": int64(1), "name": "O'Brien", "active": true, "meta": generator.Record{"k": "v"}},
+	{"id": int64(2), "name": "Smith, \"Jr\"", "active": false, "meta": nil},
+}
+
+var testFields = []string{"id", "name", "active", "meta"}
+
+func render(t *testing.T, format string, opts Options) string {
+	t.Helper()
+	return renderRecords(t, format, opts, testFields, testRecords)
+}
+
+func renderRecords(t *testing.T, format string, opts Options, fields []string, records []generator.Record) string {
+	t.Helper()
+	var buf bytes.Buffer
+	rw, err := NewRecordWriter(format, &buf, fields, opts)
+	require.NoError(t, err)
+	for _, rec := range records {
+		require.NoError(t, rw.Write(rec))
+	}
+	require.NoError(t, rw.Close())
+	return buf.String()
+}
+
+func TestJSONWriter(t *testing.T) {
+	out := render(t, FormatJSON, Options{})
+
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	require.Len(t, decoded, 2)
+	assert.Equal(t, "O'Brien", decoded[0]["name"])
+}
+
+func TestJSONWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	rw, err := NewRecordWriter("", &buf, testFields, Options{})
+	require.NoError(t, err)
+	require.NoError(t, rw.Close())
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	out := render(t, FormatNDJSON, Options{})
+
+	lines := bytes.Split(bytes.TrimSpace([]byte(out)), []byte("\n"))
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.True(t, json.Valid(line))
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := render(t, FormatCSV, Options{})
+
+	expected := "id,name,active,meta\r\n" +
+		"1,O'Brien,true,\"{\"\"k\"\":\"\"v\"\"}\"\r\n" +
+		"2,\"Smith, \"\"Jr\"\"\",false,\r\n"
+	assert.Equal(t, expected, out)
+}
+
+func TestSQLWriter_Dialects(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		table    string
+		expected string
+	}{
+		{
+			dialect: "",
+			table:   "",
+			expected: `INSERT INTO "records" ("id", "name", "active", "meta") VALUES (1, 'O''Brien', TRUE, '{"k":"v"}');` + "\n" +
+				`INSERT INTO "records" ("id", "name", "active", "meta") VALUES (2, 'Smith, "Jr"', FALSE, NULL);` + "\n",
+		},
+		{
+			dialect: DialectMySQL,
+			table:   "app.users",
+			expected: "INSERT INTO `app`.`users` (`id`, `name`, `active`, `meta`) VALUES (1, 'O''Brien', TRUE, '{\"k\":\"v\"}');\n" +
+				"INSERT INTO `app`.`users` (`id`, `name`, `active`, `meta`) VALUES (2, 'Smith, \"Jr\"', FALSE, NULL);\n",
+		},
+		{
+			dialect: DialectMSSQL,
+			table:   "users",
+			expected: `INSERT INTO [users] ([id], [name], [active], [meta]) VALUES (1, 'O''Brien', 1, '{"k":"v"}');` + "\n" +
+				`INSERT INTO [users] ([id], [name], [active], [meta]) VALUES (2, 'Smith, "Jr"', 0, NULL);` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			out := render(t, FormatSQL, Options{TableName: tt.table, SQLDialect: tt.dialect})
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+}
+
+func TestSQLWriter_QuotesIdentifiers(t *testing.T) {
+	var buf bytes.Buffer
+	rw, err := NewRecordWriter(FormatSQL, &buf, []string{`a"b`}, Options{TableName: `t"; DROP TABLE x; --`})
+	require.NoError(t, err)
+	require.NoError(t, rw.Write(generator.Record{`a"b`: `x\y`}))
+	assert.Equal(t, `INSERT INTO "t""; DROP TABLE x; --" ("a""b") VALUES ('x\y');`+"\n", buf.String())
+}
+
+func TestNewRecordWriter_Unsupported(t *testing.T) {
+	_, err := NewRecordWriter("xml", &bytes.Buffer{}, testFields, Options{})
+	assert.Error(t, err)
+
+	_, err = NewRecordWriter(FormatSQL, &bytes.Buffer{}, testFields, Options{SQLDialect: "oracle"})
+	assert.Error(t, err)
+}
+
+var testDataset = []generator.Collection{
+	{Name: "users", Fields: []string{"id"}, Records: []generator.Record{{"id": int64(1)}, {"id": int64(2)}}},
+	{Name: "orders", Fields: []string{"id", "user_id"}, Records: []generator.Record{{"id": int64(10), "user_id": int64(2)}}},
+}
+
+func TestRenderDataset(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderDataset(context.Background(), FormatSQL, &buf, testDataset, Options{TableName: "staging"}))
+	assert.Equal(t, `INSERT INTO "staging"."users" ("id") VALUES (1);`+"\n"+
+		`INSERT INTO "staging"."users" ("id") VALUES (2);`+"\n"+
+		`INSERT INTO "staging"."orders" ("id", "user_id") VALUES (10, 2);`+"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, RenderDataset(context.Background(), FormatJSON, &buf, testDataset, Options{}))
+	var decoded map[string][]map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Len(t, decoded["users"], 2)
+	assert.Equal(t, float64(2), decoded["orders"][0]["user_id"])
+
+	buf.Reset()
+	require.NoError(t, RenderDataset(context.Background(), FormatNDJSON, &buf, testDataset, Options{}))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"entity":"orders","record":{"id":10,"user_id":2}}`, string(lines[2]))
+
+	assert.Error(t, RenderDataset(context.Background(), FormatCSV, &buf, testDataset, Options{}))
+}
+
+// streamRecords передаёт записи в Stream пакетами по size записей.
+func streamRecords(t *testing.T, sink Sink, task models.Task, from *Checkpoint, records []generator.Record, size int) (Artifact, error) {
+	t.Helper()
+	batches := make(chan Batch)
+	go func() {
+		defer close(batches)
+		start := 0
+		if from != nil {
+			start = from.Records
+		}
+		for i := start; i < len(records); i += size {
+			end := min(i+size, len(records))
+			batches <- Batch{Records: records[i:end], Generated: end, State: []byte{byte(end)}}
+		}
+	}()
+	return sink.Stream(context.Background(), task, []string{"id"}, from, batches)
+}
+
+// TestArtifactSink_Stream проверяет загрузку результата частями, контрольные точки и продолжение с них.
+func TestArtifactSink_Stream(t *testing.T) {
+	ctx := context.Background()
+	records := make([]generator.Record, 50)
+	for i := range records {
+		records[i] = generator.Record{"id": int64(i)}
+	}
+
+	for _, format := range []string{FormatJSON, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := storage.NewLocalStore(dir, zap.NewNop().Sugar())
+			require.NoError(t, err)
+			sink := NewArtifactSink(store, 64, zap.NewNop().Sugar())
+			task := models.Task{TaskID: "task-" + format, Amount: len(records), Seed: 1, Format: format}
+
+			artifact, err := streamRecords(t, sink, task, nil, records, 7)
+			require.NoError(t, err)
+			want, err := os.ReadFile(filepath.Join(dir, artifact.Key))
+			require.NoError(t, err)
+			assert.Equal(t, renderRecords(t, format, Options{}, []string{"id"}, records), string(want))
+			assert.Equal(t, int64(len(want)), artifact.Size)
+
+			// The chunks and checkpoint are removed once the result is stored.
+			cp, err := sink.Checkpoint(ctx, task)
+			require.NoError(t, err)
+			assert.Nil(t, cp)
+			entries, _ := os.ReadDir(filepath.Join(dir, "chunks", task.TaskID))
+			assert.Empty(t, entries)
+
+			// A worker stopped after a few chunks leaves a checkpoint that the next one continues.
+			stopped := make(chan Batch)
+			stopCtx, stop := context.WithCancel(ctx)
+			go func() {
+				for i := 0; i < 21; i += 7 {
+					stopped <- Batch{Records: records[i : i+7], Generated: i + 7, State: []byte{byte(i + 7)}}
+				}
+				stop()
+				close(stopped)
+			}()
+			_, err = sink.Stream(stopCtx, task, []string{"id"}, nil, stopped)
+			require.Error(t, err)
+
+			cp, err = sink.Checkpoint(ctx, task)
+			require.NoError(t, err)
+			require.NotNil(t, cp)
+			assert.Equal(t, []byte{byte(cp.Records)}, cp.State)
+			assert.Positive(t, cp.Chunks)
+
+			_, err = streamRecords(t, sink, task, cp, records, 7)
+			require.NoError(t, err)
+			got, err := os.ReadFile(filepath.Join(dir, artifact.Key))
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+// TestArtifactSink_Merge проверяет, что результаты шардов собираются в тот же файл,
+// что и результат задачи без шардов.
+func TestArtifactSink_Merge(t *testing.T) {
+	ctx := context.Background()
+	records := make([]generator.Record, 10)
+	for i := range records {
+		records[i] = generator.Record{"id": int64(i)}
+	}
+
+	for _, format := range []string{FormatJSON, FormatCSV, FormatSQL} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := storage.NewLocalStore(dir, zap.NewNop().Sugar())
+			require.NoError(t, err)
+			sink := NewArtifactSink(store, 64, zap.NewNop().Sugar())
+			task := models.Task{TaskID: "task-" + format, Amount: len(records), Seed: 1, Format: format, TableName: "users", ShardCount: 3}
+
+			for _, shard := range []models.Shard{{Index: 0, Count: 3, Offset: 0, Amount: 4}, {Index: 1, Count: 3, Offset: 4, Amount: 3}, {Index: 2, Count: 3, Offset: 7, Amount: 3}} {
+				shardTask := task
+				shardTask.Shard = &shard
+				artifact, err := streamRecords(t, sink, shardTask, nil, records[shard.Offset:shard.Offset+shard.Amount], 2)
+				require.NoError(t, err)
+				assert.Equal(t, fmt.Sprintf("shards/%s/%d.%s", task.TaskID, shard.Index, Extension(format)), artifact.Key)
+			}
+
+			task.Merge = true
+			artifact, err := sink.Merge(ctx, task)
+			require.NoError(t, err)
+			assert.Equal(t, ResultKey(task), artifact.Key)
+			got, err := os.ReadFile(filepath.Join(dir, artifact.Key))
+			require.NoError(t, err)
+			assert.Equal(t, renderRecords(t, format, Options{TableName: "users"}, []string{"id"}, records), string(got))
+			assert.Equal(t, int64(len(got)), artifact.Size)
+
+			entries, _ := os.ReadDir(filepath.Join(dir, "shards", task.TaskID))
+			assert.Empty(t, entries)
+		})
+	}
+}
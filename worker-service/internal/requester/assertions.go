@@ -0,0 +1,269 @@
+package requester
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"worker-service/internal/models"
+)
+
+// assertion checks one aspect of a response and returns why it failed, or "" if it passed.
+type assertion func(r response) string
+
+// response is what the assertions of a request see.
+type response struct {
+	status  int
+	latency time.Duration
+	header  http.Header
+	body    []byte
+}
+
+// compileAssertions turns the assertions of a task into checks. Without a status
+// assertion a response passes with any status below 400.
+func compileAssertions(spec *models.Assertions) ([]assertion, error) {
+	if spec == nil {
+		spec = &models.Assertions{}
+	}
+	var checks []assertion
+
+	if len(spec.Status) > 0 {
+		expected := slices.Clone(spec.Status)
+		checks = append(checks, func(r response) string {
+			if !slices.Contains(expected, r.status) {
+				return fmt.Sprintf("status %d, expected %s", r.status, joinInts(expected))
+			}
+			return ""
+		})
+	} else {
+		checks = append(checks, func(r response) string {
+			if r.status >= 400 {
+				return fmt.Sprintf("status %d", r.status)
+			}
+			return ""
+		})
+	}
+
+	if spec.MaxLatencyMs > 0 {
+		limit := time.Duration(spec.MaxLatencyMs) * time.Millisecond
+		checks = append(checks, func(r response) string {
+			if r.latency > limit {
+				return fmt.Sprintf("latency %v exceeds %v", r.latency.Round(time.Millisecond), limit)
+			}
+			return ""
+		})
+	}
+
+	for i, h := range spec.Headers {
+		check, err := headerAssertion(h)
+		if err != nil {
+			return nil, fmt.Errorf("headers[%d]: %w", i, err)
+		}
+		checks = append(checks, check)
+	}
+	for i, b := range spec.Body {
+		check, err := bodyAssertion(b)
+		if err != nil {
+			return nil, fmt.Errorf("body[%d]: %w", i, err)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+func headerAssertion(spec models.HeaderAssertion) (assertion, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("header name is required")
+	}
+	match, err := matcher(spec.Equals, spec.Regex)
+	if err != nil {
+		return nil, err
+	}
+	name := http.CanonicalHeaderKey(spec.Name)
+	return func(r response) string {
+		values, ok := r.header[name]
+		if !ok {
+			return fmt.Sprintf("header %s is missing", name)
+		}
+		value := strings.Join(values, ", ")
+		if reason := match(value); reason != "" {
+			return fmt.Sprintf("header %s %s", name, reason)
+		}
+		return ""
+	}, nil
+}
+
+// bodyAssertion checks the value at JSONPath of a JSON body or, without a path, the raw body.
+func bodyAssertion(spec models.BodyAssertion) (assertion, error) {
+	if spec.JSONPath == "" {
+		if spec.Regex == "" {
+			return nil, fmt.Errorf("regex is required for an assertion on the raw body")
+		}
+		match, err := matcher("", spec.Regex)
+		if err != nil {
+			return nil, err
+		}
+		return func(r response) string {
+			if reason := match(string(r.body)); reason != "" {
+				return "body " + reason
+			}
+			return ""
+		}, nil
+	}
+
+	path, err := parseJSONPath(spec.JSONPath)
+	if err != nil {
+		return nil, err
+	}
+	match, err := matcher("", spec.Regex)
+	if err != nil {
+		return nil, err
+	}
+	return func(r response) string {
+		var doc interface{}
+		if err := json.Unmarshal(r.body, &doc); err != nil {
+			return "body is not valid JSON"
+		}
+		value, ok := path.lookup(doc)
+		if !ok {
+			return fmt.Sprintf("%s not found", spec.JSONPath)
+		}
+		if spec.Equals != nil && !jsonEqual(value, spec.Equals) {
+			got, _ := json.Marshal(value)
+			want, _ := json.Marshal(spec.Equals)
+			return fmt.Sprintf("%s is %s, expected %s", spec.JSONPath, got, want)
+		}
+		text, ok := value.(string)
+		if !ok {
+			data, _ := json.Marshal(value)
+			text = string(data)
+		}
+		if reason := match(text); reason != "" {
+			return spec.JSONPath + " " + reason
+		}
+		return ""
+	}, nil
+}
+
+// matcher returns a check of a text against an exact value and a regular expression;
+// empty ones are not checked.
+func matcher(equals, pattern string) (func(text string) string, error) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	return func(text string) string {
+		if equals != "" && text != equals {
+			return fmt.Sprintf("is %q, expected %q", snippet(text), equals)
+		}
+		if re != nil && !re.MatchString(text) {
+			return fmt.Sprintf("does not match %s", pattern)
+		}
+		return ""
+	}, nil
+}
+
+// jsonEqual compares a decoded JSON value with an expected one, ignoring number types.
+func jsonEqual(got, want interface{}) bool {
+	data, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, normalized)
+}
+
+// snippet shortens text for failure messages.
+func snippet(text string) string {
+	if len(text) > 64 {
+		return text[:64] + "..."
+	}
+	return text
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, " or ")
+}
+
+// jsonPath is a parsed JSONPath of the subset $.name, $['name'] and $[index].
+type jsonPath []interface{}
+
+func parseJSONPath(s string) (jsonPath, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("invalid json path %q: must start with $", s)
+	}
+	var path jsonPath
+	rest := s[1:]
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("invalid json path %q: empty name", s)
+			}
+			path, rest = append(path, name), rest[end+1:]
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unterminated name", s)
+			}
+			path, rest = append(path, rest[2:end]), rest[end+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unterminated index", s)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid json path %q: bad index %q", s, rest[1:end])
+			}
+			path, rest = append(path, index), rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid json path %q: unexpected %q", s, rest[:1])
+		}
+	}
+	return path, nil
+}
+
+// lookup returns the value at the path within a decoded JSON document.
+func (p jsonPath) lookup(doc interface{}) (interface{}, bool) {
+	value := doc
+	for _, step := range p {
+		switch key := step.(type) {
+		case string:
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = obj[key]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := value.([]interface{})
+			if !ok || key >= len(arr) {
+				return nil, false
+			}
+			value = arr[key]
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,90 @@
+package requester
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"worker-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAssertions проверяет проверки статуса, задержки, заголовков и тела ответа.
+func TestAssertions(t *testing.T) {
+	spec := &models.Assertions{
+		Status:       []int{200, 201},
+		MaxLatencyMs: 100,
+		Headers: []models.HeaderAssertion{
+			{Name: "content-type", Regex: "^application/json"},
+			{Name: "X-Version", Equals: "2"},
+		},
+		Body: []models.BodyAssertion{
+			{JSONPath: "$.user.id", Equals: 7},
+			{JSONPath: "$.items[1]['name']", Regex: "^b"},
+			{Regex: `"ok":\s*true`},
+		},
+	}
+	checks, err := compileAssertions(spec)
+	require.NoError(t, err)
+
+	header := http.Header{"Content-Type": {"application/json"}, "X-Version": {"2"}}
+	body := []byte(`{"ok": true, "user": {"id": 7}, "items": [{"name": "a"}, {"name": "bob"}]}`)
+	assert.Empty(t, failures(checks, response{status: 201, latency: 50 * time.Millisecond, header: header, body: body}))
+
+	got := failures(checks, response{
+		status:  500,
+		latency: 250 * time.Millisecond,
+		header:  http.Header{"Content-Type": {"text/plain"}},
+		body:    []byte(`{"ok": false, "user": {"id": "7"}, "items": []}`),
+	})
+	assert.Equal(t, []string{
+		"status 500, expected 200 or 201",
+		"latency 250ms exceeds 100ms",
+		"header Content-Type does not match ^application/json",
+		"header X-Version is missing",
+		`$.user.id is "7", expected 7`,
+		"$.items[1]['name'] not found",
+		`body does not match "ok":\s*true`,
+	}, got)
+}
+
+// TestAssertions_DefaultStatus проверяет, что без проверки статуса ошибкой считается только статус от 400.
+func TestAssertions_DefaultStatus(t *testing.T) {
+	checks, err := compileAssertions(nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, failures(checks, response{status: 302}))
+	assert.Equal(t, []string{"status 404"}, failures(checks, response{status: 404}))
+}
+
+func TestCompileAssertions_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec models.Assertions
+		want string
+	}{
+		{"header name", models.Assertions{Headers: []models.HeaderAssertion{{Equals: "x"}}}, "headers[0]: header name is required"},
+		{"regex", models.Assertions{Body: []models.BodyAssertion{{Regex: "("}}}, "body[0]: invalid regex"},
+		{"raw body", models.Assertions{Body: []models.BodyAssertion{{}}}, "regex is required"},
+		{"json path", models.Assertions{Body: []models.BodyAssertion{{JSONPath: "user.id"}}}, "must start with $"},
+		{"json index", models.Assertions{Body: []models.BodyAssertion{{JSONPath: "$.items[x]"}}}, "bad index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileAssertions(&tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func failures(checks []assertion, r response) []string {
+	var reasons []string
+	for _, check := range checks {
+		if reason := check(r); reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
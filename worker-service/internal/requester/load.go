@@ -0,0 +1,187 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"worker-service/internal/models"
+)
+
+// ErrLoadFinished is returned by Send once the duration of a soak profile is over. The
+// records that were not sent by then are dropped.
+var ErrLoadFinished = errors.New("load profile finished")
+
+// maxWait bounds one wait of the limiter, so that a rising rate takes effect promptly.
+const maxWait = 100 * time.Millisecond
+
+// point is a corner of the rate curve of a load profile.
+type point struct {
+	at   float64 // seconds since the first request of the task
+	rate float64 // requests per second
+}
+
+// schedule is the target rate of a task over time: a piecewise linear curve through
+// points that keeps the rate of the last point after it. Two points at the same time
+// make a jump. A schedule with an end stops sending then.
+type schedule struct {
+	points []point
+	end    float64
+}
+
+// newSchedule builds the schedule of a request description. A request without a rate
+// or load profile is not rate-limited and gets a nil schedule.
+func newSchedule(spec models.HTTPRequest) (*schedule, error) {
+	load := spec.Load
+	if load == nil {
+		if spec.Rate <= 0 {
+			return nil, nil
+		}
+		return &schedule{points: []point{{0, spec.Rate}}}, nil
+	}
+
+	rate, start, duration := load.Rate, load.StartRate, float64(load.DurationSec)
+	if rate <= 0 || start < 0 {
+		return nil, fmt.Errorf("rates must be positive")
+	}
+	timed := load.Type == models.LoadSoak || load.Type == models.LoadRamp || load.Type == models.LoadStep
+	if timed && duration <= 0 {
+		return nil, fmt.Errorf("%s profile needs a duration", load.Type)
+	}
+
+	switch load.Type {
+	case models.LoadConstant:
+		return &schedule{points: []point{{0, rate}}}, nil
+	case models.LoadSoak:
+		return &schedule{points: []point{{0, rate}}, end: duration}, nil
+	case models.LoadRamp:
+		return &schedule{points: []point{{0, start}, {duration, rate}}}, nil
+	case models.LoadStep:
+		if load.Steps < 2 {
+			return nil, fmt.Errorf("step profile needs at least 2 steps")
+		}
+		width := duration / float64(load.Steps)
+		var points []point
+		for k := 0; k < load.Steps; k++ {
+			r := start + (rate-start)*float64(k)/float64(load.Steps-1)
+			points = append(points, point{float64(k) * width, r}, point{float64(k+1) * width, r})
+		}
+		return &schedule{points: points}, nil
+	case models.LoadSpike:
+		if load.SpikeSec <= 0 {
+			return nil, fmt.Errorf("spike profile needs a spike duration")
+		}
+		if start <= 0 {
+			// Without a base rate no request would be sent after the spike.
+			return nil, fmt.Errorf("spike profile needs a positive start rate")
+		}
+		at, until := float64(load.SpikeAtSec), float64(load.SpikeAtSec+load.SpikeSec)
+		return &schedule{points: []point{{0, start}, {at, start}, {at, rate}, {until, rate}, {until, start}}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported load profile %q", load.Type)
+	}
+}
+
+// rate returns the target rate t seconds into the task.
+func (s *schedule) rate(t float64) float64 {
+	for i := 1; i < len(s.points); i++ {
+		a, b := s.points[i-1], s.points[i]
+		if t < b.at {
+			return a.rate + (b.rate-a.rate)*(t-a.at)/(b.at-a.at)
+		}
+	}
+	return s.points[len(s.points)-1].rate
+}
+
+// expected returns the number of requests the schedule asks for in the first t seconds.
+func (s *schedule) expected(t float64) float64 {
+	total := 0.0
+	for i := 1; i < len(s.points); i++ {
+		a, b := s.points[i-1], s.points[i]
+		if t <= a.at {
+			return total
+		}
+		if t < b.at {
+			return total + (a.rate+s.rate(t))/2*(t-a.at)
+		}
+		total += (a.rate + b.rate) / 2 * (b.at - a.at)
+	}
+	last := s.points[len(s.points)-1]
+	return total + last.rate*max(t-last.at, 0)
+}
+
+// limiter is a token bucket filled at the rate of a schedule. At most burst tokens are
+// saved up while the requests fall behind, for example because all of them are in flight.
+type limiter struct {
+	mu       sync.Mutex
+	schedule *schedule
+	burst    float64
+	tokens   float64
+	// filled is the number of requests the schedule had asked for at the last refill.
+	filled float64
+}
+
+func newLimiter(s *schedule, burst int) *limiter {
+	b := float64(max(burst, 1))
+	return &limiter{schedule: s, burst: b, tokens: b}
+}
+
+// resume continues the schedule elapsed into the task.
+func (l *limiter) resume(elapsed time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.filled = l.schedule.expected(elapsed.Seconds())
+	l.mu.Unlock()
+}
+
+// wait blocks until the next request may start, elapsed returning the time since the
+// first request of the task. A nil limiter never blocks.
+func (l *limiter) wait(ctx context.Context, elapsed func() time.Duration) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	for {
+		l.mu.Lock()
+		t := elapsed().Seconds()
+		if l.schedule.end > 0 && t >= l.schedule.end {
+			l.mu.Unlock()
+			return ErrLoadFinished
+		}
+		expected := l.schedule.expected(t)
+		l.tokens = min(l.burst, l.tokens+expected-l.filled)
+		l.filled = expected
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := maxWait
+		if rate := l.schedule.rate(t); rate > 0 {
+			delay = min(delay, time.Duration((1-l.tokens)/rate*float64(time.Second)))
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// target returns the average rate the schedule asks for over the first t seconds.
+func (l *limiter) target(t float64) float64 {
+	if l == nil || t <= 0 {
+		return 0
+	}
+	asked := t
+	if l.schedule.end > 0 {
+		asked = min(t, l.schedule.end)
+	}
+	return l.schedule.expected(asked) / t
+}
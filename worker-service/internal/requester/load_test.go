@@ -0,0 +1,119 @@
+package requester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchedule проверяет целевую частоту и ожидаемое число запросов для каждого профиля нагрузки.
+func TestSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		load     models.LoadProfile
+		at       []float64
+		rates    []float64
+		expected []float64
+	}{
+		{
+			name:     "constant",
+			load:     models.LoadProfile{Type: models.LoadConstant, Rate: 10},
+			at:       []float64{0, 5},
+			rates:    []float64{10, 10},
+			expected: []float64{0, 50},
+		},
+		{
+			name:     "ramp",
+			load:     models.LoadProfile{Type: models.LoadRamp, StartRate: 0, Rate: 10, DurationSec: 10},
+			at:       []float64{5, 10, 20},
+			rates:    []float64{5, 10, 10},
+			expected: []float64{12.5, 50, 150},
+		},
+		{
+			name:     "step",
+			load:     models.LoadProfile{Type: models.LoadStep, StartRate: 10, Rate: 30, DurationSec: 30, Steps: 3},
+			at:       []float64{5, 15, 25, 40},
+			rates:    []float64{10, 20, 30, 30},
+			expected: []float64{50, 200, 450, 900},
+		},
+		{
+			name:     "spike",
+			load:     models.LoadProfile{Type: models.LoadSpike, StartRate: 1, Rate: 100, SpikeAtSec: 10, SpikeSec: 5},
+			at:       []float64{5, 12, 20},
+			rates:    []float64{1, 100, 1},
+			expected: []float64{5, 210, 515},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newSchedule(models.HTTPRequest{Load: &tt.load})
+			require.NoError(t, err)
+			for i, at := range tt.at {
+				assert.InDelta(t, tt.rates[i], s.rate(at), 1e-9, "rate at %v", at)
+				assert.InDelta(t, tt.expected[i], s.expected(at), 1e-9, "expected at %v", at)
+			}
+		})
+	}
+}
+
+func TestSchedule_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		load models.LoadProfile
+		want string
+	}{
+		{"type", models.LoadProfile{Type: "wave", Rate: 1}, "unsupported load profile"},
+		{"rate", models.LoadProfile{Type: models.LoadConstant}, "rates must be positive"},
+		{"duration", models.LoadProfile{Type: models.LoadRamp, Rate: 10}, "needs a duration"},
+		{"steps", models.LoadProfile{Type: models.LoadStep, Rate: 10, DurationSec: 10, Steps: 1}, "at least 2 steps"},
+		{"spike base", models.LoadProfile{Type: models.LoadSpike, Rate: 10, SpikeSec: 1}, "positive start rate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSchedule(models.HTTPRequest{Load: &tt.load})
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+// TestLimiter_Burst проверяет, что накопленные токены ограничены размером корзины.
+func TestLimiter_Burst(t *testing.T) {
+	elapsed := time.Duration(0)
+	clock := func() time.Duration { return elapsed }
+	l := newLimiter(&schedule{points: []point{{0, 10}}}, 3)
+
+	// Простой в 10 секунд даёт не больше burst запросов без ожидания.
+	elapsed = 10 * time.Second
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.wait(context.Background(), clock))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.wait(ctx, clock), context.DeadlineExceeded)
+}
+
+// TestRequester_Soak проверяет, что по окончании профиля soak отправка прекращается.
+func TestRequester_Soak(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	sender, err := New(server.Client(), models.HTTPRequest{
+		Method: "GET",
+		URL:    server.URL,
+		Load:   &models.LoadProfile{Type: models.LoadSoak, Rate: 50, DurationSec: 1},
+	}, nil)
+	require.NoError(t, err)
+	sender.Resume(800 * time.Millisecond)
+
+	results, err := sender.Send(context.Background(), make([]generator.Record, 100), 0)
+	assert.ErrorIs(t, err, ErrLoadFinished)
+	assert.InDelta(t, 11, len(results), 2)
+	assert.InDelta(t, 50, sender.TargetRPS(), 1)
+}
@@ -0,0 +1,136 @@
+package requester
+
+import (
+	"math"
+	"strconv"
+	"time"
+	"worker-service/internal/models"
+)
+
+const (
+	// maxFailureSamples is how many failed requests a report keeps as examples.
+	maxFailureSamples = 20
+	// bucketBase is the upper bound in milliseconds of the first latency bucket; every
+	// further bucket is bucketGrowth times wider, so percentiles are accurate to about 5%.
+	bucketBase   = 0.1
+	bucketGrowth = 1.05
+)
+
+// Stats aggregates the request log of a task into its report. It is kept in the task
+// checkpoint, so its fields are exported for encoding.
+type Stats struct {
+	Total       int                     `json:"total"`
+	Passed      int                     `json:"passed"`
+	Errors      int                     `json:"errors"`
+	StatusCodes map[string]int          `json:"status_codes"`
+	Buckets     []int                   `json:"buckets"`
+	Sum         float64                 `json:"sum"`
+	Min         float64                 `json:"min"`
+	Max         float64                 `json:"max"`
+	Failures    []models.RequestFailure `json:"failures"`
+	ElapsedSec  float64                 `json:"elapsed_sec"`
+	TargetRPS   float64                 `json:"target_rps"`
+}
+
+// NewStats creates empty stats.
+func NewStats() *Stats {
+	return &Stats{StatusCodes: make(map[string]int)}
+}
+
+// Add counts the result of one request.
+func (s *Stats) Add(result Result) {
+	latencyMs, status, failure := result.LatencyMs, result.Status, result.Failure()
+	s.Total++
+	code := "error"
+	if status > 0 {
+		code = strconv.Itoa(status)
+	}
+	if s.StatusCodes == nil {
+		s.StatusCodes = make(map[string]int)
+	}
+	s.StatusCodes[code]++
+
+	switch {
+	case failure == nil:
+		s.Passed++
+	case len(s.Failures) < maxFailureSamples:
+		s.Failures = append(s.Failures, *failure)
+	}
+	if status == 0 {
+		s.Errors++
+	}
+
+	if s.Total == 1 || latencyMs < s.Min {
+		s.Min = latencyMs
+	}
+	s.Max = max(s.Max, latencyMs)
+	s.Sum += latencyMs
+	b := bucket(latencyMs)
+	if b >= len(s.Buckets) {
+		s.Buckets = append(s.Buckets, make([]int, b+1-len(s.Buckets))...)
+	}
+	s.Buckets[b]++
+}
+
+// SetThroughput records how long the task has been sending and the average rate its
+// load profile asked for in that time.
+func (s *Stats) SetThroughput(elapsed time.Duration, targetRPS float64) {
+	s.ElapsedSec = elapsed.Seconds()
+	s.TargetRPS = targetRPS
+}
+
+// Report summarizes the requests counted so far.
+func (s *Stats) Report() *models.Report {
+	report := &models.Report{
+		Total:       s.Total,
+		Passed:      s.Passed,
+		Failed:      s.Total - s.Passed,
+		Errors:      s.Errors,
+		StatusCodes: s.StatusCodes,
+		Failures:    s.Failures,
+	}
+	if s.Total > 0 {
+		report.Latency = models.LatencySummary{
+			Min:  s.Min,
+			Mean: round(s.Sum / float64(s.Total)),
+			P50:  s.percentile(0.50),
+			P95:  s.percentile(0.95),
+			P99:  s.percentile(0.99),
+			Max:  s.Max,
+		}
+	}
+	if s.ElapsedSec > 0 {
+		report.Throughput = &models.Throughput{
+			ElapsedSec:  round(s.ElapsedSec),
+			TargetRPS:   round(s.TargetRPS),
+			AchievedRPS: round(float64(s.Total) / s.ElapsedSec),
+		}
+	}
+	return report
+}
+
+// percentile returns the upper bound of the bucket holding the q-th latency, capped by the maximum.
+func (s *Stats) percentile(q float64) float64 {
+	rank := int(math.Ceil(q * float64(s.Total)))
+	seen := 0
+	for b, n := range s.Buckets {
+		seen += n
+		if seen >= rank {
+			return round(min(bucketBase*math.Pow(bucketGrowth, float64(b)), s.Max))
+		}
+	}
+	return s.Max
+}
+
+// bucket returns the histogram bucket of a latency.
+func bucket(latencyMs float64) int {
+	if latencyMs <= bucketBase {
+		return 0
+	}
+	return int(math.Ceil(math.Log(latencyMs/bucketBase) / math.Log(bucketGrowth)))
+}
+
+// round rounds to three decimals, that is milliseconds to microseconds.
+func round(ms float64) float64 {
+	return math.Round(ms*1000) / 1000
+}
@@ -0,0 +1,73 @@
+package requester
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"worker-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStats_Report проверяет итоги, перцентили задержки и примеры неудачных запросов.
+func TestStats_Report(t *testing.T) {
+	stats := NewStats()
+	for i := 1; i <= 100; i++ {
+		result := Result{Index: i, Status: 200, LatencyMs: float64(i)}
+		switch {
+		case i%10 == 0:
+			result.Status, result.Failures = 500, []string{"status 500"}
+		case i == 99:
+			result.Status, result.Error = 0, "connection refused"
+		}
+		stats.Add(result)
+	}
+
+	report := stats.Report()
+	assert.Equal(t, 100, report.Total)
+	assert.Equal(t, 89, report.Passed)
+	assert.Equal(t, 11, report.Failed)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, map[string]int{"200": 89, "500": 10, "error": 1}, report.StatusCodes)
+	require.Len(t, report.Failures, 11)
+	assert.Equal(t, []string{"connection refused"}, report.Failures[9].Reasons)
+
+	assert.Equal(t, 1.0, report.Latency.Min)
+	assert.Equal(t, 100.0, report.Latency.Max)
+	assert.Equal(t, 50.5, report.Latency.Mean)
+	assert.InDelta(t, 50, report.Latency.P50, 50*(bucketGrowth-1))
+	assert.InDelta(t, 95, report.Latency.P95, 95*(bucketGrowth-1))
+	assert.InDelta(t, 99, report.Latency.P99, 99*(bucketGrowth-1))
+	assert.LessOrEqual(t, report.Latency.P99, report.Latency.Max)
+
+	// Статистика переживает сохранение в контрольной точке.
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	var restored Stats
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, report, restored.Report())
+}
+
+// TestStats_FailureSamples проверяет ограничение числа сохраняемых примеров.
+func TestStats_FailureSamples(t *testing.T) {
+	stats := NewStats()
+	for i := 0; i < 3*maxFailureSamples; i++ {
+		stats.Add(Result{Index: i, Status: 404, Failures: []string{"status 404"}})
+	}
+	report := stats.Report()
+	assert.Equal(t, 3*maxFailureSamples, report.Failed)
+	assert.Len(t, report.Failures, maxFailureSamples)
+}
+
+// TestStats_Throughput проверяет сравнение достигнутой и целевой частоты запросов.
+func TestStats_Throughput(t *testing.T) {
+	stats := NewStats()
+	assert.Nil(t, stats.Report().Throughput)
+
+	for i := 0; i < 90; i++ {
+		stats.Add(Result{Index: i, Status: 200, LatencyMs: 1})
+	}
+	stats.SetThroughput(10*time.Second, 10)
+	assert.Equal(t, &models.Throughput{ElapsedSec: 10, TargetRPS: 10, AchievedRPS: 9}, stats.Report().Throughput)
+}
@@ -0,0 +1,254 @@
+// Package requester sends generated records to a target API as HTTP requests and
+// records the outcome of every request.
+package requester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+)
+
+const (
+	// DefaultTimeout applies to requests of tasks that do not set one.
+	DefaultTimeout = 10 * time.Second
+	// snippetSize is how much of a response body is kept in the request log.
+	snippetSize = 512
+	// drainLimit is how much of the rest of a response body is read so that the
+	// connection can be reused; longer responses close it.
+	drainLimit = 1 << 20
+)
+
+// Fields are the columns of the request log, the result of an http task.
+var Fields = []string{"index", "method", "url", "status", "latency_ms", "passed", "failures", "error", "response"}
+
+// Result is the outcome of one request.
+type Result struct {
+	Index     int
+	Method    string
+	URL       string
+	Status    int
+	LatencyMs float64
+	// Failures lists the assertions the response failed; Error is set instead if the
+	// request got no response.
+	Failures []string
+	Error    string
+	Response string
+}
+
+// Passed reports whether the request got a response that passed all assertions.
+func (r Result) Passed() bool {
+	return r.Error == "" && len(r.Failures) == 0
+}
+
+// Record returns the request log entry of the result.
+func (r Result) Record() generator.Record {
+	return generator.Record{
+		"index":      r.Index,
+		"method":     r.Method,
+		"url":        r.URL,
+		"status":     r.Status,
+		"latency_ms": r.LatencyMs,
+		"passed":     r.Passed(),
+		"failures":   strings.Join(r.Failures, "; "),
+		"error":      r.Error,
+		"response":   r.Response,
+	}
+}
+
+// Failure returns the report sample of a failed request.
+func (r Result) Failure() *models.RequestFailure {
+	if r.Passed() {
+		return nil
+	}
+	reasons := r.Failures
+	if r.Error != "" {
+		reasons = []string{r.Error}
+	}
+	return &models.RequestFailure{
+		Index:     r.Index,
+		URL:       r.URL,
+		Status:    r.Status,
+		LatencyMs: r.LatencyMs,
+		Reasons:   reasons,
+		Response:  r.Response,
+	}
+}
+
+// Doer sends HTTP requests; *http.Client satisfies it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Requester sends the requests of one http task.
+type Requester struct {
+	client      Doer
+	template    *Template
+	checks      []assertion
+	readBody    bool
+	concurrency int
+	timeout     time.Duration
+	limiter     *limiter
+	// began is when the first request was sent; offset is how long the task had been
+	// sending before it was resumed.
+	began  time.Time
+	offset time.Duration
+}
+
+// New creates a Requester for the request description of a task whose records have fields.
+func New(client Doer, spec models.HTTPRequest, fields []string) (*Requester, error) {
+	template, err := Compile(spec, fields)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := compileAssertions(spec.Assertions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid assertions: %w", err)
+	}
+	r := &Requester{
+		client:      client,
+		template:    template,
+		checks:      checks,
+		readBody:    spec.Assertions != nil && len(spec.Assertions.Body) > 0,
+		concurrency: max(spec.Concurrency, 1),
+		timeout:     DefaultTimeout,
+	}
+	if spec.TimeoutMs > 0 {
+		r.timeout = time.Duration(spec.TimeoutMs) * time.Millisecond
+	}
+	schedule, err := newSchedule(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid load profile: %w", err)
+	}
+	if schedule != nil {
+		burst := 0
+		if spec.Load != nil {
+			burst = spec.Load.Burst
+		}
+		r.limiter = newLimiter(schedule, burst)
+	}
+	return r, nil
+}
+
+// Resume continues the load profile of a task that had been sending for elapsed before
+// it was interrupted. It must be called before the first Send.
+func (r *Requester) Resume(elapsed time.Duration) {
+	r.offset = elapsed
+	r.limiter.resume(elapsed)
+}
+
+// Elapsed returns how long the task has been sending requests, including earlier runs.
+func (r *Requester) Elapsed() time.Duration {
+	if r.began.IsZero() {
+		return r.offset
+	}
+	return r.offset + time.Since(r.began)
+}
+
+// TargetRPS returns the average rate the load profile has asked for so far, or 0 if the
+// task is not rate-limited.
+func (r *Requester) TargetRPS() float64 {
+	return r.limiter.target(r.Elapsed().Seconds())
+}
+
+// Send sends a request for each record, at most concurrency at once and no faster than
+// the load profile allows, and returns the results of the requests in the order of
+// records. first is the index of the first record within the task. A failed request is
+// part of the results, not an error; Send fails if ctx is done, and returns the results
+// of the requests sent so far with ErrLoadFinished once a soak profile is over.
+func (r *Requester) Send(ctx context.Context, records []generator.Record, first int) ([]Result, error) {
+	if r.began.IsZero() {
+		r.began = time.Now()
+	}
+	results := make([]Result, len(records))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(r.concurrency, len(records)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = r.send(ctx, records[i], first+i)
+			}
+		}()
+	}
+
+	var err error
+	sent := 0
+	for ; sent < len(records) && err == nil; sent++ {
+		if err = r.limiter.wait(ctx, r.Elapsed); err != nil {
+			break
+		}
+		select {
+		case indexes <- sent:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if ctx.Err() != nil {
+		// Requests cut short by a cancelled ctx must not be logged as failures.
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return results[:sent], err
+	}
+	return results, nil
+}
+
+// send sends the request for one record and checks the response.
+func (r *Requester) send(ctx context.Context, record generator.Record, index int) Result {
+	result := Result{Index: index, Method: r.template.Method()}
+	req, err := r.template.Build(record)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.URL = req.URL.String()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	start := time.Now()
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		result.LatencyMs = milliseconds(time.Since(start))
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	// Body assertions need the whole body; otherwise a snippet is enough.
+	limit := int64(snippetSize)
+	if r.readBody {
+		limit = drainLimit
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	latency := time.Since(start)
+	result.LatencyMs = milliseconds(latency)
+	result.Status = resp.StatusCode
+	result.Response = string(body[:min(len(body), snippetSize)])
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit))
+
+	checked := response{status: resp.StatusCode, latency: latency, header: resp.Header, body: body}
+	for _, check := range r.checks {
+		if reason := check(checked); reason != "" {
+			result.Failures = append(result.Failures, reason)
+		}
+	}
+	return result
+}
+
+// milliseconds converts d to fractional milliseconds with microsecond precision.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
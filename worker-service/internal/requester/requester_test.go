@@ -0,0 +1,181 @@
+package requester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTemplate_Build проверяет подстановку полей записи в URL, параметры, заголовки и тело.
+func TestTemplate_Build(t *testing.T) {
+	spec := models.HTTPRequest{
+		Method:  "post",
+		URL:     "https://api.example.com/users/{{name}}?source={{address.city}}",
+		Params:  map[string]string{"age": "{{age}}"},
+		Headers: map[string]string{"x-request-id": "{{id}}"},
+		Body:    `{"user": {{name}}, "all": {{.}}}`,
+	}
+	tmpl, err := Compile(spec, []string{"address", "age", "id", "name"})
+	require.NoError(t, err)
+
+	record := generator.Record{
+		"id":      "a1",
+		"name":    "Анна Смит",
+		"age":     int64(30),
+		"address": map[string]interface{}{"city": "New York"},
+	}
+	req, err := tmpl.Build(record)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "/users/%D0%90%D0%BD%D0%BD%D0%B0%20%D0%A1%D0%BC%D0%B8%D1%82", req.URL.EscapedPath())
+	assert.Equal(t, "New York", req.URL.Query().Get("source"))
+	assert.Equal(t, "30", req.URL.Query().Get("age"))
+	assert.Equal(t, "a1", req.Header.Get("X-Request-Id"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user":"Анна Смит","all":{"id":"a1","name":"Анна Смит","age":30,"address":{"city":"New York"}}}`, string(body))
+}
+
+// TestTemplate_DefaultBody проверяет, что без шаблона тела POST отправляет запись целиком, а GET — без тела.
+func TestTemplate_DefaultBody(t *testing.T) {
+	record := generator.Record{"id": int64(7)}
+
+	tmpl, err := Compile(models.HTTPRequest{Method: "POST", URL: "http://localhost/items"}, []string{"id"})
+	require.NoError(t, err)
+	req, err := tmpl.Build(record)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(req.Body)
+	assert.JSONEq(t, `{"id":7}`, string(body))
+
+	tmpl, err = Compile(models.HTTPRequest{Method: "GET", URL: "http://localhost/items/{{id}}"}, []string{"id"})
+	require.NoError(t, err)
+	req, err = tmpl.Build(record)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost/items/7", req.URL.String())
+	assert.Empty(t, req.Header.Get("Content-Type"))
+	assert.Zero(t, req.ContentLength)
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec models.HTTPRequest
+		want string
+	}{
+		{"method", models.HTTPRequest{Method: "TRACE", URL: "http://localhost"}, "unsupported method"},
+		{"url", models.HTTPRequest{Method: "GET"}, "url is required"},
+		{"unknown field", models.HTTPRequest{Method: "GET", URL: "http://localhost/{{email}}"}, "unknown field"},
+		{"unknown header field", models.HTTPRequest{Method: "GET", URL: "http://localhost", Headers: map[string]string{"X-Id": "{{uid}}"}}, "unknown field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.spec, []string{"id"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+// TestTemplate_InvalidURL проверяет, что относительный URL после подстановки отклоняется.
+func TestTemplate_InvalidURL(t *testing.T) {
+	tmpl, err := Compile(models.HTTPRequest{Method: "GET", URL: "{{host}}/items"}, []string{"host"})
+	require.NoError(t, err)
+
+	_, err = tmpl.Build(generator.Record{"host": "localhost"})
+	assert.ErrorContains(t, err, "absolute http or https url")
+}
+
+// TestRequester_Send проверяет параллельную отправку, порядок журнала и запись ошибок.
+func TestRequester_Send(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "/3") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		io.WriteString(w, strings.Repeat("x", 1000))
+	}))
+	defer server.Close()
+
+	sender, err := New(server.Client(), models.HTTPRequest{Method: "GET", URL: server.URL + "/items/{{id}}", Concurrency: 3}, []string{"id"})
+	require.NoError(t, err)
+
+	records := make([]generator.Record, 8)
+	for i := range records {
+		records[i] = generator.Record{"id": int64(i)}
+	}
+	log, err := sender.Send(context.Background(), records, 100)
+	require.NoError(t, err)
+	require.Len(t, log, 8)
+
+	for i, result := range log {
+		assert.Equal(t, 100+i, result.Index)
+		assert.Equal(t, "GET", result.Method)
+		assert.Len(t, result.Response, snippetSize)
+		assert.Positive(t, result.LatencyMs)
+	}
+	assert.Equal(t, http.StatusOK, log[0].Status)
+	assert.True(t, log[0].Passed())
+	assert.Equal(t, http.StatusNotFound, log[3].Status)
+	assert.Equal(t, []string{"status 404"}, log[3].Failures)
+	assert.Equal(t, false, log[3].Record()["passed"])
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, peak.Load(), int32(1))
+
+	// Недоступный сервер записывается в журнал как ошибка запроса, а не задачи.
+	server.Close()
+	log, err = sender.Send(context.Background(), records[:1], 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, log[0].Status)
+	assert.NotEmpty(t, log[0].Error)
+	assert.False(t, log[0].Passed())
+}
+
+// TestRequester_Rate проверяет ограничение числа запросов в секунду.
+func TestRequester_Rate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	sender, err := New(server.Client(), models.HTTPRequest{Method: "GET", URL: server.URL, Concurrency: 5, Rate: 100}, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = sender.Send(context.Background(), make([]generator.Record, 11), 0)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+// TestRequester_Cancelled проверяет, что отправка прекращается при отмене контекста.
+func TestRequester_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	sender, err := New(server.Client(), models.HTTPRequest{Method: "GET", URL: server.URL, Rate: 10}, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	_, err = sender.Send(ctx, make([]generator.Record, 100), 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,251 @@
+package requester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+)
+
+// placeholder matches {{field}}, {{parent.child}} and {{.}}, the whole record.
+var placeholder = regexp.MustCompile(`\{\{\s*(\.|[A-Za-z_][\w-]*(?:\.[A-Za-z_][\w-]*)*)\s*\}\}`)
+
+// text is a string with placeholders, split into literal parts and field paths:
+// parts[0], fields[0], parts[1], ... , parts[len(fields)].
+type text struct {
+	parts  []string
+	fields []string
+}
+
+func parseText(s string) text {
+	var t text
+	last := 0
+	for _, m := range placeholder.FindAllStringSubmatchIndex(s, -1) {
+		t.parts = append(t.parts, s[last:m[0]])
+		t.fields = append(t.fields, s[m[2]:m[3]])
+		last = m[1]
+	}
+	t.parts = append(t.parts, s[last:])
+	return t
+}
+
+// render replaces the placeholders of t with the values of record formatted by format.
+func (t text) render(record generator.Record, format func(v interface{}) (string, error)) (string, error) {
+	if len(t.fields) == 0 {
+		return t.parts[0], nil
+	}
+	var b strings.Builder
+	for i, part := range t.parts {
+		b.WriteString(part)
+		if i == len(t.fields) {
+			break
+		}
+		value, err := format(lookup(record, t.fields[i]))
+		if err != nil {
+			return "", fmt.Errorf("field %q: %w", t.fields[i], err)
+		}
+		b.WriteString(value)
+	}
+	return b.String(), nil
+}
+
+// lookup returns the value of a dotted field path, or the record itself for ".".
+func lookup(record generator.Record, path string) interface{} {
+	if path == "." {
+		return map[string]interface{}(record)
+	}
+	var value interface{} = map[string]interface{}(record)
+	for _, name := range strings.Split(path, ".") {
+		switch obj := value.(type) {
+		case map[string]interface{}:
+			value = obj[name]
+		case generator.Record:
+			value = obj[name]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// plain formats a value for URLs and headers: strings as they are, anything else as JSON.
+func plain(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// jsonValue formats a value for request bodies, where placeholders stand for JSON values.
+func jsonValue(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// Template renders generated records into HTTP requests.
+type Template struct {
+	method  string
+	path    text
+	query   text
+	params  []string
+	values  map[string]text
+	headers map[string]text
+	body    *text
+}
+
+// Compile parses the request description of an http task. fields are the top-level
+// fields of the generated records; placeholders must refer to one of them.
+func Compile(spec models.HTTPRequest, fields []string) (*Template, error) {
+	method := strings.ToUpper(strings.TrimSpace(spec.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead:
+	default:
+		return nil, fmt.Errorf("unsupported method %q", spec.Method)
+	}
+	if spec.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+
+	rawPath, rawQuery, _ := strings.Cut(spec.URL, "?")
+	t := &Template{
+		method:  method,
+		path:    parseText(rawPath),
+		query:   parseText(rawQuery),
+		values:  make(map[string]text, len(spec.Params)),
+		headers: make(map[string]text, len(spec.Headers)),
+	}
+	texts := []text{t.path, t.query}
+	for name, value := range spec.Params {
+		t.params = append(t.params, name)
+		t.values[name] = parseText(value)
+		texts = append(texts, t.values[name])
+	}
+	sort.Strings(t.params)
+	for name, value := range spec.Headers {
+		t.headers[http.CanonicalHeaderKey(name)] = parseText(value)
+		texts = append(texts, t.headers[http.CanonicalHeaderKey(name)])
+	}
+	switch {
+	case spec.Body != "":
+		body := parseText(spec.Body)
+		t.body = &body
+		texts = append(texts, body)
+	case method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch:
+		// Without a body template the record itself is sent.
+		t.body = &text{parts: []string{"", ""}, fields: []string{"."}}
+	}
+	if t.body != nil {
+		if _, ok := t.headers["Content-Type"]; !ok {
+			t.headers["Content-Type"] = text{parts: []string{"application/json"}}
+		}
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f] = true
+	}
+	for _, txt := range texts {
+		for _, path := range txt.fields {
+			name, _, _ := strings.Cut(path, ".")
+			if path != "." && !known[name] {
+				return nil, fmt.Errorf("placeholder {{%s}} refers to an unknown field", path)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// Method returns the HTTP method of the requests.
+func (t *Template) Method() string {
+	return t.method
+}
+
+// URL renders the request URL for record. Placeholders are escaped for the part of
+// the URL they appear in.
+func (t *Template) URL(record generator.Record) (string, error) {
+	path, err := t.path.render(record, escaped(url.PathEscape))
+	if err != nil {
+		return "", err
+	}
+	query, err := t.query.render(record, escaped(url.QueryEscape))
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("invalid url %q: an absolute http or https url is required", path)
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid query: %w", err)
+	}
+	for _, name := range t.params {
+		value, err := t.values[name].render(record, plain)
+		if err != nil {
+			return "", err
+		}
+		values.Add(name, value)
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+// Build renders the request for record.
+func (t *Template) Build(record generator.Record) (*http.Request, error) {
+	target, err := t.URL(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if t.body != nil {
+		rendered, err := t.body.render(record, jsonValue)
+		if err != nil {
+			return nil, err
+		}
+		body = []byte(rendered)
+	}
+	req, err := http.NewRequest(t.method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body, req.ContentLength = http.NoBody, 0
+	}
+
+	for name, value := range t.headers {
+		rendered, err := value.render(record, plain)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(name, rendered)
+	}
+	return req, nil
+}
+
+// escaped formats a value as plain text and escapes it with escape.
+func escaped(escape func(string) string) func(v interface{}) (string, error) {
+	return func(v interface{}) (string, error) {
+		s, err := plain(v)
+		return escape(s), err
+	}
+}
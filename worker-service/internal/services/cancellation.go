@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTaskCancelled is the cancellation cause of a task stopped on user request.
+var ErrTaskCancelled = errors.New("task cancelled")
+
+// cancelledTTL is how long a cancel command is remembered. Task and cancel messages travel
+// through different topics, so the cancel may arrive before the task or some of its shards.
+const cancelledTTL = time.Hour
+
+// cancelRegistry tracks the cancel functions of running tasks. The shards of a sharded
+// task share its task id, so several of them may be running at once.
+type cancelRegistry struct {
+	mu        sync.Mutex
+	running   map[string]map[int]context.CancelCauseFunc
+	next      int
+	cancelled map[string]time.Time
+	now       func() time.Time
+}
+
+func newCancelRegistry() *cancelRegistry {
+	return &cancelRegistry{
+		running:   make(map[string]map[int]context.CancelCauseFunc),
+		cancelled: make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+// start derives a cancellable context for the task. The returned context is already
+// cancelled if a cancel command for the task was received before it started.
+// done must be called when processing finishes.
+func (r *cancelRegistry) start(ctx context.Context, taskID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	r.mu.Lock()
+	r.next++
+	id := r.next
+	if _, ok := r.cancelled[taskID]; ok {
+		// The entry is kept until it expires: further shards of the task may still arrive.
+		cancel(ErrTaskCancelled)
+	} else {
+		if r.running[taskID] == nil {
+			r.running[taskID] = make(map[int]context.CancelCauseFunc)
+		}
+		r.running[taskID][id] = cancel
+	}
+	r.mu.Unlock()
+
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.running[taskID], id)
+		if len(r.running[taskID]) == 0 {
+			delete(r.running, taskID)
+		}
+		r.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancel stops the task if it is running here and reports whether it was.
+// The task is also remembered, so that it, or any of its shards, is skipped if it arrives later.
+func (r *cancelRegistry) cancel(taskID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cancels, running := r.running[taskID]
+	for _, cancel := range cancels {
+		cancel(ErrTaskCancelled)
+	}
+	delete(r.running, taskID)
+
+	now := r.now()
+	for id, at := range r.cancelled {
+		if now.Sub(at) > cancelledTTL {
+			delete(r.cancelled, id)
+		}
+	}
+	r.cancelled[taskID] = now
+	return running
+}
+
+// isCancelled reports whether ctx was cancelled by a cancel command.
+func isCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrTaskCancelled)
+}
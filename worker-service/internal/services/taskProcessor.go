@@ -0,0 +1,444 @@
+package services
+
+import (
+	"context"
+	"egress"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+	"worker-service/internal/output"
+	"worker-service/internal/requester"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// progressInterval limits how often progress events are published for a single task.
+	progressInterval = 2 * time.Second
+	// seedStream is the fixed PCG stream, so the task seed alone determines the generated data.
+	// Every record is drawn from a stream derived from it and the position of the record in
+	// the task, so neither the shard layout nor the worker that generates it matters.
+	seedStream = 0x9e3779b97f4a7c15
+	// streamBatchSize is how many records the generator hands to the output stage at once.
+	streamBatchSize = 1000
+	// streamBuffer is how many batches may wait for the output stage. A full buffer blocks
+	// the generator, so a task holds at most streamBuffer+2 batches in memory.
+	streamBuffer = 4
+)
+
+// TaskProcessor generates the data requested by a task and hands it to the output stage.
+type TaskProcessor interface {
+	Process(ctx context.Context, task models.Task) error
+	TaskCanceller
+}
+
+// TaskCanceller stops tasks on request of task-service.
+type TaskCanceller interface {
+	// Cancel stops the task if it is being processed and reports whether it was.
+	// A task cancelled before it arrives is skipped.
+	Cancel(taskID string) bool
+}
+
+// StatusReporter publishes task status changes back to task-service.
+type StatusReporter interface {
+	Report(ctx context.Context, event models.StatusEvent) error
+}
+
+type taskProcessor struct {
+	engine   *generator.Engine
+	sink     output.Sink
+	reporter StatusReporter
+	cancels  *cancelRegistry
+	// client sends the requests of http tasks. The targets are chosen by users and the
+	// responses end up in their request logs, so it only connects to public addresses.
+	client requester.Doer
+	logger *zap.SugaredLogger
+}
+
+func NewTaskProcessor(engine *generator.Engine, sink output.Sink, reporter StatusReporter, logger *zap.SugaredLogger) TaskProcessor {
+	return &taskProcessor{
+		engine:   engine,
+		sink:     sink,
+		reporter: reporter,
+		cancels:  newCancelRegistry(),
+		client:   egress.NewClient(requester.DefaultTimeout),
+		logger:   logger,
+	}
+}
+
+func (p *taskProcessor) Process(ctx context.Context, task models.Task) error {
+	ctx, done := p.cancels.start(ctx, task.TaskID)
+	defer done()
+
+	if isCancelled(ctx) {
+		p.logger.Infof("Task %s was cancelled before it started, skipping", task.TaskID)
+		return nil
+	}
+	if task.Merge {
+		return p.merge(ctx, task)
+	}
+
+	p.report(ctx, task, models.StatusRunning, 0, nil)
+
+	generated, artifact, report, err := p.process(ctx, task)
+	if isCancelled(ctx) {
+		// task-service has already marked the task cancelled; partial output is discarded.
+		p.logger.Infof("Task %s cancelled after %d records", task.TaskID, generated)
+		p.discard(ctx, task)
+		return nil
+	}
+	if err != nil && ctx.Err() != nil {
+		// The worker is shutting down; the chunks and checkpoint stay so that the
+		// worker that receives the task again resumes it.
+		p.logger.Infof("Task %s interrupted after %d records, leaving its checkpoint", task.TaskID, generated)
+		return err
+	}
+	if err != nil {
+		p.report(ctx, task, models.StatusFailed, generated, err)
+		p.discard(ctx, task)
+		return err
+	}
+
+	p.publish(ctx, models.StatusEvent{
+		TaskID:           task.TaskID,
+		Shard:            shardIndex(task),
+		Status:           models.StatusSucceeded,
+		RecordsGenerated: generated,
+		ResultKey:        artifact.Key,
+		ResultSize:       artifact.Size,
+		Report:           report,
+	})
+	return nil
+}
+
+// merge assembles the result of a sharded task whose shards have all succeeded.
+func (p *taskProcessor) merge(ctx context.Context, task models.Task) error {
+	artifact, err := p.sink.Merge(ctx, task)
+	if isCancelled(ctx) {
+		p.logger.Infof("Task %s cancelled while merging its shards", task.TaskID)
+		return nil
+	}
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+	if err != nil {
+		err = fmt.Errorf("task %s: %w", task.TaskID, err)
+		p.report(ctx, task, models.StatusFailed, task.Amount, err)
+		return err
+	}
+
+	p.publish(ctx, models.StatusEvent{
+		TaskID:           task.TaskID,
+		Status:           models.StatusSucceeded,
+		RecordsGenerated: task.Amount,
+		ResultKey:        artifact.Key,
+		ResultSize:       artifact.Size,
+	})
+	return nil
+}
+
+// process generates the task result and stores it. For an http task it also returns the
+// report of the requests.
+func (p *taskProcessor) process(ctx context.Context, task models.Task) (int, output.Artifact, *models.Report, error) {
+	if task.Amount <= 0 {
+		return 0, output.Artifact{}, nil, fmt.Errorf("task %s: amount must be positive, got %d", task.TaskID, task.Amount)
+	}
+
+	if generator.IsDataset(task.Template) {
+		if task.Type == models.TaskTypeHTTP {
+			return 0, output.Artifact{}, nil, fmt.Errorf("task %s: http tasks do not support dataset templates", task.TaskID)
+		}
+		n, artifact, err := p.processDataset(ctx, task)
+		return n, artifact, nil, err
+	}
+
+	schema, err := p.engine.CompileLocale(task.Template, task.Locale)
+	if err != nil {
+		return 0, output.Artifact{}, nil, fmt.Errorf("task %s: invalid template: %w", task.TaskID, err)
+	}
+	fields := schema.Fields()
+
+	// An http task sends every record to the target API; its result is the request log.
+	var sender *requester.Requester
+	if task.Type == models.TaskTypeHTTP {
+		if task.Request == nil {
+			return 0, output.Artifact{}, nil, fmt.Errorf("task %s: http task without a request", task.TaskID)
+		}
+		if sender, err = requester.New(p.client, *task.Request, fields); err != nil {
+			return 0, output.Artifact{}, nil, fmt.Errorf("task %s: invalid request: %w", task.TaskID, err)
+		}
+		fields = requester.Fields
+	}
+
+	amount, offset := task.Amount, 0
+	if task.Shard != nil {
+		amount, offset = task.Shard.Amount, task.Shard.Offset
+	}
+	from, err := p.sink.Checkpoint(ctx, task)
+	if err != nil {
+		p.logger.Warnf("Task %s: failed to load checkpoint, starting over: %v", task.TaskID, err)
+		from = nil
+	}
+	start := 0
+	stats := requester.NewStats()
+	if from != nil {
+		state := from.State
+		var err error
+		if sender != nil {
+			state, stats, err = decodeHTTPState(from.State)
+		}
+		if err == nil && len(state) > 0 {
+			// Older workers drew the records of a task from one stream and kept its state
+			// in the checkpoint; their chunks do not match records seeded by position.
+			err = errors.New("checkpoint holds the generator state of an older worker")
+		}
+		if err != nil {
+			p.logger.Warnf("Task %s: invalid checkpoint, starting over: %v", task.TaskID, err)
+			from, stats = nil, requester.NewStats()
+		} else {
+			start = from.Records
+			if sender != nil {
+				sender.Resume(time.Duration(stats.ElapsedSec * float64(time.Second)))
+			}
+			p.logger.Infof("Task %s: resuming from record %d of %d", task.TaskID, start, amount)
+		}
+	}
+
+	// The generator runs ahead of the output stage by at most streamBuffer batches.
+	// It aborts streamCtx if it fails, so that the output stage does not store a short result.
+	streamCtx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+	batches := make(chan output.Batch, streamBuffer)
+	generated := make(chan int, 1)
+	progress := p.progress(ctx, task, nil)
+	began := time.Now()
+	go func() {
+		report := progress
+		if sender != nil {
+			report = func(int) {}
+		}
+		n, err := p.stream(streamCtx, schema, task.Seed, offset, start, amount, batches, report)
+		if err != nil {
+			abort(err)
+		}
+		close(batches)
+		generated <- n
+	}()
+
+	results := (<-chan output.Batch)(batches)
+	if sender != nil {
+		results = p.send(streamCtx, abort, sender, stats, batches, p.progress(ctx, task, stats.Report))
+	}
+	artifact, err := p.sink.Stream(streamCtx, task, fields, from, results)
+	if cause := context.Cause(streamCtx); err != nil && cause != nil {
+		err = cause
+	}
+	abort(nil)
+	n := <-generated
+	if err != nil {
+		return n, output.Artifact{}, nil, fmt.Errorf("task %s: failed to write output: %w", task.TaskID, err)
+	}
+	if sender != nil {
+		// A soak profile may end before every record is sent.
+		n = stats.Total
+	}
+	p.logger.Infof("Generated %d records for task %s in %v", n-start, task.TaskID, time.Since(began))
+	if sender != nil {
+		return n, artifact, stats.Report(), nil
+	}
+	return n, artifact, nil, nil
+}
+
+// stream generates records start..amount-1 in batches and sends them to batches. offset is
+// the position of the first record within the task, which seeds the records together with
+// seed. It stops early when ctx is cancelled and returns the number of records generated
+// for the task, including those of earlier runs.
+func (p *taskProcessor) stream(ctx context.Context, schema *generator.Schema, seed int64, offset, start, amount int, batches chan<- output.Batch, progress func(generated int)) (int, error) {
+	src := rand.NewPCG(0, 0)
+	r := rand.New(src)
+	for generated := start; generated < amount; {
+		records := make([]generator.Record, min(streamBatchSize, amount-generated))
+		for i := range records {
+			src.Seed(uint64(seed), recordStream(offset+generated+i))
+			records[i] = schema.Generate(r)
+		}
+
+		select {
+		case batches <- output.Batch{Records: records, Generated: generated + len(records)}:
+			generated += len(records)
+			progress(generated)
+		case <-ctx.Done():
+			return generated, ctx.Err()
+		}
+	}
+	return amount, nil
+}
+
+// recordStream returns the PCG stream of the record at index within its task: a
+// splitmix64 hash of the index, so that neighbouring records get unrelated streams.
+func recordStream(index int) uint64 {
+	z := uint64(index) + seedStream
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// send passes the records of every batch to sender, counts the results in stats and
+// returns the batches of the resulting request log. Requests of records generated after
+// the last checkpoint are sent again if the task is resumed. It aborts ctx if a batch
+// cannot be sent, and ends the log early when the load profile of the task is over.
+func (p *taskProcessor) send(ctx context.Context, abort context.CancelCauseFunc, sender *requester.Requester, stats *requester.Stats, batches <-chan output.Batch, progress func(generated int)) <-chan output.Batch {
+	logs := make(chan output.Batch, streamBuffer)
+	go func() {
+		defer close(logs)
+		for batch := range batches {
+			first := batch.Generated - len(batch.Records)
+			results, err := sender.Send(ctx, batch.Records, first)
+			finished := errors.Is(err, requester.ErrLoadFinished)
+			if err != nil && !finished {
+				abort(err)
+				return
+			}
+			log := make([]generator.Record, len(results))
+			for i, result := range results {
+				stats.Add(result)
+				log[i] = result.Record()
+			}
+			stats.SetThroughput(sender.Elapsed(), sender.TargetRPS())
+			state, err := json.Marshal(httpState{Generator: batch.State, Stats: stats})
+			if err != nil {
+				abort(fmt.Errorf("failed to save request stats: %w", err))
+				return
+			}
+
+			if len(log) > 0 {
+				select {
+				case logs <- output.Batch{Records: log, Generated: first + len(log), State: state}:
+					progress(first + len(log))
+				case <-ctx.Done():
+					return
+				}
+			}
+			if finished {
+				p.logger.Infof("Load profile finished after %d requests", stats.Total)
+				return
+			}
+		}
+	}()
+	return logs
+}
+
+// httpState is the checkpoint state of an http task: the stats of the requests sent up
+// to the checkpoint. Generator is only set in checkpoints of older workers.
+type httpState struct {
+	Generator []byte           `json:"generator"`
+	Stats     *requester.Stats `json:"stats"`
+}
+
+func decodeHTTPState(data []byte) ([]byte, *requester.Stats, error) {
+	var state httpState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode request stats: %w", err)
+	}
+	if state.Stats == nil {
+		state.Stats = requester.NewStats()
+	}
+	return state.Generator, state.Stats, nil
+}
+
+// processDataset generates the related entities of a dataset template and stores them as one artifact.
+func (p *taskProcessor) processDataset(ctx context.Context, task models.Task) (int, output.Artifact, error) {
+	dataset, err := p.engine.CompileDataset(task.Template, task.Locale)
+	if err != nil {
+		return 0, output.Artifact{}, fmt.Errorf("task %s: invalid template: %w", task.TaskID, err)
+	}
+
+	start := time.Now()
+	collections, err := dataset.Generate(ctx, taskRand(task), task.Amount, p.progress(ctx, task, nil))
+	if err != nil {
+		return 0, output.Artifact{}, fmt.Errorf("task %s: generation interrupted: %w", task.TaskID, err)
+	}
+	generated := 0
+	for _, c := range collections {
+		generated += len(c.Records)
+	}
+	p.logger.Infof("Generated %d records of %d entities for task %s in %v", generated, len(collections), task.TaskID, time.Since(start))
+
+	artifact, err := p.sink.WriteDataset(ctx, task, collections)
+	if err != nil {
+		return generated, output.Artifact{}, fmt.Errorf("task %s: failed to write output: %w", task.TaskID, err)
+	}
+	return generated, artifact, nil
+}
+
+// discard removes the partial output of a task that will not be resumed.
+func (p *taskProcessor) discard(ctx context.Context, task models.Task) {
+	if err := p.sink.Discard(context.WithoutCancel(ctx), task); err != nil {
+		p.logger.Warnf("Failed to discard partial output of task %s: %v", task.TaskID, err)
+	}
+}
+
+// taskRand returns the source of randomness for a task; it depends on the task seed only.
+func taskRand(task models.Task) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(task.Seed), seedStream))
+}
+
+// progress returns a callback that reports generation progress at most every progressInterval.
+// report, if not nil, supplies the report of an http task to include.
+func (p *taskProcessor) progress(ctx context.Context, task models.Task, report func() *models.Report) func(generated int) {
+	lastReport := time.Now()
+	return func(generated int) {
+		if time.Since(lastReport) < progressInterval {
+			return
+		}
+		lastReport = time.Now()
+		event := models.StatusEvent{
+			TaskID:           task.TaskID,
+			Shard:            shardIndex(task),
+			Status:           models.StatusRunning,
+			RecordsGenerated: generated,
+		}
+		if report != nil {
+			event.Report = report()
+		}
+		p.publish(ctx, event)
+	}
+}
+
+func (p *taskProcessor) Cancel(taskID string) bool {
+	return p.cancels.cancel(taskID)
+}
+
+// report publishes a status event without a result.
+func (p *taskProcessor) report(ctx context.Context, task models.Task, status string, generated int, cause error) {
+	event := models.StatusEvent{
+		TaskID:           task.TaskID,
+		Shard:            shardIndex(task),
+		Status:           status,
+		RecordsGenerated: generated,
+	}
+	if cause != nil {
+		event.Error = cause.Error()
+	}
+	p.publish(ctx, event)
+}
+
+// shardIndex returns the index of the shard a task message stands for, or nil for a whole task.
+func shardIndex(task models.Task) *int {
+	if task.Shard == nil {
+		return nil
+	}
+	index := task.Shard.Index
+	return &index
+}
+
+// publish sends a status event; failures are logged but never abort processing.
+func (p *taskProcessor) publish(ctx context.Context, event models.StatusEvent) {
+	if err := p.reporter.Report(ctx, event); err != nil {
+		p.logger.Warnf("Failed to report status %s for task %s: %v", event.Status, event.TaskID, err)
+	}
+}
@@ -0,0 +1,609 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"worker-service/internal/generator"
+	"worker-service/internal/models"
+	"worker-service/internal/output"
+	"worker-service/internal/requester"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSink — фейковая реализация output.Sink. Stream собирает все пакеты и передаёт записи в writeFunc.
+type fakeSink struct {
+	writeFunc        func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error)
+	writeDatasetFunc func(ctx context.Context, task models.Task, collections []generator.Collection) (output.Artifact, error)
+	checkpointFunc   func(ctx context.Context, task models.Task) (*output.Checkpoint, error)
+	mergeFunc        func(ctx context.Context, task models.Task) (output.Artifact, error)
+	batches          []output.Batch
+	discarded        bool
+}
+
+func (f *fakeSink) Stream(ctx context.Context, task models.Task, fields []string, from *output.Checkpoint, batches <-chan output.Batch) (output.Artifact, error) {
+	var records []generator.Record
+	for batch := range batches {
+		f.batches = append(f.batches, batch)
+		records = append(records, batch.Records...)
+	}
+	if err := ctx.Err(); err != nil {
+		return output.Artifact{}, err
+	}
+	return f.writeFunc(ctx, task, fields, records)
+}
+
+func (f *fakeSink) Checkpoint(ctx context.Context, task models.Task) (*output.Checkpoint, error) {
+	if f.checkpointFunc == nil {
+		return nil, nil
+	}
+	return f.checkpointFunc(ctx, task)
+}
+
+func (f *fakeSink) Discard(ctx context.Context, task models.Task) error {
+	f.discarded = true
+	return nil
+}
+
+func (f *fakeSink) WriteDataset(ctx context.Context, task models.Task, collections []generator.Collection) (output.Artifact, error) {
+	return f.writeDatasetFunc(ctx, task, collections)
+}
+
+func (f *fakeSink) Merge(ctx context.Context, task models.Task) (output.Artifact, error) {
+	return f.mergeFunc(ctx, task)
+}
+
+// fakeReporter — фейковая реализация StatusReporter, запоминающая события.
+type fakeReporter struct {
+	events []models.StatusEvent
+}
+
+func (f *fakeReporter) Report(ctx context.Context, event models.StatusEvent) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeReporter) statuses() []string {
+	statuses := make([]string, len(f.events))
+	for i, e := range f.events {
+		statuses[i] = e.Status
+	}
+	return statuses
+}
+
+func TestProcess_Success(t *testing.T) {
+	var written []generator.Record
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			assert.Equal(t, []string{"age", "name"}, fields)
+			written = records
+			return output.Artifact{Key: "results/task-123.json", Size: 42}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	task := models.Task{
+		TaskID:   "task-123",
+		Template: map[string]interface{}{"name": "name", "age": "int(1,100)"},
+		Amount:   5,
+	}
+
+	err := processor.Process(context.Background(), task)
+	require.NoError(t, err)
+	assert.Len(t, written, 5)
+	assert.Equal(t, []string{models.StatusRunning, models.StatusSucceeded}, reporter.statuses())
+	assert.Equal(t, 5, reporter.events[1].RecordsGenerated)
+	assert.Equal(t, "results/task-123.json", reporter.events[1].ResultKey)
+	assert.Equal(t, int64(42), reporter.events[1].ResultSize)
+}
+
+// TestProcess_Deterministic проверяет, что одинаковые шаблон, количество и seed дают одинаковые данные.
+func TestProcess_Deterministic(t *testing.T) {
+	var runs [][]generator.Record
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			runs = append(runs, records)
+			return output.Artifact{}, nil
+		},
+	}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, &fakeReporter{}, zap.NewNop().Sugar())
+
+	template := map[string]interface{}{"id": "uuid", "name": "name", "age": "int(1,100)"}
+	for i, seed := range []int64{42, 42, 43} {
+		err := processor.Process(context.Background(), models.Task{
+			ID:       int64(i + 1),
+			TaskID:   fmt.Sprintf("task-%d", i),
+			Template: template,
+			Amount:   20,
+			Seed:     seed,
+		})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, runs, 3)
+	assert.Equal(t, runs[0], runs[1])
+	assert.NotEqual(t, runs[0], runs[2])
+}
+
+// TestProcess_Shards проверяет, что события шардов несут номер шарда, а склеенные шарды
+// совпадают с несегментированной задачей с тем же seed при любом делении на шарды.
+func TestProcess_Shards(t *testing.T) {
+	written := map[int][]generator.Record{}
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			index := -1
+			if task.Shard != nil {
+				index = task.Shard.Index
+			}
+			written[index] = records
+			return output.Artifact{Key: output.ResultKey(task)}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	task := models.Task{
+		TaskID:   "task-123",
+		Template: map[string]interface{}{"id": "uuid", "age": "int(1,100)"},
+		Amount:   2500,
+		Seed:     42,
+	}
+	require.NoError(t, processor.Process(context.Background(), task))
+	whole := written[-1]
+	require.Len(t, whole, 2500)
+
+	for _, count := range []int{2, 3, 7} {
+		reporter.events = nil
+		task.ShardCount = count
+		var merged []generator.Record
+		for i, shard := range splitShards(task.Amount, count) {
+			task.Shard = &shard
+			require.NoError(t, processor.Process(context.Background(), task))
+			assert.Len(t, written[i], shard.Amount)
+			merged = append(merged, written[i]...)
+		}
+		assert.Equal(t, whole, merged, "%d shards", count)
+
+		last := reporter.events[len(reporter.events)-1]
+		assert.Equal(t, count-1, *last.Shard)
+		assert.Equal(t, fmt.Sprintf("shards/task-123/%d.json", count-1), last.ResultKey)
+	}
+}
+
+// splitShards делит amount записей на count последовательных шардов, как task-service.
+func splitShards(amount, count int) []models.Shard {
+	shards := make([]models.Shard, count)
+	offset := 0
+	for i := range shards {
+		size := amount / count
+		if i < amount%count {
+			size++
+		}
+		shards[i] = models.Shard{Index: i, Count: count, Offset: offset, Amount: size}
+		offset += size
+	}
+	return shards
+}
+
+// TestProcess_Merge проверяет сборку результата шардированной задачи.
+func TestProcess_Merge(t *testing.T) {
+	sink := &fakeSink{
+		mergeFunc: func(ctx context.Context, task models.Task) (output.Artifact, error) {
+			return output.Artifact{Key: output.ResultKey(task), Size: 128}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	err := processor.Process(context.Background(), models.Task{TaskID: "task-123", Amount: 5, Format: "csv", ShardCount: 2, Merge: true})
+	require.NoError(t, err)
+	require.Len(t, reporter.events, 1)
+	assert.Equal(t, models.StatusEvent{
+		TaskID:           "task-123",
+		Status:           models.StatusSucceeded,
+		RecordsGenerated: 5,
+		ResultKey:        "results/task-123.csv",
+		ResultSize:       128,
+	}, reporter.events[0])
+}
+
+// TestProcess_CancelledShards проверяет, что после отмены пропускаются все шарды задачи.
+func TestProcess_CancelledShards(t *testing.T) {
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			t.Fatal("sink must not be called")
+			return output.Artifact{}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	processor.Cancel("task-123")
+	for i := 0; i < 2; i++ {
+		err := processor.Process(context.Background(), models.Task{
+			TaskID:     "task-123",
+			Template:   map[string]interface{}{"id": "uuid"},
+			Amount:     4,
+			ShardCount: 2,
+			Shard:      &models.Shard{Index: i, Count: 2, Offset: 2 * i, Amount: 2},
+		})
+		require.NoError(t, err)
+	}
+	assert.Empty(t, reporter.events)
+}
+
+// TestProcess_HTTP проверяет, что http-задача отправляет каждую запись и сохраняет журнал запросов.
+func TestProcess_HTTP(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var fields []string
+	var written []generator.Record
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, f []string, records []generator.Record) (output.Artifact, error) {
+			fields, written = f, records
+			return output.Artifact{Key: "results/task-123.json"}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+	// The test server listens on loopback, which the client of the processor refuses.
+	processor.(*taskProcessor).client = server.Client()
+
+	err := processor.Process(context.Background(), models.Task{
+		TaskID:   "task-123",
+		Type:     models.TaskTypeHTTP,
+		Template: map[string]interface{}{"id": "uuid"},
+		Amount:   5,
+		Request:  &models.HTTPRequest{Method: "POST", URL: server.URL + "/users", Concurrency: 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), received.Load())
+	assert.Equal(t, requester.Fields, fields)
+	require.Len(t, written, 5)
+	assert.Equal(t, http.StatusCreated, written[4]["status"])
+	assert.Equal(t, 4, written[4]["index"])
+	assert.Equal(t, []string{models.StatusRunning, models.StatusSucceeded}, reporter.statuses())
+}
+
+// TestProcess_HTTPInternalTarget проверяет, что http-задача не отправляет запросы во
+// внутреннюю сеть и не возвращает владельцу ответы оттуда.
+func TestProcess_HTTPInternalTarget(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.Write([]byte(`{"secret":"internal"}`))
+	}))
+	defer server.Close()
+
+	for _, url := range []string{server.URL + "/users", "http://10.0.0.5/users", "http://169.254.169.254/latest/meta-data"} {
+		var written []generator.Record
+		sink := &fakeSink{
+			writeFunc: func(ctx context.Context, task models.Task, f []string, records []generator.Record) (output.Artifact, error) {
+				written = records
+				return output.Artifact{Key: "results/task-123.json"}, nil
+			},
+		}
+		reporter := &fakeReporter{}
+		processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+		err := processor.Process(context.Background(), models.Task{
+			TaskID:   "task-123",
+			Type:     models.TaskTypeHTTP,
+			Template: map[string]interface{}{"id": "uuid"},
+			Amount:   2,
+			Request:  &models.HTTPRequest{Method: "GET", URL: url},
+		})
+		require.NoError(t, err, url)
+		require.Len(t, written, 2, url)
+		for _, record := range written {
+			assert.Contains(t, record["error"], "not publicly routable", url)
+			assert.Empty(t, record["response"], url)
+			assert.Equal(t, false, record["passed"], url)
+		}
+		last := reporter.events[len(reporter.events)-1]
+		require.NotNil(t, last.Report, url)
+		assert.Equal(t, 2, last.Report.Failed, url)
+	}
+	assert.Zero(t, received.Load())
+}
+
+// TestProcess_HTTPSoak проверяет, что задача с профилем soak завершается по истечении длительности.
+func TestProcess_HTTPSoak(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer server.Close()
+
+	var written []generator.Record
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, f []string, records []generator.Record) (output.Artifact, error) {
+			written = records
+			return output.Artifact{Key: "results/task-123.json"}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+	// The test server listens on loopback, which the client of the processor refuses.
+	processor.(*taskProcessor).client = server.Client()
+
+	err := processor.Process(context.Background(), models.Task{
+		TaskID:   "task-123",
+		Type:     models.TaskTypeHTTP,
+		Template: map[string]interface{}{"id": "uuid"},
+		Amount:   5000,
+		Request: &models.HTTPRequest{
+			Method:      "GET",
+			URL:         server.URL + "/users/{{id}}",
+			Concurrency: 4,
+			Load:        &models.LoadProfile{Type: models.LoadSoak, Rate: 40, DurationSec: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	n := int(received.Load())
+	assert.InDelta(t, 41, n, 3)
+	assert.Len(t, written, n)
+	last := reporter.events[len(reporter.events)-1]
+	assert.Equal(t, models.StatusSucceeded, last.Status)
+	assert.Equal(t, n, last.RecordsGenerated)
+	require.NotNil(t, last.Report)
+	require.NotNil(t, last.Report.Throughput)
+	assert.InDelta(t, 40, last.Report.Throughput.TargetRPS, 2)
+	assert.InDelta(t, 40, last.Report.Throughput.AchievedRPS, 5)
+}
+
+// TestProcess_HTTPInvalidRequest проверяет ошибку задачи с некорректным описанием запроса.
+func TestProcess_HTTPInvalidRequest(t *testing.T) {
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), &fakeSink{}, reporter, zap.NewNop().Sugar())
+
+	err := processor.Process(context.Background(), models.Task{
+		TaskID:   "task-123",
+		Type:     models.TaskTypeHTTP,
+		Template: map[string]interface{}{"id": "uuid"},
+		Amount:   5,
+		Request:  &models.HTTPRequest{Method: "GET", URL: "http://localhost/{{email}}"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{models.StatusRunning, models.StatusFailed}, reporter.statuses())
+	assert.Contains(t, reporter.events[1].Error, "unknown field")
+}
+
+// TestProcess_Resume проверяет, что задача продолжается с контрольной точки и даёт те же записи, что и полный прогон.
+func TestProcess_Resume(t *testing.T) {
+	var written []generator.Record
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			written = records
+			return output.Artifact{Key: "results/task-123.json"}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+	task := models.Task{
+		TaskID:   "task-123",
+		Template: map[string]interface{}{"id": "uuid", "age": "int(1,100)"},
+		Amount:   2500,
+		Seed:     7,
+	}
+
+	require.NoError(t, processor.Process(context.Background(), task))
+	full := written
+	require.Len(t, full, 2500)
+	require.Len(t, sink.batches, 3)
+	assert.Equal(t, 1000, sink.batches[0].Generated)
+
+	checkpoint := &output.Checkpoint{TaskID: task.TaskID, Records: 1000, Chunks: 1, State: sink.batches[0].State}
+	sink.batches = nil
+	sink.checkpointFunc = func(ctx context.Context, task models.Task) (*output.Checkpoint, error) {
+		return checkpoint, nil
+	}
+
+	require.NoError(t, processor.Process(context.Background(), task))
+	assert.Equal(t, full[1000:], written)
+	assert.Equal(t, 2500, reporter.events[len(reporter.events)-1].RecordsGenerated)
+	assert.False(t, sink.discarded)
+}
+
+// TestProcess_ResumeOlderCheckpoint проверяет, что контрольная точка с состоянием
+// генератора от старого воркера отбрасывается и задача генерируется заново.
+func TestProcess_ResumeOlderCheckpoint(t *testing.T) {
+	var written []generator.Record
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			written = records
+			return output.Artifact{Key: "results/task-123.json"}, nil
+		},
+		checkpointFunc: func(ctx context.Context, task models.Task) (*output.Checkpoint, error) {
+			return &output.Checkpoint{TaskID: task.TaskID, Records: 1000, Chunks: 1, State: []byte("pcg state")}, nil
+		},
+	}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, &fakeReporter{}, zap.NewNop().Sugar())
+
+	task := models.Task{TaskID: "task-123", Template: map[string]interface{}{"id": "uuid"}, Amount: 1500, Seed: 7}
+	require.NoError(t, processor.Process(context.Background(), task))
+	assert.Len(t, written, 1500)
+}
+
+// TestProcess_Dataset проверяет генерацию связанных сущностей одной задачей.
+func TestProcess_Dataset(t *testing.T) {
+	var written []generator.Collection
+	sink := &fakeSink{
+		writeDatasetFunc: func(ctx context.Context, task models.Task, collections []generator.Collection) (output.Artifact, error) {
+			written = collections
+			return output.Artifact{Key: "results/task-123.sql", Size: 100}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	err := processor.Process(context.Background(), models.Task{
+		TaskID: "task-123",
+		Template: map[string]interface{}{
+			"schema_version": 1,
+			"entities": map[string]interface{}{
+				"users": map[string]interface{}{"fields": map[string]interface{}{"id": "uuid"}},
+				"orders": map[string]interface{}{
+					"parent":     "users",
+					"per_parent": 2,
+					"fields": map[string]interface{}{
+						"user_id": map[string]interface{}{"type": "ref", "entity": "users", "field": "id"},
+					},
+				},
+			},
+		},
+		Amount: 3,
+		Format: "sql",
+	})
+	require.NoError(t, err)
+	require.Len(t, written, 2)
+	assert.Equal(t, "users", written[0].Name)
+	assert.Len(t, written[0].Records, 3)
+	assert.Len(t, written[1].Records, 6)
+	assert.Equal(t, []string{models.StatusRunning, models.StatusSucceeded}, reporter.statuses())
+	assert.Equal(t, 9, reporter.events[1].RecordsGenerated)
+}
+
+func TestProcess_InvalidTemplate(t *testing.T) {
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			t.Fatal("sink must not be called")
+			return output.Artifact{}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	err := processor.Process(context.Background(), models.Task{
+		TaskID:   "task-123",
+		Template: map[string]interface{}{"x": "unknown"},
+		Amount:   5,
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{models.StatusRunning, models.StatusFailed}, reporter.statuses())
+	assert.Contains(t, reporter.events[1].Error, "unknown generator")
+}
+
+func TestProcess_SinkError(t *testing.T) {
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			return output.Artifact{}, errors.New("disk full")
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	err := processor.Process(context.Background(), models.Task{
+		TaskID:   "task-123",
+		Template: map[string]interface{}{"id": "uuid"},
+		Amount:   3,
+	})
+	require.Error(t, err)
+	assert.Equal(t, models.StatusFailed, reporter.events[1].Status)
+	assert.Contains(t, reporter.events[1].Error, "disk full")
+	assert.True(t, sink.discarded)
+}
+
+func TestProcess_CancelledWhileRunning(t *testing.T) {
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			t.Fatal("partial output must not be written")
+			return output.Artifact{}, nil
+		},
+	}
+	var processor TaskProcessor
+	reporter := &cancellingReporter{cancel: func(taskID string) bool { return processor.Cancel(taskID) }}
+	processor = NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	err := processor.Process(context.Background(), models.Task{
+		TaskID:   "task-123",
+		Template: map[string]interface{}{"id": "uuid"},
+		Amount:   100000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.StatusRunning}, reporter.statuses())
+	assert.True(t, reporter.cancelled)
+	assert.True(t, sink.discarded)
+}
+
+// TestProcess_Shutdown проверяет, что при остановке воркера контрольная точка сохраняется, а задача не помечается проваленной.
+func TestProcess_Shutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			t.Fatal("partial output must not be stored as the result")
+			return output.Artifact{}, nil
+		},
+	}
+	reporter := &shutdownReporter{cancel: cancel}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	err := processor.Process(ctx, models.Task{
+		TaskID:   "task-123",
+		Template: map[string]interface{}{"id": "uuid"},
+		Amount:   100000,
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{models.StatusRunning}, reporter.statuses())
+	assert.False(t, sink.discarded)
+}
+
+// shutdownReporter останавливает воркер, как только задача переходит в running.
+type shutdownReporter struct {
+	fakeReporter
+	cancel context.CancelFunc
+}
+
+func (s *shutdownReporter) Report(ctx context.Context, event models.StatusEvent) error {
+	s.cancel()
+	return s.fakeReporter.Report(ctx, event)
+}
+
+func TestProcess_CancelledBeforeStart(t *testing.T) {
+	sink := &fakeSink{
+		writeFunc: func(ctx context.Context, task models.Task, fields []string, records []generator.Record) (output.Artifact, error) {
+			t.Fatal("sink must not be called")
+			return output.Artifact{}, nil
+		},
+	}
+	reporter := &fakeReporter{}
+	processor := NewTaskProcessor(generator.NewEngine(), sink, reporter, zap.NewNop().Sugar())
+
+	assert.False(t, processor.Cancel("task-123"))
+
+	err := processor.Process(context.Background(), models.Task{
+		TaskID:   "task-123",
+		Template: map[string]interface{}{"id": "uuid"},
+		Amount:   3,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, reporter.events)
+}
+
+// cancellingReporter cancels the task as soon as it is reported running.
+type cancellingReporter struct {
+	fakeReporter
+	cancel    func(taskID string) bool
+	cancelled bool
+}
+
+func (c *cancellingReporter) Report(ctx context.Context, event models.StatusEvent) error {
+	if event.Status == models.StatusRunning && !c.cancelled {
+		c.cancelled = c.cancel(event.TaskID)
+	}
+	return c.fakeReporter.Report(ctx, event)
+}
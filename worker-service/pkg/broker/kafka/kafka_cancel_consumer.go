@@ -0,0 +1,86 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+	"worker-service/internal/config"
+	"worker-service/internal/models"
+	"worker-service/internal/services"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+type cancelConsumer struct {
+	reader    *kafka.Reader
+	logger    *zap.SugaredLogger
+	config    config.KafkaConfig
+	canceller services.TaskCanceller
+}
+
+// NewCancelConsumer creates a consumer of task cancel commands.
+// A task may run on any worker, so every instance reads the whole topic
+// under its own consumer group, starting from the newest commands.
+func NewCancelConsumer(cfg config.KafkaConfig, canceller services.TaskCanceller, logger *zap.SugaredLogger) (KafkaConsumer, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     strings.Split(cfg.Brokers, ","),
+		Topic:       cfg.CancelTopic,
+		GroupID:     "worker-service-cancel-" + hostname,
+		MinBytes:    1,
+		MaxBytes:    1e6,
+		MaxWait:     500 * time.Millisecond,
+		StartOffset: kafka.LastOffset,
+	})
+
+	return &cancelConsumer{
+		reader:    reader,
+		logger:    logger,
+		config:    cfg,
+		canceller: canceller,
+	}, nil
+}
+
+func (k *cancelConsumer) Consume(ctx context.Context) error {
+	for {
+		msg, err := k.reader.ReadMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+				k.logger.Info("Stopping Kafka cancel consumer due to context cancellation")
+				return nil
+			}
+			k.logger.Errorf("Failed to read cancel command: %v", err)
+			time.Sleep(time.Duration(k.config.RetryDelay) * time.Second)
+			continue
+		}
+
+		var cmd models.CancelCommand
+		if err := json.Unmarshal(msg.Value, &cmd); err != nil || cmd.TaskID == "" {
+			k.logger.Errorf("Skipping malformed cancel command: %s", msg.Value)
+			continue
+		}
+
+		if k.canceller.Cancel(cmd.TaskID) {
+			k.logger.Infof("Task %s cancelled on request", cmd.TaskID)
+		} else {
+			k.logger.Debugf("Task %s is not running here, will skip it if it arrives", cmd.TaskID)
+		}
+	}
+}
+
+func (k *cancelConsumer) Close() error {
+	if err := k.reader.Close(); err != nil {
+		k.logger.Errorf("Failed to close Kafka cancel reader: %v", err)
+		return err
+	}
+	k.logger.Info("Kafka cancel consumer connection closed")
+	return nil
+}
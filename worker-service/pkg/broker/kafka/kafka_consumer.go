@@ -8,6 +8,7 @@ import (
 	"time"
 	"worker-service/internal/config"
 	"worker-service/internal/models"
+	"worker-service/internal/services"
 
 	"github.com/segmentio/kafka-go"
 	"github.com/sony/gobreaker"
@@ -21,14 +22,15 @@ type KafkaConsumer interface {
 }
 
 type kafkaConsumer struct {
-	reader *kafka.Reader
-	logger *zap.SugaredLogger
-	cb     *gobreaker.CircuitBreaker
-	config config.KafkaConfig
+	reader    *kafka.Reader
+	logger    *zap.SugaredLogger
+	cb        *gobreaker.CircuitBreaker
+	config    config.KafkaConfig
+	processor services.TaskProcessor
 }
 
 // NewKafkaConsumer creates a new Kafka consumer instance
-func NewKafkaConsumer(cfg config.KafkaConfig, logger *zap.SugaredLogger) (KafkaConsumer, error) {
+func NewKafkaConsumer(cfg config.KafkaConfig, processor services.TaskProcessor, logger *zap.SugaredLogger) (KafkaConsumer, error) {
 	// Circuit Breaker configuration
 	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
 		Name:        "kafka-consumer",
@@ -54,10 +56,11 @@ func NewKafkaConsumer(cfg config.KafkaConfig, logger *zap.SugaredLogger) (KafkaC
 	})
 
 	return &kafkaConsumer{
-		reader: reader,
-		logger: logger,
-		cb:     cb,
-		config: cfg,
+		reader:    reader,
+		logger:    logger,
+		cb:        cb,
+		config:    cfg,
+		processor: processor,
 	}, nil
 }
 
@@ -70,7 +73,9 @@ func (k *kafkaConsumer) Consume(ctx context.Context) error {
 		default:
 			// Use circuit breaker for message consumption
 			_, err := k.cb.Execute(func() (interface{}, error) {
-				msg, err := k.reader.ReadMessage(ctx)
+				// The offset is committed only after the task is processed, so a task
+				// of a worker that stops midway is delivered again and resumed.
+				msg, err := k.reader.FetchMessage(ctx)
 				if err != nil {
 					return nil, fmt.Errorf("failed to read message: %w", err)
 				}
@@ -78,11 +83,21 @@ func (k *kafkaConsumer) Consume(ctx context.Context) error {
 				var task models.Task
 				if err := json.Unmarshal(msg.Value, &task); err != nil {
 					k.logger.Errorf("Failed to unmarshal task: %v", err)
-					return nil, nil // Skip bad messages
+					return nil, k.commit(ctx, msg) // Skip bad messages
 				}
 
-				k.logger.Infof("Я ПРИНЯЛ ТАСКУ [ID: %s], ГОТОВ К ГЕНЕРАЦИИ ДАННЫХ", task.ID)
-				return nil, nil
+				k.logger.Infof("Я ПРИНЯЛ ТАСКУ [ID: %d, TaskID: %s], НАЧИНАЮ ГЕНЕРАЦИЮ ДАННЫХ", task.ID, task.TaskID)
+				if err := k.processor.Process(ctx, task); err != nil {
+					if ctx.Err() != nil {
+						k.logger.Infof("Task %s interrupted by shutdown, leaving it to be redelivered", task.TaskID)
+						return nil, nil
+					}
+					k.logger.Errorf("Failed to process task %s: %v", task.TaskID, err)
+					return nil, k.commit(ctx, msg) // Processing errors must not trip the breaker
+				}
+
+				k.logger.Infof("Task %s processed successfully", task.TaskID)
+				return nil, k.commit(ctx, msg)
 			})
 
 			if err != nil {
@@ -93,6 +108,13 @@ func (k *kafkaConsumer) Consume(ctx context.Context) error {
 	}
 }
 
+func (k *kafkaConsumer) commit(ctx context.Context, msg kafka.Message) error {
+	if err := k.reader.CommitMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to commit message: %w", err)
+	}
+	return nil
+}
+
 func (k *kafkaConsumer) Close() error {
 	if k.reader != nil {
 		if err := k.reader.Close(); err != nil {
@@ -0,0 +1,91 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"worker-service/internal/config"
+	"worker-service/internal/models"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+// StatusProducer publishes task status events to Kafka.
+type StatusProducer interface {
+	Report(ctx context.Context, event models.StatusEvent) error
+	Close() error
+}
+
+type statusProducer struct {
+	writer *kafka.Writer
+	logger *zap.SugaredLogger
+	cb     *gobreaker.CircuitBreaker
+}
+
+// NewStatusProducer creates a producer for the task status topic.
+func NewStatusProducer(cfg config.KafkaConfig, logger *zap.SugaredLogger) StatusProducer {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "kafka-status-producer",
+		MaxRequests: 1,
+		Interval:    30 * time.Second,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= 3
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			logger.Infof("circuit breaker %s state changed from %s to %s", name, from.String(), to.String())
+		},
+	})
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(strings.Split(cfg.Brokers, ",")...),
+		Topic:                  cfg.StatusTopic,
+		Balancer:               &kafka.Hash{}, // Keep events of one task ordered
+		RequiredAcks:           kafka.RequireAll,
+		MaxAttempts:            cfg.MaxRetries,
+		BatchTimeout:           100 * time.Millisecond,
+		AllowAutoTopicCreation: true,
+	}
+
+	return &statusProducer{
+		writer: writer,
+		logger: logger,
+		cb:     cb,
+	}
+}
+
+func (s *statusProducer) Report(ctx context.Context, event models.StatusEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status event: %w", err)
+	}
+
+	_, err = s.cb.Execute(func() (interface{}, error) {
+		return nil, s.writer.WriteMessages(ctx, kafka.Message{
+			Key:   []byte(event.TaskID),
+			Value: data,
+		})
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to publish status %s for task %s: %v", event.Status, event.TaskID, err)
+		return err
+	}
+	return nil
+}
+
+func (s *statusProducer) Close() error {
+	if s.writer != nil {
+		err := s.writer.Close()
+		s.logger.Info("Kafka status producer connection closed")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type localStore struct {
+	dir    string
+	logger *zap.SugaredLogger
+}
+
+// NewLocalStore creates an ArtifactStore backed by a directory on the local filesystem.
+func NewLocalStore(dir string, logger *zap.SugaredLogger) (ArtifactStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory %s: %w", dir, err)
+	}
+	return &localStore{dir: dir, logger: logger}, nil
+}
+
+func (s *localStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	if _, err := s.publish(path, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Infof("Stored artifact %s (%d bytes) at %s", key, size, path)
+	return nil
+}
+
+func (s *localStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artifact %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func (s *localStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete artifact %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *localStore) Compose(ctx context.Context, key string, parts []string, contentType string) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	size, err := s.publish(path, func(w io.Writer) error {
+		for _, part := range parts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			r, err := s.Get(ctx, part)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(w, r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Infof("Stored artifact %s (%d bytes, %d parts) at %s", key, size, len(parts), path)
+	return size, nil
+}
+
+// publish writes a temporary file with write and renames it to path, so readers never
+// observe a partial artifact. It returns the number of bytes written.
+func (s *localStore) publish(path string, write func(w io.Writer) error) (int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create artifact file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := write(tmp); err != nil {
+		return 0, fmt.Errorf("failed to write artifact: %w", err)
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close artifact file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to publish artifact: %w", err)
+	}
+	return size, nil
+}
+
+// path resolves key inside the storage directory, rejecting keys that escape it.
+func (s *localStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if strings.Contains(key, "..") || clean == "/" {
+		return "", fmt.Errorf("invalid artifact key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
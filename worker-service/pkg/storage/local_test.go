@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLocalStore_Put(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir, zap.NewNop().Sugar())
+	require.NoError(t, err)
+
+	err = store.Put(context.Background(), "results/task-1.csv", strings.NewReader("a,b\r\n"), 5, "text/csv")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "results", "task-1.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\r\n", string(data))
+}
+
+func TestLocalStore_RejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), zap.NewNop().Sugar())
+	require.NoError(t, err)
+
+	for _, key := range []string{"../secret", "results/../../x", ""} {
+		err := store.Put(context.Background(), key, strings.NewReader("x"), 1, "text/plain")
+		assert.Error(t, err, key)
+	}
+}
+
+func TestLocalStore_GetDeleteCompose(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), zap.NewNop().Sugar())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = store.Get(ctx, "chunks/task-1/000001")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for i, part := range []string{"[\n1", ",\n2", "\n]\n"} {
+		key := "chunks/task-1/" + strconv.Itoa(i)
+		require.NoError(t, store.Put(ctx, key, strings.NewReader(part), int64(len(part)), "application/json"))
+	}
+	size, err := store.Compose(ctx, "results/task-1.json", []string{"chunks/task-1/0", "chunks/task-1/1", "chunks/task-1/2"}, "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), size)
+
+	r, err := store.Get(ctx, "results/task-1.json")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "[\n1,\n2\n]\n", string(data))
+
+	require.NoError(t, store.Delete(ctx, "chunks/task-1/0"))
+	require.NoError(t, store.Delete(ctx, "chunks/task-1/0"))
+	_, err = store.Get(ctx, "chunks/task-1/0")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"worker-service/internal/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.uber.org/zap"
+)
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+	logger *zap.SugaredLogger
+}
+
+// NewS3Store creates an ArtifactStore backed by an S3-compatible bucket (AWS S3, MinIO, ...).
+// The bucket is created if it does not exist.
+func NewS3Store(ctx context.Context, cfg config.StorageConfig, logger *zap.SugaredLogger) (ArtifactStore, error) {
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.S3Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket %s: %w", cfg.S3Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.S3Bucket, minio.MakeBucketOptions{Region: cfg.S3Region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", cfg.S3Bucket, err)
+		}
+		logger.Infof("Created bucket %s", cfg.S3Bucket)
+	}
+
+	return &s3Store{client: client, bucket: cfg.S3Bucket, logger: logger}, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	info, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", key, err)
+	}
+
+	s.logger.Infof("Stored artifact %s (%d bytes) in bucket %s", key, info.Size, s.bucket)
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err == nil {
+		// GetObject is lazy; Stat surfaces a missing key.
+		_, err = obj.Stat()
+	}
+	if err != nil {
+		if obj != nil {
+			obj.Close()
+		}
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to get artifact %s: %w", key, err)
+	}
+	return obj, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete artifact %s: %w", key, err)
+	}
+	return nil
+}
+
+// minComposePart is the smallest part S3 accepts in a server-side compose, except for the last one.
+const minComposePart = 5 << 20
+
+// Compose concatenates the parts server-side. S3 requires every part but the last to be
+// at least 5 MiB; parts that are smaller are concatenated by streaming them through the
+// worker instead.
+func (s *s3Store) Compose(ctx context.Context, key string, parts []string, contentType string) (int64, error) {
+	if len(parts) == 0 {
+		return 0, errors.New("compose requires at least one part")
+	}
+	var total int64
+	small := false
+	for i, part := range parts {
+		info, err := s.client.StatObject(ctx, s.bucket, part, minio.StatObjectOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat part %s: %w", part, err)
+		}
+		total += info.Size
+		small = small || (i < len(parts)-1 && info.Size < minComposePart)
+	}
+	if small {
+		return s.concat(ctx, key, parts, total, contentType)
+	}
+
+	srcs := make([]minio.CopySrcOptions, len(parts))
+	for i, part := range parts {
+		srcs[i] = minio.CopySrcOptions{Bucket: s.bucket, Object: part}
+	}
+	dst := minio.CopyDestOptions{
+		Bucket:          s.bucket,
+		Object:          key,
+		UserMetadata:    map[string]string{"Content-Type": contentType},
+		ReplaceMetadata: true,
+	}
+
+	info, err := s.client.ComposeObject(ctx, dst, srcs...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compose artifact %s: %w", key, err)
+	}
+
+	s.logger.Infof("Stored artifact %s (%d bytes, %d parts) in bucket %s", key, info.Size, len(parts), s.bucket)
+	return info.Size, nil
+}
+
+// concat uploads the concatenation of parts, size bytes in total, under key.
+func (s *s3Store) concat(ctx context.Context, key string, parts []string, size int64, contentType string) (int64, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		for _, part := range parts {
+			r, err := s.Get(ctx, part)
+			if err == nil {
+				_, err = io.Copy(pw, r)
+				r.Close()
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	info, err := s.client.PutObject(ctx, s.bucket, key, pr, size, minio.PutObjectOptions{ContentType: contentType})
+	// Unblock the copying goroutine if the upload stopped reading early.
+	pr.CloseWithError(errors.New("upload finished"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to concatenate artifact %s: %w", key, err)
+	}
+
+	s.logger.Infof("Stored artifact %s (%d bytes, %d parts) in bucket %s", key, info.Size, len(parts), s.bucket)
+	return info.Size, nil
+}
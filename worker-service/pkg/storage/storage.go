@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"worker-service/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// ErrNotFound is returned by Get for a key that is not stored.
+var ErrNotFound = errors.New("artifact not found")
+
+// ArtifactStore persists generated task output.
+type ArtifactStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens a stored object; the caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes an object; deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// Compose stores the concatenation of parts under key and returns its size.
+	// The parts are left in place.
+	Compose(ctx context.Context, key string, parts []string, contentType string) (int64, error)
+}
+
+// New creates the artifact store selected by cfg.Backend.
+func New(ctx context.Context, cfg config.StorageConfig, logger *zap.SugaredLogger) (ArtifactStore, error) {
+	switch cfg.Backend {
+	case config.StorageLocal:
+		return NewLocalStore(cfg.LocalDir, logger)
+	case config.StorageS3:
+		return NewS3Store(ctx, cfg, logger)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}